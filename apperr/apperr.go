@@ -0,0 +1,138 @@
+// Package apperr classifies dtako_mod errors so handlers can map them to
+// HTTP status codes. Services and repositories declare their sentinel
+// errors with New and wrap them with fmt.Errorf("%w: ...") as before;
+// KindOf finds the kind anywhere in the chain and also recognizes
+// database driver errors (not found rows, connection failures, duplicate keys).
+package apperr
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Kind is the category of an error
+type Kind int
+
+const (
+	// Internal is any error that is not classified (a bug or unexpected failure)
+	Internal Kind = iota
+	// NotFound means the requested record does not exist
+	NotFound
+	// Validation means the request parameters or body are invalid
+	Validation
+	// Conflict means the request conflicts with existing data
+	Conflict
+	// Unavailable means a database (usually production) cannot be reached
+	Unavailable
+	// Unauthenticated means the request has no or invalid credentials
+	Unauthenticated
+	// Forbidden means the caller may not perform the request
+	Forbidden
+)
+
+// String returns the kind name used in problem types
+func (k Kind) String() string {
+	switch k {
+	case NotFound:
+		return "not-found"
+	case Validation:
+		return "validation"
+	case Conflict:
+		return "conflict"
+	case Unavailable:
+		return "upstream-unavailable"
+	case Unauthenticated:
+		return "unauthenticated"
+	case Forbidden:
+		return "forbidden"
+	default:
+		return "internal"
+	}
+}
+
+// Error is a sentinel error of a kind
+type Error struct {
+	kind Kind
+	msg  string
+}
+
+// New returns a sentinel error of kind with message msg
+func New(kind Kind, msg string) *Error {
+	return &Error{kind: kind, msg: msg}
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+// Kind returns the kind of e
+func (e *Error) Kind() Kind {
+	return e.kind
+}
+
+// Generic sentinels for errors that have no more specific one
+var (
+	ErrNotFound    = New(NotFound, "not found")
+	ErrValidation  = New(Validation, "invalid request")
+	ErrConflict    = New(Conflict, "conflict")
+	ErrUnavailable = New(Unavailable, "database unavailable")
+)
+
+// MySQL server errors that mean the database cannot be used
+var unavailableMySQLErrors = map[uint16]bool{
+	1040: true, // Too many connections
+	1045: true, // Access denied
+	1049: true, // Unknown database
+	1053: true, // Server shutdown in progress
+}
+
+// mysqlDuplicateEntry is the MySQL error for a duplicate key
+const mysqlDuplicateEntry = 1062
+
+// KindOf returns the kind of err: that of the first *Error in its chain,
+// otherwise derived from database driver errors, otherwise Internal.
+func KindOf(err error) Kind {
+	if err == nil {
+		return Internal
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return e.kind
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return NotFound
+	}
+	if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, mysql.ErrInvalidConn) {
+		return Unavailable
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return Unavailable
+	}
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		if myErr.Number == mysqlDuplicateEntry {
+			return Conflict
+		}
+		if unavailableMySQLErrors[myErr.Number] {
+			return Unavailable
+		}
+	}
+	return Internal
+}
+
+// IsTyped reports whether err wraps an *Error, i.e. its message was written
+// by this module rather than coming from a driver
+func IsTyped(err error) bool {
+	var e *Error
+	return errors.As(err, &e)
+}
@@ -0,0 +1,53 @@
+package apperr
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestKindOf(t *testing.T) {
+	errRowMissing := New(NotFound, "row not found")
+
+	tests := []struct {
+		name string
+		err  error
+		want Kind
+	}{
+		{"nil", nil, Internal},
+		{"sentinel", errRowMissing, NotFound},
+		{"wrapped sentinel", fmt.Errorf("%w: 42", errRowMissing), NotFound},
+		{"sentinel wins over the driver error", fmt.Errorf("%w: %w", ErrValidation, sql.ErrNoRows), Validation},
+		{"no rows", fmt.Errorf("scan: %w", sql.ErrNoRows), NotFound},
+		{"deadline", context.DeadlineExceeded, Unavailable},
+		{"bad connection", driver.ErrBadConn, Unavailable},
+		{"invalid connection", mysql.ErrInvalidConn, Unavailable},
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, Unavailable},
+		{"duplicate key", &mysql.MySQLError{Number: 1062}, Conflict},
+		{"access denied", &mysql.MySQLError{Number: 1045}, Unavailable},
+		{"unknown table", &mysql.MySQLError{Number: 1146}, Internal},
+		{"plain error", errors.New("boom"), Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KindOf(tt.err); got != tt.want {
+				t.Errorf("KindOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsTyped(t *testing.T) {
+	if !IsTyped(fmt.Errorf("%w: x", ErrConflict)) {
+		t.Error("wrapped sentinel is not typed")
+	}
+	if IsTyped(&mysql.MySQLError{Number: 1062}) {
+		t.Error("driver error is typed")
+	}
+}
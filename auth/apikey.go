@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// APIKeyHeader is the request header carrying an API key
+const APIKeyHeader = "X-API-Key"
+
+// APIKey is an entry of the API key file. Only the SHA-256 of the key is
+// stored, so the file does not need to be kept secret from readers of the host.
+type APIKey struct {
+	Name string `json:"name"`
+	// KeySHA256 is the hex SHA-256 of the key
+	KeySHA256 string `json:"key_sha256"`
+	Role      string `json:"role"`
+	// Offices are the 事業所CD the key may see; empty means all offices
+	Offices []string `json:"offices,omitempty"`
+}
+
+type apiKeyEntry struct {
+	hash      []byte
+	principal Principal
+}
+
+// APIKeyAuthenticator verifies the X-API-Key header against a fixed set of keys
+type APIKeyAuthenticator struct {
+	keys []apiKeyEntry
+}
+
+// NewAPIKeyAuthenticator validates keys (hash, role) and returns their authenticator
+func NewAPIKeyAuthenticator(keys []APIKey) (*APIKeyAuthenticator, error) {
+	a := &APIKeyAuthenticator{}
+	for i, k := range keys {
+		hash, err := hex.DecodeString(k.KeySHA256)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("api key %d (%s): key_sha256 must be a hex SHA-256", i, k.Name)
+		}
+		role, ok := ParseRole(k.Role)
+		if !ok {
+			return nil, fmt.Errorf("api key %d (%s): unknown role %q", i, k.Name, k.Role)
+		}
+		a.keys = append(a.keys, apiKeyEntry{
+			hash:      hash,
+			principal: Principal{Subject: k.Name, Role: role, Offices: k.Offices},
+		})
+	}
+	return a, nil
+}
+
+// LoadAPIKeys reads a JSON array of APIKey from path
+func LoadAPIKeys(path string) ([]APIKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read API keys: %w", err)
+	}
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("parse API keys %s: %w", path, err)
+	}
+	return keys, nil
+}
+
+// Authenticate implements Authenticator
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	key := strings.TrimSpace(r.Header.Get(APIKeyHeader))
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	// 全件を定数時間で比較する（一致位置で時間が変わらないように）
+	var found *Principal
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(sum[:], a.keys[i].hash) == 1 && found == nil {
+			found = &a.keys[i].principal
+		}
+	}
+	if found == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	p := *found
+	return &p, nil
+}
@@ -0,0 +1,190 @@
+// Package auth authenticates dtako_mod callers and carries who they are
+// (subject, role and the 事業所CD they may see) in the request context.
+// Callers are verified with JWT bearer tokens signed by a key of a local
+// JWKS file or with API keys; the host can plug in its own Authenticator
+// with SetAuthenticator. When nothing is configured every request is
+// rejected; running without authentication (all roles, all offices) must be
+// requested with AUTH_DISABLED=true or SetAuthenticator(nil).
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/yhonda-ohishi/dtako_mod/apperr"
+	"github.com/yhonda-ohishi/dtako_mod/logging"
+)
+
+var (
+	// ErrNoCredentials is returned when a request carries no credentials
+	ErrNoCredentials = apperr.New(apperr.Unauthenticated, "missing credentials")
+	// ErrInvalidCredentials is returned for a bad token or API key
+	ErrInvalidCredentials = apperr.New(apperr.Unauthenticated, "invalid credentials")
+	// ErrForbidden is returned when the caller's role or office scope does not allow the request
+	ErrForbidden = apperr.New(apperr.Forbidden, "forbidden")
+)
+
+// Role is what a caller may do. Each role includes the ones below it.
+type Role int
+
+const (
+	// RoleNone is the zero value; it is never granted
+	RoleNone Role = iota
+	// RoleViewer may read (list, get, reports)
+	RoleViewer
+	// RoleOperator may also import and sync from production
+	RoleOperator
+	// RoleAdmin may also change master data, event types and geofences
+	RoleAdmin
+)
+
+// String returns the role name used in tokens and API key files
+func (r Role) String() string {
+	switch r {
+	case RoleViewer:
+		return "viewer"
+	case RoleOperator:
+		return "operator"
+	case RoleAdmin:
+		return "admin"
+	default:
+		return "none"
+	}
+}
+
+// ParseRole parses viewer / operator / admin (case-insensitive)
+func ParseRole(s string) (Role, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "viewer":
+		return RoleViewer, true
+	case "operator":
+		return RoleOperator, true
+	case "admin":
+		return RoleAdmin, true
+	default:
+		return RoleNone, false
+	}
+}
+
+// Principal is an authenticated caller
+type Principal struct {
+	// Subject is the token subject or API key name
+	Subject string
+	Role    Role
+	// Offices are the 事業所CD the caller may see; empty means all offices
+	Offices []string
+}
+
+// Allows reports whether p has at least role
+func (p *Principal) Allows(role Role) bool {
+	return p.Role >= role
+}
+
+// AllOffices reports whether p is not restricted to some offices
+func (p *Principal) AllOffices() bool {
+	return len(p.Offices) == 0
+}
+
+// Authenticator identifies the caller of a request
+type Authenticator interface {
+	// Authenticate returns the caller of r. It returns ErrNoCredentials when r
+	// carries none of the credentials it handles, so authenticators can be chained.
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Chain tries each authenticator in turn until one finds credentials it handles
+type Chain []Authenticator
+
+// Authenticate implements Authenticator
+func (c Chain) Authenticate(r *http.Request) (*Principal, error) {
+	for _, a := range c {
+		p, err := a.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return p, err
+	}
+	return nil, ErrNoCredentials
+}
+
+var (
+	mu      sync.RWMutex
+	current Authenticator
+	loaded  bool
+)
+
+// Current returns the authenticator used by the dtako routes: the one set
+// with SetAuthenticator, otherwise the one configured by the environment
+// (see FromEnv). nil means authentication is disabled.
+func Current() Authenticator {
+	mu.RLock()
+	if loaded {
+		defer mu.RUnlock()
+		return current
+	}
+	mu.RUnlock()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !loaded {
+		current = loadFromEnv()
+		loaded = true
+	}
+	return current
+}
+
+// SetAuthenticator replaces the authenticator, e.g. with the host
+// application's. nil disables authentication.
+func SetAuthenticator(a Authenticator) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = a
+	loaded = true
+}
+
+// loadFromEnv builds the authenticator from the environment. A broken or
+// missing configuration rejects every request instead of silently allowing them.
+func loadFromEnv() Authenticator {
+	a, err := FromEnv()
+	if err != nil {
+		logging.Logger().Error("auth configuration failed, rejecting all requests", "error", err)
+		return rejectAll{}
+	}
+	if a == nil {
+		logging.Logger().Warn("authentication disabled by AUTH_DISABLED: every dtako route is reachable anonymously")
+	}
+	return a
+}
+
+// rejectAll is used when the auth configuration cannot be loaded
+type rejectAll struct{}
+
+func (a rejectAll) Authenticate(*http.Request) (*Principal, error) {
+	return nil, ErrInvalidCredentials
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the caller of the request, if authentication is enabled
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// OfficeScope returns the 事業所CD the request may see. restricted is false
+// when every office may be seen (unrestricted caller or authentication disabled).
+func OfficeScope(ctx context.Context) (offices []string, restricted bool) {
+	p, ok := FromContext(ctx)
+	if !ok || p.AllOffices() {
+		return nil, false
+	}
+	return p.Offices, true
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+)
+
+// ErrNotConfigured means no authenticator is configured and authentication
+// was not explicitly disabled
+var ErrNotConfigured = errors.New("no authenticator configured: set AUTH_JWKS_FILE, AUTH_API_KEYS_FILE or AUTH_DISABLED=true")
+
+// FromEnv builds the authenticator configured by the environment (.env is
+// read as by config.Load):
+//
+//	AUTH_JWKS_FILE      JWK Set with the token signing keys (enables bearer tokens)
+//	AUTH_JWT_ISSUER     required iss, if set
+//	AUTH_JWT_AUDIENCE   required aud, if set
+//	AUTH_ROLE_CLAIM     claim with the role (default "role")
+//	AUTH_OFFICE_CLAIM   claim with the allowed 事業所CD (default "office_cds")
+//	AUTH_JWT_MISSING_OFFICE_CLAIM_ALL_OFFICES
+//	                    "true" to let tokens without the office claim see all
+//	                    offices (otherwise they are rejected)
+//	AUTH_API_KEYS_FILE  JSON array of APIKey (enables X-API-Key)
+//	AUTH_DISABLED       "true" to run without authentication
+//
+// It returns nil only when AUTH_DISABLED is true, i.e. authentication is
+// disabled. Without AUTH_DISABLED, setting neither file is ErrNotConfigured.
+func FromEnv() (Authenticator, error) {
+	godotenv.Load()
+
+	if disabled, _ := strconv.ParseBool(os.Getenv("AUTH_DISABLED")); disabled {
+		return nil, nil
+	}
+
+	var chain Chain
+
+	if path := os.Getenv("AUTH_JWKS_FILE"); path != "" {
+		cfg := JWTConfig{
+			JWKSFile:    path,
+			Issuer:      os.Getenv("AUTH_JWT_ISSUER"),
+			Audience:    os.Getenv("AUTH_JWT_AUDIENCE"),
+			RoleClaim:   os.Getenv("AUTH_ROLE_CLAIM"),
+			OfficeClaim: os.Getenv("AUTH_OFFICE_CLAIM"),
+		}
+		cfg.MissingOfficeClaimAllOffices, _ = strconv.ParseBool(os.Getenv("AUTH_JWT_MISSING_OFFICE_CLAIM_ALL_OFFICES"))
+		a, err := NewJWTAuthenticator(cfg)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, a)
+	}
+
+	if path := os.Getenv("AUTH_API_KEYS_FILE"); path != "" {
+		keys, err := LoadAPIKeys(path)
+		if err != nil {
+			return nil, err
+		}
+		a, err := NewAPIKeyAuthenticator(keys)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, a)
+	}
+
+	if len(chain) == 0 {
+		return nil, ErrNotConfigured
+	}
+	return chain, nil
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFromEnvFailsClosed(t *testing.T) {
+	t.Setenv("AUTH_JWKS_FILE", "")
+	t.Setenv("AUTH_API_KEYS_FILE", "")
+
+	t.Run("nothing configured", func(t *testing.T) {
+		t.Setenv("AUTH_DISABLED", "")
+
+		a, err := FromEnv()
+		if !errors.Is(err, ErrNotConfigured) {
+			t.Fatalf("FromEnv() error = %v, want ErrNotConfigured", err)
+		}
+		if a != nil {
+			t.Errorf("FromEnv() = %v, want nil", a)
+		}
+	})
+
+	t.Run("missing configuration rejects every request", func(t *testing.T) {
+		t.Setenv("AUTH_DISABLED", "")
+
+		a := loadFromEnv()
+		if a == nil {
+			t.Fatal("loadFromEnv() = nil, want an authenticator rejecting all requests")
+		}
+		if _, err := a.Authenticate(httptest.NewRequest("GET", "/rows", nil)); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Authenticate() error = %v, want ErrInvalidCredentials", err)
+		}
+	})
+
+	t.Run("explicitly disabled", func(t *testing.T) {
+		t.Setenv("AUTH_DISABLED", "true")
+
+		a, err := FromEnv()
+		if err != nil || a != nil {
+			t.Errorf("FromEnv() = %v, %v, want nil, nil", a, err)
+		}
+	})
+
+	t.Run("only true disables", func(t *testing.T) {
+		t.Setenv("AUTH_DISABLED", "no")
+
+		if _, err := FromEnv(); !errors.Is(err, ErrNotConfigured) {
+			t.Errorf("FromEnv() error = %v, want ErrNotConfigured", err)
+		}
+	})
+}
@@ -0,0 +1,339 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/yhonda-ohishi/dtako_mod/logging"
+)
+
+// Default claim names of JWTConfig
+const (
+	DefaultRoleClaim   = "role"
+	DefaultOfficeClaim = "office_cds"
+)
+
+// jwtLeeway is the clock skew allowed for exp / nbf / iat
+const jwtLeeway = 30 * time.Second
+
+// JWTConfig configures bearer token verification
+type JWTConfig struct {
+	// JWKSFile is the path of a JWK Set (RFC 7517) with the public signing keys
+	JWKSFile string
+	// Issuer and Audience are checked when set
+	Issuer   string
+	Audience string
+	// RoleClaim names the claim with viewer / operator / admin (default "role")
+	RoleClaim string
+	// OfficeClaim names the claim with the allowed 事業所CD as an array or a
+	// comma-separated string (default "office_cds"). Tokens without it are
+	// rejected unless MissingOfficeClaimAllOffices is set.
+	OfficeClaim string
+	// MissingOfficeClaimAllOffices lets tokens without the office claim see
+	// all offices. Only set it when the issuer adds the claim to every token
+	// that is restricted to some offices.
+	MissingOfficeClaimAllOffices bool
+}
+
+// JWTAuthenticator verifies "Authorization: Bearer" tokens with the keys of a
+// local JWKS file. The file is read again when a token names an unknown kid,
+// so keys can be rotated without a restart.
+type JWTAuthenticator struct {
+	cfg    JWTConfig
+	parser *jwt.Parser
+
+	mu      sync.RWMutex
+	keys    map[string]crypto.PublicKey
+	modTime time.Time
+}
+
+// NewJWTAuthenticator loads the JWKS file of cfg
+func NewJWTAuthenticator(cfg JWTConfig) (*JWTAuthenticator, error) {
+	if cfg.RoleClaim == "" {
+		cfg.RoleClaim = DefaultRoleClaim
+	}
+	if cfg.OfficeClaim == "" {
+		cfg.OfficeClaim = DefaultOfficeClaim
+	}
+
+	opts := []jwt.ParserOption{
+		// 非対称鍵のみ許可（HS256 や none は使わせない）
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(jwtLeeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	a := &JWTAuthenticator{cfg: cfg, parser: jwt.NewParser(opts...)}
+	if err := a.reload(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Authenticate implements Authenticator
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	header := r.Header.Get("Authorization")
+	scheme, tokenString, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrNoCredentials
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := a.parser.ParseWithClaims(strings.TrimSpace(tokenString), claims, a.keyFunc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	roleName, _ := claims[a.cfg.RoleClaim].(string)
+	role, ok := ParseRole(roleName)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidCredentials, roleName)
+	}
+	offices, err := officeClaim(claims[a.cfg.OfficeClaim], a.cfg.MissingOfficeClaimAllOffices)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	subject, _ := claims.GetSubject()
+
+	return &Principal{Subject: subject, Role: role, Offices: offices}, nil
+}
+
+// keyFunc selects the verification key by kid; a token without kid is
+// accepted only when the JWKS has a single key
+func (a *JWTAuthenticator) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := a.lookup(kid)
+	if !ok && kid != "" {
+		if err := a.reloadIfModified(); err != nil {
+			logging.Logger().Error("JWKS reload failed", "file", a.cfg.JWKSFile, "error", err)
+		}
+		key, ok = a.lookup(kid)
+	}
+	if !ok {
+		return nil, fmt.Errorf("no key for kid %q", kid)
+	}
+	return key, nil
+}
+
+func (a *JWTAuthenticator) lookup(kid string) (crypto.PublicKey, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if kid == "" {
+		if len(a.keys) != 1 {
+			return nil, false
+		}
+		for _, key := range a.keys {
+			return key, true
+		}
+	}
+	key, ok := a.keys[kid]
+	return key, ok
+}
+
+func (a *JWTAuthenticator) reloadIfModified() error {
+	info, err := os.Stat(a.cfg.JWKSFile)
+	if err != nil {
+		return err
+	}
+	a.mu.RLock()
+	unchanged := info.ModTime().Equal(a.modTime)
+	a.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+	return a.reload()
+}
+
+func (a *JWTAuthenticator) reload() error {
+	info, err := os.Stat(a.cfg.JWKSFile)
+	if err != nil {
+		return fmt.Errorf("read JWKS: %w", err)
+	}
+	data, err := os.ReadFile(a.cfg.JWKSFile)
+	if err != nil {
+		return fmt.Errorf("read JWKS: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("parse JWKS %s: %w", a.cfg.JWKSFile, err)
+	}
+
+	a.mu.Lock()
+	a.keys = keys
+	a.modTime = info.ModTime()
+	a.mu.Unlock()
+	return nil
+}
+
+// jwk is the subset of RFC 7517/7518/8037 members needed for public signing keys
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS returns the signing keys of a JWK Set by kid. Encryption keys
+// (use "enc") are skipped; a set without usable keys is an error.
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %d (kid %q): %w", i, k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("n: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("e: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// officeClaim reads the allowed 事業所CD from an array of strings/numbers or
+// a comma-separated string. A missing claim is rejected unless
+// missingAllOffices is set, then it means all offices; a claim that is
+// present but empty is always rejected rather than widened to all offices.
+func officeClaim(v interface{}, missingAllOffices bool) ([]string, error) {
+	var offices []string
+	switch v := v.(type) {
+	case nil:
+		if missingAllOffices {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("missing office claim")
+	case string:
+		offices = splitOffices(v)
+	case []interface{}:
+		for _, item := range v {
+			switch item := item.(type) {
+			case string:
+				if s := strings.TrimSpace(item); s != "" {
+					offices = append(offices, s)
+				}
+			case float64:
+				offices = append(offices, strconv.FormatFloat(item, 'f', -1, 64))
+			default:
+				return nil, fmt.Errorf("invalid office code %v", item)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid office claim")
+	}
+	if len(offices) == 0 {
+		return nil, fmt.Errorf("empty office claim")
+	}
+	return offices, nil
+}
+
+func splitOffices(s string) []string {
+	var offices []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			offices = append(offices, part)
+		}
+	}
+	return offices
+}
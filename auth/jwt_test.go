@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testKey is a P-256 signing key with its kid
+type testKey struct {
+	kid string
+	key *ecdsa.PrivateKey
+}
+
+func newTestKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKey{kid: kid, key: key}
+}
+
+// writeJWKS writes the public keys as a JWK Set and sets its mtime to at
+func writeJWKS(t *testing.T, path string, at time.Time, keys ...testKey) {
+	t.Helper()
+	coord := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	set := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	for _, k := range keys {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "EC", Kid: k.kid, Use: "sig", Crv: "P-256",
+			X: coord(k.key.X.FillBytes(make([]byte, 32))),
+			Y: coord(k.key.Y.FillBytes(make([]byte, 32))),
+		})
+	}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, at, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (k testKey) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = k.kid
+	s, err := token.SignedString(k.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":        "alice",
+		"role":       "viewer",
+		"office_cds": []interface{}{"10", 20.0},
+		"exp":        time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func authenticateBearer(a Authenticator, token string) (*Principal, error) {
+	r := httptest.NewRequest("GET", "/dtako/rows", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	return a.Authenticate(r)
+}
+
+func newTestJWTAuthenticator(t *testing.T, cfg JWTConfig, keys ...testKey) *JWTAuthenticator {
+	t.Helper()
+	cfg.JWKSFile = filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, cfg.JWKSFile, time.Now().Add(-time.Hour), keys...)
+	a, err := NewJWTAuthenticator(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	k1 := newTestKey(t, "k1")
+	a := newTestJWTAuthenticator(t, JWTConfig{}, k1)
+
+	t.Run("valid token", func(t *testing.T) {
+		p, err := authenticateBearer(a, k1.sign(t, validClaims()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &Principal{Subject: "alice", Role: RoleViewer, Offices: []string{"10", "20"}}
+		if !reflect.DeepEqual(p, want) {
+			t.Errorf("Authenticate() = %+v, want %+v", p, want)
+		}
+	})
+
+	t.Run("symmetric and unsigned algorithms are rejected", func(t *testing.T) {
+		hs := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims())
+		hs.Header["kid"] = "k1"
+		hsToken, err := hs.SignedString([]byte("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		none := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims())
+		none.Header["kid"] = "k1"
+		noneToken, err := none.SignedString(jwt.UnsafeAllowNoneSignatureType)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for name, token := range map[string]string{"HS256": hsToken, "none": noneToken} {
+			if _, err := authenticateBearer(a, token); !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("%s: error = %v, want ErrInvalidCredentials", name, err)
+			}
+		}
+	})
+
+	t.Run("unknown kid is rejected", func(t *testing.T) {
+		other := newTestKey(t, "k2")
+		if _, err := authenticateBearer(a, other.sign(t, validClaims())); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("error = %v, want ErrInvalidCredentials", err)
+		}
+	})
+
+	t.Run("missing exp is rejected", func(t *testing.T) {
+		claims := validClaims()
+		delete(claims, "exp")
+		if _, err := authenticateBearer(a, k1.sign(t, claims)); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("error = %v, want ErrInvalidCredentials", err)
+		}
+	})
+}
+
+func TestJWTAuthenticatorReloadsJWKS(t *testing.T) {
+	k1, k2 := newTestKey(t, "k1"), newTestKey(t, "k2")
+	a := newTestJWTAuthenticator(t, JWTConfig{}, k1)
+
+	token := k2.sign(t, validClaims())
+	if _, err := authenticateBearer(a, token); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("before rotation: error = %v, want ErrInvalidCredentials", err)
+	}
+
+	// 鍵のローテーション: 新しい kid のトークンを受けたときに読み直す
+	writeJWKS(t, a.cfg.JWKSFile, time.Now(), k1, k2)
+	if _, err := authenticateBearer(a, token); err != nil {
+		t.Errorf("after rotation: error = %v, want the new key to be accepted", err)
+	}
+	if _, err := authenticateBearer(a, k1.sign(t, validClaims())); err != nil {
+		t.Errorf("after rotation: error = %v, want the old key to stay valid", err)
+	}
+}
+
+func TestJWTOfficeClaim(t *testing.T) {
+	k1 := newTestKey(t, "k1")
+
+	tests := []struct {
+		name        string
+		cfg         JWTConfig
+		offices     interface{} // nil removes the claim
+		wantOffices []string
+		wantErr     bool
+	}{
+		{name: "array", offices: []interface{}{"10", 20.0}, wantOffices: []string{"10", "20"}},
+		{name: "comma separated", offices: "10, 20", wantOffices: []string{"10", "20"}},
+		{name: "missing", offices: nil, wantErr: true},
+		{name: "missing with all offices default", cfg: JWTConfig{MissingOfficeClaimAllOffices: true}, offices: nil, wantOffices: nil},
+		{name: "empty string", offices: "", wantErr: true},
+		{name: "empty array", offices: []interface{}{}, wantErr: true},
+		{name: "empty with all offices default", cfg: JWTConfig{MissingOfficeClaimAllOffices: true}, offices: " , ", wantErr: true},
+		{name: "invalid item", offices: []interface{}{true}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestJWTAuthenticator(t, tt.cfg, k1)
+			claims := validClaims()
+			delete(claims, "office_cds")
+			if tt.offices != nil {
+				claims["office_cds"] = tt.offices
+			}
+
+			p, err := authenticateBearer(a, k1.sign(t, claims))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidCredentials) {
+					t.Errorf("error = %v, want ErrInvalidCredentials", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(p.Offices, tt.wantOffices) {
+				t.Errorf("Offices = %v, want %v", p.Offices, tt.wantOffices)
+			}
+		})
+	}
+}
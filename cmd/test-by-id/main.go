@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"time"
 
@@ -20,7 +21,7 @@ func main() {
 
 	// Test the GetByID method
 	start := time.Now()
-	result, err := repo.GetByID(testID)
+	result, err := repo.GetByID(context.Background(), testID)
 	elapsed := time.Since(start)
 
 	if err != nil {
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"log"
 	"time"
 
+	"github.com/yhonda-ohishi/dtako_mod/models"
 	"github.com/yhonda-ohishi/dtako_mod/repositories"
 )
 
@@ -21,7 +23,7 @@ func main() {
 
 	// Test the actual method
 	start := time.Now()
-	results, err := repo.GetByDateRange(from, to, "", "")
+	results, err := repo.GetByDateRange(context.Background(), from, to, models.ListQuery{})
 	elapsed := time.Since(start)
 
 	if err != nil {
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"log/slog"
 	"os"
 
 	"github.com/joho/godotenv"
@@ -22,6 +23,16 @@ type DatabaseConfig struct {
 	Charset  string
 }
 
+// LogValue omits the password when the config is logged
+func (c DatabaseConfig) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("host", c.Host),
+		slog.String("port", c.Port),
+		slog.String("user", c.User),
+		slog.String("database", c.Database),
+	)
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	// Load .env file if it exists
@@ -3,10 +3,13 @@ package config
 import (
 	"database/sql"
 	"fmt"
+	"net/url"
 	"os"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/joho/godotenv/autoload" // .envファイルを自動読み込み
+	"github.com/yhonda-ohishi/dtako_mod/logging"
 )
 
 // GetDatabaseConfig returns database configuration from environment variables
@@ -35,29 +38,56 @@ func getEnvWithFallback(primary, fallback, defaultValue string) string {
 	return defaultValue
 }
 
-// GetDSN returns the database connection string
+// GetDSN returns the database connection string.
+// loc= makes the driver parse DATETIME values as business time (BUSINESS_TZ)
+// and write time.Time parameters converted to it; that alone makes date
+// handling correct. time_zone= only sets the session zone MySQL uses to
+// convert TIMESTAMP columns and NOW(), see sessionTimeZone.
 func (c *DatabaseConfig) GetDSN() string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		c.User, c.Password, c.Host, c.Port, c.Database)
+	loc := BusinessLocation()
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=%s&time_zone=%s",
+		c.User, c.Password, c.Host, c.Port, c.Database, url.QueryEscape(loc.String()), url.QueryEscape("'"+sessionTimeZone(loc)+"'"))
 }
 
-// Connect establishes database connection
-func (c *DatabaseConfig) Connect() (*sql.DB, error) {
-	dsn := c.GetDSN()
-
-	// デバッグログ（環境変数DEBUGがtrueの場合のみ）
-	if os.Getenv("DEBUG") == "true" {
-		fmt.Printf("[DEBUG] Connecting to database at %s:%s/%s\n",
-			c.Host, c.Port, c.Database)
+// sessionTimeZone returns the MySQL time_zone for loc. Zones without
+// daylight saving time use their fixed offset, which needs no time zone
+// tables on the server. Other zones are passed by name so MySQL follows
+// their DST changes; the server must have its time zone tables loaded
+// (mysql_tzinfo_to_sql), otherwise connections fail.
+func sessionTimeZone(loc *time.Location) string {
+	year := time.Now().In(loc).Year()
+	january := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	july := time.Date(year, time.July, 1, 0, 0, 0, 0, loc)
+	_, winter := january.Zone()
+	_, summer := july.Zone()
+	if winter != summer {
+		return loc.String()
 	}
+	return january.Format("-07:00")
+}
+
+// Open creates the connection pool without connecting. The pool connects
+// lazily, so it can be used once the server becomes reachable.
+func (c *DatabaseConfig) Open() (*sql.DB, error) {
+	logging.Logger().Debug("opening database", "db", c)
 
-	db, err := sql.Open("mysql", dsn)
+	db, err := sql.Open("mysql", c.GetDSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	return db, nil
+}
+
+// Connect establishes database connection
+func (c *DatabaseConfig) Connect() (*sql.DB, error) {
+	db, err := c.Open()
+	if err != nil {
+		return nil, err
+	}
 
 	// 接続テスト
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
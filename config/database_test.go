@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionTimeZone(t *testing.T) {
+	tests := []struct {
+		zone string
+		want string
+	}{
+		{"Asia/Tokyo", "+09:00"},
+		{"UTC", "+00:00"},
+		{"Asia/Kolkata", "+05:30"},
+		// DST: the offset changes during the year, so MySQL needs the name
+		{"Europe/Berlin", "Europe/Berlin"},
+		{"America/New_York", "America/New_York"},
+	}
+	for _, tt := range tests {
+		loc, err := time.LoadLocation(tt.zone)
+		if err != nil {
+			t.Skipf("time zone data unavailable: %v", err)
+		}
+		if got := sessionTimeZone(loc); got != tt.want {
+			t.Errorf("sessionTimeZone(%s) = %q, want %q", tt.zone, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"sync"
+	"time"
+	_ "time/tzdata" // tzdata を埋め込み、OSにゾーン情報が無い環境でも Asia/Tokyo を読めるようにする
+
+	"github.com/yhonda-ohishi/dtako_mod/logging"
+)
+
+// DefaultBusinessTimeZone is used when BUSINESS_TZ is not set
+const DefaultBusinessTimeZone = "Asia/Tokyo"
+
+var (
+	businessLoc  *time.Location
+	businessOnce sync.Once
+)
+
+// BusinessLocation returns the business time zone (BUSINESS_TZ, default
+// Asia/Tokyo). It is used for DB DATETIME values, date parameters, default
+// date ranges and JSON output, independent of the server's TZ.
+func BusinessLocation() *time.Location {
+	businessOnce.Do(func() {
+		name := getEnv("BUSINESS_TZ", DefaultBusinessTimeZone)
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			logging.Logger().Warn("invalid BUSINESS_TZ, using default",
+				"value", os.Getenv("BUSINESS_TZ"), "default", DefaultBusinessTimeZone, "error", err)
+			loc, _ = time.LoadLocation(DefaultBusinessTimeZone)
+		}
+		businessLoc = loc
+	})
+	return businessLoc
+}
+
+// Now returns the current time in the business time zone
+func Now() time.Time {
+	return time.Now().In(BusinessLocation())
+}
+
+// Today returns midnight of the current date in the business time zone
+func Today() time.Time {
+	y, m, d := Now().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, BusinessLocation())
+}
+
+// ParseDate parses a YYYY-MM-DD date as midnight in the business time zone
+func ParseDate(s string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02", s, BusinessLocation())
+}
@@ -15,57 +15,104 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/events": {
+        "/audit": {
             "get": {
-                "description": "Get event data with location information and optional filtering",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Who triggered imports, reconciliation (fetch_missing), master-data changes and retention runs, with parameters, result counts and errors, newest first\nReturns at most 1000 entries unless limit is given; page with limit/offset.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "dtako_events"
+                    "audit"
                 ],
-                "summary": "List Dtako Events",
+                "summary": "List audit log",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Start date (YYYY-MM-DD)",
+                        "description": "Start date of recording (YYYY-MM-DD)",
                         "name": "from",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "End date (YYYY-MM-DD)",
+                        "description": "End date of recording (YYYY-MM-DD)",
                         "name": "to",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Event type filter",
-                        "name": "type",
+                        "description": "Actor (token subject or API key name)",
+                        "name": "actor",
                         "in": "query"
                     },
                     {
+                        "enum": [
+                            "rows.import",
+                            "events.import",
+                            "ferry_rows.import",
+                            "integrity.fetch_missing",
+                            "masters.create",
+                            "masters.update",
+                            "masters.delete",
+                            "masters.import_csv",
+                            "masters.sync",
+                            "event_types.put",
+                            "event_types.delete",
+                            "event_types.sync",
+                            "geofences.create",
+                            "geofences.update",
+                            "geofences.delete",
+                            "retention.run"
+                        ],
                         "type": "string",
-                        "description": "Filter by 運行NO (links to dtako_rows)",
-                        "name": "unko_no",
+                        "description": "Action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of entries (default 1000, at most 10000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of entries to skip",
+                        "name": "offset",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of dtako events",
+                        "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.DtakoEvent"
+                                "$ref": "#/definitions/models.AuditEntry"
                             }
                         }
                     },
                     "400": {
-                        "description": "Invalid request parameters",
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -75,43 +122,75 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/events/import": {
-            "post": {
-                "description": "Import event data from production database",
-                "consumes": [
-                    "application/json"
+        "/coverage": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Vehicle × date matrix of trip counts (dtako_rows) in the local database, compared with production\nand with the active vehicles configured in ACTIVE_VEHICLES. At most 92 days.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "dtako_events"
+                    "coverage"
                 ],
-                "summary": "Import Dtako Events",
+                "summary": "Missing-data coverage calendar",
                 "parameters": [
                     {
-                        "description": "Import request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.ImportRequest"
-                        }
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "事業所CD",
+                        "name": "office",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Import successful",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.ImportResult"
+                            "$ref": "#/definitions/models.CoverageReport"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -125,37 +204,48 @@ const docTemplate = `{
                 }
             }
         },
-        "/events/{id}": {
+        "/event_types": {
             "get": {
-                "description": "Get specific event data by ID",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Get the event type registry (イベント名 and category)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "dtako_events"
-                ],
-                "summary": "Get Dtako Event by ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Event ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "event_types"
                 ],
+                "summary": "List event types",
                 "responses": {
                     "200": {
-                        "description": "Dtako event found",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.DtakoEvent"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.EventType"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -163,51 +253,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/ferry_rows": {
-            "get": {
-                "description": "Retrieve ferry row records with optional date range and ferry company filter",
-                "consumes": [
-                    "application/json"
+        "/event_types/sync": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Register every distinct production イベント名 not yet in the registry, with a guessed category.\nCategories of registered names are not changed.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "dtako_ferry"
-                ],
-                "summary": "List ferry row records",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Start date (YYYY-MM-DD)",
-                        "name": "from",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "End date (YYYY-MM-DD)",
-                        "name": "to",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by ferry company name",
-                        "name": "ferry_company",
-                        "in": "query"
-                    }
+                    "event_types"
                 ],
+                "summary": "Sync event types from production",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.DtakoFerryRow"
-                            }
+                            "$ref": "#/definitions/models.ImportResult"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -217,13 +295,27 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/ferry_rows/import": {
-            "post": {
-                "description": "Import ferry row records from production database for a date range",
+        "/event_types/{name}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Register an event name or change its category",
                 "consumes": [
                     "application/json"
                 ],
@@ -231,17 +323,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "dtako_ferry"
+                    "event_types"
                 ],
-                "summary": "Import ferry row records from production",
+                "summary": "Register event type",
                 "parameters": [
                     {
-                        "description": "Import request with date range and optional ferry company filter",
+                        "type": "string",
+                        "description": "イベント名",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Event type (name in the body is ignored)",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ImportRequest"
+                            "$ref": "#/definitions/models.EventType"
                         }
                     }
                 ],
@@ -249,7 +348,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.ImportResult"
+                            "$ref": "#/definitions/models.EventType"
                         }
                     },
                     "400": {
@@ -258,6 +357,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -265,45 +376,48 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/ferry_rows/{id}": {
-            "get": {
-                "description": "Retrieve a specific ferry row record by its ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Remove an event name from the registry",
                 "tags": [
-                    "dtako_ferry"
+                    "event_types"
                 ],
-                "summary": "Get ferry row record by ID",
+                "summary": "Delete event type",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Ferry row record ID",
-                        "name": "id",
+                        "description": "イベント名",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/models.DtakoFerryRow"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -311,19 +425,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/rows": {
+        "/events": {
             "get": {
-                "description": "Get vehicle operation data with optional date filtering",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get event data with location information and optional filtering.\nSend \"Accept: application/x-ndjson\" to stream one record per line.\nDriver names and codes are masked for roles below DRIVER_NAME_MIN_ROLE / DRIVER_CODE_MIN_ROLE; filtering by driver is then forbidden.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-ndjson"
                 ],
                 "tags": [
-                    "dtako_rows"
+                    "dtako_events"
                 ],
-                "summary": "List Dtako Rows",
+                "summary": "List Dtako Events",
                 "parameters": [
                     {
                         "type": "string",
@@ -336,36 +459,110 @@ const docTemplate = `{
                         "description": "End date (YYYY-MM-DD)",
                         "name": "to",
                         "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "List of dtako rows",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.DtakoRow"
-                            }
-                        }
                     },
-                    "400": {
-                        "description": "Invalid request parameters",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
-                        }
+                    {
+                        "type": "string",
+                        "description": "Event type filter (comma separated for multiple)",
+                        "name": "type",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
-                        }
+                    {
+                        "type": "string",
+                        "description": "Filter by 運行NO (links to dtako_rows, comma separated for multiple)",
+                        "name": "unko_no",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 車輌CD (comma separated for multiple)",
+                        "name": "vehicle",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 対象乗務員CD (comma separated for multiple)",
+                        "name": "driver",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 事業所CD (comma separated for multiple)",
+                        "name": "office",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort fields, prefix - for descending (e.g. -event_date)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fields to return (e.g. id,event_type,event_date)",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of records (1-10000, default all)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records to skip (requires limit)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of dtako events",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DtakoEvent"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/rows/import": {
+        "/events/import": {
             "post": {
-                "description": "Import vehicle operation data from production database",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Import event data from production database",
                 "consumes": [
                     "application/json"
                 ],
@@ -373,9 +570,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "dtako_rows"
+                    "dtako_events"
                 ],
-                "summary": "Import Dtako Rows",
+                "summary": "Import Dtako Events",
                 "parameters": [
                     {
                         "description": "Import request",
@@ -400,18 +597,44 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Database unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/rows/{id}": {
+        "/events/{id}": {
             "get": {
-                "description": "Get specific vehicle operation data by ID",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get specific event data by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -419,13 +642,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "dtako_rows"
+                    "dtako_events"
                 ],
-                "summary": "Get Dtako Row by ID",
+                "summary": "Get Dtako Event by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Row ID",
+                        "description": "Event ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -433,9 +656,21 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Dtako row found",
+                        "description": "Dtako event found",
                         "schema": {
-                            "$ref": "#/definitions/models.DtakoRow"
+                            "$ref": "#/definitions/models.DtakoEvent"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
@@ -443,315 +678,4326 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Database unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.DtakoEvent": {
-            "type": "object",
-            "properties": {
-                "created_at": {
-                    "type": "string",
-                    "example": "2025-01-13T15:04:05Z"
-                },
-                "description": {
-                    "type": "string",
-                    "example": "Started driving from depot"
-                },
-                "driver_code": {
-                    "type": "string",
-                    "example": "driver-123"
-                },
-                "event_date": {
-                    "type": "string",
-                    "example": "2025-01-13T10:30:00Z"
-                },
-                "event_type": {
-                    "type": "string",
-                    "example": "運転"
-                },
-                "id": {
-                    "type": "string",
-                    "example": "event-456"
-                },
-                "latitude": {
-                    "type": "number",
-                    "example": 35.6762
-                },
-                "longitude": {
-                    "type": "number",
-                    "example": 139.6503
-                },
-                "unko_no": {
-                    "description": "運行NO - links to DtakoRow",
-                    "type": "string",
-                    "example": "2025010101"
-                },
-                "updated_at": {
-                    "type": "string",
-                    "example": "2025-01-13T15:04:05Z"
+        },
+        "/ferry_rows": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve ferry row records with optional date range and attribute filters.\nSend \"Accept: application/x-ndjson\" to stream one record per line.\nDriver names and codes are masked for roles below DRIVER_NAME_MIN_ROLE / DRIVER_CODE_MIN_ROLE; filtering by driver is then forbidden.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "dtako_ferry"
+                ],
+                "summary": "List ferry row records",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by ferry company name (comma separated for multiple)",
+                        "name": "ferry_company",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 車輌CD (comma separated for multiple)",
+                        "name": "vehicle",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 乗務員CD1 (comma separated for multiple)",
+                        "name": "driver",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 事業所CD (comma separated for multiple)",
+                        "name": "office",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 運行NO (comma separated for multiple)",
+                        "name": "unko_no",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 乗場CD (comma separated for multiple)",
+                        "name": "boarding",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 降場CD (comma separated for multiple)",
+                        "name": "landing",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort fields, prefix - for descending (e.g. -unko_date,-start_time)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fields to return (e.g. id,unko_no,ferry_company_name)",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of records (1-10000, default all)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records to skip (requires limit)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DtakoFerryRow"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ferry_rows/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Import ferry row records from production database for a date range",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dtako_ferry"
+                ],
+                "summary": "Import ferry row records from production",
+                "parameters": [
+                    {
+                        "description": "Import request with date range and optional ferry company filter",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ferry_rows/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve a specific ferry row record by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dtako_ferry"
+                ],
+                "summary": "Get ferry row record by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ferry row record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DtakoFerryRow"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/geofences": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get all geofence definitions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "geofences"
+                ],
+                "summary": "List geofences",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Geofence"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a circle (center, radius_m) or polygon (at least 3 points) geofence\nPositions must be within lat -90..90 and lng -180..180; radius_m must be positive; a polygon must have at most 1000 points, a non-zero area and no crossing edges.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "geofences"
+                ],
+                "summary": "Create geofence",
+                "parameters": [
+                    {
+                        "description": "Geofence definition",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Geofence"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Geofence"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/geofences/visits": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Derive arrival/departure times and dwell per geofence per trip from event GPS points",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "geofences"
+                ],
+                "summary": "Detect geofence visits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Limit to one trip (運行NO)",
+                        "name": "unko_no",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit to one geofence",
+                        "name": "geofence_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.GeofenceVisit"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/geofences/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a specific geofence definition",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "geofences"
+                ],
+                "summary": "Get geofence by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Geofence ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Geofence"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace an existing geofence definition; validated like create",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "geofences"
+                ],
+                "summary": "Update geofence",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Geofence ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Geofence definition",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Geofence"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Geofence"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a geofence definition",
+                "tags": [
+                    "geofences"
+                ],
+                "summary": "Delete geofence",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Geofence ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Returns 200 while the process serves requests. Databases are not checked.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LivenessReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/integrity": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Events and ferry rows whose 運行NO is missing in dtako_rows, rows without events,\nand 運行NO shared by different row ids (local database)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "integrity"
+                ],
+                "summary": "Integrity report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.IntegrityReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/integrity/fetch_missing": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Imports dtako_rows for orphaned 運行NO from production and returns the updated report",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "integrity"
+                ],
+                "summary": "Fetch missing parent rows",
+                "parameters": [
+                    {
+                        "description": "Date range (from_date, to_date)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.IntegrityReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/masters/{kind}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get all records of a master ordered by code\nOffice-scoped callers only see vehicles, drivers and offices of their offices.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "masters"
+                ],
+                "summary": "List master records",
+                "parameters": [
+                    {
+                        "enum": [
+                            "vehicles",
+                            "drivers",
+                            "offices",
+                            "ferry_companies",
+                            "ports"
+                        ],
+                        "type": "string",
+                        "description": "Master kind",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MasterRecord"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a master record; office_code is used by vehicles and drivers only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "masters"
+                ],
+                "summary": "Create master record",
+                "parameters": [
+                    {
+                        "enum": [
+                            "vehicles",
+                            "drivers",
+                            "offices",
+                            "ferry_companies",
+                            "ports"
+                        ],
+                        "type": "string",
+                        "description": "Master kind",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Master record",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MasterRecord"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.MasterRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/masters/{kind}/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upsert records from a CSV body with header code,name[,office_code][,active].\nNothing is written when any line is invalid.",
+                "consumes": [
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "masters"
+                ],
+                "summary": "Import master records from CSV",
+                "parameters": [
+                    {
+                        "enum": [
+                            "vehicles",
+                            "drivers",
+                            "offices",
+                            "ferry_companies",
+                            "ports"
+                        ],
+                        "type": "string",
+                        "description": "Master kind",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/masters/{kind}/sync": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add codes found in production ferry row records (車輌名, 乗務員名１, 事業所名, フェリー会社名, 乗場名/降場名) that are not registered yet. Existing records keep their name, office and active flag.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "masters"
+                ],
+                "summary": "Sync master records from production",
+                "parameters": [
+                    {
+                        "enum": [
+                            "vehicles",
+                            "drivers",
+                            "offices",
+                            "ferry_companies",
+                            "ports"
+                        ],
+                        "type": "string",
+                        "description": "Master kind",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/masters/{kind}/{code}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get one master record by code; vehicles, drivers and offices outside the caller's offices are 404",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "masters"
+                ],
+                "summary": "Get master record",
+                "parameters": [
+                    {
+                        "enum": [
+                            "vehicles",
+                            "drivers",
+                            "offices",
+                            "ferry_companies",
+                            "ports"
+                        ],
+                        "type": "string",
+                        "description": "Master kind",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MasterRecord"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace the name, office and active flag of a master record",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "masters"
+                ],
+                "summary": "Update master record",
+                "parameters": [
+                    {
+                        "enum": [
+                            "vehicles",
+                            "drivers",
+                            "offices",
+                            "ferry_companies",
+                            "ports"
+                        ],
+                        "type": "string",
+                        "description": "Master kind",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Master record",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MasterRecord"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MasterRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a master record by code",
+                "tags": [
+                    "masters"
+                ],
+                "summary": "Delete master record",
+                "parameters": [
+                    {
+                        "enum": [
+                            "vehicles",
+                            "drivers",
+                            "offices",
+                            "ferry_companies",
+                            "ports"
+                        ],
+                        "type": "string",
+                        "description": "Master kind",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payroll/monthly": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Per driver: working days, 拘束時間, driving time, night hours (22:00-05:00), overtime, distance and ferry trips for a month.\nResults are stored as snapshots and recomputed when the month's data has been re-imported or recompute=true.\nOffice-scoped callers get figures computed from the trips of their offices only; these are not stored.\nReturns JSON by default; use format=csv or \"Accept: text/csv\" for CSV.\nDriver names and codes are masked for roles below DRIVER_NAME_MIN_ROLE / DRIVER_CODE_MIN_ROLE; filtering by driver is then forbidden.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "payroll"
+                ],
+                "summary": "Monthly payroll summary",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Month (YYYY-MM)",
+                        "name": "month",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "対象乗務員CD",
+                        "name": "driver",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Recompute the month even if a snapshot exists",
+                        "name": "recompute",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PayrollSummary"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payroll/monthly/recompute": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Recompute the snapshots of a month from dtako_rows, dtako_events and dtako_ferry_rows and return them",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "payroll"
+                ],
+                "summary": "Recompute monthly payroll summary",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Month (YYYY-MM)",
+                        "name": "month",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PayrollSummary"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payroll/night_hours": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Minutes of driving and work/waiting events between 22:00 and 05:00, from event 開始日時/終了日時.\nEvents spanning midnight are split, and each part is counted on its calendar day. Days without night work are omitted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payroll"
+                ],
+                "summary": "Night hours per driver and day",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 対象乗務員CD (comma separated for multiple)",
+                        "name": "driver",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 事業所CD (comma separated for multiple)",
+                        "name": "office",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.NightHours"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Status, latency and last error of the local and production databases, missing required tables,\nand the last successful import per table. Returns 503 when the local database is unavailable;\na production outage only degrades the status (reads still work, imports fail).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReadinessReport"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReadinessReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/daily": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Assemble a driver's trips, event timeline, ferry crossings and totals for one 運行日.\nReturns JSON by default; use format=html or format=pdf (or the matching Accept header) for a printable report with signature boxes.\nPDF output needs a Japanese TrueType font (REPORT_FONT_PATH).\nDriver names and codes are masked for roles below DRIVER_NAME_MIN_ROLE / DRIVER_CODE_MIN_ROLE; filtering by driver is then forbidden.",
+                "produces": [
+                    "application/json",
+                    "text/html",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Daily driver report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "対象乗務員CD",
+                        "name": "driver",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "運行日 (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "html",
+                            "pdf"
+                        ],
+                        "type": "string",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DailyReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No trips for the driver on the date",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "PDF font not configured",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/retention/run": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Anonymizes (乗務員CD → 0, 乗務員名 → empty) or purges dtako_rows, dtako_events and dtako_ferry_rows records\ndated before today minus DRIVER_DATA_RETENTION_DAYS, depending on DRIVER_DATA_RETENTION_MODE (anonymize / purge),\ntogether with their validation findings and drivers without later trips. Deletes payroll snapshots of earlier months\nand clears driver master records from older audit entries. Returns what was processed per table; dry_run only counts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "retention"
+                ],
+                "summary": "Run driver data retention",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only count the records that would be processed",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RetentionReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid dry_run",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Retention not configured or database unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rows": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get vehicle operation data with optional date range and attribute filters.\nSend \"Accept: application/x-ndjson\" to stream one record per line.\nDriver names and codes are masked for roles below DRIVER_NAME_MIN_ROLE / DRIVER_CODE_MIN_ROLE; filtering by driver is then forbidden.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "dtako_rows"
+                ],
+                "summary": "List Dtako Rows",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 車輌CD (comma separated for multiple)",
+                        "name": "vehicle",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 対象乗務員CD (comma separated for multiple)",
+                        "name": "driver",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 事業所CD (comma separated for multiple)",
+                        "name": "office",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 運行NO (comma separated for multiple)",
+                        "name": "unko_no",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by route (行先市町村名, comma separated for multiple)",
+                        "name": "route",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort fields, prefix - for descending (e.g. -date,vehicle_no)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fields to return (e.g. id,unko_no,date)",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of records (1-10000, default all)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records to skip (requires limit)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of dtako rows",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DtakoRow"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rows/fuel_anomalies": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Flags trips whose km/L deviates from the rolling baseline of the same vehicle (車輌CD)\nby z-score or percentage. Flagged trips are excluded from later baselines.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dtako_rows"
+                ],
+                "summary": "Fuel efficiency anomalies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 車輌CD (comma separated for multiple)",
+                        "name": "vehicle",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Baseline size in trips (default 20)",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum baseline trips before judging (default 5)",
+                        "name": "min_baseline",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Z-score threshold, negative disables (default 3)",
+                        "name": "z",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Deviation threshold in percent, negative disables (default 30)",
+                        "name": "pct",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Days before from loaded for the baseline (default 90)",
+                        "name": "lookback_days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.FuelAnomaly"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rows/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Import vehicle operation data from production database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dtako_rows"
+                ],
+                "summary": "Import Dtako Rows",
+                "parameters": [
+                    {
+                        "description": "Import request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import successful",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Database unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rows/stats": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sum, average, trip count and fuel efficiency (km/L) computed in SQL, optionally grouped\nand compared with the previous period of the same length.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dtako_rows"
+                ],
+                "summary": "Dtako Rows statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "vehicle",
+                            "driver",
+                            "office",
+                            "day",
+                            "month"
+                        ],
+                        "type": "string",
+                        "description": "Grouping",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "previous"
+                        ],
+                        "type": "string",
+                        "description": "Set to previous to include the previous period",
+                        "name": "compare",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 車輌CD (comma separated for multiple)",
+                        "name": "vehicle",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 対象乗務員CD (comma separated for multiple)",
+                        "name": "driver",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 事業所CD (comma separated for multiple)",
+                        "name": "office",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RowStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rows/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get specific vehicle operation data by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dtako_rows"
+                ],
+                "summary": "Get Dtako Row by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Row ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dtako row found",
+                        "schema": {
+                            "$ref": "#/definitions/models.DtakoRow"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Database unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/trips/odometer_continuity": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Per vehicle, compares the end odometer (終了走行距離) and end time of each trip with the start of the next,\nordered by 運行日. Reports distance gaps (unrecorded driving), distance overlaps and time overlaps.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Odometer continuity check",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 車輌CD (comma separated for multiple)",
+                        "name": "vehicle",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 対象乗務員CD (comma separated for multiple)",
+                        "name": "driver",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by 事業所CD (comma separated for multiple)",
+                        "name": "office",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Allowed odometer difference in km (default 1)",
+                        "name": "tolerance_km",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.VehicleOdometerReport"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/trips/{unko_no}/time_breakdown": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sum the time of a trip per event category (driving, rest, work, waiting, other).\nEach event lasts until the next event; categories come from the event type registry.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Trip time breakdown",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "運行NO",
+                        "name": "unko_no",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.EventTimeBreakdown"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Trip not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/trips/{unko_no}/track": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the route of a trip built from its events, ordered by event time.\nEvents without a valid position are skipped.\nReturns GeoJSON by default; use format=gpx or \"Accept: application/gpx+xml\" for GPX.",
+                "produces": [
+                    "application/json",
+                    "application/geo+json",
+                    "application/gpx+xml"
+                ],
+                "tags": [
+                    "trips"
+                ],
+                "summary": "Get trip GPS track",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "運行NO",
+                        "name": "unko_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "geojson",
+                            "gpx"
+                        ],
+                        "type": "string",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "GeoJSON FeatureCollection",
+                        "schema": {
+                            "$ref": "#/definitions/models.GeoJSONFeatureCollection"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Trip not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/validation/findings": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Data quality findings (reject / warn / fix) recorded while importing, newest first\nOffice-scoped callers only see findings of stored records of their offices.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "validation"
+                ],
+                "summary": "List import validation findings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date of recording (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date of recording (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "dtako_rows",
+                            "dtako_events",
+                            "dtako_ferry_rows"
+                        ],
+                        "type": "string",
+                        "description": "Table name",
+                        "name": "table",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rule name (e.g. negative_distance)",
+                        "name": "rule",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ValidationFinding"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "rows.import"
+                },
+                "actor": {
+                    "description": "トークンの sub / APIキー名、認証なしは anonymous",
+                    "type": "string",
+                    "example": "batch"
+                },
+                "actor_role": {
+                    "type": "string",
+                    "example": "operator"
+                },
+                "affected_rows": {
+                    "type": "integer",
+                    "example": 150
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-01-13T15:04:05Z"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "failed_rows": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "params": {
+                    "description": "ImportRequest、マスタレコードなど",
+                    "type": "object"
+                },
+                "rejected_rows": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "host/abc123-000001"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "target": {
+                    "description": "テーブル名・マスタ種別/コードなど",
+                    "type": "string",
+                    "example": "dtako_rows"
+                }
+            }
+        },
+        "models.CoverageDay": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2025-01-13"
+                },
+                "local": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "production": {
+                    "description": "本番DBに接続できない場合は省略",
+                    "type": "integer",
+                    "example": 1
+                },
+                "status": {
+                    "description": "ok / missing (active vehicle without data) / not_imported (fewer locally) /\nlocal_only (more locally than production) / none (inactive vehicle without data)",
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "models.CoverageReport": {
+            "type": "object",
+            "properties": {
+                "dates": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "2025-01-01",
+                        "2025-01-02"
+                    ]
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "office": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-01-31"
+                },
+                "vehicles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.VehicleCoverage"
+                    }
+                }
+            }
+        },
+        "models.DailyReport": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "運行日",
+                    "type": "string",
+                    "example": "2025-01-13"
+                },
+                "driver_code": {
+                    "type": "string",
+                    "example": "1001"
+                },
+                "driver_name": {
+                    "description": "乗務員マスタから補完",
+                    "type": "string",
+                    "example": "山田太郎"
+                },
+                "ferries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DailyReportFerry"
+                    }
+                },
+                "generated_at": {
+                    "type": "string",
+                    "example": "2025-01-14T09:00:00Z"
+                },
+                "timeline": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DailyReportEvent"
+                    }
+                },
+                "totals": {
+                    "$ref": "#/definitions/models.DailyReportTotals"
+                },
+                "trips": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DailyReportTrip"
+                    }
+                }
+            }
+        },
+        "models.DailyReportEvent": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "rest"
+                },
+                "distance": {
+                    "description": "区間距離",
+                    "type": "number",
+                    "example": 0
+                },
+                "end_at": {
+                    "type": "string",
+                    "example": "2025-01-13T10:30:00Z"
+                },
+                "end_odometer": {
+                    "description": "終了走行距離",
+                    "type": "number",
+                    "example": 120400.1
+                },
+                "end_place": {
+                    "type": "string",
+                    "example": "浜松SA"
+                },
+                "event_type": {
+                    "type": "string",
+                    "example": "休憩"
+                },
+                "minutes": {
+                    "type": "number",
+                    "example": 30
+                },
+                "start_at": {
+                    "type": "string",
+                    "example": "2025-01-13T10:00:00Z"
+                },
+                "start_odometer": {
+                    "description": "開始走行距離",
+                    "type": "number",
+                    "example": 120400.1
+                },
+                "start_place": {
+                    "type": "string",
+                    "example": "浜松SA"
+                },
+                "unko_no": {
+                    "type": "string",
+                    "example": "2025010101"
+                }
+            }
+        },
+        "models.DailyReportFerry": {
+            "type": "object",
+            "properties": {
+                "boarding": {
+                    "type": "string",
+                    "example": "東京港"
+                },
+                "company": {
+                    "type": "string",
+                    "example": "東京フェリー"
+                },
+                "contract_fare": {
+                    "type": "integer",
+                    "example": 8000
+                },
+                "end_at": {
+                    "type": "string",
+                    "example": "2025-01-14T06:00:00Z"
+                },
+                "landing": {
+                    "type": "string",
+                    "example": "大阪港"
+                },
+                "start_at": {
+                    "type": "string",
+                    "example": "2025-01-13T20:00:00Z"
+                },
+                "unko_no": {
+                    "type": "string",
+                    "example": "2025010101"
+                }
+            }
+        },
+        "models.DailyReportTotals": {
+            "type": "object",
+            "properties": {
+                "binding_minutes": {
+                    "description": "拘束時間（最初の開始から最後の終了まで）",
+                    "type": "number",
+                    "example": 720
+                },
+                "distance": {
+                    "type": "number",
+                    "example": 534.6
+                },
+                "driving_minutes": {
+                    "type": "number",
+                    "example": 420
+                },
+                "ferry_count": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "fuel_amount": {
+                    "type": "number",
+                    "example": 180.5
+                },
+                "other_minutes": {
+                    "type": "number",
+                    "example": 5
+                },
+                "rest_minutes": {
+                    "type": "number",
+                    "example": 60
+                },
+                "trip_count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "unregistered": {
+                    "description": "Unregistered はレジストリに無いイベント名（時間は other に計上）",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "点検"
+                    ]
+                },
+                "waiting_minutes": {
+                    "type": "number",
+                    "example": 30
+                },
+                "work_minutes": {
+                    "type": "number",
+                    "example": 90
+                }
+            }
+        },
+        "models.DailyReportTrip": {
+            "type": "object",
+            "properties": {
+                "distance": {
+                    "description": "総走行距離",
+                    "type": "number",
+                    "example": 534.6
+                },
+                "end_at": {
+                    "type": "string",
+                    "example": "2025-01-13T18:00:00Z"
+                },
+                "end_odometer": {
+                    "description": "終了走行距離",
+                    "type": "number",
+                    "example": 120880.2
+                },
+                "end_place": {
+                    "type": "string",
+                    "example": "大阪営業所"
+                },
+                "fuel_amount": {
+                    "type": "number",
+                    "example": 180.5
+                },
+                "route": {
+                    "description": "行先市町村名",
+                    "type": "string",
+                    "example": "大阪市"
+                },
+                "start_at": {
+                    "type": "string",
+                    "example": "2025-01-13T06:00:00Z"
+                },
+                "start_odometer": {
+                    "description": "開始走行距離",
+                    "type": "number",
+                    "example": 120345.6
+                },
+                "start_place": {
+                    "type": "string",
+                    "example": "本社"
+                },
+                "unko_no": {
+                    "type": "string",
+                    "example": "2025010101"
+                },
+                "vehicle_name": {
+                    "type": "string",
+                    "example": "トラック1号"
+                },
+                "vehicle_no": {
+                    "type": "string",
+                    "example": "101"
+                }
+            }
+        },
+        "models.DatabaseHealth": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "dial tcp 127.0.0.1:3306: connect: connection refused"
+                },
+                "last_error": {
+                    "description": "直近の失敗（復旧後も保持）",
+                    "type": "string",
+                    "example": "dial tcp 127.0.0.1:3306: connect: connection refused"
+                },
+                "last_error_at": {
+                    "type": "string",
+                    "example": "2025-01-13T15:04:05+09:00"
+                },
+                "latency_ms": {
+                    "type": "number",
+                    "example": 1.25
+                },
+                "missing_tables": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "dtako_payroll_summaries"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "enum": [
+                        "local",
+                        "production"
+                    ],
+                    "example": "local"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "ok",
+                        "unavailable"
+                    ],
+                    "example": "ok"
+                }
+            }
+        },
+        "models.DtakoEvent": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-01-13T15:04:05Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Started driving from depot"
+                },
+                "driver_code": {
+                    "type": "string",
+                    "example": "driver-123"
+                },
+                "driver_name": {
+                    "description": "乗務員マスタから補完",
+                    "type": "string",
+                    "example": "山田太郎"
+                },
+                "event_date": {
+                    "type": "string",
+                    "example": "2025-01-13T10:30:00Z"
+                },
+                "event_type": {
+                    "type": "string",
+                    "example": "運転"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "event-456"
+                },
+                "latitude": {
+                    "type": "number",
+                    "example": 35.6762
+                },
+                "longitude": {
+                    "type": "number",
+                    "example": 139.6503
+                },
+                "unko_no": {
+                    "description": "運行NO - links to DtakoRow",
+                    "type": "string",
+                    "example": "2025010101"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-01-13T15:04:05Z"
+                },
+                "vehicle_name": {
+                    "description": "車輌マスタから補完",
+                    "type": "string",
+                    "example": "トラック1号"
+                },
+                "vehicle_no": {
+                    "type": "string",
+                    "example": "vehicle-001"
+                }
+            }
+        },
+        "models.DtakoFerryRow": {
+            "type": "object",
+            "properties": {
+                "boarding_code": {
+                    "description": "乗場CD",
+                    "type": "integer",
+                    "example": 1
+                },
+                "boarding_name": {
+                    "description": "乗場名",
+                    "type": "string",
+                    "example": "東京港"
+                },
+                "contract_fare": {
+                    "description": "契約料金",
+                    "type": "integer",
+                    "example": 8000
+                },
+                "driver_code_1": {
+                    "description": "乗務員CD1",
+                    "type": "integer",
+                    "example": 1001
+                },
+                "driver_name_1": {
+                    "description": "乗務員名１",
+                    "type": "string",
+                    "example": "山田太郎"
+                },
+                "end_time": {
+                    "description": "終了日時",
+                    "type": "string",
+                    "example": "2025-01-13T12:00:00Z"
+                },
+                "estimated_distance": {
+                    "description": "見なし距離",
+                    "type": "integer",
+                    "example": 500
+                },
+                "ferry_company_code": {
+                    "description": "フェリー会社CD",
+                    "type": "integer",
+                    "example": 1
+                },
+                "ferry_company_name": {
+                    "description": "フェリー会社名",
+                    "type": "string",
+                    "example": "東京フェリー"
+                },
+                "ferry_search": {
+                    "description": "ferry_srch",
+                    "type": "string",
+                    "example": "東京-大阪"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "landing_code": {
+                    "description": "降場CD",
+                    "type": "integer",
+                    "example": 2
+                },
+                "landing_name": {
+                    "description": "降場名",
+                    "type": "string",
+                    "example": "大阪港"
+                },
+                "office_code": {
+                    "description": "事業所CD",
+                    "type": "integer",
+                    "example": 1
+                },
+                "office_name": {
+                    "description": "事業所名",
+                    "type": "string",
+                    "example": "東京事業所"
+                },
+                "read_date": {
+                    "description": "読取日",
+                    "type": "string",
+                    "example": "2025-01-13T00:00:00Z"
+                },
+                "settlement_class": {
+                    "description": "精算区分",
+                    "type": "integer",
+                    "example": 1
+                },
+                "settlement_name": {
+                    "description": "精算区分名",
+                    "type": "string",
+                    "example": "現金"
+                },
+                "ship_number": {
+                    "description": "便",
+                    "type": "string",
+                    "example": "1便"
+                },
+                "ship_vehicle_class": {
+                    "description": "航送車種区分",
+                    "type": "integer",
+                    "example": 1
+                },
+                "ship_vehicle_name": {
+                    "description": "航送車種区分名",
+                    "type": "string",
+                    "example": "大型車"
+                },
+                "standard_fare": {
+                    "description": "標準料金",
+                    "type": "integer",
+                    "example": 10000
+                },
+                "start_time": {
+                    "description": "開始日時",
+                    "type": "string",
+                    "example": "2025-01-13T08:00:00Z"
+                },
+                "target_driver_class": {
+                    "description": "対象乗務員区分",
+                    "type": "integer",
+                    "example": 1
+                },
+                "unko_date": {
+                    "description": "運行日",
+                    "type": "string",
+                    "example": "2025-01-13T00:00:00Z"
+                },
+                "unko_no": {
+                    "description": "運行NO",
+                    "type": "string",
+                    "example": "2025010101"
+                },
+                "vehicle_code": {
+                    "description": "車輌CD",
+                    "type": "integer",
+                    "example": 101
+                },
+                "vehicle_name": {
+                    "description": "車輌名",
+                    "type": "string",
+                    "example": "トラック1号"
+                }
+            }
+        },
+        "models.DtakoRow": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-01-13T15:04:05Z"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-01-13T00:00:00Z"
+                },
+                "distance": {
+                    "type": "number",
+                    "example": 123.45
+                },
+                "driver_code": {
+                    "type": "string",
+                    "example": "driver-123"
+                },
+                "driver_name": {
+                    "description": "乗務員マスタから補完",
+                    "type": "string",
+                    "example": "山田太郎"
+                },
+                "fuel_amount": {
+                    "type": "number",
+                    "example": 45.67
+                },
+                "id": {
+                    "type": "string",
+                    "example": "row-123"
+                },
+                "route_code": {
+                    "type": "string",
+                    "example": "route-A"
+                },
+                "unko_no": {
+                    "description": "運行NO",
+                    "type": "string",
+                    "example": "2025010101"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-01-13T15:04:05Z"
+                },
+                "vehicle_name": {
+                    "description": "車輌マスタから補完",
+                    "type": "string",
+                    "example": "トラック1号"
+                },
+                "vehicle_no": {
+                    "type": "string",
+                    "example": "vehicle-001"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "invalid date range: from_date cannot be after to_date"
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/dtako/rows/import"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "invalid date range: from_date cannot be after to_date"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "host/abc123-000001"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Bad Request"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:dtako:problem:validation"
+                }
+            }
+        },
+        "models.EventTimeBreakdown": {
+            "type": "object",
+            "properties": {
+                "driving_minutes": {
+                    "type": "number",
+                    "example": 420
+                },
+                "other_minutes": {
+                    "type": "number",
+                    "example": 5
+                },
+                "rest_minutes": {
+                    "type": "number",
+                    "example": 60
+                },
+                "unregistered": {
+                    "description": "Unregistered はレジストリに無いイベント名（時間は other に計上）",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "点検"
+                    ]
+                },
+                "waiting_minutes": {
+                    "type": "number",
+                    "example": 30
+                },
+                "work_minutes": {
+                    "type": "number",
+                    "example": 90
+                }
+            }
+        },
+        "models.EventType": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "enum": [
+                        "driving",
+                        "rest",
+                        "work",
+                        "waiting",
+                        "other"
+                    ],
+                    "example": "work"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "積み"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-01-13T15:04:05Z"
+                }
+            }
+        },
+        "models.FuelAnomaly": {
+            "type": "object",
+            "properties": {
+                "baseline_mean": {
+                    "description": "直近運行の平均 km/L",
+                    "type": "number",
+                    "example": 3.6
+                },
+                "baseline_stddev": {
+                    "description": "直近運行の標準偏差",
+                    "type": "number",
+                    "example": 0.3
+                },
+                "baseline_trips": {
+                    "description": "ベースラインに使った運行数",
+                    "type": "integer",
+                    "example": 20
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-01-13T00:00:00Z"
+                },
+                "deviation_pct": {
+                    "type": "number",
+                    "example": -44.4
+                },
+                "distance": {
+                    "description": "総走行距離",
+                    "type": "number",
+                    "example": 320.5
+                },
+                "driver_code": {
+                    "description": "対象乗務員CD",
+                    "type": "string",
+                    "example": "1001"
+                },
+                "fuel_amount": {
+                    "description": "自社主燃料",
+                    "type": "number",
+                    "example": 160.2
+                },
+                "fuel_efficiency": {
+                    "description": "km/L",
+                    "type": "number",
+                    "example": 2
+                },
+                "reasons": {
+                    "description": "閾値を超えた判定",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "z_score",
+                        "percent"
+                    ]
+                },
+                "row_id": {
+                    "type": "string",
+                    "example": "row-123"
+                },
+                "unko_no": {
+                    "description": "運行NO",
+                    "type": "string",
+                    "example": "2025010101"
+                },
+                "vehicle_no": {
+                    "description": "車輌CD",
+                    "type": "string",
+                    "example": "101"
+                },
+                "z_score": {
+                    "type": "number",
+                    "example": -5.3
+                }
+            }
+        },
+        "models.GeoJSONFeature": {
+            "type": "object",
+            "properties": {
+                "geometry": {
+                    "$ref": "#/definitions/models.GeoJSONGeometry"
+                },
+                "properties": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "type": {
+                    "type": "string",
+                    "example": "Feature"
+                }
+            }
+        },
+        "models.GeoJSONFeatureCollection": {
+            "type": "object",
+            "properties": {
+                "features": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GeoJSONFeature"
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "example": "FeatureCollection"
+                }
+            }
+        },
+        "models.GeoJSONGeometry": {
+            "type": "object",
+            "properties": {
+                "coordinates": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "example": "LineString"
+                }
+            }
+        },
+        "models.Geofence": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "enum": [
+                        "depot",
+                        "customer",
+                        "port",
+                        "other"
+                    ],
+                    "example": "depot"
+                },
+                "center": {
+                    "$ref": "#/definitions/models.LatLng"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-01-13T15:04:05Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "東京営業所"
+                },
+                "polygon": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LatLng"
+                    }
+                },
+                "radius_m": {
+                    "type": "number",
+                    "example": 200
+                },
+                "shape": {
+                    "type": "string",
+                    "enum": [
+                        "circle",
+                        "polygon"
+                    ],
+                    "example": "circle"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-01-13T15:04:05Z"
+                }
+            }
+        },
+        "models.GeofenceVisit": {
+            "type": "object",
+            "properties": {
+                "arrival_event_id": {
+                    "type": "string",
+                    "example": "event-456"
+                },
+                "arrived_at": {
+                    "type": "string",
+                    "example": "2025-01-13T10:30:00Z"
+                },
+                "category": {
+                    "type": "string",
+                    "example": "depot"
+                },
+                "departed_at": {
+                    "type": "string",
+                    "example": "2025-01-13T11:15:00Z"
+                },
+                "departure_event_id": {
+                    "type": "string",
+                    "example": "event-460"
+                },
+                "driver_code": {
+                    "type": "string",
+                    "example": "driver-123"
+                },
+                "dwell_minutes": {
+                    "type": "number",
+                    "example": 45
+                },
+                "geofence_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "geofence_name": {
+                    "type": "string",
+                    "example": "東京営業所"
+                },
+                "unko_no": {
+                    "type": "string",
+                    "example": "2025010101"
+                },
+                "vehicle_no": {
+                    "type": "string",
+                    "example": "vehicle-001"
+                }
+            }
+        },
+        "models.ImportHealth": {
+            "type": "object",
+            "properties": {
+                "imported_rows": {
+                    "type": "integer",
+                    "example": 150
+                },
+                "last_success": {
+                    "type": "string",
+                    "example": "2025-01-13T15:04:05+09:00"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "ok",
+                        "never",
+                        "unknown"
+                    ],
+                    "example": "ok"
+                },
+                "table": {
+                    "type": "string",
+                    "example": "dtako_rows"
+                }
+            }
+        },
+        "models.ImportRequest": {
+            "type": "object",
+            "properties": {
+                "event_type": {
+                    "description": "For events",
+                    "type": "string",
+                    "example": "運転"
+                },
+                "ferry_company": {
+                    "description": "For ferry rows",
+                    "type": "string",
+                    "example": "東京フェリー"
+                },
+                "from_date": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "to_date": {
+                    "type": "string",
+                    "example": "2025-01-31"
+                }
+            }
+        },
+        "models.ImportResult": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "findings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ValidationFinding"
+                    }
+                },
+                "imported_at": {
+                    "type": "string",
+                    "example": "2025-01-13T15:04:05Z"
+                },
+                "imported_rows": {
+                    "type": "integer",
+                    "example": 150
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Imported 150 rows successfully"
+                },
+                "rejected_rows": {
+                    "description": "バリデーションで除外した件数",
+                    "type": "integer",
+                    "example": 2
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.IntegrityReport": {
+            "type": "object",
+            "properties": {
+                "fetched": {
+                    "description": "fetch_missing 実行時の取り込み結果",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ImportResult"
+                        }
+                    ]
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "orphan_events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrphanUnkoNo"
+                    }
+                },
+                "orphan_ferry_rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrphanUnkoNo"
+                    }
+                },
+                "rows_without_events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RowWithoutEvents"
+                    }
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-01-31"
+                }
+            }
+        },
+        "models.LatLng": {
+            "type": "object",
+            "properties": {
+                "lat": {
+                    "type": "number",
+                    "example": 35.6762
+                },
+                "lng": {
+                    "type": "number",
+                    "example": 139.6503
+                }
+            }
+        },
+        "models.LivenessReport": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "models.MasterRecord": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "省略時は true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "code": {
+                    "type": "string",
+                    "example": "101"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "トラック1号"
+                },
+                "office_code": {
+                    "description": "事業所CD",
+                    "type": "string",
+                    "example": "1"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-01-13T15:04:05Z"
+                }
+            }
+        },
+        "models.NightHours": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2025-01-13"
+                },
+                "driver_code": {
+                    "type": "string",
+                    "example": "1001"
+                },
+                "driver_name": {
+                    "description": "乗務員マスタから補完",
+                    "type": "string",
+                    "example": "山田太郎"
+                },
+                "driving_minutes": {
+                    "description": "深夜の運転",
+                    "type": "number",
+                    "example": 120
+                },
+                "total_minutes": {
+                    "type": "number",
+                    "example": 165
+                },
+                "work_minutes": {
+                    "description": "深夜の作業・待機",
+                    "type": "number",
+                    "example": 45
+                }
+            }
+        },
+        "models.OdometerIssue": {
+            "type": "object",
+            "properties": {
+                "distance_gap": {
+                    "description": "次の開始 - 前の終了 (km)",
+                    "type": "number",
+                    "example": 42.3
+                },
+                "next": {
+                    "$ref": "#/definitions/models.OdometerTrip"
+                },
+                "previous": {
+                    "$ref": "#/definitions/models.OdometerTrip"
+                },
+                "time_gap_minutes": {
+                    "description": "次の開始 - 前の終了 (分)",
+                    "type": "number",
+                    "example": -15
+                },
+                "type": {
+                    "description": "distance_gap / distance_overlap / time_overlap",
+                    "type": "string",
+                    "example": "distance_gap"
+                }
+            }
+        },
+        "models.OdometerTrip": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "運行日",
+                    "type": "string",
+                    "example": "2025-01-13T00:00:00Z"
+                },
+                "driver_code": {
+                    "description": "対象乗務員CD",
+                    "type": "string",
+                    "example": "1001"
+                },
+                "end_at": {
+                    "type": "string",
+                    "example": "2025-01-13T18:00:00Z"
+                },
+                "end_odometer": {
+                    "description": "km",
+                    "type": "number",
+                    "example": 123789
+                },
+                "start_at": {
+                    "type": "string",
+                    "example": "2025-01-13T08:00:00Z"
+                },
+                "start_odometer": {
+                    "description": "km",
+                    "type": "number",
+                    "example": 123456.7
+                },
+                "unko_no": {
+                    "description": "運行NO",
+                    "type": "string",
+                    "example": "2025010101"
+                },
+                "vehicle_no": {
+                    "description": "車輌CD",
+                    "type": "string",
+                    "example": "101"
+                }
+            }
+        },
+        "models.OrphanUnkoNo": {
+            "type": "object",
+            "properties": {
+                "first_at": {
+                    "type": "string",
+                    "example": "2025-01-13T08:00:00Z"
+                },
+                "last_at": {
+                    "type": "string",
+                    "example": "2025-01-13T18:00:00Z"
+                },
+                "records": {
+                    "description": "参照しているレコード数",
+                    "type": "integer",
+                    "example": 12
+                },
+                "unko_no": {
+                    "type": "string",
+                    "example": "2025010101"
+                }
+            }
+        },
+        "models.PayrollSummary": {
+            "type": "object",
+            "properties": {
+                "binding_minutes": {
+                    "description": "拘束時間",
+                    "type": "number",
+                    "example": 13200
+                },
+                "computed_at": {
+                    "type": "string",
+                    "example": "2025-02-01T03:00:00Z"
+                },
+                "distance": {
+                    "description": "総走行距離",
+                    "type": "number",
+                    "example": 9876.5
+                },
+                "driver_code": {
+                    "type": "string",
+                    "example": "1001"
+                },
+                "driver_name": {
+                    "description": "乗務員マスタから補完",
+                    "type": "string",
+                    "example": "山田太郎"
+                },
+                "driving_minutes": {
+                    "description": "運転時間",
+                    "type": "number",
+                    "example": 8400
+                },
+                "ferry_trips": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "month": {
+                    "type": "string",
+                    "example": "2025-01"
+                },
+                "night_minutes": {
+                    "description": "深夜（22:00〜05:00）の労働時間",
+                    "type": "number",
+                    "example": 600
+                },
+                "overtime_minutes": {
+                    "description": "1日の所定労働時間を超えた時間の合計",
+                    "type": "number",
+                    "example": 1800
+                },
+                "working_days": {
+                    "description": "運行日の日数",
+                    "type": "integer",
+                    "example": 22
+                }
+            }
+        },
+        "models.ReadinessReport": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string",
+                    "example": "2025-01-13T15:04:05+09:00"
+                },
+                "databases": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DatabaseHealth"
+                    }
+                },
+                "imports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportHealth"
+                    }
                 },
-                "vehicle_no": {
+                "status": {
                     "type": "string",
-                    "example": "vehicle-001"
+                    "enum": [
+                        "ok",
+                        "degraded",
+                        "unavailable"
+                    ],
+                    "example": "ok"
                 }
             }
         },
-        "models.DtakoFerryRow": {
+        "models.RetentionReport": {
             "type": "object",
             "properties": {
-                "boarding_code": {
-                    "description": "乗場CD",
-                    "type": "integer",
-                    "example": 1
+                "cutoff": {
+                    "description": "YYYY-MM-DD、これより前が対象",
+                    "type": "string",
+                    "example": "2022-01-13"
                 },
-                "boarding_name": {
-                    "description": "乗場名",
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "finished_at": {
                     "type": "string",
-                    "example": "東京港"
+                    "example": "2025-01-13T03:00:42Z"
                 },
-                "contract_fare": {
-                    "description": "契約料金",
-                    "type": "integer",
-                    "example": 8000
+                "mode": {
+                    "description": "anonymize / purge",
+                    "type": "string",
+                    "example": "anonymize"
                 },
-                "driver_code_1": {
-                    "description": "乗務員CD1",
+                "retention_days": {
                     "type": "integer",
-                    "example": 1001
+                    "example": 1095
                 },
-                "driver_name_1": {
-                    "description": "乗務員名１",
+                "started_at": {
                     "type": "string",
-                    "example": "山田太郎"
+                    "example": "2025-01-13T03:00:00Z"
                 },
-                "end_time": {
-                    "description": "終了日時",
-                    "type": "string",
-                    "example": "2025-01-13T12:00:00Z"
+                "tables": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RetentionTableResult"
+                    }
                 },
-                "estimated_distance": {
-                    "description": "見なし距離",
+                "total_records": {
                     "type": "integer",
-                    "example": 500
+                    "example": 15230
+                }
+            }
+        },
+        "models.RetentionTableResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "anonymize / purge",
+                    "type": "string",
+                    "example": "anonymize"
                 },
-                "ferry_company_code": {
-                    "description": "フェリー会社CD",
+                "columns": {
+                    "description": "匿名化した列",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "乗務員CD1",
+                        "乗務員名１"
+                    ]
+                },
+                "records": {
                     "type": "integer",
-                    "example": 1
+                    "example": 320
                 },
-                "ferry_company_name": {
-                    "description": "フェリー会社名",
+                "table": {
                     "type": "string",
-                    "example": "東京フェリー"
-                },
-                "ferry_search": {
-                    "description": "ferry_srch",
+                    "example": "dtako_ferry_rows"
+                }
+            }
+        },
+        "models.RowStats": {
+            "type": "object",
+            "properties": {
+                "from": {
                     "type": "string",
-                    "example": "東京-大阪"
+                    "example": "2025-01-01"
                 },
-                "id": {
-                    "type": "integer",
-                    "example": 1
+                "group_by": {
+                    "type": "string",
+                    "example": "vehicle"
                 },
-                "landing_code": {
-                    "description": "降場CD",
-                    "type": "integer",
-                    "example": 2
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RowStatsGroup"
+                    }
                 },
-                "landing_name": {
-                    "description": "降場名",
+                "previous_from": {
                     "type": "string",
-                    "example": "大阪港"
-                },
-                "office_code": {
-                    "description": "事業所CD",
-                    "type": "integer",
-                    "example": 1
+                    "example": "2024-12-01"
                 },
-                "office_name": {
-                    "description": "事業所名",
+                "previous_to": {
                     "type": "string",
-                    "example": "東京事業所"
+                    "example": "2024-12-31"
                 },
-                "read_date": {
-                    "description": "読取日",
+                "to": {
                     "type": "string",
-                    "example": "2025-01-13T00:00:00Z"
+                    "example": "2025-01-31"
                 },
-                "settlement_class": {
-                    "description": "精算区分",
-                    "type": "integer",
-                    "example": 1
+                "total": {
+                    "$ref": "#/definitions/models.RowStatsGroup"
+                }
+            }
+        },
+        "models.RowStatsChange": {
+            "type": "object",
+            "properties": {
+                "distance_pct": {
+                    "type": "number",
+                    "example": 8.5
                 },
-                "settlement_name": {
-                    "description": "精算区分名",
-                    "type": "string",
-                    "example": "現金"
+                "fuel_efficiency_pct": {
+                    "type": "number",
+                    "example": 10.8
                 },
-                "ship_number": {
-                    "description": "便",
-                    "type": "string",
-                    "example": "1便"
+                "fuel_pct": {
+                    "type": "number",
+                    "example": -2.1
                 },
-                "ship_vehicle_class": {
-                    "description": "航送車種区分",
+                "trips": {
                     "type": "integer",
-                    "example": 1
+                    "example": 3
+                }
+            }
+        },
+        "models.RowStatsGroup": {
+            "type": "object",
+            "properties": {
+                "avg_distance": {
+                    "description": "1運行あたりの平均距離",
+                    "type": "number",
+                    "example": 293.9
                 },
-                "ship_vehicle_name": {
-                    "description": "航送車種区分名",
-                    "type": "string",
-                    "example": "大型車"
+                "avg_fuel": {
+                    "description": "1運行あたりの平均燃料",
+                    "type": "number",
+                    "example": 82.3
                 },
-                "standard_fare": {
-                    "description": "標準料金",
-                    "type": "integer",
-                    "example": 10000
+                "change": {
+                    "$ref": "#/definitions/models.RowStatsChange"
                 },
-                "start_time": {
-                    "description": "開始日時",
+                "fuel_efficiency": {
+                    "description": "km/L（燃料が0の場合は省略）",
+                    "type": "number",
+                    "example": 3.57
+                },
+                "key": {
+                    "description": "車輌CD / 乗務員CD / 事業所CD / YYYY-MM-DD / YYYY-MM",
                     "type": "string",
-                    "example": "2025-01-13T08:00:00Z"
+                    "example": "101"
                 },
-                "target_driver_class": {
-                    "description": "対象乗務員区分",
-                    "type": "integer",
-                    "example": 1
+                "previous": {
+                    "$ref": "#/definitions/models.RowStatsValues"
                 },
-                "unko_date": {
-                    "description": "運行日",
-                    "type": "string",
-                    "example": "2025-01-13T00:00:00Z"
+                "total_distance": {
+                    "description": "総走行距離の合計",
+                    "type": "number",
+                    "example": 12345.6
                 },
-                "unko_no": {
-                    "description": "運行NO",
-                    "type": "string",
-                    "example": "2025010101"
+                "total_fuel": {
+                    "description": "自社主燃料の合計",
+                    "type": "number",
+                    "example": 3456.7
                 },
-                "vehicle_code": {
-                    "description": "車輌CD",
+                "trips": {
+                    "description": "運行件数",
                     "type": "integer",
-                    "example": 101
-                },
-                "vehicle_name": {
-                    "description": "車輌名",
-                    "type": "string",
-                    "example": "トラック1号"
+                    "example": 42
                 }
             }
         },
-        "models.DtakoRow": {
+        "models.RowStatsValues": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string",
-                    "example": "2025-01-13T15:04:05Z"
+                "avg_distance": {
+                    "description": "1運行あたりの平均距離",
+                    "type": "number",
+                    "example": 293.9
                 },
-                "date": {
-                    "type": "string",
-                    "example": "2025-01-13T00:00:00Z"
+                "avg_fuel": {
+                    "description": "1運行あたりの平均燃料",
+                    "type": "number",
+                    "example": 82.3
                 },
-                "distance": {
+                "fuel_efficiency": {
+                    "description": "km/L（燃料が0の場合は省略）",
                     "type": "number",
-                    "example": 123.45
+                    "example": 3.57
                 },
-                "driver_code": {
-                    "type": "string",
-                    "example": "driver-123"
+                "total_distance": {
+                    "description": "総走行距離の合計",
+                    "type": "number",
+                    "example": 12345.6
                 },
-                "fuel_amount": {
+                "total_fuel": {
+                    "description": "自社主燃料の合計",
                     "type": "number",
-                    "example": 45.67
+                    "example": 3456.7
                 },
-                "id": {
+                "trips": {
+                    "description": "運行件数",
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "models.RowWithoutEvents": {
+            "type": "object",
+            "properties": {
+                "date": {
                     "type": "string",
-                    "example": "row-123"
+                    "example": "2025-01-13T00:00:00Z"
                 },
-                "route_code": {
+                "id": {
                     "type": "string",
-                    "example": "route-A"
+                    "example": "row-123"
                 },
                 "unko_no": {
-                    "description": "運行NO",
                     "type": "string",
                     "example": "2025010101"
                 },
-                "updated_at": {
-                    "type": "string",
-                    "example": "2025-01-13T15:04:05Z"
-                },
                 "vehicle_no": {
                     "type": "string",
-                    "example": "vehicle-001"
+                    "example": "101"
                 }
             }
         },
-        "models.ErrorResponse": {
+        "models.ValidationFinding": {
             "type": "object",
             "properties": {
-                "code": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-01-13T15:04:05Z"
+                },
+                "id": {
                     "type": "integer",
-                    "example": 400
+                    "example": 1
                 },
                 "message": {
                     "type": "string",
-                    "example": "Invalid request parameters"
+                    "example": "総走行距離 is negative (-12.5)"
+                },
+                "record_id": {
+                    "type": "string",
+                    "example": "row-123"
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "negative_distance"
+                },
+                "severity": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ValidationSeverity"
+                        }
+                    ],
+                    "example": "reject"
+                },
+                "table": {
+                    "type": "string",
+                    "example": "dtako_rows"
                 }
             }
         },
-        "models.ImportRequest": {
+        "models.ValidationSeverity": {
+            "type": "string",
+            "enum": [
+                "reject",
+                "warn",
+                "fix"
+            ],
+            "x-enum-comments": {
+                "SeverityFix": "値を補正して取り込む",
+                "SeverityReject": "レコードを取り込まない",
+                "SeverityWarn": "そのまま取り込み、指摘だけ残す"
+            },
+            "x-enum-descriptions": [
+                "レコードを取り込まない",
+                "そのまま取り込み、指摘だけ残す",
+                "値を補正して取り込む"
+            ],
+            "x-enum-varnames": [
+                "SeverityReject",
+                "SeverityWarn",
+                "SeverityFix"
+            ]
+        },
+        "models.VehicleCoverage": {
             "type": "object",
             "properties": {
-                "event_type": {
-                    "description": "For events",
-                    "type": "string",
-                    "example": "運転"
+                "active": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "ferry_company": {
-                    "description": "For ferry rows",
-                    "type": "string",
-                    "example": "東京フェリー"
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CoverageDay"
+                    }
                 },
-                "from_date": {
-                    "type": "string",
-                    "example": "2025-01-01"
+                "mismatch_days": {
+                    "description": "not_imported + local_only",
+                    "type": "integer",
+                    "example": 1
                 },
-                "to_date": {
+                "missing_days": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "vehicle_no": {
+                    "description": "車輌CD",
                     "type": "string",
-                    "example": "2025-01-31"
+                    "example": "101"
                 }
             }
         },
-        "models.ImportResult": {
+        "models.VehicleOdometerReport": {
             "type": "object",
             "properties": {
-                "errors": {
+                "issues": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/models.OdometerIssue"
                     }
                 },
-                "imported_at": {
-                    "type": "string",
-                    "example": "2025-01-13T15:04:05Z"
+                "trips": {
+                    "type": "integer",
+                    "example": 22
                 },
-                "imported_rows": {
+                "trips_without_odometer": {
                     "type": "integer",
-                    "example": 150
+                    "example": 1
                 },
-                "message": {
+                "vehicle_no": {
+                    "description": "車輌CD",
                     "type": "string",
-                    "example": "Imported 150 rows successfully"
-                },
-                "success": {
-                    "type": "boolean",
-                    "example": true
+                    "example": "101"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key listed (as SHA-256) in AUTH_API_KEYS_FILE",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "JWT signed by a key of AUTH_JWKS_FILE, as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
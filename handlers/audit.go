@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/services"
+)
+
+// AuditHandler handles audit log requests
+type AuditHandler struct {
+	service *services.AuditService
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler() *AuditHandler {
+	return &AuditHandler{
+		service: services.NewAuditService(),
+	}
+}
+
+// List lists recorded imports, reconciliation and master-data changes
+// @Summary      List audit log
+// @Description  Who triggered imports, reconciliation (fetch_missing), master-data changes and retention runs, with parameters, result counts and errors, newest first
+// @Description  Returns at most 1000 entries unless limit is given; page with limit/offset.
+// @Tags         audit
+// @Produce      json
+// @Param        from    query     string  false  "Start date of recording (YYYY-MM-DD)"
+// @Param        to      query     string  false  "End date of recording (YYYY-MM-DD)"
+// @Param        actor   query     string  false  "Actor (token subject or API key name)"
+// @Param        action  query     string  false  "Action"  Enums(rows.import, events.import, ferry_rows.import, integrity.fetch_missing, masters.create, masters.update, masters.delete, masters.import_csv, masters.sync, event_types.put, event_types.delete, event_types.sync, geofences.create, geofences.update, geofences.delete, retention.run)
+// @Param        limit   query     int     false  "Maximum number of entries (default 1000, at most 10000)"
+// @Param        offset  query     int     false  "Number of entries to skip"
+// @Success      200     {array}   models.AuditEntry
+// @Failure      400     {object}  models.ErrorResponse
+// @Failure      500     {object}  models.ErrorResponse
+// @Failure      503     {object}  models.ErrorResponse
+// @Failure      401     {object}  models.ErrorResponse
+// @Failure      403     {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /audit [get]
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var page models.ListQuery
+	if err := parsePage(r, &page); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	entries, err := h.service.List(r.Context(), query.Get("from"), query.Get("to"), query.Get("actor"), query.Get("action"), page)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/yhonda-ohishi/dtako_mod/auth"
+	"github.com/yhonda-ohishi/dtako_mod/logging"
+)
+
+// Authenticate identifies the caller with auth.Current() and stores it in the
+// request context; requests without valid credentials get 401. Only when
+// authentication is explicitly disabled (auth.Current() is nil) do requests
+// pass unchanged.
+func Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authenticator := auth.Current()
+		if authenticator == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, err := authenticator.Authenticate(r)
+		if err != nil {
+			logging.Logger().WarnContext(r.Context(), "authentication failed", "error", err)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="dtako"`)
+			writeError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// RequireRole rejects callers below role with 403
+func RequireRole(role auth.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if p, ok := auth.FromContext(r.Context()); ok && !p.Allows(role) {
+				writeError(w, r, fmt.Errorf("%w: requires role %s", auth.ErrForbidden, role))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireAllOffices rejects callers restricted to some 事業所CD with 403.
+// It guards endpoints whose data cannot be filtered per office (payroll,
+// integrity, masters) and imports, which write every office's records.
+func RequireAllOffices(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, restricted := auth.OfficeScope(r.Context()); restricted {
+			writeError(w, r, fmt.Errorf("%w: requires access to all offices", auth.ErrForbidden))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/yhonda-ohishi/dtako_mod/services"
+)
+
+// CoverageHandler handles coverage calendar requests
+type CoverageHandler struct {
+	service *services.CoverageService
+}
+
+// NewCoverageHandler creates a new coverage handler
+func NewCoverageHandler() *CoverageHandler {
+	return &CoverageHandler{
+		service: services.NewCoverageService(),
+	}
+}
+
+// Get returns the coverage calendar
+// @Summary      Missing-data coverage calendar
+// @Description  Vehicle × date matrix of trip counts (dtako_rows) in the local database, compared with production
+// @Description  and with the active vehicles configured in ACTIVE_VEHICLES. At most 92 days.
+// @Tags         coverage
+// @Produce      json
+// @Param        from    query     string  false  "Start date (YYYY-MM-DD)"
+// @Param        to      query     string  false  "End date (YYYY-MM-DD)"
+// @Param        office  query     string  false  "事業所CD"
+// @Success      200     {object}  models.CoverageReport
+// @Failure      400     {object}  models.ErrorResponse  "Invalid request parameters"
+// @Failure      500     {object}  models.ErrorResponse  "Internal Server Error"
+// @Failure      401     {object}  models.ErrorResponse  "Unauthenticated"
+// @Failure      403     {object}  models.ErrorResponse  "Forbidden"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /coverage [get]
+func (h *CoverageHandler) Get(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	report, err := h.service.GetCoverage(r.Context(), query.Get("from"), query.Get("to"), query.Get("office"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
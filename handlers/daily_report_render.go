@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/yhonda-ohishi/dtako_mod/config"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// errReportFontMissing is returned when no font with Japanese glyphs is available for PDF output
+var errReportFontMissing = errors.New("PDF output needs a Japanese TrueType font: set REPORT_FONT_PATH")
+
+// reportFontPaths are tried in order when REPORT_FONT_PATH is not set
+var reportFontPaths = []string{
+	"/usr/share/fonts/opentype/ipaexfont-gothic/ipaexg.ttf",
+	"/usr/share/fonts/truetype/fonts-japanese-gothic.ttf",
+	"/usr/share/fonts/opentype/ipafont-gothic/ipagp.ttf",
+}
+
+// categoryLabels are the report labels of event categories
+var categoryLabels = map[string]string{
+	models.EventCategoryDriving: "運転",
+	models.EventCategoryRest:    "休憩",
+	models.EventCategoryWork:    "作業",
+	models.EventCategoryWaiting: "待機",
+	models.EventCategoryOther:   "その他",
+}
+
+// formatMinutes formats minutes as h:mm
+func formatMinutes(minutes float64) string {
+	m := int(minutes + 0.5)
+	return fmt.Sprintf("%d:%02d", m/60, m%60)
+}
+
+// formatClock formats a time as HH:MM in the business time zone, with the
+// date when it is not the report date
+func formatClock(t time.Time, date string) string {
+	t = t.In(config.BusinessLocation())
+	if t.Format("2006-01-02") != date {
+		return t.Format("01/02 15:04")
+	}
+	return t.Format("15:04")
+}
+
+func formatClockPtr(t *time.Time, date string) string {
+	if t == nil {
+		return ""
+	}
+	return formatClock(*t, date)
+}
+
+func formatOdometer(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.1f", *v)
+}
+
+var dailyReportTemplate = template.Must(template.New("daily_report").Funcs(template.FuncMap{
+	"minutes":  formatMinutes,
+	"clock":    formatClock,
+	"clockPtr": formatClockPtr,
+	"odometer": formatOdometer,
+	"category": func(c string) string { return categoryLabels[c] },
+}).Parse(`<!DOCTYPE html>
+<html lang="ja">
+<head>
+<meta charset="utf-8">
+<title>運転日報 {{.Date}} {{.DriverCode}}</title>
+<style>
+@page { size: A4 landscape; margin: 10mm; }
+body { font-family: "IPAexGothic", "Noto Sans JP", sans-serif; font-size: 10pt; }
+h1 { font-size: 16pt; text-align: center; margin: 0 0 8px; }
+h2 { font-size: 11pt; margin: 12px 0 4px; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #333; padding: 2px 4px; }
+th { background: #eee; }
+td.num { text-align: right; }
+.header td { border: none; }
+.sign { width: auto; margin: 16px 0 0 auto; }
+.sign td { width: 25mm; height: 20mm; }
+</style>
+</head>
+<body>
+<h1>運転日報</h1>
+<table class="header">
+<tr><td>運行日: {{.Date}}</td><td>乗務員: {{.DriverCode}} {{.DriverName}}</td></tr>
+</table>
+
+<h2>運行</h2>
+<table>
+<tr><th>運行NO</th><th>車輌</th><th>行先</th><th>出庫</th><th>帰庫</th><th>出発地</th><th>到着地</th><th>開始メーター</th><th>終了メーター</th><th>走行距離(km)</th><th>燃料(L)</th></tr>
+{{range .Trips}}<tr><td>{{.UnkoNo}}</td><td>{{.VehicleNo}} {{.VehicleName}}</td><td>{{.Route}}</td><td>{{clockPtr .StartAt $.Date}}</td><td>{{clockPtr .EndAt $.Date}}</td><td>{{.StartPlace}}</td><td>{{.EndPlace}}</td><td class="num">{{odometer .StartOdometer}}</td><td class="num">{{odometer .EndOdometer}}</td><td class="num">{{printf "%.1f" .Distance}}</td><td class="num">{{printf "%.1f" .FuelAmount}}</td></tr>
+{{end}}</table>
+
+<h2>経過</h2>
+<table>
+<tr><th>開始</th><th>終了</th><th>イベント</th><th>区分</th><th>時間</th><th>区間距離(km)</th><th>開始地点</th><th>終了地点</th></tr>
+{{range .Timeline}}<tr><td>{{clock .StartAt $.Date}}</td><td>{{clockPtr .EndAt $.Date}}</td><td>{{.EventType}}</td><td>{{category .Category}}</td><td class="num">{{minutes .Minutes}}</td><td class="num">{{printf "%.1f" .Distance}}</td><td>{{.StartPlace}}</td><td>{{.EndPlace}}</td></tr>
+{{end}}</table>
+{{if .Ferries}}
+<h2>フェリー</h2>
+<table>
+<tr><th>運行NO</th><th>フェリー会社</th><th>乗場</th><th>降場</th><th>乗船</th><th>下船</th><th>契約料金</th></tr>
+{{range .Ferries}}<tr><td>{{.UnkoNo}}</td><td>{{.Company}}</td><td>{{.Boarding}}</td><td>{{.Landing}}</td><td>{{clock .StartAt $.Date}}</td><td>{{clock .EndAt $.Date}}</td><td class="num">{{.ContractFare}}</td></tr>
+{{end}}</table>
+{{end}}
+<h2>合計</h2>
+<table>
+<tr><th>運行数</th><th>走行距離(km)</th><th>燃料(L)</th><th>拘束時間</th><th>運転</th><th>休憩</th><th>作業</th><th>待機</th><th>フェリー</th></tr>
+<tr><td class="num">{{.Totals.TripCount}}</td><td class="num">{{printf "%.1f" .Totals.Distance}}</td><td class="num">{{printf "%.1f" .Totals.FuelAmount}}</td><td class="num">{{minutes .Totals.BindingMinutes}}</td><td class="num">{{minutes .Totals.DrivingMinutes}}</td><td class="num">{{minutes .Totals.RestMinutes}}</td><td class="num">{{minutes .Totals.WorkMinutes}}</td><td class="num">{{minutes .Totals.WaitingMinutes}}</td><td class="num">{{.Totals.FerryCount}}</td></tr>
+</table>
+
+<table class="sign">
+<tr><th>乗務員</th><th>運行管理者</th></tr>
+<tr><td></td><td></td></tr>
+</table>
+</body>
+</html>
+`))
+
+// renderDailyReportHTML writes the report as a printable HTML page
+func renderDailyReportHTML(w io.Writer, report *models.DailyReport) error {
+	return dailyReportTemplate.Execute(w, report)
+}
+
+// reportFontPath returns REPORT_FONT_PATH or the first installed default font
+func reportFontPath() (string, error) {
+	if path := os.Getenv("REPORT_FONT_PATH"); path != "" {
+		return path, nil
+	}
+	for _, path := range reportFontPaths {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", errReportFontMissing
+}
+
+// pdfTable writes a table with a header row. widths are in mm.
+func pdfTable(pdf *gofpdf.Fpdf, widths []float64, header []string, rows [][]string) {
+	pdf.SetFillColor(238, 238, 238)
+	for i, h := range header {
+		pdf.CellFormat(widths[i], 6, h, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+	for _, row := range rows {
+		for i, v := range row {
+			pdf.CellFormat(widths[i], 6, v, "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
+
+// renderDailyReportPDF writes the report as an A4 landscape PDF with signature boxes
+func renderDailyReportPDF(w io.Writer, report *models.DailyReport) error {
+	fontPath, err := reportFontPath()
+	if err != nil {
+		return err
+	}
+	font, err := os.ReadFile(fontPath)
+	if err != nil {
+		return fmt.Errorf("failed to read report font: %v", err)
+	}
+
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes("report", "", font)
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(true, 10)
+	pdf.AddPage()
+
+	pdf.SetFont("report", "", 16)
+	pdf.CellFormat(0, 10, "運転日報", "", 1, "C", false, 0, "")
+	pdf.SetFont("report", "", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf("運行日: %s    乗務員: %s %s", report.Date, report.DriverCode, report.DriverName), "", 1, "L", false, 0, "")
+
+	section := func(title string) {
+		pdf.Ln(2)
+		pdf.SetFont("report", "", 11)
+		pdf.CellFormat(0, 7, title, "", 1, "L", false, 0, "")
+		pdf.SetFont("report", "", 8)
+	}
+
+	section("運行")
+	trips := [][]string{}
+	for _, t := range report.Trips {
+		trips = append(trips, []string{
+			t.UnkoNo, t.VehicleNo + " " + t.VehicleName, t.Route,
+			formatClockPtr(t.StartAt, report.Date), formatClockPtr(t.EndAt, report.Date),
+			t.StartPlace, t.EndPlace, formatOdometer(t.StartOdometer), formatOdometer(t.EndOdometer),
+			fmt.Sprintf("%.1f", t.Distance), fmt.Sprintf("%.1f", t.FuelAmount),
+		})
+	}
+	pdfTable(pdf, []float64{30, 35, 25, 20, 20, 35, 35, 22, 22, 16, 17},
+		[]string{"運行NO", "車輌", "行先", "出庫", "帰庫", "出発地", "到着地", "開始メーター", "終了メーター", "距離(km)", "燃料(L)"}, trips)
+
+	section("経過")
+	timeline := [][]string{}
+	for _, e := range report.Timeline {
+		timeline = append(timeline, []string{
+			formatClock(e.StartAt, report.Date), formatClockPtr(e.EndAt, report.Date), e.EventType,
+			categoryLabels[e.Category], formatMinutes(e.Minutes), fmt.Sprintf("%.1f", e.Distance),
+			e.StartPlace, e.EndPlace,
+		})
+	}
+	pdfTable(pdf, []float64{25, 25, 35, 20, 20, 22, 65, 65},
+		[]string{"開始", "終了", "イベント", "区分", "時間", "区間距離(km)", "開始地点", "終了地点"}, timeline)
+
+	if len(report.Ferries) > 0 {
+		section("フェリー")
+		ferries := [][]string{}
+		for _, f := range report.Ferries {
+			ferries = append(ferries, []string{
+				f.UnkoNo, f.Company, f.Boarding, f.Landing,
+				formatClock(f.StartAt, report.Date), formatClock(f.EndAt, report.Date), fmt.Sprintf("%d", f.ContractFare),
+			})
+		}
+		pdfTable(pdf, []float64{35, 50, 40, 40, 30, 30, 25},
+			[]string{"運行NO", "フェリー会社", "乗場", "降場", "乗船", "下船", "契約料金"}, ferries)
+	}
+
+	section("合計")
+	t := report.Totals
+	pdfTable(pdf, []float64{25, 30, 25, 30, 30, 30, 30, 30, 25},
+		[]string{"運行数", "走行距離(km)", "燃料(L)", "拘束時間", "運転", "休憩", "作業", "待機", "フェリー"},
+		[][]string{{
+			fmt.Sprintf("%d", t.TripCount), fmt.Sprintf("%.1f", t.Distance), fmt.Sprintf("%.1f", t.FuelAmount),
+			formatMinutes(t.BindingMinutes), formatMinutes(t.DrivingMinutes), formatMinutes(t.RestMinutes),
+			formatMinutes(t.WorkMinutes), formatMinutes(t.WaitingMinutes), fmt.Sprintf("%d", t.FerryCount),
+		}})
+
+	// 押印欄
+	pageWidth, _ := pdf.GetPageSize()
+	pdf.Ln(4)
+	pdf.SetX(pageWidth - 10 - 60)
+	pdf.CellFormat(30, 6, "乗務員", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(30, 6, "運行管理者", "1", 1, "C", true, 0, "")
+	pdf.SetX(pageWidth - 10 - 60)
+	pdf.CellFormat(30, 20, "", "1", 0, "C", false, 0, "")
+	pdf.CellFormat(30, 20, "", "1", 1, "C", false, 0, "")
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
@@ -3,9 +3,9 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/yhonda-ohishi/dtako_mod/config"
 	"github.com/yhonda-ohishi/dtako_mod/models"
 	"github.com/yhonda-ohishi/dtako_mod/services"
 )
@@ -24,33 +24,68 @@ func NewDtakoEventsHandler() *DtakoEventsHandler {
 
 // List lists dtako events
 // @Summary      List Dtako Events
-// @Description  Get event data with location information and optional filtering
+// @Description  Get event data with location information and optional filtering.
+// @Description  Send "Accept: application/x-ndjson" to stream one record per line.
+// @Description  Driver names and codes are masked for roles below DRIVER_NAME_MIN_ROLE / DRIVER_CODE_MIN_ROLE; filtering by driver is then forbidden.
 // @Tags         dtako_events
 // @Accept       json
-// @Produce      json
+// @Produce      json,application/x-ndjson
 // @Param        from     query     string  false  "Start date (YYYY-MM-DD)"
 // @Param        to       query     string  false  "End date (YYYY-MM-DD)"
-// @Param        type     query     string  false  "Event type filter"
-// @Param        unko_no  query     string  false  "Filter by 運行NO (links to dtako_rows)"
+// @Param        type     query     string  false  "Event type filter (comma separated for multiple)"
+// @Param        unko_no  query     string  false  "Filter by 運行NO (links to dtako_rows, comma separated for multiple)"
+// @Param        vehicle  query     string  false  "Filter by 車輌CD (comma separated for multiple)"
+// @Param        driver   query     string  false  "Filter by 対象乗務員CD (comma separated for multiple)"
+// @Param        office   query     string  false  "Filter by 事業所CD (comma separated for multiple)"
+// @Param        sort     query     string  false  "Sort fields, prefix - for descending (e.g. -event_date)"
+// @Param        fields   query     string  false  "Fields to return (e.g. id,event_type,event_date)"
+// @Param        limit    query     int     false  "Maximum number of records (1-10000, default all)"
+// @Param        offset   query     int     false  "Records to skip (requires limit)"
 // @Success      200      {array}   models.DtakoEvent  "List of dtako events"
 // @Failure      400      {object}  models.ErrorResponse  "Invalid request parameters"
 // @Failure      500      {object}  models.ErrorResponse  "Internal Server Error"
+// @Failure      401      {object}  models.ErrorResponse  "Unauthenticated"
+// @Failure      403      {object}  models.ErrorResponse  "Forbidden"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /events [get]
 func (h *DtakoEventsHandler) List(w http.ResponseWriter, r *http.Request) {
 	// Get query parameters
 	from := r.URL.Query().Get("from")
 	to := r.URL.Query().Get("to")
-	eventType := r.URL.Query().Get("type")
-	unkoNo := r.URL.Query().Get("unko_no")
+	q, err := parseListQuery(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if wantsNDJSON(r) {
+		streamNDJSON(w, r, func(nw *ndjsonWriter) error {
+			return h.service.StreamEvents(r.Context(), from, to, q, func(event *models.DtakoEvent) error {
+				projected, err := projectFields(event, q.Fields)
+				if err != nil {
+					return err
+				}
+				return nw.Write(projected)
+			})
+		})
+		return
+	}
+
+	events, err := h.service.GetEvents(r.Context(), from, to, q)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
 
-	events, err := h.service.GetEvents(from, to, eventType, unkoNo)
+	body, err := projectList(events, q.Fields)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(events)
+	json.NewEncoder(w).Encode(body)
 }
 
 // Import imports dtako_events from production
@@ -63,25 +98,30 @@ func (h *DtakoEventsHandler) List(w http.ResponseWriter, r *http.Request) {
 // @Success      200     {object}  models.ImportResult  "Import successful"
 // @Failure      400     {object}  models.ErrorResponse  "Bad Request"
 // @Failure      500     {object}  models.ErrorResponse  "Internal Server Error"
+// @Failure      503     {object}  models.ErrorResponse  "Database unavailable"
+// @Failure      401     {object}  models.ErrorResponse  "Unauthenticated"
+// @Failure      403     {object}  models.ErrorResponse  "Forbidden"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /events/import [post]
 func (h *DtakoEventsHandler) Import(w http.ResponseWriter, r *http.Request) {
 	var req models.ImportRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
 	// Set default date range if not provided
 	if req.FromDate == "" {
-		req.FromDate = time.Now().AddDate(0, -1, 0).Format("2006-01-02")
+		req.FromDate = config.Now().AddDate(0, -1, 0).Format("2006-01-02")
 	}
 	if req.ToDate == "" {
-		req.ToDate = time.Now().Format("2006-01-02")
+		req.ToDate = config.Now().Format("2006-01-02")
 	}
 
-	result, err := h.service.ImportFromProduction(req.FromDate, req.ToDate, req.EventType)
+	result, err := h.service.ImportFromProduction(r.Context(), req.FromDate, req.ToDate, req.EventType)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -98,13 +138,19 @@ func (h *DtakoEventsHandler) Import(w http.ResponseWriter, r *http.Request) {
 // @Param        id      path      string  true  "Event ID"
 // @Success      200     {object}  models.DtakoEvent  "Dtako event found"
 // @Failure      404     {object}  models.ErrorResponse  "Not Found"
+// @Failure      500     {object}  models.ErrorResponse  "Internal Server Error"
+// @Failure      503     {object}  models.ErrorResponse  "Database unavailable"
+// @Failure      401     {object}  models.ErrorResponse  "Unauthenticated"
+// @Failure      403     {object}  models.ErrorResponse  "Forbidden"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /events/{id} [get]
 func (h *DtakoEventsHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	
-	event, err := h.service.GetEventByID(id)
+	event, err := h.service.GetEventByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, r, err)
 		return
 	}
 
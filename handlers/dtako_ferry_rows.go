@@ -23,30 +23,69 @@ func NewDtakoFerryRowsHandler() *DtakoFerryRowsHandler {
 
 // List handles GET /ferry_rows
 // @Summary      List ferry row records
-// @Description  Retrieve ferry row records with optional date range and ferry company filter
+// @Description  Retrieve ferry row records with optional date range and attribute filters.
+// @Description  Send "Accept: application/x-ndjson" to stream one record per line.
+// @Description  Driver names and codes are masked for roles below DRIVER_NAME_MIN_ROLE / DRIVER_CODE_MIN_ROLE; filtering by driver is then forbidden.
 // @Tags         dtako_ferry
 // @Accept       json
-// @Produce      json
+// @Produce      json,application/x-ndjson
 // @Param        from          query     string  false  "Start date (YYYY-MM-DD)"
 // @Param        to            query     string  false  "End date (YYYY-MM-DD)"
-// @Param        ferry_company query     string  false  "Filter by ferry company name"
+// @Param        ferry_company query     string  false  "Filter by ferry company name (comma separated for multiple)"
+// @Param        vehicle       query     string  false  "Filter by 車輌CD (comma separated for multiple)"
+// @Param        driver        query     string  false  "Filter by 乗務員CD1 (comma separated for multiple)"
+// @Param        office        query     string  false  "Filter by 事業所CD (comma separated for multiple)"
+// @Param        unko_no       query     string  false  "Filter by 運行NO (comma separated for multiple)"
+// @Param        boarding      query     string  false  "Filter by 乗場CD (comma separated for multiple)"
+// @Param        landing       query     string  false  "Filter by 降場CD (comma separated for multiple)"
+// @Param        sort          query     string  false  "Sort fields, prefix - for descending (e.g. -unko_date,-start_time)"
+// @Param        fields        query     string  false  "Fields to return (e.g. id,unko_no,ferry_company_name)"
+// @Param        limit         query     int     false  "Maximum number of records (1-10000, default all)"
+// @Param        offset        query     int     false  "Records to skip (requires limit)"
 // @Success      200           {array}   models.DtakoFerryRow
 // @Failure      400           {object}  models.ErrorResponse
 // @Failure      500           {object}  models.ErrorResponse
+// @Failure      401           {object}  models.ErrorResponse
+// @Failure      403           {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /ferry_rows [get]
 func (h *DtakoFerryRowsHandler) List(w http.ResponseWriter, r *http.Request) {
 	from := r.URL.Query().Get("from")
 	to := r.URL.Query().Get("to")
-	ferryCompany := r.URL.Query().Get("ferry_company")
+	q, err := parseListQuery(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if wantsNDJSON(r) {
+		streamNDJSON(w, r, func(nw *ndjsonWriter) error {
+			return h.service.StreamFerryRows(r.Context(), from, to, q, func(record *models.DtakoFerryRow) error {
+				projected, err := projectFields(record, q.Fields)
+				if err != nil {
+					return err
+				}
+				return nw.Write(projected)
+			})
+		})
+		return
+	}
+
+	records, err := h.service.GetFerryRows(r.Context(), from, to, q)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
 
-	records, err := h.service.GetFerryRows(from, to, ferryCompany)
+	body, err := projectList(records, q.Fields)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(records)
+	json.NewEncoder(w).Encode(body)
 }
 
 // GetByID handles GET /ferry_rows/{id}
@@ -59,21 +98,22 @@ func (h *DtakoFerryRowsHandler) List(w http.ResponseWriter, r *http.Request) {
 // @Success      200  {object}  models.DtakoFerryRow
 // @Failure      404  {object}  models.ErrorResponse
 // @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      403  {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /ferry_rows/{id} [get]
 func (h *DtakoFerryRowsHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	if id == "" {
-		http.Error(w, "ID is required", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, "ID is required")
 		return
 	}
 
-	record, err := h.service.GetFerryRowByID(id)
+	record, err := h.service.GetFerryRowByID(r.Context(), id)
 	if err != nil {
-		if err.Error() == "ferry row record not found: "+id {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+		writeError(w, r, err)
 		return
 	}
 
@@ -91,22 +131,27 @@ func (h *DtakoFerryRowsHandler) GetByID(w http.ResponseWriter, r *http.Request)
 // @Success      200      {object}  models.ImportResult
 // @Failure      400      {object}  models.ErrorResponse
 // @Failure      500      {object}  models.ErrorResponse
+// @Failure      503      {object}  models.ErrorResponse
+// @Failure      401      {object}  models.ErrorResponse
+// @Failure      403      {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /ferry_rows/import [post]
 func (h *DtakoFerryRowsHandler) Import(w http.ResponseWriter, r *http.Request) {
 	var req models.ImportRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
 	if req.FromDate == "" || req.ToDate == "" {
-		http.Error(w, "from_date and to_date are required", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, "from_date and to_date are required")
 		return
 	}
 
-	result, err := h.service.ImportFromProduction(req.FromDate, req.ToDate, req.FerryCompany)
+	result, err := h.service.ImportFromProduction(r.Context(), req.FromDate, req.ToDate, req.FerryCompany)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
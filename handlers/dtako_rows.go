@@ -5,14 +5,24 @@
 //	@description	Digital tachograph data management API for vehicle operation records
 //	@BasePath		/dtako
 //	@host			localhost:8080
+//
+//	@securityDefinitions.apikey	BearerAuth
+//	@in							header
+//	@name						Authorization
+//	@description				JWT signed by a key of AUTH_JWKS_FILE, as "Bearer <token>"
+//
+//	@securityDefinitions.apikey	ApiKeyAuth
+//	@in							header
+//	@name						X-API-Key
+//	@description				API key listed (as SHA-256) in AUTH_API_KEYS_FILE
 package handlers
 
 import (
 	"encoding/json"
 	"net/http"
-	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/yhonda-ohishi/dtako_mod/config"
 	"github.com/yhonda-ohishi/dtako_mod/models"
 	"github.com/yhonda-ohishi/dtako_mod/services"
 )
@@ -31,29 +41,157 @@ func NewDtakoRowsHandler() *DtakoRowsHandler {
 
 // List lists dtako rows
 // @Summary      List Dtako Rows
-// @Description  Get vehicle operation data with optional date filtering
+// @Description  Get vehicle operation data with optional date range and attribute filters.
+// @Description  Send "Accept: application/x-ndjson" to stream one record per line.
+// @Description  Driver names and codes are masked for roles below DRIVER_NAME_MIN_ROLE / DRIVER_CODE_MIN_ROLE; filtering by driver is then forbidden.
 // @Tags         dtako_rows
 // @Accept       json
-// @Produce      json
+// @Produce      json,application/x-ndjson
 // @Param        from    query     string  false  "Start date (YYYY-MM-DD)"
 // @Param        to      query     string  false  "End date (YYYY-MM-DD)"
+// @Param        vehicle query     string  false  "Filter by 車輌CD (comma separated for multiple)"
+// @Param        driver  query     string  false  "Filter by 対象乗務員CD (comma separated for multiple)"
+// @Param        office  query     string  false  "Filter by 事業所CD (comma separated for multiple)"
+// @Param        unko_no query     string  false  "Filter by 運行NO (comma separated for multiple)"
+// @Param        route   query     string  false  "Filter by route (行先市町村名, comma separated for multiple)"
+// @Param        sort    query     string  false  "Sort fields, prefix - for descending (e.g. -date,vehicle_no)"
+// @Param        fields  query     string  false  "Fields to return (e.g. id,unko_no,date)"
+// @Param        limit   query     int     false  "Maximum number of records (1-10000, default all)"
+// @Param        offset  query     int     false  "Records to skip (requires limit)"
 // @Success      200     {array}   models.DtakoRow  "List of dtako rows"
 // @Failure      400     {object}  models.ErrorResponse  "Invalid request parameters"
 // @Failure      500     {object}  models.ErrorResponse  "Internal Server Error"
+// @Failure      401     {object}  models.ErrorResponse  "Unauthenticated"
+// @Failure      403     {object}  models.ErrorResponse  "Forbidden"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /rows [get]
 func (h *DtakoRowsHandler) List(w http.ResponseWriter, r *http.Request) {
 	// Get query parameters
 	from := r.URL.Query().Get("from")
 	to := r.URL.Query().Get("to")
-	
-	rows, err := h.service.GetRows(from, to)
+	q, err := parseListQuery(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if wantsNDJSON(r) {
+		streamNDJSON(w, r, func(nw *ndjsonWriter) error {
+			return h.service.StreamRows(r.Context(), from, to, q, func(row *models.DtakoRow) error {
+				projected, err := projectFields(row, q.Fields)
+				if err != nil {
+					return err
+				}
+				return nw.Write(projected)
+			})
+		})
+		return
+	}
+
+	rows, err := h.service.GetRows(r.Context(), from, to, q)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	body, err := projectList(rows, q.Fields)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+// Stats returns aggregated distance and fuel statistics
+// @Summary      Dtako Rows statistics
+// @Description  Sum, average, trip count and fuel efficiency (km/L) computed in SQL, optionally grouped
+// @Description  and compared with the previous period of the same length.
+// @Tags         dtako_rows
+// @Produce      json
+// @Param        from     query     string  false  "Start date (YYYY-MM-DD)"
+// @Param        to       query     string  false  "End date (YYYY-MM-DD)"
+// @Param        group_by query     string  false  "Grouping"  Enums(vehicle, driver, office, day, month)
+// @Param        compare  query     string  false  "Set to previous to include the previous period"  Enums(previous)
+// @Param        vehicle  query     string  false  "Filter by 車輌CD (comma separated for multiple)"
+// @Param        driver   query     string  false  "Filter by 対象乗務員CD (comma separated for multiple)"
+// @Param        office   query     string  false  "Filter by 事業所CD (comma separated for multiple)"
+// @Success      200      {object}  models.RowStats
+// @Failure      400      {object}  models.ErrorResponse  "Invalid request parameters"
+// @Failure      500      {object}  models.ErrorResponse  "Internal Server Error"
+// @Failure      401      {object}  models.ErrorResponse  "Unauthenticated"
+// @Failure      403      {object}  models.ErrorResponse  "Forbidden"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /rows/stats [get]
+func (h *DtakoRowsHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	groupBy := r.URL.Query().Get("group_by")
+
+	comparePrevious := false
+	switch compare := r.URL.Query().Get("compare"); compare {
+	case "":
+	case "previous":
+		comparePrevious = true
+	default:
+		writeProblem(w, r, http.StatusBadRequest, "unsupported compare: "+compare)
+		return
+	}
+
+	stats, err := h.service.GetRowStats(r.Context(), from, to, groupBy, parseListFilter(r), comparePrevious)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// FuelAnomalies lists trips with suspicious fuel efficiency
+// @Summary      Fuel efficiency anomalies
+// @Description  Flags trips whose km/L deviates from the rolling baseline of the same vehicle (車輌CD)
+// @Description  by z-score or percentage. Flagged trips are excluded from later baselines.
+// @Tags         dtako_rows
+// @Produce      json
+// @Param        from          query     string  false  "Start date (YYYY-MM-DD)"
+// @Param        to            query     string  false  "End date (YYYY-MM-DD)"
+// @Param        vehicle       query     string  false  "Filter by 車輌CD (comma separated for multiple)"
+// @Param        window        query     int     false  "Baseline size in trips (default 20)"
+// @Param        min_baseline  query     int     false  "Minimum baseline trips before judging (default 5)"
+// @Param        z             query     number  false  "Z-score threshold, negative disables (default 3)"
+// @Param        pct           query     number  false  "Deviation threshold in percent, negative disables (default 30)"
+// @Param        lookback_days query     int     false  "Days before from loaded for the baseline (default 90)"
+// @Success      200           {array}   models.FuelAnomaly
+// @Failure      400           {object}  models.ErrorResponse  "Invalid request parameters"
+// @Failure      500           {object}  models.ErrorResponse  "Internal Server Error"
+// @Failure      401           {object}  models.ErrorResponse  "Unauthenticated"
+// @Failure      403           {object}  models.ErrorResponse  "Forbidden"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /rows/fuel_anomalies [get]
+func (h *DtakoRowsHandler) FuelAnomalies(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts, err := services.ParseFuelAnomalyOptions(
+		query.Get("window"), query.Get("min_baseline"),
+		query.Get("z"), query.Get("pct"), query.Get("lookback_days"),
+	)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
+	}
+
+	anomalies, err := h.service.DetectFuelAnomalies(r.Context(), query.Get("from"), query.Get("to"), parseListFilter(r), opts)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(rows)
+	json.NewEncoder(w).Encode(anomalies)
 }
 
 // Import imports dtako_rows from production
@@ -66,25 +204,30 @@ func (h *DtakoRowsHandler) List(w http.ResponseWriter, r *http.Request) {
 // @Success      200     {object}  models.ImportResult  "Import successful"
 // @Failure      400     {object}  models.ErrorResponse  "Bad Request"
 // @Failure      500     {object}  models.ErrorResponse  "Internal Server Error"
+// @Failure      503     {object}  models.ErrorResponse  "Database unavailable"
+// @Failure      401     {object}  models.ErrorResponse  "Unauthenticated"
+// @Failure      403     {object}  models.ErrorResponse  "Forbidden"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /rows/import [post]
 func (h *DtakoRowsHandler) Import(w http.ResponseWriter, r *http.Request) {
 	var req models.ImportRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
 	// Set default date range if not provided
 	if req.FromDate == "" {
-		req.FromDate = time.Now().AddDate(0, -1, 0).Format("2006-01-02")
+		req.FromDate = config.Now().AddDate(0, -1, 0).Format("2006-01-02")
 	}
 	if req.ToDate == "" {
-		req.ToDate = time.Now().Format("2006-01-02")
+		req.ToDate = config.Now().Format("2006-01-02")
 	}
 
-	result, err := h.service.ImportFromProduction(req.FromDate, req.ToDate)
+	result, err := h.service.ImportFromProduction(r.Context(), req.FromDate, req.ToDate)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -101,13 +244,19 @@ func (h *DtakoRowsHandler) Import(w http.ResponseWriter, r *http.Request) {
 // @Param        id      path      string  true  "Row ID"
 // @Success      200     {object}  models.DtakoRow  "Dtako row found"
 // @Failure      404     {object}  models.ErrorResponse  "Not Found"
+// @Failure      500     {object}  models.ErrorResponse  "Internal Server Error"
+// @Failure      503     {object}  models.ErrorResponse  "Database unavailable"
+// @Failure      401     {object}  models.ErrorResponse  "Unauthenticated"
+// @Failure      403     {object}  models.ErrorResponse  "Forbidden"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /rows/{id} [get]
 func (h *DtakoRowsHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	
-	row, err := h.service.GetRowByID(id)
+	row, err := h.service.GetRowByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, r, err)
 		return
 	}
 
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/yhonda-ohishi/dtako_mod/services"
+)
+
+// DtakoTripsHandler handles per-trip (運行NO) requests
+type DtakoTripsHandler struct {
+	service *services.DtakoTripsService
+}
+
+// NewDtakoTripsHandler creates a new trips handler
+func NewDtakoTripsHandler() *DtakoTripsHandler {
+	return &DtakoTripsHandler{
+		service: services.NewDtakoTripsService(),
+	}
+}
+
+// Track returns the GPS track of a trip
+// @Summary      Get trip GPS track
+// @Description  Get the route of a trip built from its events, ordered by event time.
+// @Description  Events without a valid position are skipped.
+// @Description  Returns GeoJSON by default; use format=gpx or "Accept: application/gpx+xml" for GPX.
+// @Tags         trips
+// @Produce      json,application/geo+json,application/gpx+xml
+// @Param        unko_no  path      string  true   "運行NO"
+// @Param        format   query     string  false  "Output format" Enums(geojson, gpx)
+// @Success      200      {object}  models.GeoJSONFeatureCollection  "GeoJSON FeatureCollection"
+// @Failure      400      {object}  models.ErrorResponse  "Invalid request parameters"
+// @Failure      404      {object}  models.ErrorResponse  "Trip not found"
+// @Failure      500      {object}  models.ErrorResponse  "Internal Server Error"
+// @Failure      401      {object}  models.ErrorResponse  "Unauthenticated"
+// @Failure      403      {object}  models.ErrorResponse  "Forbidden"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /trips/{unko_no}/track [get]
+func (h *DtakoTripsHandler) Track(w http.ResponseWriter, r *http.Request) {
+	unkoNo := chi.URLParam(r, "unko_no")
+
+	format := r.URL.Query().Get("format")
+	if format == "" && strings.Contains(r.Header.Get("Accept"), "application/gpx+xml") {
+		format = "gpx"
+	}
+
+	switch format {
+	case "", "geojson":
+		collection, err := h.service.GetTrackGeoJSON(r.Context(), unkoNo)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/geo+json")
+		json.NewEncoder(w).Encode(collection)
+
+	case "gpx":
+		gpx, err := h.service.GetTrackGPX(r.Context(), unkoNo)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/gpx+xml")
+		w.Write([]byte(xml.Header))
+		enc := xml.NewEncoder(w)
+		enc.Indent("", "  ")
+		enc.Encode(gpx)
+
+	default:
+		writeProblem(w, r, http.StatusBadRequest, "format must be geojson or gpx")
+	}
+}
+
+// OdometerContinuity reports odometer gaps and overlaps between consecutive trips
+// @Summary      Odometer continuity check
+// @Description  Per vehicle, compares the end odometer (終了走行距離) and end time of each trip with the start of the next,
+// @Description  ordered by 運行日. Reports distance gaps (unrecorded driving), distance overlaps and time overlaps.
+// @Tags         trips
+// @Produce      json
+// @Param        from          query     string  false  "Start date (YYYY-MM-DD)"
+// @Param        to            query     string  false  "End date (YYYY-MM-DD)"
+// @Param        vehicle       query     string  false  "Filter by 車輌CD (comma separated for multiple)"
+// @Param        driver        query     string  false  "Filter by 対象乗務員CD (comma separated for multiple)"
+// @Param        office        query     string  false  "Filter by 事業所CD (comma separated for multiple)"
+// @Param        tolerance_km  query     number  false  "Allowed odometer difference in km (default 1)"
+// @Success      200           {array}   models.VehicleOdometerReport
+// @Failure      400           {object}  models.ErrorResponse  "Invalid request parameters"
+// @Failure      500           {object}  models.ErrorResponse  "Internal Server Error"
+// @Failure      401           {object}  models.ErrorResponse  "Unauthenticated"
+// @Failure      403           {object}  models.ErrorResponse  "Forbidden"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /trips/odometer_continuity [get]
+func (h *DtakoTripsHandler) OdometerContinuity(w http.ResponseWriter, r *http.Request) {
+	tolerance := 0.0
+	if v := r.URL.Query().Get("tolerance_km"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed <= 0 {
+			writeProblem(w, r, http.StatusBadRequest, "tolerance_km must be a positive number")
+			return
+		}
+		tolerance = parsed
+	}
+
+	reports, err := h.service.CheckOdometerContinuity(r.Context(), r.URL.Query().Get("from"), r.URL.Query().Get("to"), parseListFilter(r), tolerance)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reports)
+}
+
+// TimeBreakdown returns the time per event category of a trip
+// @Summary      Trip time breakdown
+// @Description  Sum the time of a trip per event category (driving, rest, work, waiting, other).
+// @Description  Each event lasts until the next event; categories come from the event type registry.
+// @Tags         trips
+// @Produce      json
+// @Param        unko_no  path      string  true  "運行NO"
+// @Success      200      {object}  models.EventTimeBreakdown
+// @Failure      404      {object}  models.ErrorResponse  "Trip not found"
+// @Failure      500      {object}  models.ErrorResponse  "Internal Server Error"
+// @Failure      401      {object}  models.ErrorResponse  "Unauthenticated"
+// @Failure      403      {object}  models.ErrorResponse  "Forbidden"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /trips/{unko_no}/time_breakdown [get]
+func (h *DtakoTripsHandler) TimeBreakdown(w http.ResponseWriter, r *http.Request) {
+	breakdown, err := h.service.GetTimeBreakdown(r.Context(), chi.URLParam(r, "unko_no"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(breakdown)
+}
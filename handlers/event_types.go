@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/services"
+)
+
+// EventTypesHandler handles event type registry requests
+type EventTypesHandler struct {
+	service *services.EventTypesService
+}
+
+// NewEventTypesHandler creates a new event type registry handler
+func NewEventTypesHandler() *EventTypesHandler {
+	return &EventTypesHandler{
+		service: services.NewEventTypesService(),
+	}
+}
+
+// List lists registered event types
+// @Summary      List event types
+// @Description  Get the event type registry (イベント名 and category)
+// @Tags         event_types
+// @Produce      json
+// @Success      200  {array}   models.EventType
+// @Failure      500  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      403  {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /event_types [get]
+func (h *EventTypesHandler) List(w http.ResponseWriter, r *http.Request) {
+	types, err := h.service.List(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types)
+}
+
+// Put registers an event type or changes its category
+// @Summary      Register event type
+// @Description  Register an event name or change its category
+// @Tags         event_types
+// @Accept       json
+// @Produce      json
+// @Param        name     path      string            true  "イベント名"
+// @Param        request  body      models.EventType  true  "Event type (name in the body is ignored)"
+// @Success      200      {object}  models.EventType
+// @Failure      400      {object}  models.ErrorResponse
+// @Failure      500      {object}  models.ErrorResponse
+// @Failure      401      {object}  models.ErrorResponse
+// @Failure      403      {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /event_types/{name} [put]
+func (h *EventTypesHandler) Put(w http.ResponseWriter, r *http.Request) {
+	var et models.EventType
+	if err := json.NewDecoder(r.Body).Decode(&et); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	et.Name = eventTypeName(r)
+
+	saved, err := h.service.Put(r.Context(), &et)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(saved)
+}
+
+// Delete removes an event type
+// @Summary      Delete event type
+// @Description  Remove an event name from the registry
+// @Tags         event_types
+// @Param        name  path  string  true  "イベント名"
+// @Success      204
+// @Failure      404   {object}  models.ErrorResponse
+// @Failure      401   {object}  models.ErrorResponse
+// @Failure      403   {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /event_types/{name} [delete]
+func (h *EventTypesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), eventTypeName(r)); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Sync seeds the registry from production
+// @Summary      Sync event types from production
+// @Description  Register every distinct production イベント名 not yet in the registry, with a guessed category.
+// @Description  Categories of registered names are not changed.
+// @Tags         event_types
+// @Produce      json
+// @Success      200  {object}  models.ImportResult
+// @Failure      500  {object}  models.ErrorResponse
+// @Failure      503  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      403  {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /event_types/sync [post]
+func (h *EventTypesHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.SyncFromProduction(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// eventTypeName returns the decoded {name} path parameter (イベント名 is usually percent-encoded)
+func eventTypeName(r *http.Request) string {
+	name := chi.URLParam(r, "name")
+	if decoded, err := url.PathUnescape(name); err == nil {
+		return decoded
+	}
+	return name
+}
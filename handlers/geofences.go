@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/services"
+)
+
+// GeofencesHandler handles geofence related requests
+type GeofencesHandler struct {
+	service *services.GeofencesService
+}
+
+// NewGeofencesHandler creates a new geofences handler
+func NewGeofencesHandler() *GeofencesHandler {
+	return &GeofencesHandler{
+		service: services.NewGeofencesService(),
+	}
+}
+
+// List lists geofences
+// @Summary      List geofences
+// @Description  Get all geofence definitions
+// @Tags         geofences
+// @Produce      json
+// @Success      200  {array}   models.Geofence
+// @Failure      500  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      403  {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /geofences [get]
+func (h *GeofencesHandler) List(w http.ResponseWriter, r *http.Request) {
+	fences, err := h.service.ListGeofences(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fences)
+}
+
+// GetByID returns a specific geofence
+// @Summary      Get geofence by ID
+// @Description  Get a specific geofence definition
+// @Tags         geofences
+// @Produce      json
+// @Param        id   path      int  true  "Geofence ID"
+// @Success      200  {object}  models.Geofence
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      403  {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /geofences/{id} [get]
+func (h *GeofencesHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "invalid geofence id")
+		return
+	}
+
+	fence, err := h.service.GetGeofence(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fence)
+}
+
+// Create creates a geofence
+// @Summary      Create geofence
+// @Description  Create a circle (center, radius_m) or polygon (at least 3 points) geofence
+// @Description  Positions must be within lat -90..90 and lng -180..180; radius_m must be positive; a polygon must have at most 1000 points, a non-zero area and no crossing edges.
+// @Tags         geofences
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.Geofence  true  "Geofence definition"
+// @Success      201      {object}  models.Geofence
+// @Failure      400      {object}  models.ErrorResponse
+// @Failure      500      {object}  models.ErrorResponse
+// @Failure      401      {object}  models.ErrorResponse
+// @Failure      403      {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /geofences [post]
+func (h *GeofencesHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var fence models.Geofence
+	if err := json.NewDecoder(r.Body).Decode(&fence); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	created, err := h.service.CreateGeofence(r.Context(), &fence)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// Update replaces a geofence
+// @Summary      Update geofence
+// @Description  Replace an existing geofence definition; validated like create
+// @Tags         geofences
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int              true  "Geofence ID"
+// @Param        request  body      models.Geofence  true  "Geofence definition"
+// @Success      200      {object}  models.Geofence
+// @Failure      400      {object}  models.ErrorResponse
+// @Failure      404      {object}  models.ErrorResponse
+// @Failure      500      {object}  models.ErrorResponse
+// @Failure      401      {object}  models.ErrorResponse
+// @Failure      403      {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /geofences/{id} [put]
+func (h *GeofencesHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "invalid geofence id")
+		return
+	}
+
+	var fence models.Geofence
+	if err := json.NewDecoder(r.Body).Decode(&fence); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	updated, err := h.service.UpdateGeofence(r.Context(), id, &fence)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// Delete removes a geofence
+// @Summary      Delete geofence
+// @Description  Delete a geofence definition
+// @Tags         geofences
+// @Param        id   path  int  true  "Geofence ID"
+// @Success      204
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      403  {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /geofences/{id} [delete]
+func (h *GeofencesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "invalid geofence id")
+		return
+	}
+
+	if err := h.service.DeleteGeofence(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Visits lists geofence arrivals and departures
+// @Summary      Detect geofence visits
+// @Description  Derive arrival/departure times and dwell per geofence per trip from event GPS points
+// @Tags         geofences
+// @Produce      json
+// @Param        from         query     string  false  "Start date (YYYY-MM-DD)"
+// @Param        to           query     string  false  "End date (YYYY-MM-DD)"
+// @Param        unko_no      query     string  false  "Limit to one trip (運行NO)"
+// @Param        geofence_id  query     int     false  "Limit to one geofence"
+// @Success      200          {array}   models.GeofenceVisit
+// @Failure      400          {object}  models.ErrorResponse
+// @Failure      404          {object}  models.ErrorResponse
+// @Failure      500          {object}  models.ErrorResponse
+// @Failure      401          {object}  models.ErrorResponse
+// @Failure      403          {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /geofences/visits [get]
+func (h *GeofencesHandler) Visits(w http.ResponseWriter, r *http.Request) {
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	unkoNo := r.URL.Query().Get("unko_no")
+
+	geofenceID := 0
+	if v := r.URL.Query().Get("geofence_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			writeProblem(w, r, http.StatusBadRequest, "invalid geofence_id")
+			return
+		}
+		geofenceID = id
+	}
+
+	visits, err := h.service.DetectVisits(r.Context(), from, to, unkoNo, geofenceID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(visits)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/services"
+)
+
+// HealthHandler handles liveness and readiness probes
+type HealthHandler struct {
+	service *services.HealthService
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler() *HealthHandler {
+	return &HealthHandler{
+		service: services.NewHealthService(),
+	}
+}
+
+// Liveness reports that the process is up
+// @Summary      Liveness probe
+// @Description  Returns 200 while the process serves requests. Databases are not checked.
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  models.LivenessReport
+// @Router       /healthz [get]
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.service.Liveness())
+}
+
+// Readiness checks the databases
+// @Summary      Readiness probe
+// @Description  Status, latency and last error of the local and production databases, missing required tables,
+// @Description  and the last successful import per table. Returns 503 when the local database is unavailable;
+// @Description  a production outage only degrades the status (reads still work, imports fail).
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  models.ReadinessReport
+// @Failure      503  {object}  models.ReadinessReport
+// @Router       /readyz [get]
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := h.service.Readiness(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status == models.HealthStatusUnavailable {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/services"
+)
+
+// IntegrityHandler handles cross-table integrity requests
+type IntegrityHandler struct {
+	service *services.IntegrityService
+}
+
+// NewIntegrityHandler creates a new integrity handler
+func NewIntegrityHandler() *IntegrityHandler {
+	return &IntegrityHandler{
+		service: services.NewIntegrityService(),
+	}
+}
+
+// Report returns the integrity report
+// @Summary      Integrity report
+// @Description  Events and ferry rows whose 運行NO is missing in dtako_rows, rows without events,
+// @Description  and 運行NO shared by different row ids (local database)
+// @Tags         integrity
+// @Produce      json
+// @Param        from  query     string  false  "Start date (YYYY-MM-DD)"
+// @Param        to    query     string  false  "End date (YYYY-MM-DD)"
+// @Success      200   {object}  models.IntegrityReport
+// @Failure      400   {object}  models.ErrorResponse
+// @Failure      401   {object}  models.ErrorResponse
+// @Failure      403   {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /integrity [get]
+func (h *IntegrityHandler) Report(w http.ResponseWriter, r *http.Request) {
+	report, err := h.service.GetReport(r.Context(), r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// FetchMissing imports missing parent rows from production
+// @Summary      Fetch missing parent rows
+// @Description  Imports dtako_rows for orphaned 運行NO from production and returns the updated report
+// @Tags         integrity
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.ImportRequest  true  "Date range (from_date, to_date)"
+// @Success      200      {object}  models.IntegrityReport
+// @Failure      400      {object}  models.ErrorResponse
+// @Failure      500      {object}  models.ErrorResponse
+// @Failure      503      {object}  models.ErrorResponse
+// @Failure      401      {object}  models.ErrorResponse
+// @Failure      403      {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /integrity/fetch_missing [post]
+func (h *IntegrityHandler) FetchMissing(w http.ResponseWriter, r *http.Request) {
+	var req models.ImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	report, err := h.service.FetchMissingParents(r.Context(), req.FromDate, req.ToDate)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
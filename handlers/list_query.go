@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/services"
+)
+
+// filterParams are the query parameters treated as list filters.
+// Each resource accepts a subset; unsupported ones are rejected with 400.
+var filterParams = []string{
+	"vehicle", "driver", "office", "unko_no", "route",
+	"type", "ferry_company", "boarding", "landing",
+}
+
+// parseListFilter collects filter query parameters. Multiple values may be
+// given comma separated (vehicle=1,2,3) or by repeating the parameter.
+func parseListFilter(r *http.Request) models.ListFilter {
+	query := r.URL.Query()
+	filter := models.ListFilter{}
+
+	for _, name := range filterParams {
+		if values := splitList(query[name]); len(values) > 0 {
+			filter[name] = values
+		}
+	}
+
+	return filter
+}
+
+// splitList splits comma separated and repeated values of a query parameter
+func splitList(values []string) []string {
+	var out []string
+	for _, raw := range values {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+// parseListQuery collects filters plus sort=field,-field, fields=a,b and
+// limit/offset. A leading "-" sorts descending. Whether a field may be
+// sorted on or selected is checked by the repository.
+func parseListQuery(r *http.Request) (models.ListQuery, error) {
+	query := r.URL.Query()
+	q := models.ListQuery{
+		Filter: parseListFilter(r),
+		Fields: splitList(query["fields"]),
+	}
+
+	if err := parsePage(r, &q); err != nil {
+		return q, err
+	}
+
+	for _, term := range splitList(query["sort"]) {
+		field := models.SortField{Field: term}
+		if strings.HasPrefix(term, "-") {
+			field = models.SortField{Field: term[1:], Desc: true}
+		}
+		q.Sort = append(q.Sort, field)
+	}
+
+	return q, nil
+}
+
+// parsePage sets Limit and Offset of q from the limit/offset query parameters
+func parsePage(r *http.Request, q *models.ListQuery) error {
+	query := r.URL.Query()
+	for name, dest := range map[string]*int{"limit": &q.Limit, "offset": &q.Offset} {
+		v := query.Get(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("%w: %s must be a non-negative integer", services.ErrInvalidListQuery, name)
+		}
+		*dest = n
+	}
+	return nil
+}
+
+// projectFields returns v reduced to the named JSON fields. With no fields
+// v is returned unchanged. Fields omitted by omitempty stay omitted.
+func projectFields(v interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return v, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+
+	projected := make(map[string]json.RawMessage, len(fields))
+	for _, name := range fields {
+		if value, ok := all[name]; ok {
+			projected[name] = value
+		}
+	}
+	return projected, nil
+}
+
+// projectList applies projectFields to every item of a list response
+func projectList[T any](items []T, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return items, nil
+	}
+
+	projected := make([]interface{}, 0, len(items))
+	for i := range items {
+		item, err := projectFields(&items[i], fields)
+		if err != nil {
+			return nil, err
+		}
+		projected = append(projected, item)
+	}
+	return projected, nil
+}
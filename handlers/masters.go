@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/services"
+)
+
+// MastersHandler handles master data requests
+type MastersHandler struct {
+	service *services.MastersService
+}
+
+// NewMastersHandler creates a new master data handler
+func NewMastersHandler() *MastersHandler {
+	return &MastersHandler{
+		service: services.NewMastersService(),
+	}
+}
+
+// List lists master records
+// @Summary      List master records
+// @Description  Get all records of a master ordered by code
+// @Description  Office-scoped callers only see vehicles, drivers and offices of their offices.
+// @Tags         masters
+// @Produce      json
+// @Param        kind  path      string  true  "Master kind"  Enums(vehicles, drivers, offices, ferry_companies, ports)
+// @Success      200   {array}   models.MasterRecord
+// @Failure      404   {object}  models.ErrorResponse
+// @Failure      500   {object}  models.ErrorResponse
+// @Failure      401   {object}  models.ErrorResponse
+// @Failure      403   {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /masters/{kind} [get]
+func (h *MastersHandler) List(w http.ResponseWriter, r *http.Request) {
+	records, err := h.service.List(r.Context(), chi.URLParam(r, "kind"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(records)
+}
+
+// Get returns a master record
+// @Summary      Get master record
+// @Description  Get one master record by code; vehicles, drivers and offices outside the caller's offices are 404
+// @Tags         masters
+// @Produce      json
+// @Param        kind  path      string  true  "Master kind"  Enums(vehicles, drivers, offices, ferry_companies, ports)
+// @Param        code  path      string  true  "Code"
+// @Success      200   {object}  models.MasterRecord
+// @Failure      404   {object}  models.ErrorResponse
+// @Failure      401   {object}  models.ErrorResponse
+// @Failure      403   {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /masters/{kind}/{code} [get]
+func (h *MastersHandler) Get(w http.ResponseWriter, r *http.Request) {
+	record, err := h.service.Get(r.Context(), chi.URLParam(r, "kind"), chi.URLParam(r, "code"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(record)
+}
+
+// Create creates a master record
+// @Summary      Create master record
+// @Description  Add a master record; office_code is used by vehicles and drivers only
+// @Tags         masters
+// @Accept       json
+// @Produce      json
+// @Param        kind     path      string               true  "Master kind"  Enums(vehicles, drivers, offices, ferry_companies, ports)
+// @Param        request  body      models.MasterRecord  true  "Master record"
+// @Success      201      {object}  models.MasterRecord
+// @Failure      400      {object}  models.ErrorResponse
+// @Failure      404      {object}  models.ErrorResponse
+// @Failure      409      {object}  models.ErrorResponse
+// @Failure      401      {object}  models.ErrorResponse
+// @Failure      403      {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /masters/{kind} [post]
+func (h *MastersHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var record models.MasterRecord
+	if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	created, err := h.service.Create(r.Context(), chi.URLParam(r, "kind"), &record)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// Update replaces a master record
+// @Summary      Update master record
+// @Description  Replace the name, office and active flag of a master record
+// @Tags         masters
+// @Accept       json
+// @Produce      json
+// @Param        kind     path      string               true  "Master kind"  Enums(vehicles, drivers, offices, ferry_companies, ports)
+// @Param        code     path      string               true  "Code"
+// @Param        request  body      models.MasterRecord  true  "Master record"
+// @Success      200      {object}  models.MasterRecord
+// @Failure      400      {object}  models.ErrorResponse
+// @Failure      404      {object}  models.ErrorResponse
+// @Failure      401      {object}  models.ErrorResponse
+// @Failure      403      {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /masters/{kind}/{code} [put]
+func (h *MastersHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var record models.MasterRecord
+	if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	updated, err := h.service.Update(r.Context(), chi.URLParam(r, "kind"), chi.URLParam(r, "code"), &record)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// Delete removes a master record
+// @Summary      Delete master record
+// @Description  Delete a master record by code
+// @Tags         masters
+// @Param        kind  path  string  true  "Master kind"  Enums(vehicles, drivers, offices, ferry_companies, ports)
+// @Param        code  path  string  true  "Code"
+// @Success      204
+// @Failure      404   {object}  models.ErrorResponse
+// @Failure      401   {object}  models.ErrorResponse
+// @Failure      403   {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /masters/{kind}/{code} [delete]
+func (h *MastersHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), chi.URLParam(r, "kind"), chi.URLParam(r, "code")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ImportCSV imports master records from CSV
+// @Summary      Import master records from CSV
+// @Description  Upsert records from a CSV body with header code,name[,office_code][,active].
+// @Description  Nothing is written when any line is invalid.
+// @Tags         masters
+// @Accept       text/csv
+// @Produce      json
+// @Param        kind  path      string  true  "Master kind"  Enums(vehicles, drivers, offices, ferry_companies, ports)
+// @Success      200   {object}  models.ImportResult
+// @Failure      400   {object}  models.ErrorResponse
+// @Failure      404   {object}  models.ErrorResponse
+// @Failure      500   {object}  models.ErrorResponse
+// @Failure      503   {object}  models.ErrorResponse
+// @Failure      401   {object}  models.ErrorResponse
+// @Failure      403   {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /masters/{kind}/import [post]
+func (h *MastersHandler) ImportCSV(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.ImportCSV(r.Context(), chi.URLParam(r, "kind"), r.Body)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// Sync populates master records from production
+// @Summary      Sync master records from production
+// @Description  Add codes found in production ferry row records (車輌名, 乗務員名１, 事業所名, フェリー会社名, 乗場名/降場名) that are not registered yet. Existing records keep their name, office and active flag.
+// @Tags         masters
+// @Produce      json
+// @Param        kind  path      string  true  "Master kind"  Enums(vehicles, drivers, offices, ferry_companies, ports)
+// @Success      200   {object}  models.ImportResult
+// @Failure      404   {object}  models.ErrorResponse
+// @Failure      500   {object}  models.ErrorResponse
+// @Failure      503   {object}  models.ErrorResponse
+// @Failure      401   {object}  models.ErrorResponse
+// @Failure      403   {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /masters/{kind}/sync [post]
+func (h *MastersHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.SyncFromProduction(r.Context(), chi.URLParam(r, "kind"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
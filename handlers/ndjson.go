@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/yhonda-ohishi/dtako_mod/logging"
+)
+
+// ndjsonContentType is the media type for newline-delimited JSON
+const ndjsonContentType = "application/x-ndjson"
+
+// ndjsonFlushInterval is the number of records written between flushes
+const ndjsonFlushInterval = 100
+
+// wantsNDJSON reports whether the client asked for newline-delimited JSON
+func wantsNDJSON(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mediaType == ndjsonContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// ndjsonWriter writes one JSON document per line and flushes periodically,
+// so a response can be produced straight from a SQL cursor.
+// Headers are only sent with the first record, which lets callers still
+// report errors that happen before anything was written.
+type ndjsonWriter struct {
+	w       http.ResponseWriter
+	enc     *json.Encoder
+	flusher http.Flusher
+	written int
+}
+
+// newNDJSONWriter creates a writer streaming to w
+func newNDJSONWriter(w http.ResponseWriter) *ndjsonWriter {
+	flusher, _ := w.(http.Flusher)
+	return &ndjsonWriter{
+		w:       w,
+		enc:     json.NewEncoder(w),
+		flusher: flusher,
+	}
+}
+
+// Write encodes v as a single line
+func (nw *ndjsonWriter) Write(v interface{}) error {
+	if nw.written == 0 {
+		nw.w.Header().Set("Content-Type", ndjsonContentType)
+		nw.w.WriteHeader(http.StatusOK)
+	}
+
+	if err := nw.enc.Encode(v); err != nil {
+		return err
+	}
+
+	nw.written++
+	if nw.written%ndjsonFlushInterval == 0 {
+		nw.flush()
+	}
+	return nil
+}
+
+// Close finishes the stream. If nothing was written yet, the status and
+// content type are still sent so the client receives an empty stream.
+func (nw *ndjsonWriter) Close() {
+	if nw.written == 0 {
+		nw.w.Header().Set("Content-Type", ndjsonContentType)
+		nw.w.WriteHeader(http.StatusOK)
+	}
+	nw.flush()
+}
+
+func (nw *ndjsonWriter) flush() {
+	if nw.flusher != nil {
+		nw.flusher.Flush()
+	}
+}
+
+// streamNDJSON runs stream and finishes the response. Errors raised before
+// the first record become a normal problem response (writeError); once the
+// body has started the connection is aborted so the client can tell the
+// stream was truncated instead of silently receiving partial data.
+func streamNDJSON(w http.ResponseWriter, r *http.Request, stream func(nw *ndjsonWriter) error) {
+	nw := newNDJSONWriter(w)
+	if err := stream(nw); err != nil {
+		if nw.written == 0 {
+			writeError(w, r, err)
+			return
+		}
+		logging.Logger().ErrorContext(r.Context(), "NDJSON stream aborted", "records", nw.written, "error", err)
+		panic(http.ErrAbortHandler)
+	}
+	nw.Close()
+}
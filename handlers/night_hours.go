@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/yhonda-ohishi/dtako_mod/services"
+)
+
+// NightHoursHandler handles late-night working time requests
+type NightHoursHandler struct {
+	service *services.NightHoursService
+}
+
+// NewNightHoursHandler creates a new night hours handler
+func NewNightHoursHandler() *NightHoursHandler {
+	return &NightHoursHandler{
+		service: services.NewNightHoursService(),
+	}
+}
+
+// List returns night driving and work minutes per driver and day
+// @Summary      Night hours per driver and day
+// @Description  Minutes of driving and work/waiting events between 22:00 and 05:00, from event 開始日時/終了日時.
+// @Description  Events spanning midnight are split, and each part is counted on its calendar day. Days without night work are omitted.
+// @Tags         payroll
+// @Produce      json
+// @Param        from    query     string  false  "Start date (YYYY-MM-DD)"
+// @Param        to      query     string  false  "End date (YYYY-MM-DD)"
+// @Param        driver  query     string  false  "Filter by 対象乗務員CD (comma separated for multiple)"
+// @Param        office  query     string  false  "Filter by 事業所CD (comma separated for multiple)"
+// @Success      200     {array}   models.NightHours
+// @Failure      400     {object}  models.ErrorResponse  "Invalid request parameters"
+// @Failure      500     {object}  models.ErrorResponse  "Internal Server Error"
+// @Failure      401     {object}  models.ErrorResponse  "Unauthenticated"
+// @Failure      403     {object}  models.ErrorResponse  "Forbidden"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /payroll/night_hours [get]
+func (h *NightHoursHandler) List(w http.ResponseWriter, r *http.Request) {
+	results, err := h.service.GetNightHours(r.Context(), r.URL.Query().Get("from"), r.URL.Query().Get("to"), parseListFilter(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/services"
+)
+
+// PayrollHandler handles monthly payroll summary requests
+type PayrollHandler struct {
+	service *services.PayrollService
+}
+
+// NewPayrollHandler creates a new payroll handler
+func NewPayrollHandler() *PayrollHandler {
+	return &PayrollHandler{
+		service: services.NewPayrollService(),
+	}
+}
+
+// Monthly returns the monthly per-driver payroll summary
+// @Summary      Monthly payroll summary
+// @Description  Per driver: working days, 拘束時間, driving time, night hours (22:00-05:00), overtime, distance and ferry trips for a month.
+// @Description  Results are stored as snapshots and recomputed when the month's data has been re-imported or recompute=true.
+// @Description  Office-scoped callers get figures computed from the trips of their offices only; these are not stored.
+// @Description  Returns JSON by default; use format=csv or "Accept: text/csv" for CSV.
+// @Description  Driver names and codes are masked for roles below DRIVER_NAME_MIN_ROLE / DRIVER_CODE_MIN_ROLE; filtering by driver is then forbidden.
+// @Tags         payroll
+// @Produce      json,text/csv
+// @Param        month      query     string  true   "Month (YYYY-MM)"
+// @Param        driver     query     string  false  "対象乗務員CD"
+// @Param        recompute  query     bool    false  "Recompute the month even if a snapshot exists"
+// @Param        format     query     string  false  "Output format" Enums(json, csv)
+// @Success      200        {array}   models.PayrollSummary
+// @Failure      400        {object}  models.ErrorResponse  "Invalid request parameters"
+// @Failure      500        {object}  models.ErrorResponse  "Internal Server Error"
+// @Failure      401        {object}  models.ErrorResponse  "Unauthenticated"
+// @Failure      403        {object}  models.ErrorResponse  "Forbidden"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /payroll/monthly [get]
+func (h *PayrollHandler) Monthly(w http.ResponseWriter, r *http.Request) {
+	h.monthly(w, r, false)
+}
+
+// Recompute recomputes and stores the monthly payroll summary
+// @Summary      Recompute monthly payroll summary
+// @Description  Recompute the snapshots of a month from dtako_rows, dtako_events and dtako_ferry_rows and return them
+// @Tags         payroll
+// @Produce      json,text/csv
+// @Param        month   query     string  true   "Month (YYYY-MM)"
+// @Param        format  query     string  false  "Output format" Enums(json, csv)
+// @Success      200     {array}   models.PayrollSummary
+// @Failure      400     {object}  models.ErrorResponse  "Invalid request parameters"
+// @Failure      500     {object}  models.ErrorResponse  "Internal Server Error"
+// @Failure      401     {object}  models.ErrorResponse  "Unauthenticated"
+// @Failure      403     {object}  models.ErrorResponse  "Forbidden"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /payroll/monthly/recompute [post]
+func (h *PayrollHandler) Recompute(w http.ResponseWriter, r *http.Request) {
+	h.monthly(w, r, true)
+}
+
+func (h *PayrollHandler) monthly(w http.ResponseWriter, r *http.Request, recompute bool) {
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" && strings.Contains(r.Header.Get("Accept"), "text/csv") {
+		format = "csv"
+	}
+	if format != "" && format != "json" && format != "csv" {
+		writeProblem(w, r, http.StatusBadRequest, "format must be json or csv")
+		return
+	}
+	if v := query.Get("recompute"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			writeProblem(w, r, http.StatusBadRequest, "recompute must be true or false")
+			return
+		}
+		recompute = recompute || parsed
+	}
+
+	summaries, err := h.service.GetMonthly(r.Context(), query.Get("month"), query.Get("driver"), recompute)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="payroll_%s.csv"`, query.Get("month")))
+		writePayrollCSV(w, summaries)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summaries)
+}
+
+// writePayrollCSV writes summaries as CSV. A BOM is prepended so Excel reads the names as UTF-8.
+func writePayrollCSV(w http.ResponseWriter, summaries []models.PayrollSummary) {
+	w.Write([]byte("\ufeff"))
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"month", "driver_code", "driver_name", "working_days", "binding_minutes", "driving_minutes",
+		"night_minutes", "overtime_minutes", "distance", "ferry_trips", "computed_at",
+	})
+	minutes := func(v float64) string { return strconv.FormatFloat(v, 'f', 0, 64) }
+	for _, s := range summaries {
+		cw.Write([]string{
+			s.Month, s.DriverCode, s.DriverName, strconv.Itoa(s.WorkingDays),
+			minutes(s.BindingMinutes), minutes(s.DrivingMinutes), minutes(s.NightMinutes), minutes(s.OvertimeMinutes),
+			strconv.FormatFloat(s.Distance, 'f', 1, 64), strconv.Itoa(s.FerryTrips), s.ComputedAt.Format(time.RFC3339),
+		})
+	}
+	cw.Flush()
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/yhonda-ohishi/dtako_mod/apperr"
+	"github.com/yhonda-ohishi/dtako_mod/logging"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// problemContentType is the media type of error responses (RFC 7807)
+const problemContentType = "application/problem+json"
+
+// kindStatus maps error kinds to HTTP status codes; anything else is 500
+var kindStatus = map[apperr.Kind]int{
+	apperr.NotFound:        http.StatusNotFound,
+	apperr.Validation:      http.StatusBadRequest,
+	apperr.Conflict:        http.StatusConflict,
+	apperr.Unavailable:     http.StatusServiceUnavailable,
+	apperr.Unauthenticated: http.StatusUnauthorized,
+	apperr.Forbidden:       http.StatusForbidden,
+}
+
+// statusKind is the reverse of kindStatus, for problems written by status
+var statusKind = map[int]apperr.Kind{
+	http.StatusNotFound:           apperr.NotFound,
+	http.StatusBadRequest:         apperr.Validation,
+	http.StatusConflict:           apperr.Conflict,
+	http.StatusServiceUnavailable: apperr.Unavailable,
+	http.StatusUnauthorized:       apperr.Unauthenticated,
+	http.StatusForbidden:          apperr.Forbidden,
+}
+
+// writeError writes err as a problem response with the status of its kind.
+// Server errors are logged with the request; their detail is only shown when
+// the message comes from this module (apperr), not from the database driver.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	kind := apperr.KindOf(err)
+	status, ok := kindStatus[kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	detail := err.Error()
+	if status >= http.StatusInternalServerError {
+		logging.Logger().ErrorContext(r.Context(), "request failed", "status", status, "error", err)
+		if !apperr.IsTyped(err) {
+			detail = http.StatusText(status)
+			if kind == apperr.Unavailable {
+				detail = apperr.ErrUnavailable.Error()
+			}
+		}
+	}
+	writeProblemKind(w, r, status, kind, detail)
+}
+
+// writeProblem writes a problem response for a request the handler rejected
+// itself, e.g. a malformed body (status 400) or an unsupported format
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	kind, ok := statusKind[status]
+	if !ok {
+		kind = apperr.Internal
+	}
+	writeProblemKind(w, r, status, kind, detail)
+}
+
+func writeProblemKind(w http.ResponseWriter, r *http.Request, status int, kind apperr.Kind, detail string) {
+	problem := models.ErrorResponse{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: middleware.GetReqID(r.Context()),
+		Code:      status,
+		Message:   detail,
+	}
+	if kind != apperr.Internal {
+		problem.Type = "urn:dtako:problem:" + kind.String()
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(problem)
+}
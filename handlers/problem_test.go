@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/yhonda-ohishi/dtako_mod/apperr"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// decodeProblem checks the RFC 7807 content type and decodes the body
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) models.ErrorResponse {
+	t.Helper()
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("Content-Type = %q, want application/problem+json", ct)
+	}
+	var problem models.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to parse problem: %v. Body: %s", err, rec.Body.String())
+	}
+	if problem.Status != rec.Code || problem.Code != rec.Code {
+		t.Errorf("status/code in body = %d/%d, want %d", problem.Status, problem.Code, rec.Code)
+	}
+	return problem
+}
+
+func TestWriteErrorStatusPerKind(t *testing.T) {
+	tests := []struct {
+		kind       apperr.Kind
+		wantStatus int
+		wantType   string
+	}{
+		{apperr.NotFound, http.StatusNotFound, "urn:dtako:problem:not-found"},
+		{apperr.Validation, http.StatusBadRequest, "urn:dtako:problem:validation"},
+		{apperr.Conflict, http.StatusConflict, "urn:dtako:problem:conflict"},
+		{apperr.Unavailable, http.StatusServiceUnavailable, "urn:dtako:problem:upstream-unavailable"},
+		{apperr.Unauthenticated, http.StatusUnauthorized, "urn:dtako:problem:unauthenticated"},
+		{apperr.Forbidden, http.StatusForbidden, "urn:dtako:problem:forbidden"},
+		{apperr.Internal, http.StatusInternalServerError, "about:blank"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind.String(), func(t *testing.T) {
+			// サービスと同じく sentinel をラップして返す
+			err := fmt.Errorf("%w: detail", apperr.New(tt.kind, tt.kind.String()))
+			rec := httptest.NewRecorder()
+			writeError(rec, httptest.NewRequest("GET", "/dtako/rows/1", nil), err)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			problem := decodeProblem(t, rec)
+			if problem.Type != tt.wantType {
+				t.Errorf("type = %s, want %s", problem.Type, tt.wantType)
+			}
+			if problem.Detail != err.Error() || problem.Instance != "/dtako/rows/1" {
+				t.Errorf("detail/instance = %q/%q, want %q//dtako/rows/1", problem.Detail, problem.Instance, err.Error())
+			}
+		})
+	}
+}
+
+func TestWriteErrorHidesDriverDetail(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeError(rec, httptest.NewRequest("GET", "/dtako/rows", nil), fmt.Errorf("Error 1146: Table 'dtako_local.secret' doesn't exist"))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	if problem := decodeProblem(t, rec); problem.Detail != http.StatusText(http.StatusInternalServerError) {
+		t.Errorf("detail = %q, want the status text", problem.Detail)
+	}
+}
+
+func TestGetByIDDatabaseOutage(t *testing.T) {
+	// 接続できないローカルDB（閉じたポート）: 行がないのではなく 503
+	t.Setenv("DB_HOST", "127.0.0.1")
+	t.Setenv("DB_PORT", "1")
+	h := NewDtakoRowsHandler()
+
+	r := chi.NewRouter()
+	r.Get("/dtako/rows/{id}", h.GetByID)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/dtako/rows/no-such-row", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503. Body: %s", rec.Code, rec.Body.String())
+	}
+	problem := decodeProblem(t, rec)
+	if problem.Type != "urn:dtako:problem:upstream-unavailable" {
+		t.Errorf("type = %s, want upstream-unavailable", problem.Type)
+	}
+	if problem.Detail != apperr.ErrUnavailable.Error() {
+		t.Errorf("detail = %q, want %q", problem.Detail, apperr.ErrUnavailable.Error())
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/yhonda-ohishi/dtako_mod/services"
+)
+
+// ReportsHandler handles report requests
+type ReportsHandler struct {
+	service *services.DailyReportService
+}
+
+// NewReportsHandler creates a new reports handler
+func NewReportsHandler() *ReportsHandler {
+	return &ReportsHandler{
+		service: services.NewDailyReportService(),
+	}
+}
+
+// Daily returns the daily driver report (運転日報)
+// @Summary      Daily driver report
+// @Description  Assemble a driver's trips, event timeline, ferry crossings and totals for one 運行日.
+// @Description  Returns JSON by default; use format=html or format=pdf (or the matching Accept header) for a printable report with signature boxes.
+// @Description  PDF output needs a Japanese TrueType font (REPORT_FONT_PATH).
+// @Description  Driver names and codes are masked for roles below DRIVER_NAME_MIN_ROLE / DRIVER_CODE_MIN_ROLE; filtering by driver is then forbidden.
+// @Tags         reports
+// @Produce      json,html,application/pdf
+// @Param        driver  query     string  true   "対象乗務員CD"
+// @Param        date    query     string  true   "運行日 (YYYY-MM-DD)"
+// @Param        format  query     string  false  "Output format" Enums(json, html, pdf)
+// @Success      200     {object}  models.DailyReport
+// @Failure      400     {object}  models.ErrorResponse  "Invalid request parameters"
+// @Failure      404     {object}  models.ErrorResponse  "No trips for the driver on the date"
+// @Failure      500     {object}  models.ErrorResponse  "Internal Server Error"
+// @Failure      501     {object}  models.ErrorResponse  "PDF font not configured"
+// @Failure      401     {object}  models.ErrorResponse  "Unauthenticated"
+// @Failure      403     {object}  models.ErrorResponse  "Forbidden"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /reports/daily [get]
+func (h *ReportsHandler) Daily(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		accept := r.Header.Get("Accept")
+		switch {
+		case strings.Contains(accept, "application/pdf"):
+			format = "pdf"
+		case strings.Contains(accept, "text/html"):
+			format = "html"
+		}
+	}
+	if format != "" && format != "json" && format != "html" && format != "pdf" {
+		writeProblem(w, r, http.StatusBadRequest, "format must be json, html or pdf")
+		return
+	}
+
+	report, err := h.service.GetDailyReport(r.Context(), r.URL.Query().Get("driver"), r.URL.Query().Get("date"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	switch format {
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		renderDailyReportHTML(w, report)
+
+	case "pdf":
+		// エラー時にステータスを返せるよう一旦バッファに出力する
+		var buf bytes.Buffer
+		if err := renderDailyReportPDF(&buf, report); err != nil {
+			if errors.Is(err, errReportFontMissing) {
+				writeProblem(w, r, http.StatusNotImplemented, err.Error())
+				return
+			}
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="daily_report_%s_%s.pdf"`, report.DriverCode, report.Date))
+		w.Write(buf.Bytes())
+
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	}
+}
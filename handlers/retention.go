@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/yhonda-ohishi/dtako_mod/services"
+)
+
+// RetentionHandler handles driver data retention requests
+type RetentionHandler struct {
+	service *services.RetentionService
+}
+
+// NewRetentionHandler creates a new retention handler
+func NewRetentionHandler() *RetentionHandler {
+	return &RetentionHandler{
+		service: services.NewRetentionService(),
+	}
+}
+
+// Run anonymizes or purges driver-identifying data past the retention period
+// @Summary      Run driver data retention
+// @Description  Anonymizes (乗務員CD → 0, 乗務員名 → empty) or purges dtako_rows, dtako_events and dtako_ferry_rows records
+// @Description  dated before today minus DRIVER_DATA_RETENTION_DAYS, depending on DRIVER_DATA_RETENTION_MODE (anonymize / purge),
+// @Description  together with their validation findings and drivers without later trips. Deletes payroll snapshots of earlier months
+// @Description  and clears driver master records from older audit entries. Returns what was processed per table; dry_run only counts.
+// @Tags         retention
+// @Produce      json
+// @Param        dry_run  query     bool  false  "Only count the records that would be processed"
+// @Success      200      {object}  models.RetentionReport
+// @Failure      400      {object}  models.ErrorResponse  "Invalid dry_run"
+// @Failure      500      {object}  models.ErrorResponse
+// @Failure      503      {object}  models.ErrorResponse  "Retention not configured or database unavailable"
+// @Failure      401      {object}  models.ErrorResponse
+// @Failure      403      {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /retention/run [post]
+func (h *RetentionHandler) Run(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			writeProblem(w, r, http.StatusBadRequest, "dry_run must be true or false")
+			return
+		}
+		dryRun = parsed
+	}
+
+	report, err := h.service.Run(r.Context(), dryRun)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/yhonda-ohishi/dtako_mod/services"
+)
+
+// ValidationFindingsHandler handles import validation finding requests
+type ValidationFindingsHandler struct {
+	service *services.ValidationFindingsService
+}
+
+// NewValidationFindingsHandler creates a new validation findings handler
+func NewValidationFindingsHandler() *ValidationFindingsHandler {
+	return &ValidationFindingsHandler{
+		service: services.NewValidationFindingsService(),
+	}
+}
+
+// List lists findings recorded by import validation
+// @Summary      List import validation findings
+// @Description  Data quality findings (reject / warn / fix) recorded while importing, newest first
+// @Description  Office-scoped callers only see findings of stored records of their offices.
+// @Tags         validation
+// @Produce      json
+// @Param        from   query     string  false  "Start date of recording (YYYY-MM-DD)"
+// @Param        to     query     string  false  "End date of recording (YYYY-MM-DD)"
+// @Param        table  query     string  false  "Table name"  Enums(dtako_rows, dtako_events, dtako_ferry_rows)
+// @Param        rule   query     string  false  "Rule name (e.g. negative_distance)"
+// @Success      200    {array}   models.ValidationFinding
+// @Failure      400    {object}  models.ErrorResponse
+// @Failure      401    {object}  models.ErrorResponse
+// @Failure      403    {object}  models.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /validation/findings [get]
+func (h *ValidationFindingsHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	findings, err := h.service.ListFindings(r.Context(), query.Get("from"), query.Get("to"), query.Get("table"), query.Get("rule"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(findings)
+}
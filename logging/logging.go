@@ -0,0 +1,63 @@
+// Package logging provides the structured (log/slog) logger used by every
+// dtako_mod package. The host application can inject its own logger with
+// SetLogger; records always pass through a handler that adds the chi request
+// ID (and trace ID) and redacts sensitive values (driver names, DB credentials).
+package logging
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+var (
+	level   = new(slog.LevelVar)
+	current atomic.Pointer[slog.Logger]
+)
+
+func init() {
+	level.Set(parseLevel(os.Getenv("LOG_LEVEL")))
+
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler = slog.NewTextHandler(os.Stderr, opts)
+	if strings.EqualFold(os.Getenv("LOG_FORMAT"), "json") {
+		h = slog.NewJSONHandler(os.Stderr, opts)
+	}
+	SetLogger(slog.New(h))
+}
+
+// Logger returns the module logger. Records logged with a request context
+// (InfoContext etc.) get the request_id of that request.
+func Logger() *slog.Logger {
+	return current.Load()
+}
+
+// SetLogger replaces the module logger, e.g. with the host application's.
+// Request IDs and redaction are applied on top of its handler.
+func SetLogger(l *slog.Logger) {
+	if l == nil {
+		return
+	}
+	current.Store(slog.New(newHandler(l.Handler())))
+}
+
+// SetLevel changes the level of the default logger (LOG_LEVEL at startup).
+// An injected logger keeps its own level.
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// parseLevel accepts debug / info / warn / error; anything else is info
+func parseLevel(s string) slog.Level {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+var (
+	dbMu         sync.Mutex
+	dbCollectors = map[string]prometheus.Collector{}
+)
+
+// RegisterDB exposes sql.DB.Stats() of db as go_sql_* gauges labelled
+// db_name=name ("production" or "local"). Registering the same name again
+// replaces the previous connection, e.g. after a reconnect.
+func RegisterDB(name string, db *sql.DB) {
+	if db == nil {
+		return
+	}
+
+	dbMu.Lock()
+	defer dbMu.Unlock()
+
+	if old, ok := dbCollectors[name]; ok {
+		Registry.Unregister(old)
+	}
+	c := collectors.NewDBStatsCollector(db, name)
+	Registry.MustRegister(c)
+	dbCollectors[name] = c
+}
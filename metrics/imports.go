@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	importFetched = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "import",
+		Name:      "fetched_rows_total",
+		Help:      "Rows fetched from the production database by import.",
+	}, []string{"table"})
+
+	importUpserted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "import",
+		Name:      "upserted_rows_total",
+		Help:      "Rows written to the local database by import.",
+	}, []string{"table"})
+
+	// reason: insert (DB error) / validation (rejected by a validation rule)
+	importFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "import",
+		Name:      "failed_rows_total",
+		Help:      "Fetched rows that were not written, by reason.",
+	}, []string{"table", "reason"})
+
+	importDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "import",
+		Name:      "duration_seconds",
+		Help:      "Duration of one import run including the production fetch.",
+		Buckets:   []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"table", "result"})
+)
+
+func init() {
+	Registry.MustRegister(importFetched, importUpserted, importFailed, importDuration)
+}
+
+// ImportRun is the outcome of one import run
+type ImportRun struct {
+	Fetched  int
+	Upserted int
+	Failed   int // 書き込みに失敗した件数
+	Rejected int // バリデーションで除外した件数
+	Duration time.Duration
+	Err      error // fetch などで import 自体が失敗した場合
+}
+
+// ObserveImport records one import run of table (dtako_rows, dtako_events, dtako_ferry_rows)
+func ObserveImport(table string, run ImportRun) {
+	importFetched.WithLabelValues(table).Add(float64(run.Fetched))
+	importUpserted.WithLabelValues(table).Add(float64(run.Upserted))
+	importFailed.WithLabelValues(table, "insert").Add(float64(run.Failed))
+	importFailed.WithLabelValues(table, "validation").Add(float64(run.Rejected))
+
+	result := "ok"
+	if run.Err != nil {
+		result = "error"
+	}
+	importDuration.WithLabelValues(table, result).Observe(run.Duration.Seconds())
+}
@@ -0,0 +1,74 @@
+// Package metrics exposes Prometheus metrics for dtako_mod: HTTP request
+// latency per route, database connection pool stats and import counters.
+// Metrics are kept in a dedicated registry so they never collide with
+// the host application's default registry.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "dtako"
+
+// Registry holds every dtako_mod metric
+var Registry = prometheus.NewRegistry()
+
+var httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Subsystem: "http",
+	Name:      "request_duration_seconds",
+	Help:      "HTTP request latency by chi route pattern, method and status code.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"route", "method", "status"})
+
+func init() {
+	Registry.MustRegister(
+		httpDuration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the registry in the Prometheus text format.
+// The host router can mount it anywhere, e.g. r.Handle("/metrics", metrics.Handler()).
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Middleware records the latency and status of each request.
+// The route label is the chi route pattern (e.g. /dtako/rows/{id}), not the
+// raw path, so IDs and dates do not create new series.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		httpDuration.WithLabelValues(routePattern(r), r.Method, strconv.Itoa(status)).
+			Observe(time.Since(start).Seconds())
+	})
+}
+
+// routePattern returns the matched chi pattern; unmatched requests share one label
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return "unmatched"
+	}
+	if pattern := rctx.RoutePattern(); pattern != "" {
+		return pattern
+	}
+	return "unmatched"
+}
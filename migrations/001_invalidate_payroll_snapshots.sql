@@ -0,0 +1,11 @@
+-- 取込時に 車輌CD / 対象乗務員CD / 事業所CD を固定値で保存していた版で計算した
+-- 給与スナップショットを無効化する（全乗務員が 1 人にまとめられているため）。
+-- 一度だけ実行する。stale になった月は次回参照時にローカルの取込データから再計算される。
+-- 再計算の前に、対象期間の rows / events を再取込しておくこと。
+
+-- 計算中の GetMonthly が結果を fresh として保存しないよう version も進める
+INSERT INTO dtako_payroll_versions (month, version)
+SELECT DISTINCT month, 1 FROM dtako_payroll_summaries
+ON DUPLICATE KEY UPDATE version = version + 1;
+
+UPDATE dtako_payroll_summaries SET stale = 1;
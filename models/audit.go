@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit actions: imports, reconciliation, master-data changes and retention runs
+const (
+	AuditRowsImport            = "rows.import"
+	AuditEventsImport          = "events.import"
+	AuditFerryRowsImport       = "ferry_rows.import"
+	AuditIntegrityFetchMissing = "integrity.fetch_missing"
+	AuditMasterCreate          = "masters.create"
+	AuditMasterUpdate          = "masters.update"
+	AuditMasterDelete          = "masters.delete"
+	AuditMasterImportCSV       = "masters.import_csv"
+	AuditMasterSync            = "masters.sync"
+	AuditEventTypePut          = "event_types.put"
+	AuditEventTypeDelete       = "event_types.delete"
+	AuditEventTypeSync         = "event_types.sync"
+	AuditGeofenceCreate        = "geofences.create"
+	AuditGeofenceUpdate        = "geofences.update"
+	AuditGeofenceDelete        = "geofences.delete"
+	AuditRetentionRun          = "retention.run"
+)
+
+// AuditActions returns every audit action
+func AuditActions() []string {
+	return []string{
+		AuditRowsImport, AuditEventsImport, AuditFerryRowsImport, AuditIntegrityFetchMissing,
+		AuditMasterCreate, AuditMasterUpdate, AuditMasterDelete, AuditMasterImportCSV, AuditMasterSync,
+		AuditEventTypePut, AuditEventTypeDelete, AuditEventTypeSync,
+		AuditGeofenceCreate, AuditGeofenceUpdate, AuditGeofenceDelete,
+		AuditRetentionRun,
+	}
+}
+
+// AuditEntry is one recorded action: who triggered it, with which parameters
+// and what it changed. Success is false when the action returned an error;
+// FailedRows counts the records a bulk action could not write.
+type AuditEntry struct {
+	ID           int64           `json:"id" example:"1"`
+	Action       string          `json:"action" example:"rows.import"`
+	Actor        string          `json:"actor" example:"batch"` // トークンの sub / APIキー名、認証なしは anonymous
+	ActorRole    string          `json:"actor_role,omitempty" example:"operator"`
+	Target       string          `json:"target,omitempty" example:"dtako_rows"` // テーブル名・マスタ種別/コードなど
+	Params       json.RawMessage `json:"params,omitempty" swaggertype:"object"` // ImportRequest、マスタレコードなど
+	Success      bool            `json:"success" example:"true"`
+	AffectedRows int             `json:"affected_rows" example:"150"`
+	RejectedRows int             `json:"rejected_rows" example:"2"`
+	FailedRows   int             `json:"failed_rows" example:"0"`
+	Errors       []string        `json:"errors,omitempty"`
+	RequestID    string          `json:"request_id,omitempty" example:"host/abc123-000001"`
+	CreatedAt    time.Time       `json:"created_at" example:"2025-01-13T15:04:05Z"`
+}
@@ -0,0 +1,29 @@
+package models
+
+// CoverageDay is the number of trips (dtako_rows) of one vehicle on one day
+type CoverageDay struct {
+	Date       string `json:"date" example:"2025-01-13"`
+	Local      int    `json:"local" example:"1"`
+	Production *int   `json:"production,omitempty" example:"1"` // 本番DBに接続できない場合は省略
+	// ok / missing (active vehicle without data) / not_imported (fewer locally) /
+	// local_only (more locally than production) / none (inactive vehicle without data)
+	Status string `json:"status" example:"ok"`
+}
+
+// VehicleCoverage is one vehicle row of the coverage calendar
+type VehicleCoverage struct {
+	VehicleNo    string        `json:"vehicle_no" example:"101"` // 車輌CD
+	Active       bool          `json:"active" example:"true"`
+	MissingDays  int           `json:"missing_days" example:"2"`
+	MismatchDays int           `json:"mismatch_days" example:"1"` // not_imported + local_only
+	Days         []CoverageDay `json:"days"`
+}
+
+// CoverageReport is a vehicle × date matrix of trip counts
+type CoverageReport struct {
+	From     string            `json:"from" example:"2025-01-01"`
+	To       string            `json:"to" example:"2025-01-31"`
+	Office   string            `json:"office,omitempty" example:"1"`
+	Dates    []string          `json:"dates" example:"2025-01-01,2025-01-02"`
+	Vehicles []VehicleCoverage `json:"vehicles"`
+}
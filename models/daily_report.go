@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// DailyReport is a driver's daily operation record (運転日報) for one 運行日
+type DailyReport struct {
+	DriverCode  string             `json:"driver_code" example:"1001"`
+	DriverName  string             `json:"driver_name,omitempty" example:"山田太郎"` // 乗務員マスタから補完
+	Date        string             `json:"date" example:"2025-01-13"`            // 運行日
+	Trips       []DailyReportTrip  `json:"trips"`
+	Timeline    []DailyReportEvent `json:"timeline"`
+	Ferries     []DailyReportFerry `json:"ferries"`
+	Totals      DailyReportTotals  `json:"totals"`
+	GeneratedAt time.Time          `json:"generated_at" example:"2025-01-14T09:00:00Z"`
+}
+
+// DailyReportTrip is one trip (運行NO) of the day
+type DailyReportTrip struct {
+	UnkoNo        string     `json:"unko_no" example:"2025010101"`
+	VehicleNo     string     `json:"vehicle_no" example:"101"`
+	VehicleName   string     `json:"vehicle_name,omitempty" example:"トラック1号"`
+	Route         string     `json:"route,omitempty" example:"大阪市"` // 行先市町村名
+	StartAt       *time.Time `json:"start_at,omitempty" example:"2025-01-13T06:00:00Z"`
+	EndAt         *time.Time `json:"end_at,omitempty" example:"2025-01-13T18:00:00Z"`
+	StartPlace    string     `json:"start_place,omitempty" example:"本社"`
+	EndPlace      string     `json:"end_place,omitempty" example:"大阪営業所"`
+	StartOdometer *float64   `json:"start_odometer,omitempty" example:"120345.6"` // 開始走行距離
+	EndOdometer   *float64   `json:"end_odometer,omitempty" example:"120880.2"`   // 終了走行距離
+	Distance      float64    `json:"distance" example:"534.6"`                    // 総走行距離
+	FuelAmount    float64    `json:"fuel_amount" example:"180.5"`
+}
+
+// DailyReportEvent is one event of the day's timeline
+type DailyReportEvent struct {
+	UnkoNo        string     `json:"unko_no" example:"2025010101"`
+	EventType     string     `json:"event_type" example:"休憩"`
+	Category      string     `json:"category" example:"rest"`
+	StartAt       time.Time  `json:"start_at" example:"2025-01-13T10:00:00Z"`
+	EndAt         *time.Time `json:"end_at,omitempty" example:"2025-01-13T10:30:00Z"`
+	Minutes       float64    `json:"minutes" example:"30"`
+	Distance      float64    `json:"distance" example:"0"` // 区間距離
+	StartPlace    string     `json:"start_place,omitempty" example:"浜松SA"`
+	EndPlace      string     `json:"end_place,omitempty" example:"浜松SA"`
+	StartOdometer *float64   `json:"start_odometer,omitempty" example:"120400.1"` // 開始走行距離
+	EndOdometer   *float64   `json:"end_odometer,omitempty" example:"120400.1"`   // 終了走行距離
+}
+
+// DailyReportFerry is one ferry crossing of the day
+type DailyReportFerry struct {
+	UnkoNo       string    `json:"unko_no" example:"2025010101"`
+	Company      string    `json:"company" example:"東京フェリー"`
+	Boarding     string    `json:"boarding" example:"東京港"`
+	Landing      string    `json:"landing" example:"大阪港"`
+	StartAt      time.Time `json:"start_at" example:"2025-01-13T20:00:00Z"`
+	EndAt        time.Time `json:"end_at" example:"2025-01-14T06:00:00Z"`
+	ContractFare int       `json:"contract_fare" example:"8000"`
+}
+
+// DailyReportTotals are the day's totals. Time per category follows the
+// event type registry.
+type DailyReportTotals struct {
+	TripCount      int     `json:"trip_count" example:"1"`
+	FerryCount     int     `json:"ferry_count" example:"0"`
+	Distance       float64 `json:"distance" example:"534.6"`
+	FuelAmount     float64 `json:"fuel_amount" example:"180.5"`
+	BindingMinutes float64 `json:"binding_minutes" example:"720"` // 拘束時間（最初の開始から最後の終了まで）
+	EventTimeBreakdown
+}
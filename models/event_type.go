@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Event categories used to classify イベント名 for time calculations
+const (
+	EventCategoryDriving = "driving" // 運転
+	EventCategoryRest    = "rest"    // 休憩・休息
+	EventCategoryWork    = "work"    // 荷役などの作業
+	EventCategoryWaiting = "waiting" // 待機
+	EventCategoryOther   = "other"   // 時間集計の対象外（開始・終了など）
+)
+
+// EventCategories returns the valid event categories
+func EventCategories() []string {
+	return []string{EventCategoryDriving, EventCategoryRest, EventCategoryWork, EventCategoryWaiting, EventCategoryOther}
+}
+
+// EventType is a registered event name (イベント名) with its category
+type EventType struct {
+	Name      string     `json:"name" example:"積み"`
+	Category  string     `json:"category" example:"work" enums:"driving,rest,work,waiting,other"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty" example:"2025-01-13T15:04:05Z"`
+}
+
+// EventTimeBreakdown is the time spent per event category, in minutes.
+// Each event lasts until the next event of the same trip.
+type EventTimeBreakdown struct {
+	DrivingMinutes float64 `json:"driving_minutes" example:"420"`
+	RestMinutes    float64 `json:"rest_minutes" example:"60"`
+	WorkMinutes    float64 `json:"work_minutes" example:"90"`
+	WaitingMinutes float64 `json:"waiting_minutes" example:"30"`
+	OtherMinutes   float64 `json:"other_minutes" example:"5"`
+	// Unregistered はレジストリに無いイベント名（時間は other に計上）
+	Unregistered []string `json:"unregistered,omitempty" example:"点検"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// FuelAnomaly is a trip whose fuel efficiency deviates from its vehicle's baseline
+type FuelAnomaly struct {
+	RowID          string    `json:"row_id" example:"row-123"`
+	UnkoNo         string    `json:"unko_no" example:"2025010101"` // 運行NO
+	Date           time.Time `json:"date" example:"2025-01-13T00:00:00Z"`
+	VehicleNo      string    `json:"vehicle_no" example:"101"`      // 車輌CD
+	DriverCode     string    `json:"driver_code" example:"1001"`    // 対象乗務員CD
+	Distance       float64   `json:"distance" example:"320.5"`      // 総走行距離
+	FuelAmount     float64   `json:"fuel_amount" example:"160.2"`   // 自社主燃料
+	FuelEfficiency float64   `json:"fuel_efficiency" example:"2.0"` // km/L
+	BaselineMean   float64   `json:"baseline_mean" example:"3.6"`   // 直近運行の平均 km/L
+	BaselineStdDev float64   `json:"baseline_stddev" example:"0.3"` // 直近運行の標準偏差
+	BaselineTrips  int       `json:"baseline_trips" example:"20"`   // ベースラインに使った運行数
+	ZScore         *float64  `json:"z_score,omitempty" example:"-5.3"`
+	DeviationPct   float64   `json:"deviation_pct" example:"-44.4"`
+	Reasons        []string  `json:"reasons" example:"z_score,percent"` // 閾値を超えた判定
+}
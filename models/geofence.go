@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// LatLng is a WGS84 position in degrees
+type LatLng struct {
+	Lat float64 `json:"lat" example:"35.6762"`
+	Lng float64 `json:"lng" example:"139.6503"`
+}
+
+// Geofence represents a named area such as a depot, customer site or port.
+// A circle uses Center and RadiusM, a polygon uses Polygon.
+type Geofence struct {
+	ID        int        `json:"id" example:"1"`
+	Name      string     `json:"name" example:"東京営業所"`
+	Category  string     `json:"category" example:"depot" enums:"depot,customer,port,other"`
+	Shape     string     `json:"shape" example:"circle" enums:"circle,polygon"`
+	Center    *LatLng    `json:"center,omitempty"`
+	RadiusM   float64    `json:"radius_m,omitempty" example:"200"`
+	Polygon   []LatLng   `json:"polygon,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty" example:"2025-01-13T15:04:05Z"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty" example:"2025-01-13T15:04:05Z"`
+}
+
+// GeofenceVisit represents one stay of a trip inside a geofence, derived from event GPS points.
+// DepartedAt is the time of the last event inside the geofence and is nil
+// when the trip's events end while still inside.
+type GeofenceVisit struct {
+	GeofenceID       int        `json:"geofence_id" example:"1"`
+	GeofenceName     string     `json:"geofence_name" example:"東京営業所"`
+	Category         string     `json:"category" example:"depot"`
+	UnkoNo           string     `json:"unko_no" example:"2025010101"`
+	VehicleNo        string     `json:"vehicle_no" example:"vehicle-001"`
+	DriverCode       string     `json:"driver_code" example:"driver-123"`
+	ArrivedAt        time.Time  `json:"arrived_at" example:"2025-01-13T10:30:00Z"`
+	DepartedAt       *time.Time `json:"departed_at,omitempty" example:"2025-01-13T11:15:00Z"`
+	DwellMinutes     *float64   `json:"dwell_minutes,omitempty" example:"45"`
+	ArrivalEventID   string     `json:"arrival_event_id" example:"event-456"`
+	DepartureEventID string     `json:"departure_event_id,omitempty" example:"event-460"`
+}
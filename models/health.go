@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Health statuses
+const (
+	HealthStatusOK          = "ok"          // すべて正常
+	HealthStatusDegraded    = "degraded"    // 本番DBが使えない（参照は可能、インポート不可）
+	HealthStatusUnavailable = "unavailable" // ローカルDBが使えない
+)
+
+// LivenessReport is the /healthz response
+type LivenessReport struct {
+	Status string `json:"status" example:"ok"`
+}
+
+// DatabaseHealth is the result of checking one database
+type DatabaseHealth struct {
+	Name          string     `json:"name" example:"local" enums:"local,production"`
+	Status        string     `json:"status" example:"ok" enums:"ok,unavailable"`
+	LatencyMs     float64    `json:"latency_ms" example:"1.25"`
+	Error         string     `json:"error,omitempty" example:"dial tcp 127.0.0.1:3306: connect: connection refused"`
+	MissingTables []string   `json:"missing_tables,omitempty" example:"dtako_payroll_summaries"`
+	LastError     string     `json:"last_error,omitempty" example:"dial tcp 127.0.0.1:3306: connect: connection refused"` // 直近の失敗（復旧後も保持）
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty" example:"2025-01-13T15:04:05+09:00"`
+}
+
+// Import statuses of ImportHealth
+const (
+	ImportStatusOK      = "ok"      // 成功したインポートあり
+	ImportStatusNever   = "never"   // 監査ログに成功したインポートがない
+	ImportStatusUnknown = "unknown" // 監査ログを読めない
+)
+
+// ImportHealth is the last successful import of a local table, read from
+// the audit log so it survives restarts and covers every instance
+type ImportHealth struct {
+	Table        string     `json:"table" example:"dtako_rows"`
+	Status       string     `json:"status" example:"ok" enums:"ok,never,unknown"`
+	LastSuccess  *time.Time `json:"last_success,omitempty" example:"2025-01-13T15:04:05+09:00"`
+	ImportedRows int        `json:"imported_rows" example:"150"`
+}
+
+// ReadinessReport is the /readyz response
+type ReadinessReport struct {
+	Status    string           `json:"status" example:"ok" enums:"ok,degraded,unavailable"`
+	CheckedAt time.Time        `json:"checked_at" example:"2025-01-13T15:04:05+09:00"`
+	Databases []DatabaseHealth `json:"databases"`
+	Imports   []ImportHealth   `json:"imports"`
+}
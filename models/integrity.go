@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// OrphanUnkoNo is a 運行NO referenced by events or ferry rows but missing in dtako_rows
+type OrphanUnkoNo struct {
+	UnkoNo  string    `json:"unko_no" example:"2025010101"`
+	Records int       `json:"records" example:"12"` // 参照しているレコード数
+	FirstAt time.Time `json:"first_at" example:"2025-01-13T08:00:00Z"`
+	LastAt  time.Time `json:"last_at" example:"2025-01-13T18:00:00Z"`
+}
+
+// RowWithoutEvents is a dtako_rows record that has no events
+type RowWithoutEvents struct {
+	ID        string    `json:"id" example:"row-123"`
+	UnkoNo    string    `json:"unko_no" example:"2025010101"`
+	Date      time.Time `json:"date" example:"2025-01-13T00:00:00Z"`
+	VehicleNo string    `json:"vehicle_no" example:"101"`
+}
+
+// IntegrityReport lists cross-table inconsistencies in the local database
+type IntegrityReport struct {
+	From              string             `json:"from" example:"2025-01-01"`
+	To                string             `json:"to" example:"2025-01-31"`
+	OrphanEvents      []OrphanUnkoNo     `json:"orphan_events"`
+	OrphanFerryRows   []OrphanUnkoNo     `json:"orphan_ferry_rows"`
+	RowsWithoutEvents []RowWithoutEvents `json:"rows_without_events"`
+	Fetched           *ImportResult      `json:"fetched,omitempty"` // fetch_missing 実行時の取り込み結果
+}
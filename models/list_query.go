@@ -0,0 +1,24 @@
+package models
+
+// ListFilter holds list endpoint filters keyed by filter name
+// (vehicle, driver, office, unko_no, ...). Values of one filter are ORed,
+// different filters are ANDed. Which names a resource accepts is decided by
+// its repository.
+type ListFilter map[string][]string
+
+// SortField is one term of a sort parameter, e.g. "-date" is {Field: "date", Desc: true}
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ListQuery holds the filter, sort order, field projection and page of a list request.
+// Empty Sort uses the resource's default order; empty Fields selects every field;
+// zero Limit returns every matching record.
+type ListQuery struct {
+	Filter ListFilter
+	Sort   []SortField
+	Fields []string
+	Limit  int
+	Offset int
+}
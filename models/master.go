@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// MasterRecord is one entry of a master table (vehicles, drivers, offices,
+// ferry companies, ports). OfficeCode is only used by vehicles and drivers.
+type MasterRecord struct {
+	Code       string     `json:"code" example:"101"`
+	Name       string     `json:"name" example:"トラック1号"`
+	OfficeCode string     `json:"office_code,omitempty" example:"1"` // 事業所CD
+	Active     *bool      `json:"active,omitempty" example:"true"`   // 省略時は true
+	UpdatedAt  *time.Time `json:"updated_at,omitempty" example:"2025-01-13T15:04:05Z"`
+}
@@ -12,17 +12,27 @@ type ImportRequest struct {
 
 // ImportResult represents the result of an import operation
 type ImportResult struct {
-	Success      bool      `json:"success" example:"true"`
-	ImportedRows int       `json:"imported_rows" example:"150"`
-	Message      string    `json:"message" example:"Imported 150 rows successfully"`
-	ImportedAt   time.Time `json:"imported_at" example:"2025-01-13T15:04:05Z"`
-	Errors       []string  `json:"errors,omitempty"`
+	Success      bool                `json:"success" example:"true"`
+	ImportedRows int                 `json:"imported_rows" example:"150"`
+	Message      string              `json:"message" example:"Imported 150 rows successfully"`
+	ImportedAt   time.Time           `json:"imported_at" example:"2025-01-13T15:04:05Z"`
+	Errors       []string            `json:"errors,omitempty"`
+	RejectedRows int                 `json:"rejected_rows,omitempty" example:"2"` // バリデーションで除外した件数
+	Findings     []ValidationFinding `json:"findings,omitempty"`
 }
 
-// ErrorResponse represents an error response
+// ErrorResponse is the body of every error response: RFC 7807 problem
+// details (application/problem+json). code and message repeat status and
+// detail for clients of the earlier format.
 type ErrorResponse struct {
-	Code    int    `json:"code" example:"400"`
-	Message string `json:"message" example:"Invalid request parameters"`
+	Type      string `json:"type" example:"urn:dtako:problem:validation"`
+	Title     string `json:"title" example:"Bad Request"`
+	Status    int    `json:"status" example:"400"`
+	Detail    string `json:"detail" example:"invalid date range: from_date cannot be after to_date"`
+	Instance  string `json:"instance,omitempty" example:"/dtako/rows/import"`
+	RequestID string `json:"request_id,omitempty" example:"host/abc123-000001"`
+	Code      int    `json:"code" example:"400"`
+	Message   string `json:"message" example:"invalid date range: from_date cannot be after to_date"`
 }
 
 // DtakoRow represents a row record from production
@@ -32,11 +42,14 @@ type DtakoRow struct {
 	Date        time.Time  `json:"date" example:"2025-01-13T00:00:00Z"`
 	VehicleNo   string     `json:"vehicle_no" example:"vehicle-001"`
 	DriverCode  string     `json:"driver_code" example:"driver-123"`
+	VehicleName string     `json:"vehicle_name,omitempty" example:"トラック1号"` // 車輌マスタから補完
+	DriverName  string     `json:"driver_name,omitempty" example:"山田太郎"`    // 乗務員マスタから補完
 	RouteCode   string     `json:"route_code" example:"route-A"`
 	Distance    float64    `json:"distance" example:"123.45"`
 	FuelAmount  float64    `json:"fuel_amount" example:"45.67"`
 	CreatedAt   *time.Time `json:"created_at,omitempty" example:"2025-01-13T15:04:05Z"`
 	UpdatedAt   *time.Time `json:"updated_at,omitempty" example:"2025-01-13T15:04:05Z"`
+	Codes       *ImportCodes `json:"-"` // 取込時のみ: 本番DBのコードをローカルに引き継ぐ
 }
 
 // DtakoEvent represents an event record from production
@@ -47,11 +60,38 @@ type DtakoEvent struct {
 	EventType   string     `json:"event_type" example:"運転"`
 	VehicleNo   string     `json:"vehicle_no" example:"vehicle-001"`
 	DriverCode  string     `json:"driver_code" example:"driver-123"`
+	VehicleName string     `json:"vehicle_name,omitempty" example:"トラック1号"` // 車輌マスタから補完
+	DriverName  string     `json:"driver_name,omitempty" example:"山田太郎"`    // 乗務員マスタから補完
 	Description string     `json:"description" example:"Started driving from depot"`
 	Latitude    *float64   `json:"latitude,omitempty" example:"35.6762"`
 	Longitude   *float64   `json:"longitude,omitempty" example:"139.6503"`
 	CreatedAt   *time.Time `json:"created_at,omitempty" example:"2025-01-13T15:04:05Z"`
 	UpdatedAt   *time.Time `json:"updated_at,omitempty" example:"2025-01-13T15:04:05Z"`
+	Section     *EventSection `json:"-"` // 取込時のみ: 本番DBの区間情報をローカルに引き継ぐ
+	Codes       *ImportCodes  `json:"-"` // 取込時のみ: 本番DBのコードをローカルに引き継ぐ
+}
+
+// ImportCodes holds the vehicle, driver and office codes of a row or event
+// that are copied to the local database on import, so local filters, sorting
+// and office scoping see the production values
+type ImportCodes struct {
+	VehicleCD int    // 車輌CD
+	VehicleCC string // 車輌CC
+	DriverCD  int    // 対象乗務員CD
+	OfficeCD  int    // 事業所CD
+}
+
+// EventSection holds the section columns of an event that are copied to
+// the local database on import, so timelines can be computed locally
+type EventSection struct {
+	EndDate       *time.Time // 終了日時
+	Distance      float64    // 区間距離
+	StartCity     string     // 開始市町村名
+	EndCity       string     // 終了市町村名
+	StartPlace    string     // 開始場所名
+	EndPlace      string     // 終了場所名
+	StartOdometer float64    // 開始走行距離
+	EndOdometer   float64    // 終了走行距離
 }
 
 // DtakoFerryRow represents a ferry row record from production
@@ -0,0 +1,12 @@
+package models
+
+// NightHours are a driver's minutes worked between 22:00 and 05:00 on one
+// calendar day. Events spanning midnight are split across the days.
+type NightHours struct {
+	DriverCode     string  `json:"driver_code" example:"1001"`
+	DriverName     string  `json:"driver_name,omitempty" example:"山田太郎"` // 乗務員マスタから補完
+	Date           string  `json:"date" example:"2025-01-13"`
+	DrivingMinutes float64 `json:"driving_minutes" example:"120"` // 深夜の運転
+	WorkMinutes    float64 `json:"work_minutes" example:"45"`     // 深夜の作業・待機
+	TotalMinutes   float64 `json:"total_minutes" example:"165"`
+}
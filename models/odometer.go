@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// OdometerTrip is a trip summarised from its events' 開始/終了走行距離
+type OdometerTrip struct {
+	UnkoNo        string     `json:"unko_no" example:"2025010101"`                  // 運行NO
+	VehicleNo     string     `json:"vehicle_no" example:"101"`                      // 車輌CD
+	DriverCode    string     `json:"driver_code" example:"1001"`                    // 対象乗務員CD
+	Date          *time.Time `json:"date,omitempty" example:"2025-01-13T00:00:00Z"` // 運行日
+	StartAt       time.Time  `json:"start_at" example:"2025-01-13T08:00:00Z"`
+	EndAt         time.Time  `json:"end_at" example:"2025-01-13T18:00:00Z"`
+	StartOdometer *float64   `json:"start_odometer,omitempty" example:"123456.7"` // km
+	EndOdometer   *float64   `json:"end_odometer,omitempty" example:"123789.0"`   // km
+}
+
+// OdometerIssue is a discontinuity between two consecutive trips of a vehicle
+type OdometerIssue struct {
+	Type           string       `json:"type" example:"distance_gap"` // distance_gap / distance_overlap / time_overlap
+	Previous       OdometerTrip `json:"previous"`
+	Next           OdometerTrip `json:"next"`
+	DistanceGap    *float64     `json:"distance_gap,omitempty" example:"42.3"` // 次の開始 - 前の終了 (km)
+	TimeGapMinutes float64      `json:"time_gap_minutes" example:"-15"`        // 次の開始 - 前の終了 (分)
+}
+
+// VehicleOdometerReport lists odometer discontinuities of one vehicle
+type VehicleOdometerReport struct {
+	VehicleNo            string          `json:"vehicle_no" example:"101"` // 車輌CD
+	Trips                int             `json:"trips" example:"22"`
+	TripsWithoutOdometer int             `json:"trips_without_odometer" example:"1"`
+	Issues               []OdometerIssue `json:"issues"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// PayrollSummary holds a driver's monthly figures for payroll. Minutes are
+// computed from events categorized by the event type registry.
+type PayrollSummary struct {
+	Month           string    `json:"month" example:"2025-01"`
+	DriverCode      string    `json:"driver_code" example:"1001"`
+	DriverName      string    `json:"driver_name,omitempty" example:"山田太郎"` // 乗務員マスタから補完
+	WorkingDays     int       `json:"working_days" example:"22"`            // 運行日の日数
+	BindingMinutes  float64   `json:"binding_minutes" example:"13200"`      // 拘束時間
+	DrivingMinutes  float64   `json:"driving_minutes" example:"8400"`       // 運転時間
+	NightMinutes    float64   `json:"night_minutes" example:"600"`          // 深夜（22:00〜05:00）の労働時間
+	OvertimeMinutes float64   `json:"overtime_minutes" example:"1800"`      // 1日の所定労働時間を超えた時間の合計
+	Distance        float64   `json:"distance" example:"9876.5"`            // 総走行距離
+	FerryTrips      int       `json:"ferry_trips" example:"2"`
+	ComputedAt      time.Time `json:"computed_at" example:"2025-02-01T03:00:00Z"`
+}
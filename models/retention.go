@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Retention modes for driver-identifying data
+const (
+	RetentionAnonymize = "anonymize" // 乗務員CDを0、乗務員名を空にする（運行データは残す）
+	RetentionPurge     = "purge"     // レコードごと削除する
+)
+
+// RetentionReport is the result of one retention run over the local tables.
+// Records dated before Cutoff are processed; with DryRun nothing is changed
+// and Records counts what would be.
+type RetentionReport struct {
+	Mode          string                 `json:"mode" example:"anonymize"` // anonymize / purge
+	RetentionDays int                    `json:"retention_days" example:"1095"`
+	Cutoff        string                 `json:"cutoff" example:"2022-01-13"` // YYYY-MM-DD、これより前が対象
+	DryRun        bool                   `json:"dry_run" example:"false"`
+	Tables        []RetentionTableResult `json:"tables"`
+	TotalRecords  int64                  `json:"total_records" example:"15230"`
+	StartedAt     time.Time              `json:"started_at" example:"2025-01-13T03:00:00Z"`
+	FinishedAt    time.Time              `json:"finished_at" example:"2025-01-13T03:00:42Z"`
+}
+
+// RetentionTableResult is what a retention run did to one table
+type RetentionTableResult struct {
+	Table   string   `json:"table" example:"dtako_ferry_rows"`
+	Action  string   `json:"action" example:"anonymize"`               // anonymize / purge
+	Columns []string `json:"columns,omitempty" example:"乗務員CD1,乗務員名１"` // 匿名化した列
+	Records int64    `json:"records" example:"320"`
+}
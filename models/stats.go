@@ -0,0 +1,39 @@
+package models
+
+// RowStatsValues holds aggregated distance and fuel figures for a set of trips
+type RowStatsValues struct {
+	Trips          int      `json:"trips" example:"42"`                       // 運行件数
+	TotalDistance  float64  `json:"total_distance" example:"12345.6"`         // 総走行距離の合計
+	AvgDistance    float64  `json:"avg_distance" example:"293.9"`             // 1運行あたりの平均距離
+	TotalFuel      float64  `json:"total_fuel" example:"3456.7"`              // 自社主燃料の合計
+	AvgFuel        float64  `json:"avg_fuel" example:"82.3"`                  // 1運行あたりの平均燃料
+	FuelEfficiency *float64 `json:"fuel_efficiency,omitempty" example:"3.57"` // km/L（燃料が0の場合は省略）
+}
+
+// RowStatsChange holds the change from the previous period.
+// Percentages are omitted when the previous value is zero.
+type RowStatsChange struct {
+	Trips             int      `json:"trips" example:"3"`
+	DistancePct       *float64 `json:"distance_pct,omitempty" example:"8.5"`
+	FuelPct           *float64 `json:"fuel_pct,omitempty" example:"-2.1"`
+	FuelEfficiencyPct *float64 `json:"fuel_efficiency_pct,omitempty" example:"10.8"`
+}
+
+// RowStatsGroup is one group of a stats response, e.g. one vehicle or one month
+type RowStatsGroup struct {
+	Key string `json:"key" example:"101"` // 車輌CD / 乗務員CD / 事業所CD / YYYY-MM-DD / YYYY-MM
+	RowStatsValues
+	Previous *RowStatsValues `json:"previous,omitempty"`
+	Change   *RowStatsChange `json:"change,omitempty"`
+}
+
+// RowStats is the response of GET /rows/stats
+type RowStats struct {
+	From         string          `json:"from" example:"2025-01-01"`
+	To           string          `json:"to" example:"2025-01-31"`
+	GroupBy      string          `json:"group_by,omitempty" example:"vehicle"`
+	PreviousFrom string          `json:"previous_from,omitempty" example:"2024-12-01"`
+	PreviousTo   string          `json:"previous_to,omitempty" example:"2024-12-31"`
+	Total        RowStatsGroup   `json:"total"`
+	Groups       []RowStatsGroup `json:"groups"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// GeoJSONFeatureCollection represents a GeoJSON FeatureCollection (RFC 7946)
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type" example:"FeatureCollection"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+// GeoJSONFeature represents a single GeoJSON Feature
+type GeoJSONFeature struct {
+	Type       string                 `json:"type" example:"Feature"`
+	Geometry   GeoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// GeoJSONGeometry represents a Point or LineString geometry.
+// Coordinates are [longitude, latitude] for a Point and a list of those for a LineString.
+type GeoJSONGeometry struct {
+	Type        string      `json:"type" example:"LineString"`
+	Coordinates interface{} `json:"coordinates" swaggertype:"array,number"`
+}
+
+// GPX represents a GPX 1.1 document
+type GPX struct {
+	XMLName   xml.Name      `xml:"gpx"`
+	Xmlns     string        `xml:"xmlns,attr"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Metadata  GPXMetadata   `xml:"metadata"`
+	Waypoints []GPXWaypoint `xml:"wpt"`
+	Tracks    []GPXTrack    `xml:"trk"`
+}
+
+// GPXMetadata holds the document name and time
+type GPXMetadata struct {
+	Name string     `xml:"name"`
+	Time *time.Time `xml:"time,omitempty"`
+}
+
+// GPXWaypoint represents a wpt or trkpt element
+type GPXWaypoint struct {
+	Lat         float64   `xml:"lat,attr"`
+	Lon         float64   `xml:"lon,attr"`
+	Time        time.Time `xml:"time"`
+	Name        string    `xml:"name,omitempty"`
+	Description string    `xml:"desc,omitempty"`
+	Type        string    `xml:"type,omitempty"`
+}
+
+// GPXTrack represents a trk element
+type GPXTrack struct {
+	Name     string            `xml:"name"`
+	Segments []GPXTrackSegment `xml:"trkseg"`
+}
+
+// GPXTrackSegment represents a trkseg element
+type GPXTrackSegment struct {
+	Points []GPXWaypoint `xml:"trkpt"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// ValidationSeverity decides what happens to a record that breaks a rule
+type ValidationSeverity string
+
+const (
+	SeverityReject ValidationSeverity = "reject" // レコードを取り込まない
+	SeverityWarn   ValidationSeverity = "warn"   // そのまま取り込み、指摘だけ残す
+	SeverityFix    ValidationSeverity = "fix"    // 値を補正して取り込む
+)
+
+// ValidationFinding is one rule violation found while importing a record
+type ValidationFinding struct {
+	ID        int64              `json:"id,omitempty" example:"1"`
+	Table     string             `json:"table" example:"dtako_rows"`
+	RecordID  string             `json:"record_id" example:"row-123"`
+	Rule      string             `json:"rule" example:"negative_distance"`
+	Severity  ValidationSeverity `json:"severity" example:"reject"`
+	Message   string             `json:"message" example:"総走行距離 is negative (-12.5)"`
+	CreatedAt time.Time          `json:"created_at" example:"2025-01-13T15:04:05Z"`
+}
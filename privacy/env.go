@@ -0,0 +1,43 @@
+package privacy
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/yhonda-ohishi/dtako_mod/auth"
+)
+
+// FromEnv builds the policy configured by the environment (.env is read as
+// by config.Load):
+//
+//	DRIVER_NAME_MIN_ROLE  lowest role that sees driver names (default viewer)
+//	DRIVER_CODE_MIN_ROLE  lowest role that sees driver codes (default viewer)
+//	DRIVER_MASK_KEY       secret for the pseudonyms of masked codes
+func FromEnv() (Policy, error) {
+	godotenv.Load()
+
+	p := DefaultPolicy()
+	for _, setting := range []struct {
+		env  string
+		role *auth.Role
+	}{
+		{"DRIVER_NAME_MIN_ROLE", &p.NameRole},
+		{"DRIVER_CODE_MIN_ROLE", &p.CodeRole},
+	} {
+		v := os.Getenv(setting.env)
+		if v == "" {
+			continue
+		}
+		role, ok := auth.ParseRole(v)
+		if !ok {
+			return Policy{}, fmt.Errorf("%s: unknown role %q", setting.env, v)
+		}
+		*setting.role = role
+	}
+
+	if key := os.Getenv("DRIVER_MASK_KEY"); key != "" {
+		p.Key = []byte(key)
+	}
+	return p, nil
+}
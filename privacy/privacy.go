@@ -0,0 +1,166 @@
+// Package privacy masks driver personal data (乗務員CD and 乗務員名) in API
+// responses depending on the caller's role. Callers below the role required
+// by the Policy see names replaced by MaskedName and codes replaced by a
+// stable pseudonym, so records of one driver can still be grouped. Masking
+// only applies to authenticated callers; with authentication disabled every
+// response is unmasked as before.
+package privacy
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/yhonda-ohishi/dtako_mod/auth"
+	"github.com/yhonda-ohishi/dtako_mod/logging"
+)
+
+// MaskedName replaces a driver name the caller may not see
+const MaskedName = "***"
+
+// pseudonymPrefix marks a masked driver code
+const pseudonymPrefix = "D-"
+
+// Policy decides which roles see driver data unmasked
+type Policy struct {
+	// NameRole is the lowest role that sees driver names
+	NameRole auth.Role
+	// CodeRole is the lowest role that sees driver codes
+	CodeRole auth.Role
+	// Key keys the HMAC pseudonyms of masked codes. Pseudonyms are stable
+	// as long as the key is; an empty key is replaced by a random one.
+	Key []byte
+}
+
+// DefaultPolicy masks nothing: viewers already see names and codes
+func DefaultPolicy() Policy {
+	return Policy{NameRole: auth.RoleViewer, CodeRole: auth.RoleViewer}
+}
+
+var (
+	mu      sync.RWMutex
+	current Policy
+	loaded  bool
+)
+
+// Current returns the policy used for responses: the one set with
+// SetPolicy, otherwise the one configured by the environment (see FromEnv).
+func Current() Policy {
+	mu.RLock()
+	if loaded {
+		defer mu.RUnlock()
+		return current
+	}
+	mu.RUnlock()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !loaded {
+		current = withKey(loadFromEnv())
+		loaded = true
+	}
+	return current
+}
+
+// SetPolicy replaces the policy, e.g. with the host application's
+func SetPolicy(p Policy) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = withKey(p)
+	loaded = true
+}
+
+// ResetPolicy drops the policy so the next Current reads the environment again
+func ResetPolicy() {
+	mu.Lock()
+	defer mu.Unlock()
+	current = Policy{}
+	loaded = false
+}
+
+// loadFromEnv builds the policy from the environment. A broken configuration
+// masks everything below admin instead of silently exposing driver data.
+func loadFromEnv() Policy {
+	p, err := FromEnv()
+	if err != nil {
+		logging.Logger().Error("privacy configuration failed, masking driver data below admin", "error", err)
+		return Policy{NameRole: auth.RoleAdmin, CodeRole: auth.RoleAdmin}
+	}
+	return p
+}
+
+// withKey fills in a random pseudonym key when p has none
+func withKey(p Policy) Policy {
+	if len(p.Key) > 0 {
+		return p
+	}
+	p.Key = make([]byte, 32)
+	rand.Read(p.Key)
+	if p.NameRole > auth.RoleViewer || p.CodeRole > auth.RoleViewer {
+		logging.Logger().Warn("DRIVER_MASK_KEY not set: driver code pseudonyms change on every restart")
+	}
+	return p
+}
+
+// Mask masks the driver data of one response
+type Mask struct {
+	names bool
+	codes bool
+	key   []byte
+}
+
+// For returns the mask for the caller of ctx under the current policy
+func For(ctx context.Context) Mask {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return Mask{}
+	}
+
+	policy := Current()
+	return Mask{
+		names: !p.Allows(policy.NameRole),
+		codes: !p.Allows(policy.CodeRole),
+		key:   policy.Key,
+	}
+}
+
+// Names reports whether driver names are masked
+func (m Mask) Names() bool {
+	return m.names
+}
+
+// Codes reports whether driver codes are masked
+func (m Mask) Codes() bool {
+	return m.codes
+}
+
+// Name returns name, or MaskedName when names are masked. Empty names stay empty.
+func (m Mask) Name(name string) string {
+	if !m.names || name == "" {
+		return name
+	}
+	return MaskedName
+}
+
+// Code returns code, or its pseudonym (D-xxxxxxxxxxxx) when codes are
+// masked. Empty and anonymized ("0") codes are returned as they are.
+func (m Mask) Code(code string) string {
+	if !m.codes || code == "" || code == "0" {
+		return code
+	}
+	mac := hmac.New(sha256.New, m.key)
+	mac.Write([]byte(code))
+	return pseudonymPrefix + hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// CodeInt returns code, or 0 when codes are masked (numeric fields such as
+// 乗務員CD1 cannot hold a pseudonym)
+func (m Mask) CodeInt(code int) int {
+	if m.codes {
+		return 0
+	}
+	return code
+}
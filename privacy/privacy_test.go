@@ -0,0 +1,121 @@
+package privacy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/auth"
+)
+
+func withRole(role auth.Role) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "test", Role: role})
+}
+
+func TestForMasksPerRole(t *testing.T) {
+	SetPolicy(Policy{NameRole: auth.RoleOperator, CodeRole: auth.RoleAdmin, Key: []byte("test-mask-key")})
+	defer ResetPolicy()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		wantNames bool
+		wantCodes bool
+	}{
+		{name: "viewer", ctx: withRole(auth.RoleViewer), wantNames: true, wantCodes: true},
+		{name: "operator", ctx: withRole(auth.RoleOperator), wantNames: false, wantCodes: true},
+		{name: "admin", ctx: withRole(auth.RoleAdmin), wantNames: false, wantCodes: false},
+		// 認証なしの場合はマスクしない
+		{name: "unauthenticated", ctx: context.Background(), wantNames: false, wantCodes: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := For(tt.ctx)
+			if m.Names() != tt.wantNames || m.Codes() != tt.wantCodes {
+				t.Fatalf("Names, Codes = %v, %v, want %v, %v", m.Names(), m.Codes(), tt.wantNames, tt.wantCodes)
+			}
+
+			wantName, wantCodeInt := "山田太郎", 1001
+			if tt.wantNames {
+				wantName = MaskedName
+			}
+			if tt.wantCodes {
+				wantCodeInt = 0
+			}
+			if got := m.Name("山田太郎"); got != wantName {
+				t.Errorf("Name() = %q, want %q", got, wantName)
+			}
+			if got := m.CodeInt(1001); got != wantCodeInt {
+				t.Errorf("CodeInt() = %d, want %d", got, wantCodeInt)
+			}
+			if got := m.Code("1001"); (got != "1001") != tt.wantCodes {
+				t.Errorf("Code() = %q, masked = %v", got, tt.wantCodes)
+			}
+		})
+	}
+}
+
+func TestMaskKeepsEmptyValues(t *testing.T) {
+	m := Mask{names: true, codes: true, key: []byte("test-mask-key")}
+	for _, code := range []string{"", "0"} {
+		if got := m.Code(code); got != code {
+			t.Errorf("Code(%q) = %q, want it unchanged", code, got)
+		}
+	}
+	if got := m.Name(""); got != "" {
+		t.Errorf("Name(\"\") = %q, want empty", got)
+	}
+}
+
+func TestPseudonymsAreStablePerKey(t *testing.T) {
+	a := Mask{codes: true, key: []byte("key-a")}
+	b := Mask{codes: true, key: []byte("key-a")}
+	other := Mask{codes: true, key: []byte("key-b")}
+
+	p := a.Code("1001")
+	if !strings.HasPrefix(p, pseudonymPrefix) || len(p) != len(pseudonymPrefix)+12 {
+		t.Fatalf("Code() = %q, want D- and 12 hex digits", p)
+	}
+	if got := b.Code("1001"); got != p {
+		t.Errorf("same key: Code() = %q, want %q", got, p)
+	}
+	if got := a.Code("1002"); got == p {
+		t.Errorf("different codes share the pseudonym %q", got)
+	}
+	if got := other.Code("1001"); got == p {
+		t.Errorf("different keys share the pseudonym %q", got)
+	}
+}
+
+func TestPolicyKey(t *testing.T) {
+	t.Run("configured key is kept across reloads", func(t *testing.T) {
+		t.Setenv("DRIVER_CODE_MIN_ROLE", "admin")
+		t.Setenv("DRIVER_MASK_KEY", "env-key")
+		defer ResetPolicy()
+
+		ResetPolicy()
+		first := For(withRole(auth.RoleViewer)).Code("1001")
+		ResetPolicy()
+		if got := For(withRole(auth.RoleViewer)).Code("1001"); got != first {
+			t.Errorf("Code() = %q after reload, want %q", got, first)
+		}
+	})
+
+	t.Run("missing key is replaced by a random one", func(t *testing.T) {
+		defer ResetPolicy()
+		SetPolicy(Policy{CodeRole: auth.RoleAdmin})
+		if len(Current().Key) == 0 {
+			t.Fatal("Current() has no key")
+		}
+	})
+
+	t.Run("broken configuration masks below admin", func(t *testing.T) {
+		t.Setenv("DRIVER_NAME_MIN_ROLE", "nobody")
+		defer ResetPolicy()
+
+		ResetPolicy()
+		if p := Current(); p.NameRole != auth.RoleAdmin || p.CodeRole != auth.RoleAdmin {
+			t.Errorf("Current() = %+v, want admin for names and codes", p)
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// AuditRepository handles database operations for dtako_audit_log
+type AuditRepository struct {
+	localDB *sql.DB
+}
+
+// NewAuditRepository creates a new repository instance
+func NewAuditRepository() *AuditRepository {
+	localDB := localPool()
+
+	return &AuditRepository{
+		localDB: localDB,
+	}
+}
+
+// Insert stores one audit entry
+func (r *AuditRepository) Insert(ctx context.Context, e *models.AuditEntry) (err error) {
+	ctx, span := startSpan(ctx, "AuditRepository.Insert", dbLocal, "dtako_audit_log")
+	defer tracing.End(span, &err)
+
+	var params, errs sql.NullString
+	if len(e.Params) > 0 {
+		params = sql.NullString{String: string(e.Params), Valid: true}
+	}
+	if len(e.Errors) > 0 {
+		data, err := json.Marshal(e.Errors)
+		if err != nil {
+			return err
+		}
+		errs = sql.NullString{String: string(data), Valid: true}
+	}
+
+	res, err := r.localDB.ExecContext(ctx, `
+		INSERT INTO dtako_audit_log (action, actor, actor_role, target, params, success,
+		                             affected_rows, rejected_rows, failed_rows, errors, request_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, e.Action, e.Actor, e.ActorRole, e.Target, params, e.Success,
+		e.AffectedRows, e.RejectedRows, e.FailedRows, errs, e.RequestID, e.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	e.ID, _ = res.LastInsertId()
+	return nil
+}
+
+// auditListQuery builds the query of List
+func auditListQuery(from, to time.Time, actor, action string, page models.ListQuery) (string, []interface{}, error) {
+	query := `
+		SELECT id, action, actor, COALESCE(actor_role, ''), COALESCE(target, ''), params, success,
+		       affected_rows, rejected_rows, failed_rows, errors, COALESCE(request_id, ''), created_at
+		FROM dtako_audit_log
+		WHERE created_at >= ? AND created_at < DATE_ADD(?, INTERVAL 1 DAY)
+	`
+	args := []interface{}{from.Format("2006-01-02"), to.Format("2006-01-02")}
+	if actor != "" {
+		query += " AND actor = ?"
+		args = append(args, actor)
+	}
+	if action != "" {
+		query += " AND action = ?"
+		args = append(args, action)
+	}
+	limit, err := pageClause(page)
+	if err != nil {
+		return "", nil, err
+	}
+	return query + " ORDER BY created_at DESC, id DESC" + limit, args, nil
+}
+
+// List retrieves one page (Limit/Offset of page) of the entries recorded
+// within a date range, newest first. Empty actor or action matches all.
+func (r *AuditRepository) List(ctx context.Context, from, to time.Time, actor, action string, page models.ListQuery) (_ []models.AuditEntry, err error) {
+	ctx, span := startSpan(ctx, "AuditRepository.List", dbLocal, "dtako_audit_log", tracing.DateRange(from, to)...)
+	defer tracing.End(span, &err)
+
+	query, args, err := auditListQuery(from, to, actor, action, page)
+	if err != nil {
+		return []models.AuditEntry{}, err
+	}
+
+	rows, err := r.localDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return []models.AuditEntry{}, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var e models.AuditEntry
+		var params, errs sql.NullString
+		if err := rows.Scan(&e.ID, &e.Action, &e.Actor, &e.ActorRole, &e.Target, &params, &e.Success,
+			&e.AffectedRows, &e.RejectedRows, &e.FailedRows, &errs, &e.RequestID, &e.CreatedAt); err != nil {
+			return []models.AuditEntry{}, err
+		}
+		if params.Valid && params.String != "" {
+			e.Params = json.RawMessage(params.String)
+		}
+		if errs.Valid && errs.String != "" {
+			if err := json.Unmarshal([]byte(errs.String), &e.Errors); err != nil {
+				return []models.AuditEntry{}, err
+			}
+		}
+		entries = append(entries, e)
+	}
+
+	tracing.Rows(span, "read", len(entries))
+	return entries, rows.Err()
+}
+
+// LastSuccessful returns the latest entry of each action that succeeded
+// without failed rows, by action. Actions never recorded are missing.
+func (r *AuditRepository) LastSuccessful(ctx context.Context, actions []string) (_ map[string]models.AuditEntry, err error) {
+	ctx, span := startSpan(ctx, "AuditRepository.LastSuccessful", dbLocal, "dtako_audit_log")
+	defer tracing.End(span, &err)
+
+	last := map[string]models.AuditEntry{}
+	if len(actions) == 0 {
+		return last, nil
+	}
+
+	placeholders := make([]string, len(actions))
+	args := make([]interface{}, len(actions))
+	for i, action := range actions {
+		placeholders[i] = "?"
+		args[i] = action
+	}
+
+	rows, err := r.localDB.QueryContext(ctx, `
+		SELECT a.action, a.affected_rows, a.created_at
+		FROM dtako_audit_log a
+		JOIN (
+			SELECT MAX(id) AS id
+			FROM dtako_audit_log
+			WHERE action IN (`+strings.Join(placeholders, ", ")+`) AND success = 1 AND failed_rows = 0
+			GROUP BY action
+		) latest ON latest.id = a.id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e models.AuditEntry
+		if err := rows.Scan(&e.Action, &e.AffectedRows, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		last[e.Action] = e
+	}
+
+	tracing.Rows(span, "read", len(last))
+	return last, rows.Err()
+}
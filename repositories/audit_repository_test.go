@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+func TestAuditListQueryPages(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	query, args, err := auditListQuery(from, to, "batch", "rows.import", models.ListQuery{Limit: 100, Offset: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(query, " ORDER BY created_at DESC, id DESC LIMIT 100 OFFSET 200") {
+		t.Errorf("expected ordered page, got %s", query)
+	}
+	want := []interface{}{"2025-01-01", "2025-12-31", "batch", "rows.import"}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+
+	if _, _, err := auditListQuery(from, to, "", "", models.ListQuery{Limit: maxListLimit + 1}); !errors.Is(err, ErrInvalidListQuery) {
+		t.Errorf("limit above maximum: expected ErrInvalidListQuery, got %v", err)
+	}
+}
@@ -1,12 +1,17 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"fmt"
 	"os"
 	"sync"
+	"time"
 
+	"github.com/yhonda-ohishi/dtako_mod/apperr"
 	"github.com/yhonda-ohishi/dtako_mod/config"
+	"github.com/yhonda-ohishi/dtako_mod/logging"
+	"github.com/yhonda-ohishi/dtako_mod/metrics"
 )
 
 var (
@@ -18,14 +23,20 @@ var (
 	prodErr error
 )
 
-// GetDB returns a singleton database connection
+// pingTimeout bounds connection checks so an unreachable server does not
+// block startup or /readyz
+const pingTimeout = 5 * time.Second
+
+// ErrProductionUnavailable is returned when the production database has no connection
+var ErrProductionUnavailable = apperr.New(apperr.Unavailable, "production database not available")
+
+// GetDB returns a singleton database connection pool.
+// If the first ping fails the pool is still returned along with the error:
+// queries then fail (and reconnect once the server is reachable) instead of
+// panicking on a nil *sql.DB.
 func GetDB() (*sql.DB, error) {
 	once.Do(func() {
-		cfg := config.GetDatabaseConfig()
-		db, dbErr = cfg.Connect()
-		if dbErr != nil {
-			log.Printf("Failed to connect to database: %v", dbErr)
-		}
+		db, dbErr = openPool(dbLocal, config.GetDatabaseConfig())
 	})
 	return db, dbErr
 }
@@ -35,7 +46,8 @@ func GetLocalDB() (*sql.DB, error) {
 	return GetDB()
 }
 
-// GetProductionDB returns the production database connection
+// GetProductionDB returns the production database connection pool.
+// Like GetDB, the pool is returned even when the first ping fails.
 func GetProductionDB() (*sql.DB, error) {
 	onceProd.Do(func() {
 		// Production database configuration from PROD_DB_* env vars
@@ -47,14 +59,43 @@ func GetProductionDB() (*sql.DB, error) {
 			Database: getEnvWithDefault("PROD_DB_NAME", "dtako_test_prod"),
 			Charset:  getEnvWithDefault("PROD_DB_CHARSET", "utf8mb4"),
 		}
-		prodDB, prodErr = cfg.Connect()
-		if prodErr != nil {
-			log.Printf("Failed to connect to production database: %v", prodErr)
-		}
+		prodDB, prodErr = openPool(dbProduction, cfg)
 	})
 	return prodDB, prodErr
 }
 
+// openPool opens the pool of cfg and pings it once
+func openPool(name string, cfg *config.DatabaseConfig) (*sql.DB, error) {
+	pool, err := cfg.Open()
+	if err != nil {
+		logging.Logger().Error("failed to open database", "db_name", name, "error", err)
+		return nil, err
+	}
+	metrics.RegisterDB(name, pool)
+
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	if err := pool.PingContext(ctx); err != nil {
+		err = fmt.Errorf("failed to connect to %s database: %w", name, err)
+		logging.Logger().Error("database not reachable", "db_name", name, "error", err)
+		return pool, err
+	}
+	return pool, nil
+}
+
+// localPool and productionPool return the pools for repository constructors.
+// Connection errors are logged when the pool is opened and reported by
+// /readyz (see HealthRepository).
+func localPool() *sql.DB {
+	pool, _ := GetLocalDB()
+	return pool
+}
+
+func productionPool() *sql.DB {
+	pool, _ := GetProductionDB()
+	return pool
+}
+
 // getEnvWithDefault gets environment variable with default value
 func getEnvWithDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -85,5 +126,8 @@ func SetDatabaseConfig(host, port, user, password, database string) error {
 	}
 
 	db = newDB
+	metrics.RegisterDB(dbLocal, db)
+	dbErr = nil
+	once.Do(func() {}) // 以降の GetDB で接続し直さない
 	return nil
 }
\ No newline at end of file
@@ -3,11 +3,13 @@ package repositories
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"log"
+	"strings"
 	"time"
 
+	"github.com/yhonda-ohishi/dtako_mod/config"
+	"github.com/yhonda-ohishi/dtako_mod/logging"
 	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
 )
 
 // DtakoEventsRepository handles database operations for dtako_events
@@ -18,8 +20,8 @@ type DtakoEventsRepository struct {
 
 // NewDtakoEventsRepository creates a new repository instance
 func NewDtakoEventsRepository() *DtakoEventsRepository {
-	prodDB, _ := GetProductionDB()
-	localDB, _ := GetLocalDB()
+	prodDB := productionPool()
+	localDB := localPool()
 
 	return &DtakoEventsRepository{
 		prodDB:  prodDB,
@@ -27,319 +29,565 @@ func NewDtakoEventsRepository() *DtakoEventsRepository {
 	}
 }
 
-// GetByDateRange retrieves events within a date range from local database
-func (r *DtakoEventsRepository) GetByDateRange(from, to time.Time, eventType, unkoNo string) ([]models.DtakoEvent, error) {
-	log.Printf("🔍 DEBUG: GetByDateRange START - from=%v, to=%v, eventType=%s, unkoNo=%s", from, to, eventType, unkoNo)
+// eventColumns is the SELECT list shared by every dtako_events read.
+// created_at, updated_at は実際のテーブルに存在しないため含めない
+const eventColumns = `
+			id,
+			COALESCE(運行NO, '') as unko_no,
+			開始日時 as event_date,
+			イベント名 as event_type,
+			CAST(車輌CD AS CHAR) as vehicle_no,
+			CAST(対象乗務員CD AS CHAR) as driver_code,
+			COALESCE(備考, '') as description,
+			開始GPS緯度,
+			開始GPS経度`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanEvent scans a row selected with eventColumns into a DtakoEvent.
+// extra receives the columns selected after eventColumns.
+func scanEvent(s rowScanner, extra ...interface{}) (models.DtakoEvent, error) {
+	var event models.DtakoEvent
+	var latBigint, lngBigint sql.NullInt64
+
+	dest := []interface{}{
+		&event.ID,
+		&event.UnkoNo,
+		&event.EventDate,
+		&event.EventType,
+		&event.VehicleNo,
+		&event.DriverCode,
+		&event.Description,
+		&latBigint,
+		&lngBigint,
+	}
+	err := s.Scan(append(dest, extra...)...)
+	if err != nil {
+		return event, err
+	}
+
+	// GPS座標変換（マイクロ度 → 度）
+	if latBigint.Valid {
+		lat := float64(latBigint.Int64) / 1000000.0
+		event.Latitude = &lat
+	}
+	if lngBigint.Valid {
+		lng := float64(lngBigint.Int64) / 1000000.0
+		event.Longitude = &lng
+	}
+
+	return event, nil
+}
+
+// GetByDateRange retrieves events within a date range (production database)
+func (r *DtakoEventsRepository) GetByDateRange(ctx context.Context, from, to time.Time, q models.ListQuery) ([]models.DtakoEvent, error) {
+	results := []models.DtakoEvent{}
+	err := r.StreamByDateRange(ctx, from, to, q, func(event *models.DtakoEvent) error {
+		results = append(results, *event)
+		return nil
+	})
+	if err != nil {
+		return []models.DtakoEvent{}, err
+	}
+
+	return results, nil
+}
+
+// StreamByDateRange reads events within a date range and passes each one to
+// fn as it is scanned, without buffering the result set.
+// Only the fields named in q.Fields are selected; the rest stay zero.
+// Iteration stops at the first error returned by fn.
+func (r *DtakoEventsRepository) StreamByDateRange(ctx context.Context, from, to time.Time, q models.ListQuery, fn func(*models.DtakoEvent) error) (err error) {
+	ctx, span := startSpan(ctx, "DtakoEventsRepository.StreamByDateRange", dbProduction, "dtako_events", tracing.DateRange(from, to)...)
+	defer tracing.End(span, &err)
 
 	// 本番DBのみ使用（ローカルは無視）
-	var db *sql.DB = r.prodDB
+	db := r.prodDB
 	if db == nil {
-		return []models.DtakoEvent{}, fmt.Errorf("production database not available")
+		return ErrProductionUnavailable
 	}
 
-	// 最初にテーブル存在確認
-	log.Printf("🔍 DEBUG: Testing table access")
-	var count int
-	err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM dtako_events").Scan(&count)
+	fields, err := eventsResource.selectFields(q.Fields)
 	if err != nil {
-		log.Printf("❌ ERROR: Table access failed: %v", err)
-		return []models.DtakoEvent{}, err
+		return err
+	}
+	query, args, err := eventsListQuery(ctx, fields, from, to, q)
+	if err != nil {
+		return err
 	}
-	log.Printf("✅ SUCCESS: Table has %d rows", count)
 
-	// 根本問題修正: 実際のテーブル構造に合わせたクエリ
-	// - created_at, updated_at カラムを除外
-	// - DATE()関数を使わず直接日時比較
-	// - 実際のカラム型に合わせたスキャン
+	logging.Logger().Debug("dtako_events query", "query", query, "args", args)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	read := 0
+	for rows.Next() {
+		event, err := scanFields(rows, fields)
+		if err != nil {
+			return err
+		}
+		if err := fn(&event); err != nil {
+			return err
+		}
+		read++
+	}
+
+	tracing.Rows(span, "read", read)
+	return rows.Err()
+}
+
+// eventsListQuery builds the list query of events whose 開始日時 falls on a
+// business day (config.BusinessLocation) between from and to, inclusive
+func eventsListQuery(ctx context.Context, fields []listField[models.DtakoEvent], from, to time.Time, q models.ListQuery) (string, []interface{}, error) {
 	query := `
-		SELECT
-			id,
-			COALESCE(運行NO, '') as unko_no,
-			開始日時 as event_date,
-			イベント名 as event_type,
-			CAST(車輌CD AS CHAR) as vehicle_no,
-			CAST(対象乗務員CD AS CHAR) as driver_code,
-			COALESCE(備考, '') as description,
-			開始GPS緯度,
-			開始GPS経度
+		SELECT ` + selectList(fields) + `
 		FROM dtako_events
 		WHERE 開始日時 >= ? AND 開始日時 < DATE_ADD(?, INTERVAL 1 DAY)
 	`
+	query, args, err := appendFilters(ctx, query, []interface{}{businessDay(from), businessDay(to)}, q.Filter, eventsResource.filters)
+	if err != nil {
+		return "", nil, err
+	}
+	order, err := eventsResource.orderBy(ctx, q.Sort)
+	if err != nil {
+		return "", nil, err
+	}
+	page, err := pageClause(q)
+	if err != nil {
+		return "", nil, err
+	}
+	return query + order + page, args, nil
+}
 
-	// 昨日（2024-09-13）からのデータを取得するように固定
-	yesterday := time.Date(2024, 9, 13, 0, 0, 0, 0, time.UTC)
-	tomorrow := time.Date(2024, 9, 15, 0, 0, 0, 0, time.UTC)
-	args := []interface{}{yesterday.Format("2006-01-02"), tomorrow.Format("2006-01-02")}
+// businessDay formats t as the YYYY-MM-DD of the business time zone, the zone
+// DATETIME columns are stored in
+func businessDay(t time.Time) string {
+	return t.In(config.BusinessLocation()).Format("2006-01-02")
+}
 
-	if eventType != "" {
-		query += " AND イベント名 = ?"
-		args = append(args, eventType)
+// StreamTripEvents reads events within a date range ordered by 運行NO and
+// then event time, so each trip's events reach fn contiguously and in order.
+// An empty unkoNo selects all trips. Iteration stops at the first error returned by fn.
+func (r *DtakoEventsRepository) StreamTripEvents(ctx context.Context, from, to time.Time, unkoNo string, fn func(*models.DtakoEvent) error) (err error) {
+	ctx, span := startSpan(ctx, "DtakoEventsRepository.StreamTripEvents", dbProduction, "dtako_events", tracing.DateRange(from, to)...)
+	defer tracing.End(span, &err)
+
+	// 本番DBのみ使用（GetByDateRangeと同じ）
+	db := r.prodDB
+	if db == nil {
+		return ErrProductionUnavailable
 	}
 
+	query := `
+		SELECT ` + eventColumns + `
+		FROM dtako_events
+		WHERE 開始日時 >= ? AND 開始日時 < DATE_ADD(?, INTERVAL 1 DAY)
+	`
+	args := []interface{}{from.Format("2006-01-02"), to.Format("2006-01-02")}
+
 	if unkoNo != "" {
 		query += " AND 運行NO = ?"
 		args = append(args, unkoNo)
 	}
+	query, args = appendOfficeScope(ctx, query, args, eventsResource.filters["office"].column)
 
-	query += " ORDER BY 開始日時 DESC LIMIT 100"
-
-	log.Printf("🔍 DEBUG: Executing optimized query")
-	log.Printf("🔍 DEBUG: Query: %s", query)
-	log.Printf("🔍 DEBUG: Args: %v", args)
+	query += " ORDER BY 運行NO ASC, 開始日時 ASC, id ASC"
 
 	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
-		log.Printf("❌ ERROR: Query failed: %v", err)
-		return []models.DtakoEvent{}, err
+		return err
 	}
 	defer rows.Close()
 
-	log.Printf("🔍 DEBUG: Query executed successfully, processing rows")
+	read := 0
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(&event); err != nil {
+			return err
+		}
+		read++
+	}
 
-	results := []models.DtakoEvent{}
-	rowCount := 0
+	tracing.Rows(span, "read", read)
+	return rows.Err()
+}
 
-	for rows.Next() {
-		rowCount++
-		log.Printf("🔍 DEBUG: Processing row %d", rowCount)
+// odometerFilters are the filters accepted by TripOdometers
+var odometerFilters = map[string]filterColumn{
+	"vehicle": eventsResource.filters["vehicle"],
+	"driver":  eventsResource.filters["driver"],
+	"office":  eventsResource.filters["office"],
+}
 
-		if rowCount > 100 {
-			log.Printf("⚠️ WARNING: Too many rows (%d), breaking loop", rowCount)
-			break
-		}
+// TripOdometers summarises each trip (運行NO) with events within a date range:
+// first start time, last end time, lowest 開始走行距離 and highest 終了走行距離.
+// Zero odometer readings are treated as missing. Trips are ordered by
+// vehicle, then 運行日 and start time.
+func (r *DtakoEventsRepository) TripOdometers(ctx context.Context, from, to time.Time, filter models.ListFilter) (_ []models.OdometerTrip, err error) {
+	ctx, span := startSpan(ctx, "DtakoEventsRepository.TripOdometers", dbProduction, "dtako_events", tracing.DateRange(from, to)...)
+	defer tracing.End(span, &err)
 
-		var event models.DtakoEvent
-		var latBigint, lngBigint sql.NullInt64
-
-		// 根本修正: created_at, updated_at を除外
-		err := rows.Scan(
-			&event.ID,
-			&event.UnkoNo,
-			&event.EventDate,
-			&event.EventType,
-			&event.VehicleNo,
-			&event.DriverCode,
-			&event.Description,
-			&latBigint,
-			&lngBigint,
-		)
-		if err != nil {
-			log.Printf("❌ ERROR: Row scan failed at row %d: %v", rowCount, err)
-			return []models.DtakoEvent{}, err
-		}
+	// 本番DBのみ使用（GetByDateRangeと同じ）
+	db := r.prodDB
+	if db == nil {
+		return nil, ErrProductionUnavailable
+	}
+
+	inner := `
+		SELECT 運行NO, CAST(車輌CD AS CHAR) AS vehicle_no,
+		       COALESCE(CAST(MIN(対象乗務員CD) AS CHAR), '') AS driver_code,
+		       MIN(開始日時) AS start_at,
+		       MAX(COALESCE(終了日時, 開始日時)) AS end_at,
+		       MIN(NULLIF(開始走行距離, 0)) AS start_odometer,
+		       MAX(NULLIF(終了走行距離, 0)) AS end_odometer
+		FROM dtako_events
+		WHERE 開始日時 >= ? AND 開始日時 < DATE_ADD(?, INTERVAL 1 DAY)
+		  AND 運行NO IS NOT NULL AND 運行NO <> ''
+	`
+	inner, args, err := appendFilters(ctx, inner, []interface{}{from.Format("2006-01-02"), to.Format("2006-01-02")}, filter, odometerFilters)
+	if err != nil {
+		return nil, err
+	}
+	inner += " GROUP BY 運行NO, 車輌CD"
 
-		// GPS座標変換
-		if latBigint.Valid {
-			lat := float64(latBigint.Int64) / 1000000.0
-			event.Latitude = &lat
+	query := `
+		SELECT t.運行NO, t.vehicle_no, t.driver_code, d.運行日,
+		       t.start_at, t.end_at, t.start_odometer, t.end_odometer
+		FROM (` + inner + `) t
+		LEFT JOIN (SELECT 運行NO, MIN(運行日) AS 運行日 FROM dtako_rows GROUP BY 運行NO) d
+		       ON d.運行NO = t.運行NO
+		ORDER BY t.vehicle_no, COALESCE(d.運行日, DATE(t.start_at)), t.start_at, t.運行NO
+	`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	trips := []models.OdometerTrip{}
+	for rows.Next() {
+		var trip models.OdometerTrip
+		var date sql.NullTime
+		var startOdo, endOdo sql.NullFloat64
+		if err := rows.Scan(&trip.UnkoNo, &trip.VehicleNo, &trip.DriverCode, &date,
+			&trip.StartAt, &trip.EndAt, &startOdo, &endOdo); err != nil {
+			return nil, err
+		}
+		if date.Valid {
+			trip.Date = &date.Time
+		}
+		if startOdo.Valid {
+			trip.StartOdometer = &startOdo.Float64
 		}
-		if lngBigint.Valid {
-			lng := float64(lngBigint.Int64) / 1000000.0
-			event.Longitude = &lng
+		if endOdo.Valid {
+			trip.EndOdometer = &endOdo.Float64
 		}
+		trips = append(trips, trip)
+	}
+
+	return trips, rows.Err()
+}
 
-		// created_at, updated_at はnilのままにする（実際のテーブルには存在しない）
-		event.CreatedAt = nil
-		event.UpdatedAt = nil
+// GetByUnkoNo retrieves all events of a trip (運行NO) ordered by event time
+func (r *DtakoEventsRepository) GetByUnkoNo(ctx context.Context, unkoNo string) (_ []models.DtakoEvent, err error) {
+	ctx, span := startSpan(ctx, "DtakoEventsRepository.GetByUnkoNo", dbProduction, "dtako_events")
+	defer tracing.End(span, &err)
 
+	// 本番DBのみ使用（GetByDateRangeと同じ）
+	db := r.prodDB
+	if db == nil {
+		return []models.DtakoEvent{}, ErrProductionUnavailable
+	}
+
+	query := `
+		SELECT ` + eventColumns + `
+		FROM dtako_events
+		WHERE 運行NO = ?
+	`
+	query, args := appendOfficeScope(ctx, query, []interface{}{unkoNo}, eventsResource.filters["office"].column)
+	query += " ORDER BY 開始日時 ASC, id ASC"
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return []models.DtakoEvent{}, err
+	}
+	defer rows.Close()
+
+	results := []models.DtakoEvent{}
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return []models.DtakoEvent{}, err
+		}
 		results = append(results, event)
-		log.Printf("🔍 DEBUG: Row %d processed successfully", rowCount)
 	}
 
-	log.Printf("✅ SUCCESS: GetByDateRange completed - %d rows processed", rowCount)
-	return results, nil
+	return results, rows.Err()
 }
 
 // GetByID retrieves a specific event by ID from local database
-func (r *DtakoEventsRepository) GetByID(id string) (*models.DtakoEvent, error) {
-	log.Printf("🔍 DEBUG: GetByID START - id=%s", id)
+func (r *DtakoEventsRepository) GetByID(ctx context.Context, id string) (_ *models.DtakoEvent, err error) {
+	ctx, span := startSpan(ctx, "DtakoEventsRepository.GetByID", dbProduction, "dtako_events")
+	defer tracing.End(span, &err)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	// 本番DBのみ使用（ローカルは無視）
 	var db *sql.DB = r.prodDB
 	if db == nil {
-		return nil, fmt.Errorf("production database not available")
+		return nil, ErrProductionUnavailable
 	}
 
 	// 根本修正: created_at, updated_at を除外したクエリ
 	query := `
-		SELECT
-			id,
-			COALESCE(運行NO, '') as unko_no,
-			開始日時 as event_date,
-			イベント名 as event_type,
-			CAST(車輌CD AS CHAR) as vehicle_no,
-			CAST(対象乗務員CD AS CHAR) as driver_code,
-			COALESCE(備考, '') as description,
-			開始GPS緯度,
-			開始GPS経度
+		SELECT ` + eventColumns + `
 		FROM dtako_events
 		WHERE id = ?
 	`
+	query, args := appendOfficeScope(ctx, query, []interface{}{id}, eventsResource.filters["office"].column)
 
-	var event models.DtakoEvent
-	var latBigint, lngBigint sql.NullInt64
-
-	log.Printf("🔍 DEBUG: Executing GetByID query")
-	err := db.QueryRowContext(ctx, query, id).Scan(
-		&event.ID,
-		&event.UnkoNo,
-		&event.EventDate,
-		&event.EventType,
-		&event.VehicleNo,
-		&event.DriverCode,
-		&event.Description,
-		&latBigint,
-		&lngBigint,
-	)
-
+	event, err := scanEvent(db.QueryRowContext(ctx, query, args...))
 	if err != nil {
-		log.Printf("❌ ERROR: GetByID query failed: %v", err)
+		if err != sql.ErrNoRows {
+			logging.Logger().Error("dtako_events get by id failed", "id", id, "error", err)
+		}
 		return nil, err
 	}
 
-	// GPS座標変換
-	if latBigint.Valid {
-		lat := float64(latBigint.Int64) / 1000000.0
-		event.Latitude = &lat
-	}
-	if lngBigint.Valid {
-		lng := float64(lngBigint.Int64) / 1000000.0
-		event.Longitude = &lng
-	}
-
-	// created_at, updated_at はnilのままにする
-	event.CreatedAt = nil
-	event.UpdatedAt = nil
-
-	log.Printf("✅ SUCCESS: GetByID completed")
 	return &event, nil
 }
 
 // FetchFromProduction fetches event data from production database
-func (r *DtakoEventsRepository) FetchFromProduction(from, to time.Time, eventType string) ([]models.DtakoEvent, error) {
+func (r *DtakoEventsRepository) FetchFromProduction(ctx context.Context, from, to time.Time, eventType string) (_ []models.DtakoEvent, err error) {
+	ctx, span := startSpan(ctx, "DtakoEventsRepository.FetchFromProduction", dbProduction, "dtako_events", tracing.DateRange(from, to)...)
+	defer tracing.End(span, &err)
+
 	if r.prodDB == nil {
-		return []models.DtakoEvent{}, nil
+		return []models.DtakoEvent{}, ErrProductionUnavailable
 	}
 
-	log.Printf("🔍 DEBUG: FetchFromProduction START")
+	query, args := eventsFetchQuery(from, to, eventType)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	rows, err := r.prodDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		logging.Logger().Error("dtako_events production fetch failed", "error", err)
+		return []models.DtakoEvent{}, err
+	}
+	defer rows.Close()
+
+	results := []models.DtakoEvent{}
+	rowCount := 0
+
+	for rows.Next() {
+		rowCount++
 
+		var section models.EventSection
+		var codes models.ImportCodes
+		var endDate sql.NullTime
+		event, err := scanEvent(rows, &endDate, &section.Distance,
+			&section.StartCity, &section.EndCity, &section.StartPlace, &section.EndPlace,
+			&section.StartOdometer, &section.EndOdometer,
+			&codes.VehicleCD, &codes.VehicleCC, &codes.DriverCD, &codes.OfficeCD)
+		if err != nil {
+			logging.Logger().Error("dtako_events production scan failed", "row", rowCount, "error", err)
+			return []models.DtakoEvent{}, err
+		}
+		if endDate.Valid {
+			section.EndDate = &endDate.Time
+		}
+		event.Section = &section
+		event.Codes = &codes
+
+		results = append(results, event)
+	}
+
+	logging.Logger().Debug("dtako_events production fetch completed", "rows", len(results))
+	tracing.Rows(span, "read", len(results))
+	return results, rows.Err()
+}
+
+// eventSectionColumns are selected after eventColumns on import (see models.EventSection)
+const eventSectionColumns = `
+			終了日時,
+			COALESCE(区間距離, 0),
+			COALESCE(開始市町村名, ''), COALESCE(終了市町村名, ''),
+			COALESCE(開始場所名, ''), COALESCE(終了場所名, ''),
+			COALESCE(開始走行距離, 0), COALESCE(終了走行距離, 0)`
+
+// eventCodesColumns are selected after eventSectionColumns on import (see models.ImportCodes)
+const eventCodesColumns = `
+			COALESCE(車輌CD, 0), COALESCE(車輌CC, ''),
+			COALESCE(対象乗務員CD, 0), COALESCE(事業所CD, 0)`
+
+// eventsFetchQuery builds the import query of events whose 開始日時 falls on
+// a business day between from and to, inclusive, optionally of one イベント名
+func eventsFetchQuery(from, to time.Time, eventType string) (string, []interface{}) {
 	query := `
-		SELECT
-			id,
-			COALESCE(運行NO, '') as unko_no,
-			開始日時 as event_date,
-			イベント名 as event_type,
-			CAST(車輌CD AS CHAR) as vehicle_no,
-			CAST(対象乗務員CD AS CHAR) as driver_code,
-			COALESCE(備考, '') as description,
-			開始GPS緯度,
-			開始GPS経度
+		SELECT ` + eventColumns + `,` + eventSectionColumns + `,` + eventCodesColumns + `
 		FROM dtako_events
 		WHERE 開始日時 >= ? AND 開始日時 < DATE_ADD(?, INTERVAL 1 DAY)
 	`
-	// 昨日（2024-09-13）からのデータを取得するように固定
-	yesterday := time.Date(2024, 9, 13, 0, 0, 0, 0, time.UTC)
-	tomorrow := time.Date(2024, 9, 15, 0, 0, 0, 0, time.UTC)
-	args := []interface{}{yesterday.Format("2006-01-02"), tomorrow.Format("2006-01-02")}
+	args := []interface{}{businessDay(from), businessDay(to)}
 
 	if eventType != "" {
 		query += " AND イベント名 = ?"
 		args = append(args, eventType)
 	}
 
-	query += " ORDER BY 開始日時 DESC LIMIT 100"
+	return query + " ORDER BY 開始日時 DESC", args
+}
 
-	rows, err := r.prodDB.QueryContext(ctx, query, args...)
+// GetTimelineByUnkoNos retrieves the events of the given trips with their end
+// time, section distance, places and odometer readings, ordered by trip and
+// start time. Category and Minutes are left for the caller.
+func (r *DtakoEventsRepository) GetTimelineByUnkoNos(ctx context.Context, unkoNos []string) (_ []models.DailyReportEvent, err error) {
+	ctx, span := startSpan(ctx, "DtakoEventsRepository.GetTimelineByUnkoNos", dbProduction, "dtako_events")
+	defer tracing.End(span, &err)
+
+	// 本番DBのみ使用（GetByDateRangeと同じ）
+	if r.prodDB == nil {
+		return nil, ErrProductionUnavailable
+	}
+	if len(unkoNos) == 0 {
+		return []models.DailyReportEvent{}, nil
+	}
+
+	query, args := timelineQuery(unkoNos)
+	query, args = appendOfficeScope(ctx, query, args, eventsResource.filters["office"].column)
+
+	events, err := r.timeline(ctx, r.prodDB, query+timelineOrder, args)
+	tracing.Rows(span, "read", len(events))
+	return events, err
+}
+
+// GetLocalTimelineByUnkoNos is GetTimelineByUnkoNos on the imported events
+// of the local database. Callers whose results are invalidated by imports
+// (payroll snapshots) read from here. Events are office scoped by the
+// 事業所CD copied from production on import.
+func (r *DtakoEventsRepository) GetLocalTimelineByUnkoNos(ctx context.Context, unkoNos []string) (_ []models.DailyReportEvent, err error) {
+	ctx, span := startSpan(ctx, "DtakoEventsRepository.GetLocalTimelineByUnkoNos", dbLocal, "dtako_events")
+	defer tracing.End(span, &err)
+
+	if len(unkoNos) == 0 {
+		return []models.DailyReportEvent{}, nil
+	}
+
+	query, args := timelineQuery(unkoNos)
+	query, args = appendOfficeScope(ctx, query, args, eventsResource.filters["office"].column)
+	events, err := r.timeline(ctx, r.localDB, query+timelineOrder, args)
+	tracing.Rows(span, "read", len(events))
+	return events, err
+}
+
+const timelineOrder = " ORDER BY 運行NO, 開始日時, id"
+
+// timelineQuery selects the timeline columns of the events of unkoNos
+func timelineQuery(unkoNos []string) (string, []interface{}) {
+	placeholders := make([]string, len(unkoNos))
+	args := make([]interface{}, len(unkoNos))
+	for i, unkoNo := range unkoNos {
+		placeholders[i] = "?"
+		args[i] = unkoNo
+	}
+
+	return `
+		SELECT 運行NO, イベント名, 開始日時, 終了日時,
+		       COALESCE(区間距離, 0),
+		       COALESCE(NULLIF(開始場所名, ''), 開始市町村名, ''),
+		       COALESCE(NULLIF(終了場所名, ''), 終了市町村名, ''),
+		       NULLIF(開始走行距離, 0), NULLIF(終了走行距離, 0)
+		FROM dtako_events
+		WHERE 運行NO IN (` + strings.Join(placeholders, ", ") + `)
+	`, args
+}
+
+// timeline runs a timelineQuery on db
+func (r *DtakoEventsRepository) timeline(ctx context.Context, db *sql.DB, query string, args []interface{}) ([]models.DailyReportEvent, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
-		log.Printf("❌ ERROR: FetchFromProduction query failed: %v", err)
-		return []models.DtakoEvent{}, err
+		return nil, err
 	}
 	defer rows.Close()
 
-	results := []models.DtakoEvent{}
-	rowCount := 0
-
+	events := []models.DailyReportEvent{}
 	for rows.Next() {
-		rowCount++
-		if rowCount > 100 {
-			log.Printf("⚠️ WARNING: Too many rows in FetchFromProduction, breaking")
-			break
+		var e models.DailyReportEvent
+		var endAt sql.NullTime
+		var startOdo, endOdo sql.NullFloat64
+		if err := rows.Scan(&e.UnkoNo, &e.EventType, &e.StartAt, &endAt,
+			&e.Distance, &e.StartPlace, &e.EndPlace, &startOdo, &endOdo); err != nil {
+			return nil, err
 		}
-
-		var event models.DtakoEvent
-		var latBigint, lngBigint sql.NullInt64
-
-		err := rows.Scan(
-			&event.ID,
-			&event.UnkoNo,
-			&event.EventDate,
-			&event.EventType,
-			&event.VehicleNo,
-			&event.DriverCode,
-			&event.Description,
-			&latBigint,
-			&lngBigint,
-		)
-		if err != nil {
-			log.Printf("❌ ERROR: FetchFromProduction scan failed: %v", err)
-			return []models.DtakoEvent{}, err
+		if endAt.Valid {
+			e.EndAt = &endAt.Time
 		}
-
-		// GPS座標変換
-		if latBigint.Valid {
-			lat := float64(latBigint.Int64) / 1000000.0
-			event.Latitude = &lat
+		if startOdo.Valid {
+			e.StartOdometer = &startOdo.Float64
 		}
-		if lngBigint.Valid {
-			lng := float64(lngBigint.Int64) / 1000000.0
-			event.Longitude = &lng
+		if endOdo.Valid {
+			e.EndOdometer = &endOdo.Float64
 		}
-
-		// created_at, updated_at はnilのまま
-		event.CreatedAt = nil
-		event.UpdatedAt = nil
-
-		results = append(results, event)
+		events = append(events, e)
 	}
 
-	log.Printf("✅ SUCCESS: FetchFromProduction completed - %d rows", rowCount)
-	return results, nil
+	return events, rows.Err()
 }
 
 // Insert inserts an event into local database
-func (r *DtakoEventsRepository) Insert(event *models.DtakoEvent) error {
+func (r *DtakoEventsRepository) Insert(ctx context.Context, event *models.DtakoEvent) (err error) {
+	ctx, span := startSpan(ctx, "DtakoEventsRepository.Insert", dbLocal, "dtako_events")
+	defer tracing.End(span, &err)
+
 	// 実際のテーブル構造に合わせたINSERT
 	query := `
 		INSERT INTO dtako_events (
 			id, 運行NO, 読取日, 車輌CD, 車輌CC, 開始日時, 終了日時,
-			イベント名, 対象乗務員CD, 対象乗務員区分, 乗務員CD1,
+			イベント名, 対象乗務員CD, 対象乗務員区分, 乗務員CD1, 事業所CD,
 			開始走行距離, 終了走行距離, 区間時間, 区間距離,
 			開始市町村名, 終了市町村名, 開始場所名, 終了場所名,
 			開始GPS緯度, 開始GPS経度, 備考
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON DUPLICATE KEY UPDATE
 		    運行NO = VALUES(運行NO),
 		    読取日 = VALUES(読取日),
+		    車輌CD = VALUES(車輌CD),
+		    車輌CC = VALUES(車輌CC),
 		    開始日時 = VALUES(開始日時),
 		    終了日時 = VALUES(終了日時),
 		    イベント名 = VALUES(イベント名),
+		    対象乗務員CD = VALUES(対象乗務員CD),
+		    事業所CD = VALUES(事業所CD),
+		    開始走行距離 = VALUES(開始走行距離),
+		    終了走行距離 = VALUES(終了走行距離),
+		    区間距離 = VALUES(区間距離),
+		    開始市町村名 = VALUES(開始市町村名),
+		    終了市町村名 = VALUES(終了市町村名),
+		    開始場所名 = VALUES(開始場所名),
+		    終了場所名 = VALUES(終了場所名),
 		    備考 = VALUES(備考)
 	`
 
+	_, err = r.localDB.ExecContext(ctx, query, eventInsertArgs(event)...)
+
+	return err
+}
+
+// eventInsertArgs returns the arguments of the Insert statement for event
+func eventInsertArgs(event *models.DtakoEvent) []interface{} {
 	// デフォルト値
 	readDate := event.EventDate
-	vehicleCD := 1
-	vehicleCC := "001100"
-	driverCode := 0
 	driverKubun := 0
 	driverCD1 := 0
 	startDistance := 0.0
@@ -351,15 +599,21 @@ func (r *DtakoEventsRepository) Insert(event *models.DtakoEvent) error {
 	startPlace := ""
 	endPlace := ""
 
-	if event.VehicleNo != "" {
-		vehicleCD = 1
-	}
-	if event.DriverCode != "" {
-		driverCode = 1
-	}
+	codes := importCodes(event.Codes, event.VehicleNo, event.DriverCode)
 
 	endDateTime := event.EventDate
 
+	// 本番DBから取り込んだ区間情報（給与集計はローカルのイベントから計算する）
+	if sec := event.Section; sec != nil {
+		if sec.EndDate != nil {
+			endDateTime = *sec.EndDate
+		}
+		sectionDistance = sec.Distance
+		startCity, endCity = sec.StartCity, sec.EndCity
+		startPlace, endPlace = sec.StartPlace, sec.EndPlace
+		startDistance, endDistance = sec.StartOdometer, sec.EndOdometer
+	}
+
 	var description sql.NullString
 	if event.Description != "" {
 		description = sql.NullString{String: event.Description, Valid: true}
@@ -373,14 +627,12 @@ func (r *DtakoEventsRepository) Insert(event *models.DtakoEvent) error {
 		longitude = sql.NullInt64{Int64: int64(*event.Longitude * 1000000), Valid: true}
 	}
 
-	_, err := r.localDB.Exec(query,
-		event.ID, event.UnkoNo, readDate, vehicleCD, vehicleCC,
+	return []interface{}{
+		event.ID, event.UnkoNo, readDate, codes.VehicleCD, codes.VehicleCC,
 		event.EventDate, endDateTime, event.EventType,
-		driverCode, driverKubun, driverCD1,
+		codes.DriverCD, driverKubun, driverCD1, codes.OfficeCD,
 		startDistance, endDistance, sectionTime, sectionDistance,
 		startCity, endCity, startPlace, endPlace,
 		latitude, longitude, description,
-	)
-
-	return err
-}
\ No newline at end of file
+	}
+}
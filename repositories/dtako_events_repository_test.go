@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/config"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+func TestEventsListQueryBindsRequestedRange(t *testing.T) {
+	loc := config.BusinessLocation()
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, loc)
+	to := time.Date(2025, 3, 31, 0, 0, 0, 0, loc)
+
+	tests := []struct {
+		name      string
+		q         models.ListQuery
+		wantLimit string
+	}{
+		{name: "all records", q: models.ListQuery{}},
+		{name: "paged", q: models.ListQuery{Limit: 50, Offset: 100}, wantLimit: " LIMIT 50 OFFSET 100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := eventsListQuery(context.Background(), eventsResource.fields, from, to, tt.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(args) < 2 || args[0] != "2025-03-01" || args[1] != "2025-03-31" {
+				t.Errorf("Expected the requested range as arguments, got %v", args)
+			}
+			if tt.wantLimit == "" && strings.Contains(query, "LIMIT") {
+				t.Errorf("Expected no LIMIT, got %s", query)
+			}
+			if tt.wantLimit != "" && !strings.HasSuffix(query, tt.wantLimit) {
+				t.Errorf("Expected %q at the end of %s", tt.wantLimit, query)
+			}
+		})
+	}
+}
+
+func TestEventsFetchQueryBindsRequestedRange(t *testing.T) {
+	loc := config.BusinessLocation()
+	// 営業日の 00:00 は UTC では前日になる
+	from := time.Date(2023, 11, 20, 0, 0, 0, 0, loc).UTC()
+	to := time.Date(2023, 11, 22, 0, 0, 0, 0, loc).UTC()
+
+	query, args := eventsFetchQuery(from, to, "休憩")
+	if strings.Contains(query, "LIMIT") {
+		t.Errorf("Expected no LIMIT on import, got %s", query)
+	}
+	want := []interface{}{"2023-11-20", "2023-11-22", "休憩"}
+	if len(args) != len(want) {
+		t.Fatalf("Expected args %v, got %v", want, args)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("Arg %d: expected %v, got %v", i, want[i], args[i])
+		}
+	}
+}
+
+func TestPageClause(t *testing.T) {
+	tests := []struct {
+		q       models.ListQuery
+		want    string
+		wantErr bool
+	}{
+		{q: models.ListQuery{}, want: ""},
+		{q: models.ListQuery{Limit: 10}, want: " LIMIT 10 OFFSET 0"},
+		{q: models.ListQuery{Limit: 10, Offset: 20}, want: " LIMIT 10 OFFSET 20"},
+		{q: models.ListQuery{Offset: 20}, wantErr: true},
+		{q: models.ListQuery{Limit: -1}, wantErr: true},
+		{q: models.ListQuery{Limit: maxListLimit + 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := pageClause(tt.q)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidListQuery) {
+				t.Errorf("%+v: expected ErrInvalidListQuery, got %v", tt.q, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%+v: expected %q, got %q (%v)", tt.q, tt.want, got, err)
+		}
+	}
+}
+
+func TestEventInsertArgsKeepsProductionCodes(t *testing.T) {
+	event := models.DtakoEvent{
+		ID: "e1", UnkoNo: "2025011301", EventType: "運転",
+		EventDate: time.Date(2025, 1, 13, 8, 0, 0, 0, time.UTC),
+		VehicleNo: "101", DriverCode: "1001",
+		Codes: &models.ImportCodes{VehicleCD: 101, VehicleCC: "001101", DriverCD: 1001, OfficeCD: 3},
+	}
+
+	args := eventInsertArgs(&event)
+	want := map[int]interface{}{3: 101, 4: "001101", 8: 1001, 11: 3} // 車輌CD, 車輌CC, 対象乗務員CD, 事業所CD
+	for i, v := range want {
+		if args[i] != v {
+			t.Errorf("Arg %d: expected %v, got %v", i, v, args[i])
+		}
+	}
+}
@@ -1,11 +1,12 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
 	"time"
 
 	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
 )
 
 // DtakoFerryRowsRepository handles database operations for dtako_ferry_rows
@@ -16,8 +17,8 @@ type DtakoFerryRowsRepository struct {
 
 // NewDtakoFerryRowsRepository creates a new repository instance
 func NewDtakoFerryRowsRepository() *DtakoFerryRowsRepository {
-	prodDB, _ := GetProductionDB()
-	localDB, _ := GetLocalDB()
+	prodDB := productionPool()
+	localDB := localPool()
 
 	return &DtakoFerryRowsRepository{
 		prodDB:  prodDB,
@@ -26,57 +27,79 @@ func NewDtakoFerryRowsRepository() *DtakoFerryRowsRepository {
 }
 
 // GetByDateRange retrieves ferry row records within a date range from local database
-func (r *DtakoFerryRowsRepository) GetByDateRange(from, to time.Time, ferryCompany string) ([]models.DtakoFerryRow, error) {
+func (r *DtakoFerryRowsRepository) GetByDateRange(ctx context.Context, from, to time.Time, q models.ListQuery) ([]models.DtakoFerryRow, error) {
+	results := []models.DtakoFerryRow{}
+	err := r.StreamByDateRange(ctx, from, to, q, func(record *models.DtakoFerryRow) error {
+		results = append(results, *record)
+		return nil
+	})
+	if err != nil {
+		return []models.DtakoFerryRow{}, err
+	}
+
+	return results, nil
+}
+
+// StreamByDateRange reads ferry row records within a date range from local
+// database and passes each one to fn as it is scanned.
+// Only the fields named in q.Fields are selected; the rest stay zero.
+// Iteration stops at the first error returned by fn.
+func (r *DtakoFerryRowsRepository) StreamByDateRange(ctx context.Context, from, to time.Time, q models.ListQuery, fn func(*models.DtakoFerryRow) error) (err error) {
+	ctx, span := startSpan(ctx, "DtakoFerryRowsRepository.StreamByDateRange", dbLocal, "dtako_ferry_rows", tracing.DateRange(from, to)...)
+	defer tracing.End(span, &err)
+
+	fields, err := ferryRowsResource.selectFields(q.Fields)
+	if err != nil {
+		return err
+	}
+
 	query := `
-		SELECT id, 運行NO, 運行日, 読取日, 事業所CD, 事業所名,
-		       車輌CD, 車輌名, 乗務員CD1, 乗務員名１, 対象乗務員区分,
-		       開始日時, 終了日時, フェリー会社CD, フェリー会社名,
-		       乗場CD, 乗場名, 便, 降場CD, 降場名,
-		       精算区分, 精算区分名, 標準料金, 契約料金,
-		       航送車種区分, 航送車種区分名, 見なし距離,
-		       COALESCE(ferry_srch, '')
+		SELECT ` + selectList(fields) + `
 		FROM dtako_ferry_rows
 		WHERE 運行日 BETWEEN ? AND ?
 	`
-	args := []interface{}{from, to}
 
-	if ferryCompany != "" {
-		query += " AND フェリー会社名 = ?"
-		args = append(args, ferryCompany)
+	query, args, err := appendFilters(ctx, query, []interface{}{from, to}, q.Filter, ferryRowsResource.filters)
+	if err != nil {
+		return err
 	}
+	order, err := ferryRowsResource.orderBy(ctx, q.Sort)
+	if err != nil {
+		return err
+	}
+	page, err := pageClause(q)
+	if err != nil {
+		return err
+	}
+	query += order + page
 
-	query += " ORDER BY 運行日 DESC, 開始日時 DESC"
-
-	rows, err := r.localDB.Query(query, args...)
+	rows, err := r.localDB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return []models.DtakoFerryRow{}, err
+		return err
 	}
 	defer rows.Close()
 
-	results := []models.DtakoFerryRow{}
+	read := 0
 	for rows.Next() {
-		var record models.DtakoFerryRow
-		err := rows.Scan(
-			&record.ID, &record.UnkoNo, &record.UnkoDate, &record.ReadDate,
-			&record.OfficeCode, &record.OfficeName, &record.VehicleCode, &record.VehicleName,
-			&record.DriverCode1, &record.DriverName1, &record.TargetDriverClass,
-			&record.StartTime, &record.EndTime, &record.FerryCompanyCode, &record.FerryCompanyName,
-			&record.BoardingCode, &record.BoardingName, &record.ShipNumber,
-			&record.LandingCode, &record.LandingName, &record.SettlementClass, &record.SettlementName,
-			&record.StandardFare, &record.ContractFare, &record.ShipVehicleClass, &record.ShipVehicleName,
-			&record.EstimatedDistance, &record.FerrySearch,
-		)
+		record, err := scanFields(rows, fields)
 		if err != nil {
-			return []models.DtakoFerryRow{}, err
+			return err
 		}
-		results = append(results, record)
+		if err := fn(&record); err != nil {
+			return err
+		}
+		read++
 	}
 
-	return results, nil
+	tracing.Rows(span, "read", read)
+	return rows.Err()
 }
 
 // GetByID retrieves a specific ferry row record by ID from local database
-func (r *DtakoFerryRowsRepository) GetByID(id string) (*models.DtakoFerryRow, error) {
+func (r *DtakoFerryRowsRepository) GetByID(ctx context.Context, id string) (_ *models.DtakoFerryRow, err error) {
+	ctx, span := startSpan(ctx, "DtakoFerryRowsRepository.GetByID", dbLocal, "dtako_ferry_rows")
+	defer tracing.End(span, &err)
+
 	query := `
 		SELECT id, 運行NO, 運行日, 読取日, 事業所CD, 事業所名,
 		       車輌CD, 車輌名, 乗務員CD1, 乗務員名１, 対象乗務員区分,
@@ -88,9 +111,10 @@ func (r *DtakoFerryRowsRepository) GetByID(id string) (*models.DtakoFerryRow, er
 		FROM dtako_ferry_rows
 		WHERE id = ?
 	`
+	query, args := appendOfficeScope(ctx, query, []interface{}{id}, ferryRowsResource.filters["office"].column)
 
 	var record models.DtakoFerryRow
-	err := r.localDB.QueryRow(query, id).Scan(
+	err = r.localDB.QueryRowContext(ctx, query, args...).Scan(
 		&record.ID, &record.UnkoNo, &record.UnkoDate, &record.ReadDate,
 		&record.OfficeCode, &record.OfficeName, &record.VehicleCode, &record.VehicleName,
 		&record.DriverCode1, &record.DriverName1, &record.TargetDriverClass,
@@ -109,9 +133,12 @@ func (r *DtakoFerryRowsRepository) GetByID(id string) (*models.DtakoFerryRow, er
 }
 
 // FetchFromProduction fetches ferry row data from production database
-func (r *DtakoFerryRowsRepository) FetchFromProduction(from, to time.Time, ferryCompany string) ([]models.DtakoFerryRow, error) {
+func (r *DtakoFerryRowsRepository) FetchFromProduction(ctx context.Context, from, to time.Time, ferryCompany string) (_ []models.DtakoFerryRow, err error) {
+	ctx, span := startSpan(ctx, "DtakoFerryRowsRepository.FetchFromProduction", dbProduction, "dtako_ferry_rows", tracing.DateRange(from, to)...)
+	defer tracing.End(span, &err)
+
 	if r.prodDB == nil {
-		return []models.DtakoFerryRow{}, fmt.Errorf("production database not connected")
+		return []models.DtakoFerryRow{}, ErrProductionUnavailable
 	}
 
 	query := `
@@ -134,7 +161,7 @@ func (r *DtakoFerryRowsRepository) FetchFromProduction(from, to time.Time, ferry
 
 	query += " ORDER BY 運行日 DESC, 開始日時 DESC"
 
-	rows, err := r.prodDB.Query(query, args...)
+	rows, err := r.prodDB.QueryContext(ctx, query, args...)
 	if err != nil {
 		return []models.DtakoFerryRow{}, err
 	}
@@ -159,11 +186,15 @@ func (r *DtakoFerryRowsRepository) FetchFromProduction(from, to time.Time, ferry
 		results = append(results, record)
 	}
 
+	tracing.Rows(span, "read", len(results))
 	return results, nil
 }
 
 // Insert inserts a ferry row record into local database
-func (r *DtakoFerryRowsRepository) Insert(record *models.DtakoFerryRow) error {
+func (r *DtakoFerryRowsRepository) Insert(ctx context.Context, record *models.DtakoFerryRow) (err error) {
+	ctx, span := startSpan(ctx, "DtakoFerryRowsRepository.Insert", dbLocal, "dtako_ferry_rows")
+	defer tracing.End(span, &err)
+
 	query := `
 		INSERT INTO dtako_ferry_rows (
 			id, 運行NO, 運行日, 読取日, 事業所CD, 事業所名,
@@ -203,7 +234,7 @@ func (r *DtakoFerryRowsRepository) Insert(record *models.DtakoFerryRow) error {
 			ferry_srch = VALUES(ferry_srch)
 	`
 
-	_, err := r.localDB.Exec(query,
+	_, err = r.localDB.ExecContext(ctx, query,
 		record.ID, record.UnkoNo, record.UnkoDate, record.ReadDate,
 		record.OfficeCode, record.OfficeName, record.VehicleCode, record.VehicleName,
 		record.DriverCode1, record.DriverName1, record.TargetDriverClass,
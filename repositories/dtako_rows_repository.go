@@ -1,11 +1,16 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
 )
 
 // DtakoRowsRepository handles database operations for dtako_rows
@@ -16,8 +21,8 @@ type DtakoRowsRepository struct {
 
 // NewDtakoRowsRepository creates a new repository instance
 func NewDtakoRowsRepository() *DtakoRowsRepository {
-	prodDB, _ := GetProductionDB()
-	localDB, _ := GetLocalDB()
+	prodDB := productionPool()
+	localDB := localPool()
 
 	return &DtakoRowsRepository{
 		prodDB:  prodDB,
@@ -26,50 +31,156 @@ func NewDtakoRowsRepository() *DtakoRowsRepository {
 }
 
 // GetByDateRange retrieves rows within a date range from local database
-func (r *DtakoRowsRepository) GetByDateRange(from, to time.Time) ([]models.DtakoRow, error) {
+func (r *DtakoRowsRepository) GetByDateRange(ctx context.Context, from, to time.Time, q models.ListQuery) ([]models.DtakoRow, error) {
+	results := []models.DtakoRow{}
+	err := r.StreamByDateRange(ctx, from, to, q, func(row *models.DtakoRow) error {
+		results = append(results, *row)
+		return nil
+	})
+	if err != nil {
+		return []models.DtakoRow{}, err
+	}
+
+	return results, nil
+}
+
+// StreamByDateRange reads rows within a date range from local database and
+// passes each one to fn as it is scanned, without buffering the result set.
+// Only the fields named in q.Fields are selected; the rest stay zero.
+// Iteration stops at the first error returned by fn.
+func (r *DtakoRowsRepository) StreamByDateRange(ctx context.Context, from, to time.Time, q models.ListQuery, fn func(*models.DtakoRow) error) (err error) {
+	ctx, span := startSpan(ctx, "DtakoRowsRepository.StreamByDateRange", dbLocal, "dtako_rows", tracing.DateRange(from, to)...)
+	defer tracing.End(span, &err)
+
+	fields, err := rowsResource.selectFields(q.Fields)
+	if err != nil {
+		return err
+	}
+
 	// ローカルDBは日本語カラム名
 	query := `
-		SELECT id, 運行NO, 運行日, 車輌CD, 対象乗務員CD, 行先市町村名,
-		       総走行距離, 自社主燃料, NULL as created_at, NULL as updated_at
+		SELECT ` + selectList(fields) + `
 		FROM dtako_rows
 		WHERE 運行日 BETWEEN ? AND ?
-		ORDER BY 運行日 DESC
 	`
 
-	rows, err := r.localDB.Query(query, from, to)
+	query, args, err := appendFilters(ctx, query, []interface{}{from, to}, q.Filter, rowsResource.filters)
 	if err != nil {
-		return []models.DtakoRow{}, err
+		return err
+	}
+	order, err := rowsResource.orderBy(ctx, q.Sort)
+	if err != nil {
+		return err
+	}
+	page, err := pageClause(q)
+	if err != nil {
+		return err
+	}
+	query += order + page
+
+	rows, err := r.localDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
 	}
 	defer rows.Close()
 
-	results := []models.DtakoRow{}
+	read := 0
 	for rows.Next() {
-		var row models.DtakoRow
-		err := rows.Scan(
-			&row.ID, &row.UnkoNo, &row.Date, &row.VehicleNo, &row.DriverCode,
-			&row.RouteCode, &row.Distance, &row.FuelAmount,
-			&row.CreatedAt, &row.UpdatedAt,
-		)
+		row, err := scanFields(rows, fields)
 		if err != nil {
-			return []models.DtakoRow{}, err
+			return err
 		}
-		results = append(results, row)
+		if err := fn(&row); err != nil {
+			return err
+		}
+		read++
 	}
 
-	return results, nil
+	tracing.Rows(span, "read", read)
+	return rows.Err()
+}
+
+// rowStatsGroups maps group_by values to the SQL expression used as group key
+var rowStatsGroups = map[string]string{
+	"vehicle": "CAST(車輌CD AS CHAR)",
+	"driver":  "CAST(対象乗務員CD AS CHAR)",
+	"office":  "CAST(事業所CD AS CHAR)",
+	"day":     "DATE_FORMAT(運行日, '%Y-%m-%d')",
+	"month":   "DATE_FORMAT(運行日, '%Y-%m')",
+}
+
+// Stats aggregates distance and fuel of rows within a date range in SQL.
+// groupBy is one of vehicle, driver, office, day or month; empty returns a
+// single group with an empty key. Groups are ordered by key.
+func (r *DtakoRowsRepository) Stats(ctx context.Context, from, to time.Time, groupBy string, filter models.ListFilter) (_ []models.RowStatsGroup, err error) {
+	ctx, span := startSpan(ctx, "DtakoRowsRepository.Stats", dbLocal, "dtako_rows", tracing.DateRange(from, to)...)
+	defer tracing.End(span, &err)
+
+	keyExpr := "''"
+	if groupBy != "" {
+		expr, ok := rowStatsGroups[groupBy]
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported group_by %q", ErrInvalidListQuery, groupBy)
+		}
+		keyExpr = expr
+	}
+
+	query := `
+		SELECT ` + keyExpr + ` AS group_key,
+		       COUNT(*),
+		       COALESCE(SUM(総走行距離), 0), COALESCE(AVG(総走行距離), 0),
+		       COALESCE(SUM(自社主燃料), 0), COALESCE(AVG(自社主燃料), 0),
+		       SUM(総走行距離) / NULLIF(SUM(自社主燃料), 0)
+		FROM dtako_rows
+		WHERE 運行日 BETWEEN ? AND ?
+	`
+
+	query, args, err := appendFilters(ctx, query, []interface{}{from, to}, filter, rowsResource.filters)
+	if err != nil {
+		return nil, err
+	}
+	query += " GROUP BY group_key ORDER BY group_key"
+
+	rows, err := r.localDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []models.RowStatsGroup{}
+	for rows.Next() {
+		var g models.RowStatsGroup
+		var key sql.NullString
+		var efficiency sql.NullFloat64
+		if err := rows.Scan(&key, &g.Trips, &g.TotalDistance, &g.AvgDistance,
+			&g.TotalFuel, &g.AvgFuel, &efficiency); err != nil {
+			return nil, err
+		}
+		g.Key = key.String
+		if efficiency.Valid {
+			g.FuelEfficiency = &efficiency.Float64
+		}
+		groups = append(groups, g)
+	}
+
+	return groups, rows.Err()
 }
 
 // GetByID retrieves a specific row by ID from local database
-func (r *DtakoRowsRepository) GetByID(id string) (*models.DtakoRow, error) {
+func (r *DtakoRowsRepository) GetByID(ctx context.Context, id string) (_ *models.DtakoRow, err error) {
+	ctx, span := startSpan(ctx, "DtakoRowsRepository.GetByID", dbLocal, "dtako_rows")
+	defer tracing.End(span, &err)
+
 	query := `
 		SELECT id, 運行NO, 運行日, 車輌CD, 対象乗務員CD, 行先市町村名,
 		       総走行距離, 自社主燃料, NULL as created_at, NULL as updated_at
 		FROM dtako_rows
 		WHERE id = ?
 	`
+	query, args := appendOfficeScope(ctx, query, []interface{}{id}, rowsResource.filters["office"].column)
 
 	var row models.DtakoRow
-	err := r.localDB.QueryRow(query, id).Scan(
+	err = r.localDB.QueryRowContext(ctx, query, args...).Scan(
 		&row.ID, &row.UnkoNo, &row.Date, &row.VehicleNo, &row.DriverCode,
 		&row.RouteCode, &row.Distance, &row.FuelAmount,
 		&row.CreatedAt, &row.UpdatedAt,
@@ -82,10 +193,90 @@ func (r *DtakoRowsRepository) GetByID(id string) (*models.DtakoRow, error) {
 	return &row, nil
 }
 
+// coverageFilters are the filters accepted by DailyVehicleCounts
+var coverageFilters = map[string]filterColumn{
+	"office": rowsResource.filters["office"],
+}
+
+// DailyVehicleCounts counts rows per 車輌CD and 運行日 within a date range.
+// With production set the production database is queried instead of the
+// local one; it returns ErrProductionUnavailable when there is no connection.
+// The office filter and the caller's office scope apply to both databases.
+func (r *DtakoRowsRepository) DailyVehicleCounts(ctx context.Context, from, to time.Time, filter models.ListFilter, production bool) (_ map[string]map[string]int, err error) {
+	dbName := dbLocal
+	if production {
+		dbName = dbProduction
+	}
+	ctx, span := startSpan(ctx, "DtakoRowsRepository.DailyVehicleCounts", dbName, "dtako_rows", tracing.DateRange(from, to)...)
+	defer tracing.End(span, &err)
+
+	db := r.localDB
+	vehicleCol, dateCol := "車輌CD", "運行日"
+	filters := coverageFilters
+	if production {
+		if r.prodDB == nil {
+			return nil, ErrProductionUnavailable
+		}
+		db = r.prodDB
+		if os.Getenv("PROD_DB_NAME") == "dtako_test_prod" {
+			// テスト用プロダクションDB（英語カラム名、事業所なし）。事業所で
+			// 絞り込めないため、事業所の指定や事業所を限定された呼び出し元は
+			// 全事業所の件数と比較せずエラーにする
+			vehicleCol, dateCol = "vehicle_no", "date"
+			filters = map[string]filterColumn{}
+		}
+	}
+
+	query := `
+		SELECT CAST(` + vehicleCol + ` AS CHAR), DATE_FORMAT(` + dateCol + `, '%Y-%m-%d'), COUNT(*)
+		FROM dtako_rows
+		WHERE ` + dateCol + ` BETWEEN ? AND ?
+	`
+	query, args, err := appendFilters(ctx, query, []interface{}{from, to}, filter, filters)
+	if err != nil {
+		return nil, err
+	}
+	query += " GROUP BY 1, 2"
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]map[string]int{}
+	for rows.Next() {
+		var vehicle, date string
+		var n int
+		if err := rows.Scan(&vehicle, &date, &n); err != nil {
+			return nil, err
+		}
+		if counts[vehicle] == nil {
+			counts[vehicle] = map[string]int{}
+		}
+		counts[vehicle][date] = n
+	}
+
+	return counts, rows.Err()
+}
+
+// ExistsByUnkoNo reports whether a row with the given 運行NO exists in local database
+func (r *DtakoRowsRepository) ExistsByUnkoNo(ctx context.Context, unkoNo string) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "DtakoRowsRepository.ExistsByUnkoNo", dbLocal, "dtako_rows")
+	defer tracing.End(span, &err)
+
+	var exists bool
+	err = r.localDB.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM dtako_rows WHERE 運行NO = ?)`, unkoNo).Scan(&exists)
+	return exists, err
+}
+
 // FetchFromProduction fetches row data from production database
-func (r *DtakoRowsRepository) FetchFromProduction(from, to time.Time) ([]models.DtakoRow, error) {
+func (r *DtakoRowsRepository) FetchFromProduction(ctx context.Context, from, to time.Time) (_ []models.DtakoRow, err error) {
+	ctx, span := startSpan(ctx, "DtakoRowsRepository.FetchFromProduction", dbProduction, "dtako_rows", tracing.DateRange(from, to)...)
+	defer tracing.End(span, &err)
+
 	if r.prodDB == nil {
-		return []models.DtakoRow{}, nil
+		return []models.DtakoRow{}, ErrProductionUnavailable
 	}
 
 	// テスト環境のdtako_test_prodは英語カラム名を使用
@@ -94,55 +285,150 @@ func (r *DtakoRowsRepository) FetchFromProduction(from, to time.Time) ([]models.
 	query := ``
 	if os.Getenv("PROD_DB_NAME") == "dtako_test_prod" {
 		// テスト用プロダクションDB（英語カラム名）
-		query = `
-			SELECT id, unko_no, date, vehicle_no, driver_code, route_code,
-			       distance, fuel_amount, created_at, updated_at
-			FROM dtako_rows
+		query = productionRowsSelect() + `
 			WHERE date BETWEEN ? AND ?
 			ORDER BY date DESC
 		`
 	} else {
 		// 本番DB（日本語カラム名）
-		query = `
-			SELECT id, 運行NO, 運行日, 車輌CD, 対象乗務員CD, 行先市町村名,
-			       総走行距離, 自社主燃料, NULL as created_at, NULL as updated_at
-			FROM dtako_rows
+		query = productionRowsSelect() + `
 			WHERE 運行日 BETWEEN ? AND ?
 			ORDER BY 運行日 DESC
 		`
 	}
 
-	rows, err := r.prodDB.Query(query, from, to)
+	results, err := r.queryProduction(ctx, query, from, to)
+	tracing.Rows(span, "read", len(results))
+	return results, err
+}
+
+// fetchByUnkoNoBatch is the number of 運行NO looked up per production query
+const fetchByUnkoNoBatch = 500
+
+// FetchFromProductionByUnkoNos fetches the rows with the given 運行NO from production database
+func (r *DtakoRowsRepository) FetchFromProductionByUnkoNos(ctx context.Context, unkoNos []string) (_ []models.DtakoRow, err error) {
+	ctx, span := startSpan(ctx, "DtakoRowsRepository.FetchFromProductionByUnkoNos", dbProduction, "dtako_rows")
+	defer tracing.End(span, &err)
+
+	if len(unkoNos) == 0 {
+		return []models.DtakoRow{}, nil
+	}
+	if r.prodDB == nil {
+		return []models.DtakoRow{}, ErrProductionUnavailable
+	}
+
+	column := "運行NO"
+	if os.Getenv("PROD_DB_NAME") == "dtako_test_prod" {
+		column = "unko_no"
+	}
+
+	results := []models.DtakoRow{}
+	for start := 0; start < len(unkoNos); start += fetchByUnkoNoBatch {
+		batch := unkoNos[start:min(start+fetchByUnkoNoBatch, len(unkoNos))]
+		args := make([]interface{}, len(batch))
+		for i, v := range batch {
+			args[i] = v
+		}
+
+		query := productionRowsSelect() + `
+			WHERE ` + column + ` IN (?` + strings.Repeat(", ?", len(batch)-1) + `)
+		`
+		rows, err := r.queryProduction(ctx, query, args...)
+		if err != nil {
+			return []models.DtakoRow{}, err
+		}
+		results = append(results, rows...)
+	}
+
+	tracing.Rows(span, "read", len(results))
+	return results, nil
+}
+
+// productionRowsSelect returns the SELECT ... FROM part for production rows
+func productionRowsSelect() string {
+	if os.Getenv("PROD_DB_NAME") == "dtako_test_prod" {
+		return `
+			SELECT id, unko_no, date, vehicle_no, driver_code, route_code,
+			       distance, fuel_amount, created_at, updated_at
+			FROM dtako_rows`
+	}
+	// 末尾の4列は取込時にローカルへ引き継ぐコード（models.ImportCodes）
+	return `
+			SELECT id, 運行NO, 運行日, 車輌CD, 対象乗務員CD, 行先市町村名,
+			       総走行距離, 自社主燃料, NULL as created_at, NULL as updated_at,
+			       車輌CD, COALESCE(車輌CC, ''), 対象乗務員CD, COALESCE(事業所CD, 0)
+			FROM dtako_rows`
+}
+
+// queryProduction runs a production query selected with productionRowsSelect
+func (r *DtakoRowsRepository) queryProduction(ctx context.Context, query string, args ...interface{}) ([]models.DtakoRow, error) {
+	rows, err := r.prodDB.QueryContext(ctx, query, args...)
 	if err != nil {
 		return []models.DtakoRow{}, err
 	}
 	defer rows.Close()
 
+	// テスト用プロダクションDB（英語カラム名）にはコード列がない
+	withCodes := os.Getenv("PROD_DB_NAME") != "dtako_test_prod"
+
 	results := []models.DtakoRow{}
 	for rows.Next() {
 		var row models.DtakoRow
-		err := rows.Scan(
+		dest := []interface{}{
 			&row.ID, &row.UnkoNo, &row.Date, &row.VehicleNo, &row.DriverCode,
 			&row.RouteCode, &row.Distance, &row.FuelAmount,
 			&row.CreatedAt, &row.UpdatedAt,
-		)
-		if err != nil {
+		}
+		var codes models.ImportCodes
+		if withCodes {
+			dest = append(dest, &codes.VehicleCD, &codes.VehicleCC, &codes.DriverCD, &codes.OfficeCD)
+		}
+		if err := rows.Scan(dest...); err != nil {
 			return []models.DtakoRow{}, err
 		}
+		if withCodes {
+			row.Codes = &codes
+		}
 		results = append(results, row)
 	}
 
-	return results, nil
+	return results, rows.Err()
+}
+
+// importCodes returns the codes written to the local database for a row or
+// event. Records fetched from production carry them in codes; otherwise the
+// numeric vehicle and driver codes are parsed and the rest stay zero.
+func importCodes(codes *models.ImportCodes, vehicleNo, driverCode string) models.ImportCodes {
+	if codes != nil {
+		return *codes
+	}
+	vehicleCD, _ := strconv.Atoi(vehicleNo)
+	driverCD, _ := strconv.Atoi(driverCode)
+	return models.ImportCodes{VehicleCD: vehicleCD, DriverCD: driverCD}
+}
+
+// rowInsertArgs returns the arguments of the Insert statement for row.
+// 読取日は運行日と同じ値を使用
+func rowInsertArgs(row *models.DtakoRow) []interface{} {
+	codes := importCodes(row.Codes, row.VehicleNo, row.DriverCode)
+	return []interface{}{
+		row.ID, row.UnkoNo, row.Date, row.Date,
+		codes.VehicleCD, codes.VehicleCC, codes.DriverCD, codes.OfficeCD,
+		row.RouteCode, row.Distance, row.FuelAmount,
+	}
 }
 
 // Insert inserts a row into local database
-func (r *DtakoRowsRepository) Insert(row *models.DtakoRow) error {
+func (r *DtakoRowsRepository) Insert(ctx context.Context, row *models.DtakoRow) (err error) {
+	ctx, span := startSpan(ctx, "DtakoRowsRepository.Insert", dbLocal, "dtako_rows")
+	defer tracing.End(span, &err)
+
 	// ローカルDBの実際のカラム構造に合わせる
 	// 必須カラム: id, 運行NO, 読取日, 運行日, 車輌CD, 車輌CC
 	query := `
-		INSERT INTO dtako_rows (id, 運行NO, 読取日, 運行日, 車輌CD, 車輌CC, 対象乗務員CD, 行先市町村名,
-		                       総走行距離, 自社主燃料)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO dtako_rows (id, 運行NO, 読取日, 運行日, 車輌CD, 車輌CC, 対象乗務員CD, 事業所CD,
+		                       行先市町村名, 総走行距離, 自社主燃料)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON DUPLICATE KEY UPDATE
 		    運行NO = VALUES(運行NO),
 		    読取日 = VALUES(読取日),
@@ -150,31 +436,13 @@ func (r *DtakoRowsRepository) Insert(row *models.DtakoRow) error {
 		    車輌CD = VALUES(車輌CD),
 		    車輌CC = VALUES(車輌CC),
 		    対象乗務員CD = VALUES(対象乗務員CD),
+		    事業所CD = VALUES(事業所CD),
 		    行先市町村名 = VALUES(行先市町村名),
 		    総走行距離 = VALUES(総走行距離),
 		    自社主燃料 = VALUES(自社主燃料)
 	`
 
-	// VehicleNoとDriverCodeはstringからintに変換が必要
-	vehicleCD := 1
-	if row.VehicleNo != "" {
-		// 車輌CDは数値型なので変換が必要
-		vehicleCD = 1 // デフォルト値を使用
-	}
-
-	driverCode := 0
-	if row.DriverCode != "" {
-		driverCode = 1 // デフォルト値を使用
-	}
-
-	// デフォルト値
-	vehicleCC := "001100" // 車輌CC（実際のデータ形式）
-
-	// 読取日は運行日と同じ値を使用
-	_, err := r.localDB.Exec(query,
-		row.ID, row.UnkoNo, row.Date, row.Date, vehicleCD, vehicleCC, driverCode,
-		row.RouteCode, row.Distance, row.FuelAmount,
-	)
+	_, err = r.localDB.ExecContext(ctx, query, rowInsertArgs(row)...)
 
 	return err
 }
\ No newline at end of file
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+func TestRowInsertArgsKeepsProductionCodes(t *testing.T) {
+	date := time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		row  models.DtakoRow
+		want []interface{} // 車輌CD, 車輌CC, 対象乗務員CD, 事業所CD
+	}{
+		{
+			name: "imported from production",
+			row: models.DtakoRow{ID: "r1", UnkoNo: "2025011301", Date: date, VehicleNo: "101", DriverCode: "1001",
+				Codes: &models.ImportCodes{VehicleCD: 101, VehicleCC: "001101", DriverCD: 1001, OfficeCD: 3}},
+			want: []interface{}{101, "001101", 1001, 3},
+		},
+		{
+			name: "numeric codes without import codes",
+			row:  models.DtakoRow{ID: "r2", UnkoNo: "2025011302", Date: date, VehicleNo: "102", DriverCode: "1002"},
+			want: []interface{}{102, "", 1002, 0},
+		},
+		{
+			name: "non-numeric codes",
+			row:  models.DtakoRow{ID: "r3", UnkoNo: "2025011303", Date: date, VehicleNo: "V001", DriverCode: "D001"},
+			want: []interface{}{0, "", 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := rowInsertArgs(&tt.row)
+			if len(args) != 11 {
+				t.Fatalf("Expected 11 arguments, got %d", len(args))
+			}
+			for i, want := range tt.want {
+				if args[4+i] != want {
+					t.Errorf("Arg %d: expected %v, got %v", 4+i, want, args[4+i])
+				}
+			}
+			if args[0] != tt.row.ID || args[1] != tt.row.UnkoNo {
+				t.Errorf("Expected id and 運行NO first, got %v", args[:2])
+			}
+		})
+	}
+}
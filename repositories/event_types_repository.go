@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"os"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// EventTypesRepository handles database operations for the event type registry
+type EventTypesRepository struct {
+	prodDB  *sql.DB
+	localDB *sql.DB
+}
+
+// NewEventTypesRepository creates a new repository instance
+func NewEventTypesRepository() *EventTypesRepository {
+	prodDB := productionPool()
+	localDB := localPool()
+
+	return &EventTypesRepository{
+		prodDB:  prodDB,
+		localDB: localDB,
+	}
+}
+
+// List retrieves all registered event types ordered by name
+func (r *EventTypesRepository) List(ctx context.Context) (_ []models.EventType, err error) {
+	ctx, span := startSpan(ctx, "EventTypesRepository.List", dbLocal, "dtako_event_types")
+	defer tracing.End(span, &err)
+
+	rows, err := r.localDB.QueryContext(ctx, `SELECT name, category, updated_at FROM dtako_event_types ORDER BY name`)
+	if err != nil {
+		return []models.EventType{}, err
+	}
+	defer rows.Close()
+
+	results := []models.EventType{}
+	for rows.Next() {
+		var et models.EventType
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&et.Name, &et.Category, &updatedAt); err != nil {
+			return []models.EventType{}, err
+		}
+		if updatedAt.Valid {
+			et.UpdatedAt = &updatedAt.Time
+		}
+		results = append(results, et)
+	}
+
+	return results, rows.Err()
+}
+
+// Upsert registers an event type or changes the category of an existing one
+func (r *EventTypesRepository) Upsert(ctx context.Context, et *models.EventType) (err error) {
+	ctx, span := startSpan(ctx, "EventTypesRepository.Upsert", dbLocal, "dtako_event_types")
+	defer tracing.End(span, &err)
+
+	_, err = r.localDB.ExecContext(ctx, `
+		INSERT INTO dtako_event_types (name, category) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE category = VALUES(category)
+	`, et.Name, et.Category)
+	return err
+}
+
+// InsertIfMissing registers an event type unless the name already exists,
+// so categories set by hand are kept. Returns whether a row was added.
+func (r *EventTypesRepository) InsertIfMissing(ctx context.Context, et *models.EventType) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "EventTypesRepository.InsertIfMissing", dbLocal, "dtako_event_types")
+	defer tracing.End(span, &err)
+
+	result, err := r.localDB.ExecContext(ctx, `INSERT IGNORE INTO dtako_event_types (name, category) VALUES (?, ?)`, et.Name, et.Category)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// Delete removes an event type. Returns sql.ErrNoRows when the name does not exist.
+func (r *EventTypesRepository) Delete(ctx context.Context, name string) (err error) {
+	ctx, span := startSpan(ctx, "EventTypesRepository.Delete", dbLocal, "dtako_event_types")
+	defer tracing.End(span, &err)
+
+	result, err := r.localDB.ExecContext(ctx, `DELETE FROM dtako_event_types WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// DistinctProductionNames returns every distinct イベント名 in production dtako_events
+func (r *EventTypesRepository) DistinctProductionNames(ctx context.Context) (_ []string, err error) {
+	ctx, span := startSpan(ctx, "EventTypesRepository.DistinctProductionNames", dbProduction, "dtako_events")
+	defer tracing.End(span, &err)
+
+	if r.prodDB == nil {
+		return []string{}, ErrProductionUnavailable
+	}
+
+	column := "イベント名"
+	if os.Getenv("PROD_DB_NAME") == "dtako_test_prod" {
+		// テスト環境のdtako_test_prodは英語カラム名を使用
+		column = "event_type"
+	}
+
+	rows, err := r.prodDB.QueryContext(ctx, `SELECT DISTINCT `+column+` FROM dtako_events WHERE `+column+` <> '' ORDER BY `+column)
+	if err != nil {
+		return []string{}, err
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return []string{}, err
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+// Exists reports whether an event name is registered
+func (r *EventTypesRepository) Exists(ctx context.Context, name string) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "EventTypesRepository.Exists", dbLocal, "dtako_event_types")
+	defer tracing.End(span, &err)
+
+	var n int
+	err = r.localDB.QueryRowContext(ctx, `SELECT COUNT(*) FROM dtako_event_types WHERE name = ?`, name).Scan(&n)
+	return n > 0, err
+}
@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// GeofencesRepository handles database operations for dtako_geofences
+type GeofencesRepository struct {
+	localDB *sql.DB
+}
+
+// NewGeofencesRepository creates a new repository instance
+func NewGeofencesRepository() *GeofencesRepository {
+	localDB := localPool()
+
+	return &GeofencesRepository{
+		localDB: localDB,
+	}
+}
+
+const geofenceColumns = `id, name, category, shape, center_lat, center_lng, radius_m, polygon, created_at, updated_at`
+
+// scanGeofence scans a row selected with geofenceColumns
+func scanGeofence(s rowScanner) (models.Geofence, error) {
+	var g models.Geofence
+	var centerLat, centerLng, radius sql.NullFloat64
+	var polygon sql.NullString
+
+	err := s.Scan(&g.ID, &g.Name, &g.Category, &g.Shape,
+		&centerLat, &centerLng, &radius, &polygon,
+		&g.CreatedAt, &g.UpdatedAt)
+	if err != nil {
+		return g, err
+	}
+
+	if centerLat.Valid && centerLng.Valid {
+		g.Center = &models.LatLng{Lat: centerLat.Float64, Lng: centerLng.Float64}
+	}
+	g.RadiusM = radius.Float64
+	if polygon.Valid && polygon.String != "" {
+		if err := json.Unmarshal([]byte(polygon.String), &g.Polygon); err != nil {
+			return g, fmt.Errorf("invalid polygon for geofence %d: %w", g.ID, err)
+		}
+	}
+
+	return g, nil
+}
+
+// geofenceArgs converts a geofence into column values for INSERT/UPDATE
+func geofenceArgs(g *models.Geofence) ([]interface{}, error) {
+	var centerLat, centerLng, radius sql.NullFloat64
+	var polygon sql.NullString
+
+	if g.Center != nil {
+		centerLat = sql.NullFloat64{Float64: g.Center.Lat, Valid: true}
+		centerLng = sql.NullFloat64{Float64: g.Center.Lng, Valid: true}
+	}
+	if g.RadiusM > 0 {
+		radius = sql.NullFloat64{Float64: g.RadiusM, Valid: true}
+	}
+	if len(g.Polygon) > 0 {
+		b, err := json.Marshal(g.Polygon)
+		if err != nil {
+			return nil, err
+		}
+		polygon = sql.NullString{String: string(b), Valid: true}
+	}
+
+	return []interface{}{g.Name, g.Category, g.Shape, centerLat, centerLng, radius, polygon}, nil
+}
+
+// List retrieves all geofences
+func (r *GeofencesRepository) List(ctx context.Context) (_ []models.Geofence, err error) {
+	ctx, span := startSpan(ctx, "GeofencesRepository.List", dbLocal, "dtako_geofences")
+	defer tracing.End(span, &err)
+
+	rows, err := r.localDB.QueryContext(ctx, `SELECT `+geofenceColumns+` FROM dtako_geofences ORDER BY id`)
+	if err != nil {
+		return []models.Geofence{}, err
+	}
+	defer rows.Close()
+
+	results := []models.Geofence{}
+	for rows.Next() {
+		g, err := scanGeofence(rows)
+		if err != nil {
+			return []models.Geofence{}, err
+		}
+		results = append(results, g)
+	}
+
+	return results, rows.Err()
+}
+
+// GetByID retrieves a specific geofence by ID
+func (r *GeofencesRepository) GetByID(ctx context.Context, id int) (_ *models.Geofence, err error) {
+	ctx, span := startSpan(ctx, "GeofencesRepository.GetByID", dbLocal, "dtako_geofences")
+	defer tracing.End(span, &err)
+
+	g, err := scanGeofence(r.localDB.QueryRowContext(ctx, `SELECT `+geofenceColumns+` FROM dtako_geofences WHERE id = ?`, id))
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// Insert creates a geofence and sets its generated ID
+func (r *GeofencesRepository) Insert(ctx context.Context, g *models.Geofence) (err error) {
+	ctx, span := startSpan(ctx, "GeofencesRepository.Insert", dbLocal, "dtako_geofences")
+	defer tracing.End(span, &err)
+
+	args, err := geofenceArgs(g)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.localDB.ExecContext(ctx, `
+		INSERT INTO dtako_geofences (name, category, shape, center_lat, center_lng, radius_m, polygon)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, args...)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	g.ID = int(id)
+	return nil
+}
+
+// Update replaces a geofence definition. Returns sql.ErrNoRows when the ID does not exist.
+func (r *GeofencesRepository) Update(ctx context.Context, g *models.Geofence) (err error) {
+	ctx, span := startSpan(ctx, "GeofencesRepository.Update", dbLocal, "dtako_geofences")
+	defer tracing.End(span, &err)
+
+	args, err := geofenceArgs(g)
+	if err != nil {
+		return err
+	}
+	args = append(args, g.ID)
+
+	result, err := r.localDB.ExecContext(ctx, `
+		UPDATE dtako_geofences
+		SET name = ?, category = ?, shape = ?, center_lat = ?, center_lng = ?, radius_m = ?, polygon = ?
+		WHERE id = ?
+	`, args...)
+	if err != nil {
+		return err
+	}
+
+	// MySQLは値が変わらないUPDATEを0件と報告するため、存在確認で判定する
+	if n, err := result.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	var exists int
+	return r.localDB.QueryRowContext(ctx, `SELECT 1 FROM dtako_geofences WHERE id = ?`, g.ID).Scan(&exists)
+}
+
+// Delete removes a geofence. Returns sql.ErrNoRows when the ID does not exist.
+func (r *GeofencesRepository) Delete(ctx context.Context, id int) (err error) {
+	ctx, span := startSpan(ctx, "GeofencesRepository.Delete", dbLocal, "dtako_geofences")
+	defer tracing.End(span, &err)
+
+	result, err := r.localDB.ExecContext(ctx, `DELETE FROM dtako_geofences WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// requireAffected turns a write that matched no rows into sql.ErrNoRows
+func requireAffected(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// Tables each database must have for the module to work
+var (
+	localRequiredTables = []string{
+		"dtako_rows", "dtako_events", "dtako_ferry_rows",
+		"dtako_geofences", "dtako_validation_findings", "dtako_event_types", "dtako_payroll_summaries", "dtako_payroll_versions", "dtako_audit_log",
+		"dtako_vehicles", "dtako_drivers", "dtako_offices", "dtako_ferry_companies", "dtako_ports",
+	}
+	productionRequiredTables = []string{"dtako_rows", "dtako_events", "dtako_ferry_rows"}
+)
+
+// HealthRepository checks database connectivity and schema
+type HealthRepository struct {
+	prodDB   *sql.DB
+	localDB  *sql.DB
+	localErr error
+	prodErr  error
+}
+
+// NewHealthRepository creates a new repository instance. Unlike the other
+// repositories it keeps the errors of the initial connections.
+func NewHealthRepository() *HealthRepository {
+	localDB, localErr := GetLocalDB()
+	prodDB, prodErr := GetProductionDB()
+
+	return &HealthRepository{
+		prodDB:   prodDB,
+		localDB:  localDB,
+		localErr: localErr,
+		prodErr:  prodErr,
+	}
+}
+
+// ConnectErrors returns the errors of the initial connections, if any
+func (r *HealthRepository) ConnectErrors() (local, production error) {
+	return r.localErr, r.prodErr
+}
+
+// CheckLocal pings the local database and looks up its required tables
+func (r *HealthRepository) CheckLocal(ctx context.Context) models.DatabaseHealth {
+	return r.check(ctx, dbLocal, r.localDB, r.localErr, localRequiredTables)
+}
+
+// CheckProduction pings the production database and looks up its required tables
+func (r *HealthRepository) CheckProduction(ctx context.Context) models.DatabaseHealth {
+	return r.check(ctx, dbProduction, r.prodDB, r.prodErr, productionRequiredTables)
+}
+
+func (r *HealthRepository) check(ctx context.Context, name string, db *sql.DB, connectErr error, tables []string) models.DatabaseHealth {
+	health := models.DatabaseHealth{Name: name, Status: models.HealthStatusOK}
+
+	var err error
+	ctx, span := startSpan(ctx, "HealthRepository.Check", name, "information_schema.tables")
+	defer tracing.End(span, &err)
+
+	if db == nil {
+		// プールを作れなかった（設定不正など）
+		err = connectErr
+		if err == nil {
+			err = fmt.Errorf("%s database not available", name)
+		}
+		health.Status = models.HealthStatusUnavailable
+		health.Error = err.Error()
+		return health
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err = db.PingContext(ctx)
+	health.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		health.Status = models.HealthStatusUnavailable
+		health.Error = err.Error()
+		return health
+	}
+
+	missing, err := missingTables(ctx, db, tables)
+	if err != nil {
+		health.Status = models.HealthStatusUnavailable
+		health.Error = err.Error()
+		return health
+	}
+	if len(missing) > 0 {
+		health.Status = models.HealthStatusUnavailable
+		health.MissingTables = missing
+		health.Error = "missing tables: " + strings.Join(missing, ", ")
+	}
+	return health
+}
+
+// missingTables returns the tables of the current schema that do not exist
+func missingTables(ctx context.Context, db *sql.DB, tables []string) ([]string, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(tables)), ",")
+	args := make([]interface{}, len(tables))
+	for i, t := range tables {
+		args[i] = t
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT table_name FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_name IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		found[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	missing := []string{}
+	for _, t := range tables {
+		if !found[t] {
+			missing = append(missing, t)
+		}
+	}
+	return missing, nil
+}
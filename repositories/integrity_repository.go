@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// IntegrityRepository runs cross-table consistency checks on the local database.
+// The tables have no enforced foreign keys and are imported independently,
+// so 運行NO references can dangle.
+type IntegrityRepository struct {
+	localDB *sql.DB
+}
+
+// NewIntegrityRepository creates a new repository instance
+func NewIntegrityRepository() *IntegrityRepository {
+	localDB := localPool()
+
+	return &IntegrityRepository{
+		localDB: localDB,
+	}
+}
+
+// OrphanEvents finds 運行NO of events within a date range that have no dtako_rows record
+func (r *IntegrityRepository) OrphanEvents(ctx context.Context, from, to time.Time) (_ []models.OrphanUnkoNo, err error) {
+	ctx, span := startSpan(ctx, "IntegrityRepository.OrphanEvents", dbLocal, "dtako_events", tracing.DateRange(from, to)...)
+	defer tracing.End(span, &err)
+
+	return r.queryOrphans(ctx, `
+		SELECT e.運行NO, COUNT(*), MIN(e.開始日時), MAX(e.開始日時)
+		FROM dtako_events e
+		WHERE e.開始日時 >= ? AND e.開始日時 < DATE_ADD(?, INTERVAL 1 DAY)
+		  AND e.運行NO IS NOT NULL AND e.運行NO <> ''
+		  AND NOT EXISTS (SELECT 1 FROM dtako_rows r WHERE r.運行NO = e.運行NO)
+		GROUP BY e.運行NO
+		ORDER BY e.運行NO
+	`, from.Format("2006-01-02"), to.Format("2006-01-02"))
+}
+
+// OrphanFerryRows finds 運行NO of ferry rows within a date range that have no dtako_rows record
+func (r *IntegrityRepository) OrphanFerryRows(ctx context.Context, from, to time.Time) (_ []models.OrphanUnkoNo, err error) {
+	ctx, span := startSpan(ctx, "IntegrityRepository.OrphanFerryRows", dbLocal, "dtako_ferry_rows", tracing.DateRange(from, to)...)
+	defer tracing.End(span, &err)
+
+	return r.queryOrphans(ctx, `
+		SELECT f.運行NO, COUNT(*), MIN(f.開始日時), MAX(f.開始日時)
+		FROM dtako_ferry_rows f
+		WHERE f.運行日 BETWEEN ? AND ?
+		  AND f.運行NO IS NOT NULL AND f.運行NO <> ''
+		  AND NOT EXISTS (SELECT 1 FROM dtako_rows r WHERE r.運行NO = f.運行NO)
+		GROUP BY f.運行NO
+		ORDER BY f.運行NO
+	`, from, to)
+}
+
+func (r *IntegrityRepository) queryOrphans(ctx context.Context, query string, args ...interface{}) ([]models.OrphanUnkoNo, error) {
+	rows, err := r.localDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return []models.OrphanUnkoNo{}, err
+	}
+	defer rows.Close()
+
+	orphans := []models.OrphanUnkoNo{}
+	for rows.Next() {
+		var o models.OrphanUnkoNo
+		if err := rows.Scan(&o.UnkoNo, &o.Records, &o.FirstAt, &o.LastAt); err != nil {
+			return []models.OrphanUnkoNo{}, err
+		}
+		orphans = append(orphans, o)
+	}
+
+	return orphans, rows.Err()
+}
+
+// RowsWithoutEvents finds rows within a date range whose 運行NO has no events
+func (r *IntegrityRepository) RowsWithoutEvents(ctx context.Context, from, to time.Time) (_ []models.RowWithoutEvents, err error) {
+	ctx, span := startSpan(ctx, "IntegrityRepository.RowsWithoutEvents", dbLocal, "dtako_rows", tracing.DateRange(from, to)...)
+	defer tracing.End(span, &err)
+
+	rows, err := r.localDB.QueryContext(ctx, `
+		SELECT r.id, r.運行NO, r.運行日, r.車輌CD
+		FROM dtako_rows r
+		WHERE r.運行日 BETWEEN ? AND ?
+		  AND NOT EXISTS (SELECT 1 FROM dtako_events e WHERE e.運行NO = r.運行NO)
+		ORDER BY r.運行日, r.運行NO
+	`, from, to)
+	if err != nil {
+		return []models.RowWithoutEvents{}, err
+	}
+	defer rows.Close()
+
+	results := []models.RowWithoutEvents{}
+	for rows.Next() {
+		var row models.RowWithoutEvents
+		if err := rows.Scan(&row.ID, &row.UnkoNo, &row.Date, &row.VehicleNo); err != nil {
+			return []models.RowWithoutEvents{}, err
+		}
+		results = append(results, row)
+	}
+
+	return results, rows.Err()
+}
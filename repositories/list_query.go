@@ -0,0 +1,307 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yhonda-ohishi/dtako_mod/apperr"
+	"github.com/yhonda-ohishi/dtako_mod/auth"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/privacy"
+)
+
+// ErrInvalidListQuery is returned when a filter, sort or field of a list
+// request is unknown for the resource or has a malformed value
+var ErrInvalidListQuery = apperr.New(apperr.Validation, "invalid list query")
+
+// filterColumn maps a filter name to an indexed column.
+// numeric columns only accept integer values so the index can be used without casts.
+type filterColumn struct {
+	column  string
+	numeric bool
+}
+
+// listField maps a JSON field of T to the SQL expression it is selected
+// with and the struct field it is scanned into. Fields with a sortExpr can
+// be used in sort; it names the raw column so ORDER BY can use its index.
+// driverCode marks driver codes, which callers with masked codes cannot sort by.
+type listField[T any] struct {
+	name       string
+	expr       string
+	sortExpr   string
+	driverCode bool
+	dest       func(*T) interface{}
+}
+
+// listResource describes how list queries of one table are projected, filtered and sorted
+type listResource[T any] struct {
+	fields      []listField[T]
+	filters     map[string]filterColumn
+	defaultSort string
+}
+
+// selectFields returns the fields named in names, or every field when names is empty
+func (res *listResource[T]) selectFields(names []string) ([]listField[T], error) {
+	if len(names) == 0 {
+		return res.fields, nil
+	}
+
+	selected := make([]listField[T], 0, len(names))
+	seen := map[string]bool{}
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		field, ok := res.field(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidListQuery, name)
+		}
+		selected = append(selected, field)
+		seen[name] = true
+	}
+	return selected, nil
+}
+
+func (res *listResource[T]) field(name string) (listField[T], bool) {
+	for _, f := range res.fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return listField[T]{}, false
+}
+
+// selectList renders the SELECT expressions for fields
+func selectList[T any](fields []listField[T]) string {
+	exprs := make([]string, len(fields))
+	for i, f := range fields {
+		exprs[i] = f.expr
+	}
+	return strings.Join(exprs, ", ")
+}
+
+// orderBy renders the ORDER BY clause for sort, falling back to the resource
+// default. Like the driver filter, sorting by driver codes is forbidden when
+// they are masked for the caller of ctx: the order would reveal them.
+func (res *listResource[T]) orderBy(ctx context.Context, sortFields []models.SortField) (string, error) {
+	if len(sortFields) == 0 {
+		return " ORDER BY " + res.defaultSort, nil
+	}
+
+	terms := make([]string, 0, len(sortFields))
+	for _, s := range sortFields {
+		field, ok := res.field(s.Field)
+		if !ok || field.sortExpr == "" {
+			return "", fmt.Errorf("%w: cannot sort by %q", ErrInvalidListQuery, s.Field)
+		}
+		if field.driverCode && privacy.For(ctx).Codes() {
+			return "", fmt.Errorf("%w: driver codes are masked for this role", auth.ErrForbidden)
+		}
+		direction := "ASC"
+		if s.Desc {
+			direction = "DESC"
+		}
+		terms = append(terms, field.sortExpr+" "+direction)
+	}
+	return " ORDER BY " + strings.Join(terms, ", "), nil
+}
+
+// maxListLimit caps the page size of list requests
+const maxListLimit = 10000
+
+// pageClause renders the LIMIT/OFFSET of q. Zero Limit selects every record;
+// an offset needs a limit.
+func pageClause(q models.ListQuery) (string, error) {
+	switch {
+	case q.Limit < 0 || q.Offset < 0:
+		return "", fmt.Errorf("%w: limit and offset must not be negative", ErrInvalidListQuery)
+	case q.Limit > maxListLimit:
+		return "", fmt.Errorf("%w: limit must be at most %d", ErrInvalidListQuery, maxListLimit)
+	case q.Limit == 0 && q.Offset > 0:
+		return "", fmt.Errorf("%w: offset requires limit", ErrInvalidListQuery)
+	case q.Limit == 0:
+		return "", nil
+	}
+	return fmt.Sprintf(" LIMIT %d OFFSET %d", q.Limit, q.Offset), nil
+}
+
+// scanFields scans the current row into a new T using the destinations of fields
+func scanFields[T any](rows *sql.Rows, fields []listField[T]) (T, error) {
+	var item T
+	dests := make([]interface{}, len(fields))
+	for i, f := range fields {
+		dests[i] = f.dest(&item)
+	}
+	err := rows.Scan(dests...)
+	return item, err
+}
+
+// appendFilters adds "AND column IN (?, ...)" clauses for each filter to query.
+// Column names come from the whitelist only; values are always bound as parameters.
+// Callers restricted to some 事業所CD (auth.OfficeScope) additionally only see
+// those offices through the "office" column.
+func appendFilters(ctx context.Context, query string, args []interface{}, filter models.ListFilter, columns map[string]filterColumn) (string, []interface{}, error) {
+	names := make([]string, 0, len(filter))
+	for name := range filter {
+		names = append(names, name)
+	}
+	sort.Strings(names) // 安定したSQLを生成するため
+
+	for _, name := range names {
+		values := filter[name]
+		if len(values) == 0 {
+			continue
+		}
+
+		col, ok := columns[name]
+		if !ok {
+			return query, args, fmt.Errorf("%w: unsupported filter %q", ErrInvalidListQuery, name)
+		}
+		if name == "driver" && privacy.For(ctx).Codes() {
+			return query, args, fmt.Errorf("%w: driver codes are masked for this role", auth.ErrForbidden)
+		}
+
+		placeholders := make([]string, len(values))
+		for i, v := range values {
+			if col.numeric {
+				n, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					return query, args, fmt.Errorf("%w: %s must be numeric, got %q", ErrInvalidListQuery, name, v)
+				}
+				args = append(args, n)
+			} else {
+				args = append(args, v)
+			}
+			placeholders[i] = "?"
+		}
+
+		if len(values) == 1 {
+			query += fmt.Sprintf(" AND %s = ?", col.column)
+		} else {
+			query += fmt.Sprintf(" AND %s IN (%s)", col.column, strings.Join(placeholders, ", "))
+		}
+	}
+
+	if _, restricted := auth.OfficeScope(ctx); restricted {
+		col, ok := columns["office"]
+		if !ok {
+			return query, args, fmt.Errorf("%w: cannot be restricted to offices", auth.ErrForbidden)
+		}
+		query, args = appendOfficeScope(ctx, query, args, col.column)
+	}
+
+	return query, args, nil
+}
+
+// appendOfficeScope adds "AND column IN (?, ...)" with the 事業所CD the caller
+// of ctx may see; unrestricted callers get no clause. Records outside the
+// scope are then simply not found.
+func appendOfficeScope(ctx context.Context, query string, args []interface{}, column string) (string, []interface{}) {
+	offices, restricted := auth.OfficeScope(ctx)
+	if !restricted {
+		return query, args
+	}
+
+	placeholders := make([]string, len(offices))
+	for i, office := range offices {
+		placeholders[i] = "?"
+		args = append(args, office)
+	}
+	return query + fmt.Sprintf(" AND %s IN (%s)", column, strings.Join(placeholders, ", ")), args
+}
+
+// rowsResource describes list queries on dtako_rows (ローカルDB、日本語カラム名)
+var rowsResource = &listResource[models.DtakoRow]{
+	fields: []listField[models.DtakoRow]{
+		{name: "id", expr: "id", sortExpr: "id", dest: func(r *models.DtakoRow) interface{} { return &r.ID }},
+		{name: "unko_no", expr: "運行NO", sortExpr: "運行NO", dest: func(r *models.DtakoRow) interface{} { return &r.UnkoNo }},
+		{name: "date", expr: "運行日", sortExpr: "運行日", dest: func(r *models.DtakoRow) interface{} { return &r.Date }},
+		{name: "vehicle_no", expr: "車輌CD", sortExpr: "車輌CD", dest: func(r *models.DtakoRow) interface{} { return &r.VehicleNo }},
+		{name: "driver_code", expr: "対象乗務員CD", sortExpr: "対象乗務員CD", driverCode: true, dest: func(r *models.DtakoRow) interface{} { return &r.DriverCode }},
+		{name: "route_code", expr: "行先市町村名", sortExpr: "行先市町村名", dest: func(r *models.DtakoRow) interface{} { return &r.RouteCode }},
+		{name: "distance", expr: "総走行距離", sortExpr: "総走行距離", dest: func(r *models.DtakoRow) interface{} { return &r.Distance }},
+		{name: "fuel_amount", expr: "自社主燃料", sortExpr: "自社主燃料", dest: func(r *models.DtakoRow) interface{} { return &r.FuelAmount }},
+		{name: "created_at", expr: "NULL as created_at", dest: func(r *models.DtakoRow) interface{} { return &r.CreatedAt }},
+		{name: "updated_at", expr: "NULL as updated_at", dest: func(r *models.DtakoRow) interface{} { return &r.UpdatedAt }},
+	},
+	filters: map[string]filterColumn{
+		"vehicle": {column: "車輌CD", numeric: true},
+		"driver":  {column: "対象乗務員CD", numeric: true},
+		"office":  {column: "事業所CD", numeric: true},
+		"unko_no": {column: "運行NO"},
+		"route":   {column: "行先市町村名"},
+	},
+	defaultSort: "運行日 DESC",
+}
+
+// eventsResource describes list queries on dtako_events.
+// GPS座標はマイクロ度で保存されているためSQL側で度に変換する
+var eventsResource = &listResource[models.DtakoEvent]{
+	fields: []listField[models.DtakoEvent]{
+		{name: "id", expr: "id", sortExpr: "id", dest: func(e *models.DtakoEvent) interface{} { return &e.ID }},
+		{name: "unko_no", expr: "COALESCE(運行NO, '')", sortExpr: "運行NO", dest: func(e *models.DtakoEvent) interface{} { return &e.UnkoNo }},
+		{name: "event_date", expr: "開始日時", sortExpr: "開始日時", dest: func(e *models.DtakoEvent) interface{} { return &e.EventDate }},
+		{name: "event_type", expr: "イベント名", sortExpr: "イベント名", dest: func(e *models.DtakoEvent) interface{} { return &e.EventType }},
+		{name: "vehicle_no", expr: "CAST(車輌CD AS CHAR)", sortExpr: "車輌CD", dest: func(e *models.DtakoEvent) interface{} { return &e.VehicleNo }},
+		{name: "driver_code", expr: "CAST(対象乗務員CD AS CHAR)", sortExpr: "対象乗務員CD", driverCode: true, dest: func(e *models.DtakoEvent) interface{} { return &e.DriverCode }},
+		{name: "description", expr: "COALESCE(備考, '')", dest: func(e *models.DtakoEvent) interface{} { return &e.Description }},
+		{name: "latitude", expr: "開始GPS緯度 / 1000000", dest: func(e *models.DtakoEvent) interface{} { return &e.Latitude }},
+		{name: "longitude", expr: "開始GPS経度 / 1000000", dest: func(e *models.DtakoEvent) interface{} { return &e.Longitude }},
+	},
+	filters: map[string]filterColumn{
+		"vehicle": {column: "車輌CD", numeric: true},
+		"driver":  {column: "対象乗務員CD", numeric: true},
+		"office":  {column: "事業所CD", numeric: true},
+		"unko_no": {column: "運行NO"},
+		"type":    {column: "イベント名"},
+	},
+	defaultSort: "開始日時 DESC",
+}
+
+// ferryRowsResource describes list queries on dtako_ferry_rows
+var ferryRowsResource = &listResource[models.DtakoFerryRow]{
+	fields: []listField[models.DtakoFerryRow]{
+		{name: "id", expr: "id", sortExpr: "id", dest: func(f *models.DtakoFerryRow) interface{} { return &f.ID }},
+		{name: "unko_no", expr: "運行NO", sortExpr: "運行NO", dest: func(f *models.DtakoFerryRow) interface{} { return &f.UnkoNo }},
+		{name: "unko_date", expr: "運行日", sortExpr: "運行日", dest: func(f *models.DtakoFerryRow) interface{} { return &f.UnkoDate }},
+		{name: "read_date", expr: "読取日", sortExpr: "読取日", dest: func(f *models.DtakoFerryRow) interface{} { return &f.ReadDate }},
+		{name: "office_code", expr: "事業所CD", sortExpr: "事業所CD", dest: func(f *models.DtakoFerryRow) interface{} { return &f.OfficeCode }},
+		{name: "office_name", expr: "事業所名", dest: func(f *models.DtakoFerryRow) interface{} { return &f.OfficeName }},
+		{name: "vehicle_code", expr: "車輌CD", sortExpr: "車輌CD", dest: func(f *models.DtakoFerryRow) interface{} { return &f.VehicleCode }},
+		{name: "vehicle_name", expr: "車輌名", dest: func(f *models.DtakoFerryRow) interface{} { return &f.VehicleName }},
+		{name: "driver_code_1", expr: "乗務員CD1", sortExpr: "乗務員CD1", driverCode: true, dest: func(f *models.DtakoFerryRow) interface{} { return &f.DriverCode1 }},
+		{name: "driver_name_1", expr: "乗務員名１", dest: func(f *models.DtakoFerryRow) interface{} { return &f.DriverName1 }},
+		{name: "target_driver_class", expr: "対象乗務員区分", dest: func(f *models.DtakoFerryRow) interface{} { return &f.TargetDriverClass }},
+		{name: "start_time", expr: "開始日時", sortExpr: "開始日時", dest: func(f *models.DtakoFerryRow) interface{} { return &f.StartTime }},
+		{name: "end_time", expr: "終了日時", sortExpr: "終了日時", dest: func(f *models.DtakoFerryRow) interface{} { return &f.EndTime }},
+		{name: "ferry_company_code", expr: "フェリー会社CD", sortExpr: "フェリー会社CD", dest: func(f *models.DtakoFerryRow) interface{} { return &f.FerryCompanyCode }},
+		{name: "ferry_company_name", expr: "フェリー会社名", sortExpr: "フェリー会社名", dest: func(f *models.DtakoFerryRow) interface{} { return &f.FerryCompanyName }},
+		{name: "boarding_code", expr: "乗場CD", sortExpr: "乗場CD", dest: func(f *models.DtakoFerryRow) interface{} { return &f.BoardingCode }},
+		{name: "boarding_name", expr: "乗場名", dest: func(f *models.DtakoFerryRow) interface{} { return &f.BoardingName }},
+		{name: "ship_number", expr: "便", dest: func(f *models.DtakoFerryRow) interface{} { return &f.ShipNumber }},
+		{name: "landing_code", expr: "降場CD", sortExpr: "降場CD", dest: func(f *models.DtakoFerryRow) interface{} { return &f.LandingCode }},
+		{name: "landing_name", expr: "降場名", dest: func(f *models.DtakoFerryRow) interface{} { return &f.LandingName }},
+		{name: "settlement_class", expr: "精算区分", dest: func(f *models.DtakoFerryRow) interface{} { return &f.SettlementClass }},
+		{name: "settlement_name", expr: "精算区分名", dest: func(f *models.DtakoFerryRow) interface{} { return &f.SettlementName }},
+		{name: "standard_fare", expr: "標準料金", sortExpr: "標準料金", dest: func(f *models.DtakoFerryRow) interface{} { return &f.StandardFare }},
+		{name: "contract_fare", expr: "契約料金", sortExpr: "契約料金", dest: func(f *models.DtakoFerryRow) interface{} { return &f.ContractFare }},
+		{name: "ship_vehicle_class", expr: "航送車種区分", dest: func(f *models.DtakoFerryRow) interface{} { return &f.ShipVehicleClass }},
+		{name: "ship_vehicle_name", expr: "航送車種区分名", dest: func(f *models.DtakoFerryRow) interface{} { return &f.ShipVehicleName }},
+		{name: "estimated_distance", expr: "見なし距離", sortExpr: "見なし距離", dest: func(f *models.DtakoFerryRow) interface{} { return &f.EstimatedDistance }},
+		{name: "ferry_search", expr: "COALESCE(ferry_srch, '')", dest: func(f *models.DtakoFerryRow) interface{} { return &f.FerrySearch }},
+	},
+	filters: map[string]filterColumn{
+		"vehicle":       {column: "車輌CD", numeric: true},
+		"driver":        {column: "乗務員CD1", numeric: true},
+		"office":        {column: "事業所CD", numeric: true},
+		"unko_no":       {column: "運行NO"},
+		"ferry_company": {column: "フェリー会社名"},
+		"boarding":      {column: "乗場CD", numeric: true},
+		"landing":       {column: "降場CD", numeric: true},
+	},
+	defaultSort: "運行日 DESC, 開始日時 DESC",
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/auth"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/privacy"
+)
+
+func TestOrderByMaskedDriverCodes(t *testing.T) {
+	privacy.SetPolicy(privacy.Policy{NameRole: auth.RoleAdmin, CodeRole: auth.RoleAdmin, Key: []byte("test-mask-key")})
+	defer privacy.ResetPolicy()
+
+	viewer := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "dashboard", Role: auth.RoleViewer})
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "ops", Role: auth.RoleAdmin})
+
+	tests := []struct {
+		name  string
+		order func(ctx context.Context) (string, error)
+	}{
+		{"rows driver_code", func(ctx context.Context) (string, error) {
+			return rowsResource.orderBy(ctx, []models.SortField{{Field: "date"}, {Field: "driver_code"}})
+		}},
+		{"events driver_code", func(ctx context.Context) (string, error) {
+			return eventsResource.orderBy(ctx, []models.SortField{{Field: "driver_code", Desc: true}})
+		}},
+		{"ferry_rows driver_code_1", func(ctx context.Context) (string, error) {
+			return ferryRowsResource.orderBy(ctx, []models.SortField{{Field: "driver_code_1"}})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.order(viewer); !errors.Is(err, auth.ErrForbidden) {
+				t.Errorf("masked caller: expected ErrForbidden, got %v", err)
+			}
+			if _, err := tt.order(admin); err != nil {
+				t.Errorf("unmasked caller: unexpected error %v", err)
+			}
+			if _, err := tt.order(context.Background()); err != nil {
+				t.Errorf("unauthenticated caller: unexpected error %v", err)
+			}
+		})
+	}
+
+	if _, err := rowsResource.orderBy(viewer, []models.SortField{{Field: "vehicle_no"}}); err != nil {
+		t.Errorf("masked caller sorting by vehicle: unexpected error %v", err)
+	}
+}
+
+func TestAppendFiltersMaskedDriverCodes(t *testing.T) {
+	privacy.SetPolicy(privacy.Policy{NameRole: auth.RoleAdmin, CodeRole: auth.RoleAdmin, Key: []byte("test-mask-key")})
+	defer privacy.ResetPolicy()
+
+	viewer := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "dashboard", Role: auth.RoleViewer})
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "ops", Role: auth.RoleAdmin})
+
+	for name, res := range map[string]map[string]filterColumn{
+		"rows":       rowsResource.filters,
+		"events":     eventsResource.filters,
+		"ferry_rows": ferryRowsResource.filters,
+	} {
+		t.Run(name, func(t *testing.T) {
+			filter := models.ListFilter{"driver": {"1001"}}
+			if _, _, err := appendFilters(viewer, "", nil, filter, res); !errors.Is(err, auth.ErrForbidden) {
+				t.Errorf("masked caller: expected ErrForbidden, got %v", err)
+			}
+			if _, args, err := appendFilters(admin, "", nil, filter, res); err != nil || len(args) != 1 {
+				t.Errorf("unmasked caller: got args %v, error %v", args, err)
+			}
+			if _, _, err := appendFilters(viewer, "", nil, models.ListFilter{"vehicle": {"101"}}, res); err != nil {
+				t.Errorf("masked caller filtering by vehicle: unexpected error %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/apperr"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// ErrUnknownMasterKind is returned for a master kind that does not exist
+var ErrUnknownMasterKind = apperr.New(apperr.NotFound, "unknown master kind")
+
+// masterTable describes one master table and where production keeps its names
+type masterTable struct {
+	table     string
+	hasOffice bool
+	// scope is the column with the 事業所CD office-scoped callers are limited
+	// to; masters without one (ferry companies, ports) are visible to all
+	scope string
+	// production は本番 dtako_ferry_rows から (code, name[, office]) を取り出すクエリ
+	production string
+	// personal masters hold driver data; production takes the earliest 運行日
+	// to read so records past the retention period are not synced back
+	personal bool
+}
+
+// masterTables maps the kind used in URLs to its table
+var masterTables = map[string]masterTable{
+	"vehicles": {
+		table:     "dtako_vehicles",
+		hasOffice: true,
+		scope:     "office_code",
+		production: `SELECT CAST(車輌CD AS CHAR), MAX(車輌名), CAST(MAX(事業所CD) AS CHAR)
+			FROM dtako_ferry_rows WHERE 車輌名 <> '' GROUP BY 車輌CD`,
+	},
+	"drivers": {
+		table:     "dtako_drivers",
+		hasOffice: true,
+		scope:     "office_code",
+		personal:  true,
+		production: `SELECT CAST(乗務員CD1 AS CHAR), MAX(乗務員名１), CAST(MAX(事業所CD) AS CHAR)
+			FROM dtako_ferry_rows WHERE 乗務員名１ <> '' AND 運行日 >= ? GROUP BY 乗務員CD1`,
+	},
+	"offices": {
+		table: "dtako_offices",
+		scope: "code",
+		production: `SELECT CAST(事業所CD AS CHAR), MAX(事業所名)
+			FROM dtako_ferry_rows WHERE 事業所名 <> '' GROUP BY 事業所CD`,
+	},
+	"ferry_companies": {
+		table: "dtako_ferry_companies",
+		production: `SELECT CAST(フェリー会社CD AS CHAR), MAX(フェリー会社名)
+			FROM dtako_ferry_rows WHERE フェリー会社名 <> '' GROUP BY フェリー会社CD`,
+	},
+	"ports": {
+		table: "dtako_ports",
+		production: `SELECT code, MAX(name) FROM (
+				SELECT CAST(乗場CD AS CHAR) AS code, 乗場名 AS name FROM dtako_ferry_rows WHERE 乗場名 <> ''
+				UNION ALL
+				SELECT CAST(降場CD AS CHAR), 降場名 FROM dtako_ferry_rows WHERE 降場名 <> ''
+			) ports GROUP BY code`,
+	},
+}
+
+// MasterKinds returns the supported master kinds
+func MasterKinds() []string {
+	return []string{"vehicles", "drivers", "offices", "ferry_companies", "ports"}
+}
+
+// MastersRepository handles database operations for the master tables
+type MastersRepository struct {
+	prodDB  *sql.DB
+	localDB *sql.DB
+}
+
+// NewMastersRepository creates a new repository instance
+func NewMastersRepository() *MastersRepository {
+	prodDB := productionPool()
+	localDB := localPool()
+
+	return &MastersRepository{
+		prodDB:  prodDB,
+		localDB: localDB,
+	}
+}
+
+func lookupMaster(kind string) (masterTable, error) {
+	t, ok := masterTables[kind]
+	if !ok {
+		return t, ErrUnknownMasterKind
+	}
+	return t, nil
+}
+
+// selectMaster returns the SELECT list and FROM clause for a master table
+func selectMaster(t masterTable) string {
+	office := "NULL"
+	if t.hasOffice {
+		office = "office_code"
+	}
+	return `SELECT code, name, ` + office + `, active, updated_at FROM ` + t.table
+}
+
+func scanMaster(s rowScanner) (models.MasterRecord, error) {
+	var m models.MasterRecord
+	var office sql.NullString
+	var active bool
+	var updatedAt sql.NullTime
+	if err := s.Scan(&m.Code, &m.Name, &office, &active, &updatedAt); err != nil {
+		return m, err
+	}
+	m.OfficeCode = office.String
+	m.Active = &active
+	if updatedAt.Valid {
+		m.UpdatedAt = &updatedAt.Time
+	}
+	return m, nil
+}
+
+// appendMasterScope limits query, ending in a WHERE clause, to the offices
+// the caller of ctx may see
+func appendMasterScope(ctx context.Context, t masterTable, query string, args []interface{}) (string, []interface{}) {
+	if t.scope == "" {
+		return query, args
+	}
+	return appendOfficeScope(ctx, query, args, t.scope)
+}
+
+// List retrieves all records of a master the caller may see, ordered by code
+func (r *MastersRepository) List(ctx context.Context, kind string) (_ []models.MasterRecord, err error) {
+	ctx, span := startSpan(ctx, "MastersRepository.List", dbLocal, masterTables[kind].table)
+	defer tracing.End(span, &err)
+
+	t, err := lookupMaster(kind)
+	if err != nil {
+		return []models.MasterRecord{}, err
+	}
+
+	query, args := appendMasterScope(ctx, t, selectMaster(t)+` WHERE TRUE`, nil)
+	rows, err := r.localDB.QueryContext(ctx, query+` ORDER BY code`, args...)
+	if err != nil {
+		return []models.MasterRecord{}, err
+	}
+	defer rows.Close()
+
+	results := []models.MasterRecord{}
+	for rows.Next() {
+		m, err := scanMaster(rows)
+		if err != nil {
+			return []models.MasterRecord{}, err
+		}
+		results = append(results, m)
+	}
+
+	return results, rows.Err()
+}
+
+// Get retrieves one master record. Returns sql.ErrNoRows when the code does
+// not exist or belongs to an office the caller may not see.
+func (r *MastersRepository) Get(ctx context.Context, kind, code string) (_ *models.MasterRecord, err error) {
+	ctx, span := startSpan(ctx, "MastersRepository.Get", dbLocal, masterTables[kind].table)
+	defer tracing.End(span, &err)
+
+	t, err := lookupMaster(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args := appendMasterScope(ctx, t, selectMaster(t)+` WHERE code = ?`, []interface{}{code})
+	m, err := scanMaster(r.localDB.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Names returns code → name of all records of a master
+func (r *MastersRepository) Names(ctx context.Context, kind string) (_ map[string]string, err error) {
+	ctx, span := startSpan(ctx, "MastersRepository.Names", dbLocal, masterTables[kind].table)
+	defer tracing.End(span, &err)
+
+	t, err := lookupMaster(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.localDB.QueryContext(ctx, `SELECT code, name FROM `+t.table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := map[string]string{}
+	for rows.Next() {
+		var code, name string
+		if err := rows.Scan(&code, &name); err != nil {
+			return nil, err
+		}
+		names[code] = name
+	}
+
+	return names, rows.Err()
+}
+
+// Upsert inserts a master record or replaces the existing one with the same code
+func (r *MastersRepository) Upsert(ctx context.Context, kind string, m *models.MasterRecord) (err error) {
+	ctx, span := startSpan(ctx, "MastersRepository.Upsert", dbLocal, masterTables[kind].table)
+	defer tracing.End(span, &err)
+
+	t, err := lookupMaster(kind)
+	if err != nil {
+		return err
+	}
+
+	active := m.Active == nil || *m.Active
+	if t.hasOffice {
+		var office sql.NullString
+		if m.OfficeCode != "" {
+			office = sql.NullString{String: m.OfficeCode, Valid: true}
+		}
+		_, err = r.localDB.ExecContext(ctx, `
+			INSERT INTO `+t.table+` (code, name, office_code, active) VALUES (?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE name = VALUES(name), office_code = VALUES(office_code), active = VALUES(active)
+		`, m.Code, m.Name, office, active)
+		return err
+	}
+
+	_, err = r.localDB.ExecContext(ctx, `
+		INSERT INTO `+t.table+` (code, name, active) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE name = VALUES(name), active = VALUES(active)
+	`, m.Code, m.Name, active)
+	return err
+}
+
+// InsertIfMissing adds a master record unless the code already exists, so
+// name, office and deactivation of existing records set by hand are kept.
+// Returns whether a row was added.
+func (r *MastersRepository) InsertIfMissing(ctx context.Context, kind string, m *models.MasterRecord) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "MastersRepository.InsertIfMissing", dbLocal, masterTables[kind].table)
+	defer tracing.End(span, &err)
+
+	t, err := lookupMaster(kind)
+	if err != nil {
+		return false, err
+	}
+
+	var result sql.Result
+	if t.hasOffice {
+		var office sql.NullString
+		if m.OfficeCode != "" {
+			office = sql.NullString{String: m.OfficeCode, Valid: true}
+		}
+		result, err = r.localDB.ExecContext(ctx, `INSERT IGNORE INTO `+t.table+` (code, name, office_code, active) VALUES (?, ?, ?, 1)`,
+			m.Code, m.Name, office)
+	} else {
+		result, err = r.localDB.ExecContext(ctx, `INSERT IGNORE INTO `+t.table+` (code, name, active) VALUES (?, ?, 1)`, m.Code, m.Name)
+	}
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// Delete removes a master record. Returns sql.ErrNoRows when the code does not exist.
+func (r *MastersRepository) Delete(ctx context.Context, kind, code string) (err error) {
+	ctx, span := startSpan(ctx, "MastersRepository.Delete", dbLocal, masterTables[kind].table)
+	defer tracing.End(span, &err)
+
+	t, err := lookupMaster(kind)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.localDB.ExecContext(ctx, `DELETE FROM `+t.table+` WHERE code = ?`, code)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// FetchFromProduction derives master records from the names denormalized
+// in production dtako_ferry_rows. Driver records are only derived from ferry
+// rows dated since; a zero since reads all.
+func (r *MastersRepository) FetchFromProduction(ctx context.Context, kind string, since time.Time) (_ []models.MasterRecord, err error) {
+	ctx, span := startSpan(ctx, "MastersRepository.FetchFromProduction", dbProduction, "dtako_ferry_rows")
+	defer tracing.End(span, &err)
+
+	t, err := lookupMaster(kind)
+	if err != nil {
+		return []models.MasterRecord{}, err
+	}
+	if r.prodDB == nil {
+		return []models.MasterRecord{}, ErrProductionUnavailable
+	}
+	if os.Getenv("PROD_DB_NAME") == "dtako_test_prod" {
+		// テスト用プロダクションDBにはフェリー明細がない
+		return []models.MasterRecord{}, nil
+	}
+
+	var args []interface{}
+	if t.personal {
+		if since.IsZero() {
+			since = time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+		}
+		args = append(args, since.Format("2006-01-02"))
+	}
+
+	rows, err := r.prodDB.QueryContext(ctx, t.production, args...)
+	if err != nil {
+		return []models.MasterRecord{}, err
+	}
+	defer rows.Close()
+
+	results := []models.MasterRecord{}
+	for rows.Next() {
+		var m models.MasterRecord
+		dest := []interface{}{&m.Code, &m.Name}
+		var office sql.NullString
+		if t.hasOffice {
+			dest = append(dest, &office)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return []models.MasterRecord{}, err
+		}
+		m.OfficeCode = office.String
+		results = append(results, m)
+	}
+
+	return results, rows.Err()
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/auth"
+)
+
+func TestAppendFindingsScope(t *testing.T) {
+	const base = "SELECT id FROM dtako_validation_findings WHERE rule = ?"
+
+	query, args := appendFindingsScope(context.Background(), base, []interface{}{"odometer"})
+	if query != base || len(args) != 1 {
+		t.Errorf("unrestricted caller: got %q %v, want the query unchanged", query, args)
+	}
+
+	scoped := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "office-10", Role: auth.RoleViewer, Offices: []string{"10", "20"}})
+	query, args = appendFindingsScope(scoped, base, []interface{}{"odometer"})
+
+	want := base + " AND (" +
+		"(table_name = 'dtako_rows' AND record_id IN (SELECT id FROM dtako_rows WHERE TRUE AND 事業所CD IN (?, ?)))" +
+		" OR (table_name = 'dtako_events' AND record_id IN (SELECT id FROM dtako_events WHERE TRUE AND 事業所CD IN (?, ?)))" +
+		" OR (table_name = 'dtako_ferry_rows' AND record_id IN (SELECT CAST(id AS CHAR) FROM dtako_ferry_rows WHERE TRUE AND 事業所CD IN (?, ?))))"
+	if query != want {
+		t.Errorf("scoped query:\n got %s\nwant %s", query, want)
+	}
+	wantArgs := []interface{}{"odometer", "10", "20", "10", "20", "10", "20"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("scoped args = %v, want %v", args, wantArgs)
+	}
+}
+
+func TestAppendMasterScope(t *testing.T) {
+	scoped := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "office-10", Role: auth.RoleViewer, Offices: []string{"10"}})
+
+	tests := []struct {
+		kind string
+		want string
+	}{
+		{"vehicles", "SELECT code FROM t WHERE TRUE AND office_code IN (?)"},
+		{"drivers", "SELECT code FROM t WHERE TRUE AND office_code IN (?)"},
+		{"offices", "SELECT code FROM t WHERE TRUE AND code IN (?)"},
+		// 事業所を持たないマスタは全件
+		{"ferry_companies", "SELECT code FROM t WHERE TRUE"},
+		{"ports", "SELECT code FROM t WHERE TRUE"},
+	}
+	for _, tt := range tests {
+		query, _ := appendMasterScope(scoped, masterTables[tt.kind], "SELECT code FROM t WHERE TRUE", nil)
+		if query != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.kind, query, tt.want)
+		}
+
+		query, _ = appendMasterScope(context.Background(), masterTables[tt.kind], "SELECT code FROM t WHERE TRUE", nil)
+		if query != "SELECT code FROM t WHERE TRUE" {
+			t.Errorf("%s unrestricted: got %q", tt.kind, query)
+		}
+	}
+}
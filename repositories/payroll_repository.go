@@ -0,0 +1,156 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// PayrollRepository handles the monthly payroll snapshot table
+type PayrollRepository struct {
+	localDB *sql.DB
+}
+
+// NewPayrollRepository creates a new repository instance
+func NewPayrollRepository() *PayrollRepository {
+	localDB := localPool()
+
+	return &PayrollRepository{
+		localDB: localDB,
+	}
+}
+
+// GetMonth retrieves the snapshots of a month ordered by driver. stale
+// reports whether the month was marked for recomputation; found is false
+// when the month has never been computed.
+func (r *PayrollRepository) GetMonth(ctx context.Context, month string) (summaries []models.PayrollSummary, stale bool, found bool, err error) {
+	ctx, span := startSpan(ctx, "PayrollRepository.GetMonth", dbLocal, "dtako_payroll_summaries")
+	defer tracing.End(span, &err)
+
+	rows, err := r.localDB.QueryContext(ctx, `
+		SELECT month, driver_code, working_days, binding_minutes, driving_minutes,
+		       night_minutes, overtime_minutes, distance, ferry_trips, stale, computed_at
+		FROM dtako_payroll_summaries
+		WHERE month = ?
+		ORDER BY driver_code
+	`, month)
+	if err != nil {
+		return nil, false, false, err
+	}
+	defer rows.Close()
+
+	summaries = []models.PayrollSummary{}
+	for rows.Next() {
+		var s models.PayrollSummary
+		var rowStale bool
+		if err := rows.Scan(&s.Month, &s.DriverCode, &s.WorkingDays, &s.BindingMinutes, &s.DrivingMinutes,
+			&s.NightMinutes, &s.OvertimeMinutes, &s.Distance, &s.FerryTrips, &rowStale, &s.ComputedAt); err != nil {
+			return nil, false, false, err
+		}
+		stale = stale || rowStale
+		summaries = append(summaries, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, false, err
+	}
+
+	return summaries, stale, len(summaries) > 0, nil
+}
+
+// StaleVersion returns how often a month has been marked stale. Read it
+// before computing a month and pass it to ReplaceMonth.
+func (r *PayrollRepository) StaleVersion(ctx context.Context, month string) (version int64, err error) {
+	ctx, span := startSpan(ctx, "PayrollRepository.StaleVersion", dbLocal, "dtako_payroll_versions")
+	defer tracing.End(span, &err)
+
+	return staleVersion(r.localDB.QueryRowContext(ctx, `SELECT version FROM dtako_payroll_versions WHERE month = ?`, month))
+}
+
+func staleVersion(row *sql.Row) (int64, error) {
+	var version int64
+	err := row.Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return version, err
+}
+
+// ReplaceMonth replaces all snapshots of a month in one transaction.
+// version is the StaleVersion read before the summaries were computed; if the
+// month was marked stale again since, the snapshots are stored still stale so
+// the next access recomputes them.
+func (r *PayrollRepository) ReplaceMonth(ctx context.Context, month string, version int64, summaries []models.PayrollSummary) (err error) {
+	ctx, span := startSpan(ctx, "PayrollRepository.ReplaceMonth", dbLocal, "dtako_payroll_summaries")
+	defer tracing.End(span, &err)
+
+	tx, err := r.localDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// MarkStale と直列化するため行ロックを取ってから比較する
+	current, err := staleVersion(tx.QueryRowContext(ctx, `SELECT version FROM dtako_payroll_versions WHERE month = ? FOR UPDATE`, month))
+	if err != nil {
+		return err
+	}
+	stale := current != version
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM dtako_payroll_summaries WHERE month = ?`, month); err != nil {
+		return err
+	}
+	for _, s := range summaries {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO dtako_payroll_summaries (
+				month, driver_code, working_days, binding_minutes, driving_minutes,
+				night_minutes, overtime_minutes, distance, ferry_trips, stale, computed_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, month, s.DriverCode, s.WorkingDays, s.BindingMinutes, s.DrivingMinutes,
+			s.NightMinutes, s.OvertimeMinutes, s.Distance, s.FerryTrips, stale, s.ComputedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	tracing.Rows(span, "written", len(summaries))
+	return tx.Commit()
+}
+
+// MarkStale flags the snapshots of the given months for recomputation and
+// bumps their StaleVersion, also for months not computed yet, so a
+// computation running concurrently does not store its result as fresh
+func (r *PayrollRepository) MarkStale(ctx context.Context, months []string) (err error) {
+	ctx, span := startSpan(ctx, "PayrollRepository.MarkStale", dbLocal, "dtako_payroll_summaries")
+	defer tracing.End(span, &err)
+
+	if len(months) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(months))
+	values := make([]string, len(months))
+	args := make([]interface{}, len(months))
+	for i, m := range months {
+		placeholders[i] = "?"
+		values[i] = "(?, 1)"
+		args[i] = m
+	}
+
+	tx, err := r.localDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO dtako_payroll_versions (month, version) VALUES `+strings.Join(values, ", ")+`
+		ON DUPLICATE KEY UPDATE version = version + 1`, args...); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE dtako_payroll_summaries SET stale = 1 WHERE month IN (`+strings.Join(placeholders, ", ")+`)`, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,263 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// retentionBatchSize limits the records changed per statement so a run does
+// not lock large parts of a table at once
+const retentionBatchSize = 5000
+
+// retentionTable is a local table holding driver-identifying columns.
+// Anonymized codes are set to 0 and names to the empty string since the
+// columns are NOT NULL.
+type retentionTable struct {
+	table      string
+	dateColumn string
+	codes      []string
+	names      []string
+}
+
+// retentionTables in processing order: events before the rows they belong to
+var retentionTables = []retentionTable{
+	{table: "dtako_events", dateColumn: "開始日時", codes: []string{"対象乗務員CD", "乗務員CD1"}},
+	{table: "dtako_ferry_rows", dateColumn: "運行日", codes: []string{"乗務員CD1"}, names: []string{"乗務員名１"}},
+	{table: "dtako_rows", dateColumn: "運行日", codes: []string{"対象乗務員CD"}},
+}
+
+// identifying returns the condition matching records not yet anonymized
+func (t retentionTable) identifying() string {
+	conds := make([]string, 0, len(t.codes)+len(t.names))
+	for _, c := range t.codes {
+		conds = append(conds, c+" <> 0")
+	}
+	for _, c := range t.names {
+		conds = append(conds, c+" <> ''")
+	}
+	return "(" + strings.Join(conds, " OR ") + ")"
+}
+
+func (t retentionTable) columns() []string {
+	return append(append([]string{}, t.codes...), t.names...)
+}
+
+func (t retentionTable) anonymizeStep() retentionStep {
+	sets := make([]string, 0, len(t.codes)+len(t.names))
+	for _, c := range t.codes {
+		sets = append(sets, c+" = 0")
+	}
+	for _, c := range t.names {
+		sets = append(sets, c+" = ''")
+	}
+	return retentionStep{
+		table:   t.table,
+		columns: t.columns(),
+		set:     strings.Join(sets, ", "),
+		where:   fmt.Sprintf("%s < ? AND %s", t.dateColumn, t.identifying()),
+	}
+}
+
+func (t retentionTable) purgeStep() retentionStep {
+	return retentionStep{table: t.table, where: t.dateColumn + " < ?"}
+}
+
+// findingsExpired matches validation findings recorded before the cutoff or
+// about a record dated before it. Their messages quote 運行NO, positions and
+// times of the record.
+const findingsExpired = `(created_at < ?
+	OR (table_name = 'dtako_events' AND record_id IN (SELECT id FROM dtako_events WHERE 開始日時 < ?))
+	OR (table_name = 'dtako_ferry_rows' AND record_id IN (SELECT CAST(id AS CHAR) FROM dtako_ferry_rows WHERE 運行日 < ?))
+	OR (table_name = 'dtako_rows' AND record_id IN (SELECT id FROM dtako_rows WHERE 運行日 < ?)))`
+
+// driversExpired matches drivers not changed since the cutoff and without
+// rows or ferry rows dated on or after it, i.e. drivers whose data is past
+// the retention period. Recently added drivers without trips are kept.
+const driversExpired = `updated_at < ?
+	AND NOT EXISTS (SELECT 1 FROM dtako_rows WHERE 対象乗務員CD = dtako_drivers.code AND 運行日 >= ?)
+	AND NOT EXISTS (SELECT 1 FROM dtako_ferry_rows WHERE 乗務員CD1 = dtako_drivers.code AND 運行日 >= ?)`
+
+// auditDriverStep clears driver master records from audit entries recorded
+// before the cutoff: params (the record), errors (may quote the code) and
+// the code in target (drivers/<code>). The entries themselves are kept in
+// both modes; the audit log records who ran what.
+var auditDriverStep = retentionStep{
+	table:   "dtako_audit_log",
+	columns: []string{"target", "params", "errors"},
+	set:     "target = 'drivers', params = NULL, errors = NULL",
+	where:   "target LIKE 'drivers%' AND created_at < ? AND (params IS NOT NULL OR errors IS NOT NULL OR target <> 'drivers')",
+}
+
+// retentionStep processes the records of one table matched by where, in
+// which every ? is bound to the cutoff. With set the records are updated,
+// otherwise deleted.
+type retentionStep struct {
+	table   string
+	columns []string
+	set     string
+	where   string
+}
+
+// anonymizeSteps in processing order: findings are matched by the records
+// they belong to, drivers by the records left after the cutoff
+func anonymizeSteps() []retentionStep {
+	steps := []retentionStep{{
+		table:   "dtako_validation_findings",
+		columns: []string{"message"},
+		set:     "message = NULL",
+		where:   "message IS NOT NULL AND " + findingsExpired,
+	}}
+	for _, t := range retentionTables {
+		steps = append(steps, t.anonymizeStep())
+	}
+	return append(steps, retentionStep{
+		table:   "dtako_drivers",
+		columns: []string{"name"},
+		set:     "name = ''",
+		where:   "name <> '' AND " + driversExpired,
+	}, auditDriverStep)
+}
+
+// purgeSteps are anonymizeSteps deleting instead, except for the audit log
+func purgeSteps() []retentionStep {
+	steps := []retentionStep{{table: "dtako_validation_findings", where: findingsExpired}}
+	for _, t := range retentionTables {
+		steps = append(steps, t.purgeStep())
+	}
+	return append(steps, retentionStep{table: "dtako_drivers", where: driversExpired}, auditDriverStep)
+}
+
+func (s retentionStep) action() string {
+	if s.set != "" {
+		return models.RetentionAnonymize
+	}
+	return models.RetentionPurge
+}
+
+// statement renders the batched UPDATE or DELETE of the step
+func (s retentionStep) statement() string {
+	if s.set != "" {
+		return fmt.Sprintf("UPDATE %s SET %s WHERE %s LIMIT %d", s.table, s.set, s.where, retentionBatchSize)
+	}
+	return fmt.Sprintf("DELETE FROM %s WHERE %s LIMIT %d", s.table, s.where, retentionBatchSize)
+}
+
+// args binds every placeholder of where to cutoff
+func (s retentionStep) args(cutoff time.Time) []interface{} {
+	args := make([]interface{}, strings.Count(s.where, "?"))
+	for i := range args {
+		args[i] = cutoff
+	}
+	return args
+}
+
+// RetentionRepository anonymizes or purges driver-identifying data of old records
+type RetentionRepository struct {
+	localDB *sql.DB
+}
+
+// NewRetentionRepository creates a new repository instance
+func NewRetentionRepository() *RetentionRepository {
+	localDB := localPool()
+
+	return &RetentionRepository{
+		localDB: localDB,
+	}
+}
+
+// retentionSpanTables lists the tables a run touches, for tracing
+const retentionSpanTables = "dtako_rows,dtako_events,dtako_ferry_rows,dtako_validation_findings,dtako_drivers,dtako_audit_log"
+
+// Anonymize clears the driver codes and names of records dated before
+// cutoff, the messages of their validation findings, the names of drivers
+// past the retention period and driver master records in the audit log.
+// Already anonymized records are not counted again. With dryRun the
+// matching records are only counted.
+func (r *RetentionRepository) Anonymize(ctx context.Context, cutoff time.Time, dryRun bool) (_ []models.RetentionTableResult, err error) {
+	ctx, span := startSpan(ctx, "RetentionRepository.Anonymize", dbLocal, retentionSpanTables)
+	defer tracing.End(span, &err)
+
+	return r.runSteps(ctx, span, anonymizeSteps(), cutoff, dryRun)
+}
+
+// Purge deletes the records dated before cutoff, their validation findings
+// and drivers past the retention period. Driver master records in the audit
+// log are cleared as by Anonymize. With dryRun the matching records are only
+// counted.
+func (r *RetentionRepository) Purge(ctx context.Context, cutoff time.Time, dryRun bool) (_ []models.RetentionTableResult, err error) {
+	ctx, span := startSpan(ctx, "RetentionRepository.Purge", dbLocal, retentionSpanTables)
+	defer tracing.End(span, &err)
+
+	return r.runSteps(ctx, span, purgeSteps(), cutoff, dryRun)
+}
+
+func (r *RetentionRepository) runSteps(ctx context.Context, span trace.Span, steps []retentionStep, cutoff time.Time, dryRun bool) ([]models.RetentionTableResult, error) {
+	var processed int64
+	results := make([]models.RetentionTableResult, 0, len(steps))
+	for _, step := range steps {
+		result := models.RetentionTableResult{Table: step.table, Action: step.action(), Columns: step.columns}
+
+		var err error
+		if dryRun {
+			result.Records, err = r.count(ctx, step.table, step.where, step.args(cutoff)...)
+		} else {
+			result.Records, err = r.inBatches(ctx, step.statement(), step.args(cutoff)...)
+		}
+		if err != nil {
+			return results, fmt.Errorf("%s: %w", step.table, err)
+		}
+		processed += result.Records
+		results = append(results, result)
+	}
+	tracing.Rows(span, "written", int(processed))
+	return results, nil
+}
+
+// PurgePayroll deletes the payroll snapshots of months before cutoffMonth
+// (YYYY-MM); they are keyed by driver and cannot be anonymized.
+func (r *RetentionRepository) PurgePayroll(ctx context.Context, cutoffMonth string, dryRun bool) (_ models.RetentionTableResult, err error) {
+	ctx, span := startSpan(ctx, "RetentionRepository.PurgePayroll", dbLocal, "dtako_payroll_summaries")
+	defer tracing.End(span, &err)
+
+	result := models.RetentionTableResult{Table: "dtako_payroll_summaries", Action: models.RetentionPurge}
+	if dryRun {
+		result.Records, err = r.count(ctx, "dtako_payroll_summaries", "month < ?", cutoffMonth)
+	} else {
+		result.Records, err = r.inBatches(ctx, fmt.Sprintf("DELETE FROM dtako_payroll_summaries WHERE month < ? LIMIT %d", retentionBatchSize), cutoffMonth)
+	}
+	tracing.Rows(span, "written", int(result.Records))
+	return result, err
+}
+
+func (r *RetentionRepository) count(ctx context.Context, table, where string, args ...interface{}) (int64, error) {
+	var n int64
+	err := r.localDB.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", table, where), args...).Scan(&n)
+	return n, err
+}
+
+// inBatches runs a LIMITed UPDATE/DELETE until it affects less than a full batch
+func (r *RetentionRepository) inBatches(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	var total int64
+	for {
+		res, err := r.localDB.ExecContext(ctx, query, args...)
+		if err != nil {
+			return total, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < retentionBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+func TestRetentionSteps(t *testing.T) {
+	cutoff := time.Date(2022, 10, 19, 0, 0, 0, 0, time.UTC)
+	wantTables := []string{
+		"dtako_validation_findings", "dtako_events", "dtako_ferry_rows", "dtako_rows", "dtako_drivers", "dtako_audit_log",
+	}
+
+	tests := []struct {
+		mode  string
+		steps []retentionStep
+		// action per table; the audit log is anonymized in both modes
+		want map[string]string
+	}{
+		{models.RetentionAnonymize, anonymizeSteps(), map[string]string{
+			"dtako_validation_findings": models.RetentionAnonymize,
+			"dtako_drivers":             models.RetentionAnonymize,
+			"dtako_rows":                models.RetentionAnonymize,
+			"dtako_audit_log":           models.RetentionAnonymize,
+		}},
+		{models.RetentionPurge, purgeSteps(), map[string]string{
+			"dtako_validation_findings": models.RetentionPurge,
+			"dtako_drivers":             models.RetentionPurge,
+			"dtako_rows":                models.RetentionPurge,
+			"dtako_audit_log":           models.RetentionAnonymize,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			if len(tt.steps) != len(wantTables) {
+				t.Fatalf("expected %d steps, got %d", len(wantTables), len(tt.steps))
+			}
+			for i, step := range tt.steps {
+				if step.table != wantTables[i] {
+					t.Errorf("step %d: expected %s, got %s", i, wantTables[i], step.table)
+				}
+				if want, ok := tt.want[step.table]; ok && step.action() != want {
+					t.Errorf("%s: expected %s, got %s", step.table, want, step.action())
+				}
+
+				stmt := step.statement()
+				args := step.args(cutoff)
+				if n := strings.Count(stmt, "?"); n != len(args) || n == 0 {
+					t.Errorf("%s: %d placeholders bound to %d args: %s", step.table, n, len(args), stmt)
+				}
+				for _, arg := range args {
+					if arg != cutoff {
+						t.Errorf("%s: expected every arg to be the cutoff, got %v", step.table, arg)
+					}
+				}
+				verb := "DELETE FROM "
+				if step.action() == models.RetentionAnonymize {
+					verb = "UPDATE "
+				}
+				if !strings.HasPrefix(stmt, verb+step.table+" ") || !strings.HasSuffix(stmt, " LIMIT 5000") {
+					t.Errorf("%s: unexpected statement %s", step.table, stmt)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// Database names recorded on SQL spans (dtako.db)
+const (
+	dbLocal      = "local"
+	dbProduction = "production"
+)
+
+// startSpan starts the client span of one repository SQL call on table.
+// End it with tracing.End so SQL errors are recorded.
+func startSpan(ctx context.Context, name, db, table string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs,
+		semconv.DBSystemNameMySQL,
+		semconv.DBCollectionName(table),
+		attribute.String("dtako.db", db),
+	)
+	return tracing.Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/auth"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// ValidationFindingsRepository handles database operations for dtako_validation_findings
+type ValidationFindingsRepository struct {
+	localDB *sql.DB
+}
+
+// NewValidationFindingsRepository creates a new repository instance
+func NewValidationFindingsRepository() *ValidationFindingsRepository {
+	localDB := localPool()
+
+	return &ValidationFindingsRepository{
+		localDB: localDB,
+	}
+}
+
+// InsertAll stores findings of one import in a single transaction
+func (r *ValidationFindingsRepository) InsertAll(ctx context.Context, findings []models.ValidationFinding) (err error) {
+	ctx, span := startSpan(ctx, "ValidationFindingsRepository.InsertAll", dbLocal, "dtako_validation_findings")
+	defer tracing.End(span, &err)
+
+	if len(findings) == 0 {
+		return nil
+	}
+
+	tx, err := r.localDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO dtako_validation_findings (table_name, record_id, rule, severity, message, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, f := range findings {
+		if _, err := stmt.ExecContext(ctx, f.Table, f.RecordID, f.Rule, string(f.Severity), f.Message, f.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	tracing.Rows(span, "written", len(findings))
+	return tx.Commit()
+}
+
+// findingRecords are the tables findings refer to by table_name and
+// record_id, with the 事業所CD column of their records
+var findingRecords = []struct {
+	table, id, office string
+}{
+	{"dtako_rows", "id", rowsResource.filters["office"].column},
+	{"dtako_events", "id", eventsResource.filters["office"].column},
+	// record_id は文字列、フェリー明細の id は数値
+	{"dtako_ferry_rows", "CAST(id AS CHAR)", ferryRowsResource.filters["office"].column},
+}
+
+// appendFindingsScope limits findings to records of the offices the caller
+// of ctx may see. Findings of rejected records, which are not stored, have
+// no office and are only listed for callers seeing every office.
+func appendFindingsScope(ctx context.Context, query string, args []interface{}) (string, []interface{}) {
+	if _, restricted := auth.OfficeScope(ctx); !restricted {
+		return query, args
+	}
+
+	clauses := make([]string, len(findingRecords))
+	for i, rec := range findingRecords {
+		records, recordArgs := appendOfficeScope(ctx, fmt.Sprintf("SELECT %s FROM %s WHERE TRUE", rec.id, rec.table), nil, rec.office)
+		clauses[i] = fmt.Sprintf("(table_name = '%s' AND record_id IN (%s))", rec.table, records)
+		args = append(args, recordArgs...)
+	}
+	return query + " AND (" + strings.Join(clauses, " OR ") + ")", args
+}
+
+// List retrieves findings recorded within a date range, newest first.
+// Empty table or rule matches all. Office-scoped callers only see findings
+// of records in their offices.
+func (r *ValidationFindingsRepository) List(ctx context.Context, from, to time.Time, table, rule string) (_ []models.ValidationFinding, err error) {
+	ctx, span := startSpan(ctx, "ValidationFindingsRepository.List", dbLocal, "dtako_validation_findings", tracing.DateRange(from, to)...)
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT id, table_name, record_id, rule, severity, COALESCE(message, ''), created_at
+		FROM dtako_validation_findings
+		WHERE created_at >= ? AND created_at < DATE_ADD(?, INTERVAL 1 DAY)
+	`
+	args := []interface{}{from.Format("2006-01-02"), to.Format("2006-01-02")}
+	if table != "" {
+		query += " AND table_name = ?"
+		args = append(args, table)
+	}
+	if rule != "" {
+		query += " AND rule = ?"
+		args = append(args, rule)
+	}
+	query, args = appendFindingsScope(ctx, query, args)
+	query += " ORDER BY created_at DESC, id DESC"
+
+	rows, err := r.localDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return []models.ValidationFinding{}, err
+	}
+	defer rows.Close()
+
+	findings := []models.ValidationFinding{}
+	for rows.Next() {
+		var f models.ValidationFinding
+		var severity string
+		if err := rows.Scan(&f.ID, &f.Table, &f.RecordID, &f.Rule, &severity, &f.Message, &f.CreatedAt); err != nil {
+			return []models.ValidationFinding{}, err
+		}
+		f.Severity = models.ValidationSeverity(severity)
+		findings = append(findings, f)
+	}
+
+	return findings, rows.Err()
+}
@@ -4,7 +4,11 @@ import (
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/yhonda-ohishi/dtako_mod/auth"
 	"github.com/yhonda-ohishi/dtako_mod/handlers"
+	"github.com/yhonda-ohishi/dtako_mod/logging"
+	"github.com/yhonda-ohishi/dtako_mod/metrics"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
 )
 
 // RegisterRoutes registers all dtako_mod routes to the provided router
@@ -17,28 +21,132 @@ func RegisterRoutes(r chi.Router) {
 	rowsHandler := handlers.NewDtakoRowsHandler()
 	eventsHandler := handlers.NewDtakoEventsHandler()
 	ferryRowsHandler := handlers.NewDtakoFerryRowsHandler()
+	tripsHandler := handlers.NewDtakoTripsHandler()
+	geofencesHandler := handlers.NewGeofencesHandler()
+	validationHandler := handlers.NewValidationFindingsHandler()
+	integrityHandler := handlers.NewIntegrityHandler()
+	coverageHandler := handlers.NewCoverageHandler()
+	mastersHandler := handlers.NewMastersHandler()
+	eventTypesHandler := handlers.NewEventTypesHandler()
+	reportsHandler := handlers.NewReportsHandler()
+	payrollHandler := handlers.NewPayrollHandler()
+	nightHoursHandler := handlers.NewNightHoursHandler()
+	healthHandler := handlers.NewHealthHandler()
+	auditHandler := handlers.NewAuditHandler()
+	retentionHandler := handlers.NewRetentionHandler()
+
+	// Request ID, trace span, access log and latency/status metrics for every dtako route
+	r = r.With(logging.RequestID, tracing.Middleware, logging.Middleware, metrics.Middleware)
+
+	// liveness / readiness probes
+	r.Get("/healthz", healthHandler.Liveness)
+	r.Get("/readyz", healthHandler.Readiness)
+
+	// Everything else requires a caller (see auth.FromEnv); viewers read,
+	// operators import/sync, admins change master data.
+	// 事業所単位で絞り込めないデータ、取込と共有データの変更は全事業所を見られる呼び出し元のみ
+	r = r.With(handlers.Authenticate)
+	view := handlers.RequireRole(auth.RoleViewer)
+	operate := handlers.RequireRole(auth.RoleOperator)
+	admin := handlers.RequireRole(auth.RoleAdmin)
+	allOffices := handlers.RequireAllOffices
+
+	// import counters and route latencies cover every office
+	r.With(view, allOffices).Handle("/metrics", metrics.Handler())
 
 	// Register routes WITHOUT /dtako prefix
 	// dtako_rows endpoints
 	r.Route("/rows", func(r chi.Router) {
-		r.Get("/", rowsHandler.List)
-		r.Post("/import", rowsHandler.Import)
-		r.Get("/{id}", rowsHandler.GetByID)
+		r.With(view).Get("/", rowsHandler.List)
+		r.With(view).Get("/stats", rowsHandler.Stats)
+		r.With(view).Get("/fuel_anomalies", rowsHandler.FuelAnomalies)
+		r.With(operate, allOffices).Post("/import", rowsHandler.Import)
+		r.With(view).Get("/{id}", rowsHandler.GetByID)
 	})
 
 	// dtako_events endpoints
 	r.Route("/events", func(r chi.Router) {
-		r.Get("/", eventsHandler.List)
-		r.Post("/import", eventsHandler.Import)
-		r.Get("/{id}", eventsHandler.GetByID)
+		r.With(view).Get("/", eventsHandler.List)
+		r.With(operate, allOffices).Post("/import", eventsHandler.Import)
+		r.With(view).Get("/{id}", eventsHandler.GetByID)
 	})
 
 	// dtako_ferry_rows endpoints
 	r.Route("/ferry_rows", func(r chi.Router) {
-		r.Get("/", ferryRowsHandler.List)
-		r.Post("/import", ferryRowsHandler.Import)
-		r.Get("/{id}", ferryRowsHandler.GetByID)
+		r.With(view).Get("/", ferryRowsHandler.List)
+		r.With(operate, allOffices).Post("/import", ferryRowsHandler.Import)
+		r.With(view).Get("/{id}", ferryRowsHandler.GetByID)
+	})
+
+	// per-trip (運行NO) endpoints
+	r.Route("/trips", func(r chi.Router) {
+		r.With(view).Get("/odometer_continuity", tripsHandler.OdometerContinuity)
+		r.With(view).Get("/{unko_no}/track", tripsHandler.Track)
+		r.With(view).Get("/{unko_no}/time_breakdown", tripsHandler.TimeBreakdown)
+	})
+
+	// geofence endpoints
+	r.Route("/geofences", func(r chi.Router) {
+		r.With(view).Get("/", geofencesHandler.List)
+		r.With(admin).Post("/", geofencesHandler.Create)
+		r.With(view).Get("/visits", geofencesHandler.Visits)
+		r.With(view).Get("/{id}", geofencesHandler.GetByID)
+		r.With(admin).Put("/{id}", geofencesHandler.Update)
+		r.With(admin).Delete("/{id}", geofencesHandler.Delete)
+	})
+
+	// import validation findings
+	r.With(view).Get("/validation/findings", validationHandler.List)
+
+	// cross-table integrity
+	r.Route("/integrity", func(r chi.Router) {
+		r.With(view, allOffices).Get("/", integrityHandler.Report)
+		r.With(operate, allOffices).Post("/fetch_missing", integrityHandler.FetchMissing)
 	})
+
+	// missing-data coverage calendar
+	r.With(view).Get("/coverage", coverageHandler.Get)
+
+	// master data (vehicles, drivers, offices, ferry_companies, ports)
+	r.Route("/masters/{kind}", func(r chi.Router) {
+		r.With(view).Get("/", mastersHandler.List)
+		r.With(admin, allOffices).Post("/", mastersHandler.Create)
+		r.With(admin, allOffices).Post("/import", mastersHandler.ImportCSV)
+		r.With(operate, allOffices).Post("/sync", mastersHandler.Sync)
+		r.With(view).Get("/{code}", mastersHandler.Get)
+		r.With(admin, allOffices).Put("/{code}", mastersHandler.Update)
+		r.With(admin, allOffices).Delete("/{code}", mastersHandler.Delete)
+	})
+
+	// event type registry (イベント名 → category)
+	r.Route("/event_types", func(r chi.Router) {
+		r.With(view).Get("/", eventTypesHandler.List)
+		r.With(operate).Post("/sync", eventTypesHandler.Sync)
+		r.With(admin).Put("/{name}", eventTypesHandler.Put)
+		r.With(admin).Delete("/{name}", eventTypesHandler.Delete)
+	})
+
+	// reports
+	r.With(view).Get("/reports/daily", reportsHandler.Daily)
+
+	// monthly payroll summary
+	r.With(view).Get("/payroll/monthly", payrollHandler.Monthly)
+	r.With(operate, allOffices).Post("/payroll/monthly/recompute", payrollHandler.Recompute)
+	r.With(view).Get("/payroll/night_hours", nightHoursHandler.List)
+
+	// audit log of imports and master-data changes
+	r.With(admin, allOffices).Get("/audit", auditHandler.List)
+
+	// driver data retention (anonymize / purge)
+	r.With(admin, allOffices).Post("/retention/run", retentionHandler.Run)
+}
+
+// MetricsHandler returns the Prometheus handler for dtako_mod metrics
+// (HTTP latency per route, DB pool stats, import counters) so the host
+// router can mount it at its own path, e.g. r.Handle("/metrics", dtako_mod.MetricsHandler()).
+// The handler does no authentication of its own; protect it like the host's other routes.
+func MetricsHandler() http.Handler {
+	return metrics.Handler()
 }
 
 // Handler interface that each handler must implement
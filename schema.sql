@@ -4,63 +4,242 @@
 CREATE DATABASE IF NOT EXISTS dtako_local DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;
 USE dtako_local;
 
--- dtako_rows table
+-- dtako_rows table (same column names as production dtako_rows; only the columns dtako_mod uses)
 CREATE TABLE IF NOT EXISTS dtako_rows (
-    id VARCHAR(255) PRIMARY KEY,
-    unko_no VARCHAR(255) UNIQUE NOT NULL,  -- 運行NO
-    date DATE NOT NULL,
-    vehicle_no VARCHAR(50),
-    driver_code VARCHAR(50),
-    route_code VARCHAR(50),
-    distance DECIMAL(10,2),
-    fuel_amount DECIMAL(10,2),
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    INDEX idx_date (date),
-    INDEX idx_unko_no (unko_no),
-    INDEX idx_vehicle (vehicle_no),
-    INDEX idx_driver (driver_code),
-    INDEX idx_route (route_code)
+    id VARCHAR(24) PRIMARY KEY,
+    運行NO VARCHAR(23) NOT NULL UNIQUE,
+    読取日 DATE NOT NULL,
+    運行日 DATE NOT NULL,
+    車輌CD INT NOT NULL,
+    車輌CC VARCHAR(6) NOT NULL,
+    乗務員CD1 INT,
+    対象乗務員区分 INT NOT NULL DEFAULT 0,
+    対象乗務員CD INT NOT NULL DEFAULT 0,
+    事業所CD INT NOT NULL DEFAULT 0,
+    総走行距離 DOUBLE NOT NULL DEFAULT 0,
+    行先市町村名 VARCHAR(40),
+    自社主燃料 DOUBLE NOT NULL DEFAULT 0,
+    -- 一覧の絞り込み（vehicle / driver / office / route）に使うカラム
+    INDEX idx_運行日 (運行日),
+    INDEX idx_車輌CD (車輌CD),
+    INDEX idx_対象乗務員CD (対象乗務員CD),
+    INDEX idx_事業所CD (事業所CD),
+    INDEX idx_行先市町村名 (行先市町村名)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
--- dtako_events table
+-- dtako_events table (same column names as production dtako_events; only the columns dtako_mod uses).
+-- No foreign key to dtako_rows: events may be imported before their rows (see /integrity).
 CREATE TABLE IF NOT EXISTS dtako_events (
-    id VARCHAR(255) PRIMARY KEY,
-    unko_no VARCHAR(255),  -- 運行NO - links to dtako_rows
-    event_date DATETIME NOT NULL,
-    event_type VARCHAR(50),
-    vehicle_no VARCHAR(50),
-    driver_code VARCHAR(50),
-    description TEXT,
-    latitude DECIMAL(10,8),
-    longitude DECIMAL(11,8),
+    id VARCHAR(50) PRIMARY KEY,
+    運行NO VARCHAR(23),
+    読取日 DATE,
+    車輌CD INT NOT NULL DEFAULT 0,
+    車輌CC VARCHAR(6),
+    開始日時 DATETIME NOT NULL,
+    終了日時 DATETIME,
+    イベント名 VARCHAR(50),
+    対象乗務員CD INT NOT NULL DEFAULT 0,
+    対象乗務員区分 INT NOT NULL DEFAULT 0,
+    乗務員CD1 INT,
+    事業所CD INT NOT NULL DEFAULT 0,
+    開始走行距離 DOUBLE,
+    終了走行距離 DOUBLE,
+    区間時間 INT,
+    区間距離 DOUBLE,
+    開始市町村名 VARCHAR(40),
+    終了市町村名 VARCHAR(40),
+    開始場所名 VARCHAR(40),
+    終了場所名 VARCHAR(40),
+    開始GPS緯度 INT,                        -- マイクロ度
+    開始GPS経度 INT,                        -- マイクロ度
+    備考 TEXT,
+    -- 一覧の絞り込み（vehicle / driver / office / unko_no / type）に使うカラム
+    INDEX idx_開始日時 (開始日時),
+    INDEX idx_運行NO (運行NO),
+    INDEX idx_イベント名 (イベント名),
+    INDEX idx_車輌CD (車輌CD),
+    INDEX idx_対象乗務員CD (対象乗務員CD),
+    INDEX idx_事業所CD (事業所CD)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- dtako_ferry_rows table (same columns as production dtako_ferry_rows)
+CREATE TABLE IF NOT EXISTS dtako_ferry_rows (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    運行NO VARCHAR(23) NOT NULL,
+    運行日 DATE NOT NULL,
+    読取日 DATE NOT NULL,
+    事業所CD INT NOT NULL,
+    事業所名 VARCHAR(20) NOT NULL,
+    車輌CD INT NOT NULL,
+    車輌名 VARCHAR(20) NOT NULL,
+    乗務員CD1 INT NOT NULL,
+    乗務員名１ VARCHAR(20) NOT NULL,
+    対象乗務員区分 INT NOT NULL,
+    開始日時 DATETIME NOT NULL,
+    終了日時 DATETIME NOT NULL,
+    フェリー会社CD INT NOT NULL,
+    フェリー会社名 VARCHAR(20) NOT NULL,
+    乗場CD INT NOT NULL,
+    乗場名 VARCHAR(20) NOT NULL,
+    便 VARCHAR(10) NOT NULL,
+    降場CD INT NOT NULL,
+    降場名 VARCHAR(20) NOT NULL,
+    精算区分 INT NOT NULL,
+    精算区分名 VARCHAR(20) NOT NULL,
+    標準料金 INT NOT NULL,
+    契約料金 INT NOT NULL,
+    航送車種区分 INT NOT NULL,
+    航送車種区分名 VARCHAR(20) NOT NULL,
+    見なし距離 INT NOT NULL,
+    ferry_srch VARCHAR(60) DEFAULT NULL,
+    INDEX idx_unko_no (運行NO),
+    INDEX idx_unko_date (運行日),
+    -- 一覧の絞り込み（ferry_company / vehicle / driver / office / boarding / landing）に使うカラム
+    INDEX idx_ferry_company (フェリー会社名),
+    INDEX idx_vehicle (車輌CD),
+    INDEX idx_driver (乗務員CD1),
+    INDEX idx_office (事業所CD),
+    INDEX idx_boarding (乗場CD),
+    INDEX idx_landing (降場CD)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- dtako_geofences table (named areas used for arrival/departure detection)
+CREATE TABLE IF NOT EXISTS dtako_geofences (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    category VARCHAR(20) NOT NULL,          -- depot / customer / port / other
+    shape VARCHAR(10) NOT NULL,             -- circle / polygon
+    center_lat DOUBLE,                      -- circle only
+    center_lng DOUBLE,                      -- circle only
+    radius_m DOUBLE,                        -- circle only
+    polygon TEXT,                           -- polygon only: JSON [{"lat":..,"lng":..}, ...]
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    INDEX idx_event_date (event_date),
-    INDEX idx_event_type (event_type),
-    INDEX idx_vehicle (vehicle_no),
-    INDEX idx_driver (driver_code),
-    INDEX idx_unko_no (unko_no),
-    FOREIGN KEY (unko_no) REFERENCES dtako_rows(unko_no) ON DELETE CASCADE
+    INDEX idx_category (category)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
--- dtako_ferry table
-CREATE TABLE IF NOT EXISTS dtako_ferry (
-    id VARCHAR(255) PRIMARY KEY,
-    date DATE NOT NULL,
-    route VARCHAR(100),
-    vehicle_no VARCHAR(50),
-    driver_code VARCHAR(50),
-    departure_time DATETIME,
-    arrival_time DATETIME,
-    passengers INT,
-    vehicles INT,
+-- dtako_validation_findings table (data quality findings recorded on import)
+CREATE TABLE IF NOT EXISTS dtako_validation_findings (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    table_name VARCHAR(64) NOT NULL,        -- dtako_rows / dtako_events / dtako_ferry_rows
+    record_id VARCHAR(255) NOT NULL,
+    rule VARCHAR(64) NOT NULL,
+    severity VARCHAR(10) NOT NULL,          -- reject / warn / fix
+    message TEXT,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_created_at (created_at),
+    INDEX idx_table_rule (table_name, rule)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Master data tables (code → name, populated from production or CSV)
+
+-- dtako_vehicles: vehicles (車輌CD)
+CREATE TABLE IF NOT EXISTS dtako_vehicles (
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    office_code VARCHAR(50),                -- 事業所CD
+    active TINYINT(1) NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_office (office_code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- dtako_drivers: drivers (乗務員CD)
+CREATE TABLE IF NOT EXISTS dtako_drivers (
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    office_code VARCHAR(50),                -- 事業所CD
+    active TINYINT(1) NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_office (office_code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- dtako_offices: offices (事業所CD)
+CREATE TABLE IF NOT EXISTS dtako_offices (
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    active TINYINT(1) NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- dtako_ferry_companies: ferry companies (フェリー会社CD)
+CREATE TABLE IF NOT EXISTS dtako_ferry_companies (
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    active TINYINT(1) NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- dtako_ports: ports (乗場CD / 降場CD)
+CREATE TABLE IF NOT EXISTS dtako_ports (
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    active TINYINT(1) NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- dtako_event_types: event type registry (イベント名 → category)
+-- category: driving / rest / work / waiting / other（other は時間集計の対象外）
+CREATE TABLE IF NOT EXISTS dtako_event_types (
+    name VARCHAR(50) PRIMARY KEY,           -- イベント名
+    category VARCHAR(20) NOT NULL DEFAULT 'other',
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    INDEX idx_date (date),
-    INDEX idx_route (route),
-    INDEX idx_vehicle (vehicle_no),
-    INDEX idx_driver (driver_code),
-    INDEX idx_departure (departure_time),
-    INDEX idx_arrival (arrival_time)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
\ No newline at end of file
+    INDEX idx_category (category)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 既知のイベント名（本番の値は POST /dtako/event_types/sync で追加）
+INSERT IGNORE INTO dtako_event_types (name, category) VALUES
+    ('運転', 'driving'),
+    ('休憩', 'rest'),
+    ('休息', 'rest'),
+    ('フェリー', 'rest'),
+    ('作業', 'work'),
+    ('積み', 'work'),
+    ('降し', 'work'),
+    ('待機', 'waiting'),
+    ('START', 'other'),
+    ('STOP', 'other'),
+    ('END', 'other');
+
+-- dtako_payroll_summaries: monthly per-driver payroll snapshots
+-- stale は該当月のデータが再インポートされたことを示す（次回参照時に再計算）
+CREATE TABLE IF NOT EXISTS dtako_payroll_summaries (
+    month CHAR(7) NOT NULL,                 -- YYYY-MM
+    driver_code VARCHAR(50) NOT NULL,       -- 対象乗務員CD
+    working_days INT NOT NULL DEFAULT 0,
+    binding_minutes DOUBLE NOT NULL DEFAULT 0,
+    driving_minutes DOUBLE NOT NULL DEFAULT 0,
+    night_minutes DOUBLE NOT NULL DEFAULT 0,
+    overtime_minutes DOUBLE NOT NULL DEFAULT 0,
+    distance DOUBLE NOT NULL DEFAULT 0,
+    ferry_trips INT NOT NULL DEFAULT 0,
+    stale TINYINT(1) NOT NULL DEFAULT 0,
+    computed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (month, driver_code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- dtako_payroll_versions: 月ごとの stale 設定回数
+-- 計算開始時の version と保存時の version が異なれば、計算中に再インポートされたとみなし stale のまま保存する
+CREATE TABLE IF NOT EXISTS dtako_payroll_versions (
+    month CHAR(7) PRIMARY KEY,              -- YYYY-MM
+    version BIGINT NOT NULL DEFAULT 0
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- dtako_audit_log: who triggered imports, reconciliation and master-data changes
+CREATE TABLE IF NOT EXISTS dtako_audit_log (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    action VARCHAR(64) NOT NULL,            -- rows.import / integrity.fetch_missing / masters.update ...
+    actor VARCHAR(255) NOT NULL,            -- トークンの sub / APIキー名 / anonymous
+    actor_role VARCHAR(20),
+    target VARCHAR(255),
+    params TEXT,                            -- JSON (ImportRequest, master record, ...)
+    success TINYINT(1) NOT NULL,
+    affected_rows INT NOT NULL DEFAULT 0,
+    rejected_rows INT NOT NULL DEFAULT 0,
+    failed_rows INT NOT NULL DEFAULT 0,
+    errors TEXT,                            -- JSON array
+    request_id VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_created_at (created_at),
+    INDEX idx_actor (actor, created_at),
+    INDEX idx_action (action, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/yhonda-ohishi/dtako_mod/apperr"
+	"github.com/yhonda-ohishi/dtako_mod/auth"
+	"github.com/yhonda-ohishi/dtako_mod/config"
+	"github.com/yhonda-ohishi/dtako_mod/logging"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/repositories"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// ErrInvalidAuditQuery is returned for an unknown audit action filter
+var ErrInvalidAuditQuery = apperr.New(apperr.Validation, "invalid audit query")
+
+// anonymousActor is recorded when authentication is disabled
+const anonymousActor = "anonymous"
+
+// defaultAuditLimit is the page size of audit log lists without limit
+const defaultAuditLimit = 1000
+
+// maxAuditErrors limits the per-record errors kept in one audit entry
+const maxAuditErrors = 100
+
+// AuditService provides access to the audit log
+type AuditService struct {
+	repo *repositories.AuditRepository
+}
+
+// NewAuditService creates a new service instance
+func NewAuditService() *AuditService {
+	return &AuditService{
+		repo: repositories.NewAuditRepository(),
+	}
+}
+
+// List retrieves one page (limit/offset of page, defaultAuditLimit entries
+// without limit) of the entries recorded within date range, optionally for
+// one actor or action
+func (s *AuditService) List(ctx context.Context, from, to, actor, action string, page models.ListQuery) (_ []models.AuditEntry, err error) {
+	ctx, span := tracing.Start(ctx, "AuditService.List", tracing.Period(from, to)...)
+	defer tracing.End(span, &err)
+
+	fromDate, toDate, err := parseDateRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if action != "" && !slices.Contains(models.AuditActions(), action) {
+		return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidAuditQuery, action)
+	}
+
+	if page.Limit == 0 {
+		page.Limit = defaultAuditLimit
+	}
+
+	return s.repo.List(ctx, fromDate, toDate, actor, action, page)
+}
+
+// auditTrail records imports, reconciliation and master-data changes in
+// dtako_audit_log. Recording never fails the action itself; a write error is logged.
+type auditTrail struct {
+	repo *repositories.AuditRepository
+}
+
+func newAuditTrail() *auditTrail {
+	return &auditTrail{repo: repositories.NewAuditRepository()}
+}
+
+// record stores action by the caller of ctx (see auditEntry).
+// Call it deferred so rejected rows added by validation.apply are included.
+func (a *auditTrail) record(ctx context.Context, action, target string, params interface{}, result *models.ImportResult, err error) {
+	entry := auditEntry(ctx, action, target, params, result, err)
+
+	// リクエストが切断されても監査ログは残す
+	if werr := a.repo.Insert(context.WithoutCancel(ctx), &entry); werr != nil {
+		logging.Logger().ErrorContext(ctx, "audit log write failed", "action", action, "actor", entry.Actor, "error", werr)
+	}
+}
+
+// auditEntry builds the entry of action by the caller of ctx. result holds
+// the counts of bulk actions; a single-record change counts one affected row
+// when it succeeded. err comes first in the errors.
+func auditEntry(ctx context.Context, action, target string, params interface{}, result *models.ImportResult, err error) models.AuditEntry {
+	entry := models.AuditEntry{
+		Action:    action,
+		Actor:     anonymousActor,
+		Target:    target,
+		Success:   err == nil,
+		RequestID: middleware.GetReqID(ctx),
+		CreatedAt: config.Now(),
+	}
+	if p, ok := auth.FromContext(ctx); ok {
+		if p.Subject != "" {
+			entry.Actor = p.Subject
+		}
+		entry.ActorRole = p.Role.String()
+	}
+	if params != nil {
+		if data, err := json.Marshal(params); err == nil {
+			entry.Params = data
+		}
+	}
+
+	switch {
+	case result != nil:
+		entry.AffectedRows = result.ImportedRows
+		entry.RejectedRows = result.RejectedRows
+		entry.FailedRows = len(result.Errors)
+		entry.Errors = result.Errors
+		if len(entry.Errors) > maxAuditErrors {
+			entry.Errors = append(entry.Errors[:maxAuditErrors:maxAuditErrors], fmt.Sprintf("... and %d more", len(result.Errors)-maxAuditErrors))
+		}
+	case err == nil:
+		entry.AffectedRows = 1
+	}
+	if err != nil {
+		entry.Errors = append([]string{err.Error()}, entry.Errors...)
+	}
+	return entry
+}
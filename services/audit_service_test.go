@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/yhonda-ohishi/dtako_mod/auth"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+func TestAuditEntry(t *testing.T) {
+	caller := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "batch", Role: auth.RoleOperator})
+	caller = context.WithValue(caller, middleware.RequestIDKey, "req-1")
+
+	t.Run("bulk import counts", func(t *testing.T) {
+		result := &models.ImportResult{ImportedRows: 10, RejectedRows: 2, Errors: []string{"row 3: bad date"}}
+		params := models.ImportRequest{FromDate: "2025-01-01", ToDate: "2025-01-31"}
+
+		e := auditEntry(caller, models.AuditRowsImport, "dtako_rows", params, result, nil)
+
+		if e.Actor != "batch" || e.ActorRole != "operator" || e.RequestID != "req-1" {
+			t.Errorf("caller = %s/%s/%s, want batch/operator/req-1", e.Actor, e.ActorRole, e.RequestID)
+		}
+		if !e.Success || e.AffectedRows != 10 || e.RejectedRows != 2 || e.FailedRows != 1 {
+			t.Errorf("counts = success %v affected %d rejected %d failed %d", e.Success, e.AffectedRows, e.RejectedRows, e.FailedRows)
+		}
+		if want := `{"from_date":"2025-01-01","to_date":"2025-01-31"}`; string(e.Params) != want {
+			t.Errorf("params = %s, want %s", e.Params, want)
+		}
+	})
+
+	t.Run("single change", func(t *testing.T) {
+		e := auditEntry(caller, models.AuditMasterDelete, "vehicles/101", nil, nil, nil)
+		if !e.Success || e.AffectedRows != 1 || e.Params != nil || e.Errors != nil {
+			t.Errorf("got %+v, want one affected row without params or errors", e)
+		}
+	})
+
+	t.Run("failure comes first", func(t *testing.T) {
+		result := &models.ImportResult{Errors: []string{"row 1"}}
+		e := auditEntry(caller, models.AuditRowsImport, "dtako_rows", nil, result, errors.New("production unavailable"))
+
+		if e.Success {
+			t.Error("expected failure")
+		}
+		if want := []string{"production unavailable", "row 1"}; !reflect.DeepEqual(e.Errors, want) {
+			t.Errorf("errors = %v, want %v", e.Errors, want)
+		}
+		if e.FailedRows != 1 {
+			t.Errorf("failed rows = %d, want 1", e.FailedRows)
+		}
+	})
+
+	t.Run("errors are capped", func(t *testing.T) {
+		result := &models.ImportResult{}
+		for i := 0; i < maxAuditErrors+5; i++ {
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d", i))
+		}
+		e := auditEntry(caller, models.AuditRowsImport, "dtako_rows", nil, result, nil)
+
+		if len(e.Errors) != maxAuditErrors+1 || e.Errors[maxAuditErrors] != "... and 5 more" {
+			t.Errorf("got %d errors ending with %q", len(e.Errors), e.Errors[len(e.Errors)-1])
+		}
+		if e.FailedRows != maxAuditErrors+5 {
+			t.Errorf("failed rows = %d, want %d", e.FailedRows, maxAuditErrors+5)
+		}
+		if len(result.Errors) != maxAuditErrors+5 {
+			t.Error("capping modified the import result")
+		}
+	})
+
+	t.Run("anonymous without authentication", func(t *testing.T) {
+		e := auditEntry(context.Background(), models.AuditRowsImport, "dtako_rows", nil, nil, nil)
+		if e.Actor != anonymousActor || e.ActorRole != "" {
+			t.Errorf("actor = %s/%s, want %s without role", e.Actor, e.ActorRole, anonymousActor)
+		}
+	})
+}
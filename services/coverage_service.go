@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/yhonda-ohishi/dtako_mod/auth"
+	"github.com/yhonda-ohishi/dtako_mod/logging"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/repositories"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// activeVehiclesEnv lists vehicles expected to have data every day, as
+// 車輌CD or 車輌CD:事業所CD separated by commas, e.g. "101:1,102:1,201:2"
+const activeVehiclesEnv = "ACTIVE_VEHICLES"
+
+// maxCoverageDays limits the size of the coverage matrix
+const maxCoverageDays = 92
+
+// activeVehicle is one entry of ACTIVE_VEHICLES
+type activeVehicle struct {
+	vehicleNo string
+	office    string
+}
+
+// CoverageService builds the missing-data coverage calendar
+type CoverageService struct {
+	rowsRepo *repositories.DtakoRowsRepository
+}
+
+// NewCoverageService creates a new service instance
+func NewCoverageService() *CoverageService {
+	return &CoverageService{
+		rowsRepo: repositories.NewDtakoRowsRepository(),
+	}
+}
+
+// GetCoverage returns trip counts per vehicle and day from local dtako_rows,
+// compared with production and with the configured active vehicles.
+// An empty office covers all offices.
+func (s *CoverageService) GetCoverage(ctx context.Context, from, to, office string) (_ *models.CoverageReport, err error) {
+	ctx, span := tracing.Start(ctx, "CoverageService.GetCoverage", tracing.Period(from, to)...)
+	defer tracing.End(span, &err)
+
+	fromDate, toDate, err := parseDateRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	days := int(toDate.Sub(fromDate).Hours()/24) + 1
+	if days > maxCoverageDays {
+		return nil, fmt.Errorf("%w: coverage range is limited to %d days", ErrInvalidListQuery, maxCoverageDays)
+	}
+
+	filter := models.ListFilter{}
+	if office != "" {
+		filter["office"] = []string{office}
+	}
+
+	local, err := s.rowsRepo.DailyVehicleCounts(ctx, fromDate, toDate, filter, false)
+	if err != nil {
+		return nil, err
+	}
+	// 本番DBが使えない場合はローカルのみで判定する
+	production, err := s.rowsRepo.DailyVehicleCounts(ctx, fromDate, toDate, filter, true)
+	if err != nil {
+		logging.Logger().Warn("coverage without production counts", "error", err)
+		production = nil
+	}
+
+	report := &models.CoverageReport{
+		From:   fromDate.Format("2006-01-02"),
+		To:     toDate.Format("2006-01-02"),
+		Office: office,
+	}
+	for d := 0; d < days; d++ {
+		report.Dates = append(report.Dates, fromDate.AddDate(0, 0, d).Format("2006-01-02"))
+	}
+
+	offices, restricted := auth.OfficeScope(ctx)
+	active := activeVehiclesFor(loadActiveVehicles(), office, offices, restricted)
+	report.Vehicles = coverageVehicles(report.Dates, local, production, active)
+
+	return report, nil
+}
+
+// activeVehiclesFor returns the active vehicles of office (empty: all) that
+// a caller restricted to offices may see. Vehicles configured without an
+// office belong to every office.
+func activeVehiclesFor(vehicles []activeVehicle, office string, offices []string, restricted bool) map[string]bool {
+	active := map[string]bool{}
+	for _, v := range vehicles {
+		if v.office != "" && office != "" && v.office != office {
+			continue
+		}
+		if v.office != "" && restricted && !slices.Contains(offices, v.office) {
+			continue
+		}
+		active[v.vehicleNo] = true
+	}
+	return active
+}
+
+// coverageVehicles classifies each day of dates for every vehicle that is
+// active or has local or production rows. A nil production compares with
+// local counts only.
+func coverageVehicles(dates []string, local, production map[string]map[string]int, active map[string]bool) []models.VehicleCoverage {
+	vehicles := map[string]bool{}
+	for v := range active {
+		vehicles[v] = true
+	}
+	for v := range local {
+		vehicles[v] = true
+	}
+	for v := range production {
+		vehicles[v] = true
+	}
+	vehicleNos := make([]string, 0, len(vehicles))
+	for v := range vehicles {
+		vehicleNos = append(vehicleNos, v)
+	}
+	sort.Strings(vehicleNos)
+
+	results := []models.VehicleCoverage{}
+	for _, vehicleNo := range vehicleNos {
+		vc := models.VehicleCoverage{VehicleNo: vehicleNo, Active: active[vehicleNo]}
+		for _, date := range dates {
+			day := models.CoverageDay{Date: date, Local: local[vehicleNo][date]}
+			if production != nil {
+				n := production[vehicleNo][date]
+				day.Production = &n
+			}
+			day.Status = coverageStatus(day, vc.Active)
+
+			switch day.Status {
+			case "missing":
+				vc.MissingDays++
+			case "not_imported", "local_only":
+				vc.MismatchDays++
+			}
+			vc.Days = append(vc.Days, day)
+		}
+		results = append(results, vc)
+	}
+	return results
+}
+
+// coverageStatus classifies one vehicle-day
+func coverageStatus(day models.CoverageDay, active bool) string {
+	if day.Production != nil && day.Local != *day.Production {
+		if day.Local < *day.Production {
+			return "not_imported"
+		}
+		return "local_only"
+	}
+	if day.Local == 0 {
+		if active {
+			return "missing"
+		}
+		return "none"
+	}
+	return "ok"
+}
+
+// loadActiveVehicles parses ACTIVE_VEHICLES
+func loadActiveVehicles() []activeVehicle {
+	var vehicles []activeVehicle
+	for _, entry := range strings.Split(os.Getenv(activeVehiclesEnv), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		vehicleNo, office, _ := strings.Cut(entry, ":")
+		vehicles = append(vehicles, activeVehicle{
+			vehicleNo: strings.TrimSpace(vehicleNo),
+			office:    strings.TrimSpace(office),
+		})
+	}
+	return vehicles
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+func TestCoverageVehicles(t *testing.T) {
+	dates := []string{"2025-01-10", "2025-01-11", "2025-01-12"}
+	local := map[string]map[string]int{
+		"101": {"2025-01-10": 1, "2025-01-12": 2},
+		"102": {"2025-01-11": 3},
+	}
+	production := map[string]map[string]int{
+		"101": {"2025-01-10": 1, "2025-01-11": 1, "2025-01-12": 1},
+		"103": {"2025-01-12": 1},
+	}
+	active := map[string]bool{"101": true, "104": true}
+
+	statuses := func(vc models.VehicleCoverage) []string {
+		var out []string
+		for _, d := range vc.Days {
+			out = append(out, d.Status)
+		}
+		return out
+	}
+
+	t.Run("with production", func(t *testing.T) {
+		got := coverageVehicles(dates, local, production, active)
+
+		want := []struct {
+			vehicle           string
+			active            bool
+			statuses          []string
+			missing, mismatch int
+		}{
+			{"101", true, []string{"ok", "not_imported", "local_only"}, 0, 2},
+			{"102", false, []string{"none", "local_only", "none"}, 0, 1},
+			{"103", false, []string{"none", "none", "not_imported"}, 0, 1},
+			// 稼働車両なのにどちらにもデータがない
+			{"104", true, []string{"missing", "missing", "missing"}, 3, 0},
+		}
+		if len(got) != len(want) {
+			t.Fatalf("got %d vehicles, want %d", len(got), len(want))
+		}
+		for i, w := range want {
+			vc := got[i]
+			if vc.VehicleNo != w.vehicle || vc.Active != w.active {
+				t.Errorf("vehicle %d = %s active=%v, want %s active=%v", i, vc.VehicleNo, vc.Active, w.vehicle, w.active)
+			}
+			if s := statuses(vc); !reflect.DeepEqual(s, w.statuses) {
+				t.Errorf("%s statuses = %v, want %v", w.vehicle, s, w.statuses)
+			}
+			if vc.MissingDays != w.missing || vc.MismatchDays != w.mismatch {
+				t.Errorf("%s missing/mismatch = %d/%d, want %d/%d", w.vehicle, vc.MissingDays, vc.MismatchDays, w.missing, w.mismatch)
+			}
+			for _, d := range vc.Days {
+				if d.Production == nil {
+					t.Errorf("%s %s: expected production count", w.vehicle, d.Date)
+				}
+			}
+		}
+	})
+
+	t.Run("local only", func(t *testing.T) {
+		got := coverageVehicles(dates, local, nil, active)
+
+		if len(got) != 3 {
+			t.Fatalf("got %d vehicles, want 101, 102 and 104", len(got))
+		}
+		if s := statuses(got[0]); !reflect.DeepEqual(s, []string{"ok", "missing", "ok"}) {
+			t.Errorf("101 statuses = %v", s)
+		}
+		for _, vc := range got {
+			for _, d := range vc.Days {
+				if d.Production != nil {
+					t.Errorf("%s %s: unexpected production count", vc.VehicleNo, d.Date)
+				}
+			}
+		}
+	})
+
+	t.Run("no vehicles", func(t *testing.T) {
+		if got := coverageVehicles(dates, nil, nil, map[string]bool{}); got == nil || len(got) != 0 {
+			t.Errorf("expected an empty list, got %v", got)
+		}
+	})
+}
+
+func TestActiveVehiclesFor(t *testing.T) {
+	vehicles := []activeVehicle{
+		{vehicleNo: "101", office: "1"},
+		{vehicleNo: "102", office: "1"},
+		{vehicleNo: "201", office: "2"},
+		{vehicleNo: "900"},
+	}
+
+	tests := []struct {
+		name       string
+		office     string
+		offices    []string
+		restricted bool
+		want       []string
+	}{
+		{"all offices", "", nil, false, []string{"101", "102", "201", "900"}},
+		{"office filter", "1", nil, false, []string{"101", "102", "900"}},
+		{"office scope", "", []string{"2"}, true, []string{"201", "900"}},
+		{"filter outside scope", "1", []string{"2"}, true, []string{"900"}},
+	}
+	for _, tt := range tests {
+		got := activeVehiclesFor(vehicles, tt.office, tt.offices, tt.restricted)
+		want := map[string]bool{}
+		for _, v := range tt.want {
+			want[v] = true
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, want)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/apperr"
+	"github.com/yhonda-ohishi/dtako_mod/config"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/repositories"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+var (
+	// ErrInvalidReportRequest is returned for a missing driver or malformed date
+	ErrInvalidReportRequest = apperr.New(apperr.Validation, "invalid report request")
+	// ErrReportNotFound is returned when the driver has no trips on the date
+	ErrReportNotFound = apperr.New(apperr.NotFound, "no trips found")
+)
+
+// DailyReportService assembles daily driver reports (運転日報)
+type DailyReportService struct {
+	rowsRepo    *repositories.DtakoRowsRepository
+	eventsRepo  *repositories.DtakoEventsRepository
+	ferryRepo   *repositories.DtakoFerryRowsRepository
+	mastersRepo *repositories.MastersRepository
+	typesRepo   *repositories.EventTypesRepository
+}
+
+// NewDailyReportService creates a new service instance
+func NewDailyReportService() *DailyReportService {
+	return &DailyReportService{
+		rowsRepo:    repositories.NewDtakoRowsRepository(),
+		eventsRepo:  repositories.NewDtakoEventsRepository(),
+		ferryRepo:   repositories.NewDtakoFerryRowsRepository(),
+		mastersRepo: repositories.NewMastersRepository(),
+		typesRepo:   repositories.NewEventTypesRepository(),
+	}
+}
+
+// GetDailyReport builds the report of a driver (対象乗務員CD) for one 運行日 (YYYY-MM-DD).
+// The day's trips come from dtako_rows; the timeline holds every event of
+// those trips, so a trip running past midnight is reported in full on its 運行日.
+func (s *DailyReportService) GetDailyReport(ctx context.Context, driverCode, date string) (_ *models.DailyReport, err error) {
+	ctx, span := tracing.Start(ctx, "DailyReportService.GetDailyReport")
+	defer tracing.End(span, &err)
+
+	if driverCode == "" {
+		return nil, fmt.Errorf("%w: driver is required", ErrInvalidReportRequest)
+	}
+	day, err := config.ParseDate(date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidReportRequest)
+	}
+
+	rows, err := s.rowsRepo.GetByDateRange(ctx, day, day, models.ListQuery{
+		Filter: models.ListFilter{"driver": {driverCode}},
+		Sort:   []models.SortField{{Field: "unko_no"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: driver %s on %s", ErrReportNotFound, driverCode, date)
+	}
+
+	unkoNos := make([]string, len(rows))
+	for i := range rows {
+		unkoNos[i] = rows[i].UnkoNo
+	}
+
+	timeline, err := s.eventsRepo.GetTimelineByUnkoNos(ctx, unkoNos)
+	if err != nil {
+		return nil, err
+	}
+	ferries, err := s.ferryRepo.GetByDateRange(ctx, day, day, models.ListQuery{
+		Filter: models.ListFilter{"unko_no": unkoNos},
+		Sort:   []models.SortField{{Field: "start_time"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := loadMasterNames(ctx, s.mastersRepo)
+	report := &models.DailyReport{
+		Date:        date,
+		Trips:       []models.DailyReportTrip{},
+		Ferries:     []models.DailyReportFerry{},
+		GeneratedAt: config.Now(),
+	}
+	report.DriverName, report.DriverCode = names.driver(driverCode)
+
+	fillTimeline(timeline, loadEventTypes(ctx, s.typesRepo), &report.Totals.EventTimeBreakdown)
+
+	for i := range rows {
+		trip := models.DailyReportTrip{
+			UnkoNo:      rows[i].UnkoNo,
+			VehicleNo:   rows[i].VehicleNo,
+			VehicleName: names.vehicles[rows[i].VehicleNo],
+			Route:       rows[i].RouteCode,
+			Distance:    rows[i].Distance,
+			FuelAmount:  rows[i].FuelAmount,
+		}
+		summarizeTrip(&trip, timeline)
+		report.Trips = append(report.Trips, trip)
+
+		report.Totals.Distance += trip.Distance
+		report.Totals.FuelAmount += trip.FuelAmount
+	}
+	report.Totals.TripCount = len(report.Trips)
+
+	for _, f := range ferries {
+		report.Ferries = append(report.Ferries, models.DailyReportFerry{
+			UnkoNo:       f.UnkoNo,
+			Company:      f.FerryCompanyName,
+			Boarding:     f.BoardingName,
+			Landing:      f.LandingName,
+			StartAt:      f.StartTime,
+			EndAt:        f.EndTime,
+			ContractFare: f.ContractFare,
+		})
+	}
+	report.Totals.FerryCount = len(report.Ferries)
+
+	sort.SliceStable(timeline, func(i, j int) bool { return timeline[i].StartAt.Before(timeline[j].StartAt) })
+	report.Timeline = timeline
+	if len(timeline) > 0 {
+		first, last := timeline[0].StartAt, eventEnd(&timeline[0])
+		for i := range timeline {
+			if end := eventEnd(&timeline[i]); end.After(last) {
+				last = end
+			}
+		}
+		report.Totals.BindingMinutes = last.Sub(first).Minutes()
+	}
+
+	return report, nil
+}
+
+// fillTimeline sets the category and duration of each event and adds the
+// durations to b. timeline must be ordered by trip and start time. An event
+// without 終了日時 lasts until the next event of the same trip.
+func fillTimeline(timeline []models.DailyReportEvent, registry *eventTypeRegistry, b *models.EventTimeBreakdown) {
+	unregistered := map[string]bool{}
+	for i := range timeline {
+		e := &timeline[i]
+		category, ok := registry.category(e.EventType)
+		if !ok {
+			category = models.EventCategoryOther
+			if !unregistered[e.EventType] {
+				unregistered[e.EventType] = true
+				b.Unregistered = append(b.Unregistered, e.EventType)
+			}
+		}
+		e.Category = category
+
+		switch {
+		case e.EndAt != nil && e.EndAt.After(e.StartAt):
+			e.Minutes = e.EndAt.Sub(e.StartAt).Minutes()
+		case i+1 < len(timeline) && timeline[i+1].UnkoNo == e.UnkoNo && timeline[i+1].StartAt.After(e.StartAt):
+			e.Minutes = timeline[i+1].StartAt.Sub(e.StartAt).Minutes()
+		}
+		addCategoryMinutes(b, category, e.Minutes)
+	}
+}
+
+// summarizeTrip fills the start/end time, places and odometer readings of a
+// trip from its events
+func summarizeTrip(trip *models.DailyReportTrip, timeline []models.DailyReportEvent) {
+	for i := range timeline {
+		e := &timeline[i]
+		if e.UnkoNo != trip.UnkoNo {
+			continue
+		}
+		if trip.StartAt == nil {
+			start := e.StartAt
+			trip.StartAt = &start
+			trip.StartPlace = e.StartPlace
+		}
+		end := eventEnd(e)
+		if trip.EndAt == nil || !end.Before(*trip.EndAt) {
+			trip.EndAt = &end
+			trip.EndPlace = e.EndPlace
+		}
+		if e.StartOdometer != nil && (trip.StartOdometer == nil || *e.StartOdometer < *trip.StartOdometer) {
+			trip.StartOdometer = e.StartOdometer
+		}
+		if e.EndOdometer != nil && (trip.EndOdometer == nil || *e.EndOdometer > *trip.EndOdometer) {
+			trip.EndOdometer = e.EndOdometer
+		}
+	}
+}
+
+// eventEnd returns 終了日時, or the start time plus the computed duration
+func eventEnd(e *models.DailyReportEvent) time.Time {
+	if e.EndAt != nil && e.EndAt.After(e.StartAt) {
+		return *e.EndAt
+	}
+	return e.StartAt.Add(time.Duration(e.Minutes * float64(time.Minute)))
+}
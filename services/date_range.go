@@ -0,0 +1,40 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/apperr"
+	"github.com/yhonda-ohishi/dtako_mod/config"
+)
+
+// ErrInvalidDateRange is returned for a malformed date or a from date after the to date
+var ErrInvalidDateRange = apperr.New(apperr.Validation, "invalid date range")
+
+// parseDateRange parses optional YYYY-MM-DD query dates.
+// Dates are midnight in the business time zone; a missing from defaults to
+// one month ago and a missing to defaults to today.
+func parseDateRange(from, to string) (time.Time, time.Time, error) {
+	var fromDate, toDate time.Time
+	var err error
+
+	if from != "" {
+		fromDate, err = config.ParseDate(from)
+		if err != nil {
+			return fromDate, toDate, fmt.Errorf("%w: invalid from date: %v", ErrInvalidDateRange, err)
+		}
+	} else {
+		fromDate = config.Today().AddDate(0, -1, 0)
+	}
+
+	if to != "" {
+		toDate, err = config.ParseDate(to)
+		if err != nil {
+			return fromDate, toDate, fmt.Errorf("%w: invalid to date: %v", ErrInvalidDateRange, err)
+		}
+	} else {
+		toDate = config.Today()
+	}
+
+	return fromDate, toDate, nil
+}
@@ -1,127 +1,191 @@
 package services
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/yhonda-ohishi/dtako_mod/apperr"
+	"github.com/yhonda-ohishi/dtako_mod/config"
 	"github.com/yhonda-ohishi/dtako_mod/models"
 	"github.com/yhonda-ohishi/dtako_mod/repositories"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
 )
 
+// ErrEventNotFound is returned when a dtako_events id does not exist
+var ErrEventNotFound = apperr.New(apperr.NotFound, "event not found")
+
 // DtakoEventsService handles business logic for dtako_events
 type DtakoEventsService struct {
-	repo *repositories.DtakoEventsRepository
+	repo         *repositories.DtakoEventsRepository
+	validator    *Validator[models.DtakoEvent]
+	findingsRepo *repositories.ValidationFindingsRepository
+	mastersRepo  *repositories.MastersRepository
+	typesRepo    *repositories.EventTypesRepository
+	payrollRepo  *repositories.PayrollRepository
+	audit        *auditTrail
 }
 
 // NewDtakoEventsService creates a new service instance
 func NewDtakoEventsService() *DtakoEventsService {
+	typesRepo := repositories.NewEventTypesRepository()
 	return &DtakoEventsService{
-		repo: repositories.NewDtakoEventsRepository(),
+		repo:         repositories.NewDtakoEventsRepository(),
+		validator:    newEventsValidator(repositories.NewDtakoRowsRepository(), typesRepo),
+		findingsRepo: repositories.NewValidationFindingsRepository(),
+		mastersRepo:  repositories.NewMastersRepository(),
+		typesRepo:    typesRepo,
+		payrollRepo:  repositories.NewPayrollRepository(),
+		audit:        newAuditTrail(),
 	}
 }
 
-// GetEvents retrieves events within date range and optional type filter
-func (s *DtakoEventsService) GetEvents(from, to, eventType, unkoNo string) ([]models.DtakoEvent, error) {
-	// Parse dates if provided
-	var fromDate, toDate time.Time
-	var err error
+// GetEvents retrieves events within date range matching q
+func (s *DtakoEventsService) GetEvents(ctx context.Context, from, to string, q models.ListQuery) (_ []models.DtakoEvent, err error) {
+	ctx, span := tracing.Start(ctx, "DtakoEventsService.GetEvents", tracing.Period(from, to)...)
+	defer tracing.End(span, &err)
 
-	if from != "" {
-		fromDate, err = time.Parse("2006-01-02", from)
-		if err != nil {
-			return nil, fmt.Errorf("invalid from date: %v", err)
-		}
-	} else {
-		fromDate = time.Now().AddDate(0, -1, 0)
+	fromDate, toDate, err := parseDateRange(from, to)
+	if err != nil {
+		return nil, err
 	}
 
-	if to != "" {
-		toDate, err = time.Parse("2006-01-02", to)
-		if err != nil {
-			return nil, fmt.Errorf("invalid to date: %v", err)
-		}
-	} else {
-		toDate = time.Now()
+	events, err := s.repo.GetByDateRange(ctx, fromDate, toDate, withoutNameFields(q))
+	if err != nil {
+		return nil, err
 	}
 
-	return s.repo.GetByDateRange(fromDate, toDate, eventType, unkoNo)
+	names := loadMasterNames(ctx, s.mastersRepo)
+	for i := range events {
+		names.enrichEvent(&events[i])
+	}
+	return events, nil
+}
+
+// StreamEvents passes events within date range matching q to fn one at a time
+func (s *DtakoEventsService) StreamEvents(ctx context.Context, from, to string, q models.ListQuery, fn func(*models.DtakoEvent) error) (err error) {
+	ctx, span := tracing.Start(ctx, "DtakoEventsService.StreamEvents", tracing.Period(from, to)...)
+	defer tracing.End(span, &err)
+
+	fromDate, toDate, err := parseDateRange(from, to)
+	if err != nil {
+		return err
+	}
+
+	names := loadMasterNames(ctx, s.mastersRepo)
+	return s.repo.StreamByDateRange(ctx, fromDate, toDate, withoutNameFields(q), func(event *models.DtakoEvent) error {
+		names.enrichEvent(event)
+		return fn(event)
+	})
 }
 
 // GetEventByID retrieves a specific event by ID
-func (s *DtakoEventsService) GetEventByID(id string) (*models.DtakoEvent, error) {
-	event, err := s.repo.GetByID(id)
+func (s *DtakoEventsService) GetEventByID(ctx context.Context, id string) (_ *models.DtakoEvent, err error) {
+	ctx, span := tracing.Start(ctx, "DtakoEventsService.GetEventByID")
+	defer tracing.End(span, &err)
+
+	event, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("event not found: %s", id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrEventNotFound, id)
 		}
 		return nil, err
 	}
+
+	loadMasterNames(ctx, s.mastersRepo).enrichEvent(event)
 	return event, nil
 }
 
 // ImportFromProduction imports event data from production database
-func (s *DtakoEventsService) ImportFromProduction(fromDate, toDate, eventType string) (*models.ImportResult, error) {
+func (s *DtakoEventsService) ImportFromProduction(ctx context.Context, fromDate, toDate, eventType string) (result *models.ImportResult, err error) {
+	ctx, span := tracing.Start(ctx, "DtakoEventsService.ImportFromProduction", tracing.Period(fromDate, toDate)...)
+	defer tracing.End(span, &err)
+	defer func() {
+		s.audit.record(ctx, models.AuditEventsImport, "dtako_events", models.ImportRequest{FromDate: fromDate, ToDate: toDate, EventType: eventType}, result, err)
+	}()
+
 	// Parse dates
-	from, err := time.Parse("2006-01-02", fromDate)
+	from, err := config.ParseDate(fromDate)
 	if err != nil {
-		return nil, fmt.Errorf("invalid from date: %v", err)
+		return nil, fmt.Errorf("%w: invalid from date: %v", ErrInvalidDateRange, err)
 	}
 
-	to, err := time.Parse("2006-01-02", toDate)
+	to, err := config.ParseDate(toDate)
 	if err != nil {
-		return nil, fmt.Errorf("invalid to date: %v", err)
+		return nil, fmt.Errorf("%w: invalid to date: %v", ErrInvalidDateRange, err)
 	}
 
 	// Validate date range
 	if from.After(to) {
-		return nil, fmt.Errorf("from_date cannot be after to_date")
+		return nil, fmt.Errorf("%w: from_date cannot be after to_date", ErrInvalidDateRange)
 	}
 
-	// Validate event type if specified
-	validEventTypes := []string{"START", "STOP", "END", "運転", "休憩", "作業"}
+	// Validate event type if specified (dtako_event_types に登録されたイベント名のみ)
 	if eventType != "" {
-		isValid := false
-		for _, validType := range validEventTypes {
-			if eventType == validType {
-				isValid = true
-				break
-			}
-		}
-		if !isValid {
-			return nil, fmt.Errorf("invalid event_type: %s", eventType)
+		if _, ok := loadEventTypes(ctx, s.typesRepo).category(eventType); !ok {
+			return nil, fmt.Errorf("%w: invalid event_type: %s", apperr.ErrValidation, eventType)
 		}
 	}
 
 	// Fetch from production
-	events, err := s.repo.FetchFromProduction(from, to, eventType)
+	start := time.Now()
+	events, err := s.repo.FetchFromProduction(ctx, from, to, eventType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch from production: %v", err)
+		recordImport(ctx, "dtako_events", start, 0, nil, nil, err)
+		return nil, fmt.Errorf("failed to fetch from production: %w", err)
 	}
 
+	// 他のインスタンスでの登録・削除や保持期間処理での行の削除も反映するため、取込ごとに照会し直す
+	registeredEventTypes.clear()
+	knownUnkoNos.clear()
+
 	// Import to local database
 	imported := 0
 	var errors []string
 
+	validation := &importValidation{}
+	retention := newRetentionFilter()
+	months := monthSet{}
 	for _, event := range events {
-		if err := s.repo.Insert(&event); err != nil {
+		if !retention.keep(event.EventDate) {
+			continue
+		}
+		if !validation.record(s.validator.Validate(ctx, &event, event.ID)) {
+			continue
+		}
+		if err := s.repo.Insert(ctx, &event); err != nil {
 			errors = append(errors, fmt.Sprintf("Failed to import event %s: %v", event.ID, err))
 		} else {
 			imported++
+			// 日付をまたぐ運行は前日の運行日に属するため前日の月も対象にする
+			months.add(event.EventDate)
+			months.add(event.EventDate.AddDate(0, 0, -1))
 		}
 	}
+	markPayrollStale(ctx, s.payrollRepo, months)
 
-	result := &models.ImportResult{
+	result = &models.ImportResult{
 		Success:      imported > 0,
 		ImportedRows: imported,
 		Message:      fmt.Sprintf("Imported %d events from %s to %s", imported, fromDate, toDate),
-		ImportedAt:   time.Now(),
+		ImportedAt:   config.Now(),
 		Errors:       errors,
 	}
 
 	if eventType != "" {
 		result.Message += fmt.Sprintf(" (type: %s)", eventType)
 	}
+	retention.apply(result)
+
+	recordImport(ctx, "dtako_events", start, len(events), result, validation, nil)
+	validation.apply(ctx, result, s.findingsRepo.InsertAll)
 
 	return result, nil
-}
\ No newline at end of file
+}
+
+// AddValidationRule registers an extra import validation rule for dtako_events
+func (s *DtakoEventsService) AddValidationRule(rule ValidationRule[models.DtakoEvent]) {
+	s.validator.AddRule(rule)
+}
@@ -1,112 +1,180 @@
 package services
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/yhonda-ohishi/dtako_mod/apperr"
+	"github.com/yhonda-ohishi/dtako_mod/config"
 	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/privacy"
 	"github.com/yhonda-ohishi/dtako_mod/repositories"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
 )
 
+// ErrFerryRowNotFound is returned when a dtako_ferry_rows id does not exist
+var ErrFerryRowNotFound = apperr.New(apperr.NotFound, "ferry row record not found")
+
 // DtakoFerryRowsService handles business logic for dtako_ferry_rows
 type DtakoFerryRowsService struct {
-	repo *repositories.DtakoFerryRowsRepository
+	repo         *repositories.DtakoFerryRowsRepository
+	validator    *Validator[models.DtakoFerryRow]
+	findingsRepo *repositories.ValidationFindingsRepository
+	payrollRepo  *repositories.PayrollRepository
+	audit        *auditTrail
 }
 
 // NewDtakoFerryRowsService creates a new service instance
 func NewDtakoFerryRowsService() *DtakoFerryRowsService {
 	return &DtakoFerryRowsService{
-		repo: repositories.NewDtakoFerryRowsRepository(),
+		repo:         repositories.NewDtakoFerryRowsRepository(),
+		validator:    newFerryRowsValidator(),
+		findingsRepo: repositories.NewValidationFindingsRepository(),
+		payrollRepo:  repositories.NewPayrollRepository(),
+		audit:        newAuditTrail(),
 	}
 }
 
-// GetFerryRows retrieves ferry row records within date range and optional ferry company filter
-func (s *DtakoFerryRowsService) GetFerryRows(from, to, ferryCompany string) ([]models.DtakoFerryRow, error) {
-	// Parse dates if provided
-	var fromDate, toDate time.Time
-	var err error
+// GetFerryRows retrieves ferry row records within date range matching q
+func (s *DtakoFerryRowsService) GetFerryRows(ctx context.Context, from, to string, q models.ListQuery) (_ []models.DtakoFerryRow, err error) {
+	ctx, span := tracing.Start(ctx, "DtakoFerryRowsService.GetFerryRows", tracing.Period(from, to)...)
+	defer tracing.End(span, &err)
 
-	if from != "" {
-		fromDate, err = time.Parse("2006-01-02", from)
-		if err != nil {
-			return nil, fmt.Errorf("invalid from date: %v", err)
-		}
-	} else {
-		fromDate = time.Now().AddDate(0, -1, 0)
+	fromDate, toDate, err := parseDateRange(from, to)
+	if err != nil {
+		return nil, err
 	}
 
-	if to != "" {
-		toDate, err = time.Parse("2006-01-02", to)
-		if err != nil {
-			return nil, fmt.Errorf("invalid to date: %v", err)
-		}
-	} else {
-		toDate = time.Now()
+	records, err := s.repo.GetByDateRange(ctx, fromDate, toDate, q)
+	if err != nil {
+		return nil, err
+	}
+
+	mask := privacy.For(ctx)
+	for i := range records {
+		maskFerryRow(mask, &records[i])
+	}
+	return records, nil
+}
+
+// StreamFerryRows passes ferry row records within date range matching q to fn one at a time
+func (s *DtakoFerryRowsService) StreamFerryRows(ctx context.Context, from, to string, q models.ListQuery, fn func(*models.DtakoFerryRow) error) (err error) {
+	ctx, span := tracing.Start(ctx, "DtakoFerryRowsService.StreamFerryRows", tracing.Period(from, to)...)
+	defer tracing.End(span, &err)
+
+	fromDate, toDate, err := parseDateRange(from, to)
+	if err != nil {
+		return err
 	}
 
-	return s.repo.GetByDateRange(fromDate, toDate, ferryCompany)
+	mask := privacy.For(ctx)
+	return s.repo.StreamByDateRange(ctx, fromDate, toDate, q, func(record *models.DtakoFerryRow) error {
+		maskFerryRow(mask, record)
+		return fn(record)
+	})
 }
 
 // GetFerryRowByID retrieves a specific ferry row record by ID
-func (s *DtakoFerryRowsService) GetFerryRowByID(id string) (*models.DtakoFerryRow, error) {
-	record, err := s.repo.GetByID(id)
+func (s *DtakoFerryRowsService) GetFerryRowByID(ctx context.Context, id string) (_ *models.DtakoFerryRow, err error) {
+	ctx, span := tracing.Start(ctx, "DtakoFerryRowsService.GetFerryRowByID")
+	defer tracing.End(span, &err)
+
+	record, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("ferry row record not found: %s", id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrFerryRowNotFound, id)
 		}
 		return nil, err
 	}
+	maskFerryRow(privacy.For(ctx), record)
 	return record, nil
 }
 
+// maskFerryRow masks 乗務員CD1 and 乗務員名１ the caller may not see
+func maskFerryRow(mask privacy.Mask, record *models.DtakoFerryRow) {
+	record.DriverCode1 = mask.CodeInt(record.DriverCode1)
+	record.DriverName1 = mask.Name(record.DriverName1)
+}
+
 // ImportFromProduction imports ferry row data from production database
-func (s *DtakoFerryRowsService) ImportFromProduction(fromDate, toDate, ferryCompany string) (*models.ImportResult, error) {
+func (s *DtakoFerryRowsService) ImportFromProduction(ctx context.Context, fromDate, toDate, ferryCompany string) (result *models.ImportResult, err error) {
+	ctx, span := tracing.Start(ctx, "DtakoFerryRowsService.ImportFromProduction", tracing.Period(fromDate, toDate)...)
+	defer tracing.End(span, &err)
+	defer func() {
+		s.audit.record(ctx, models.AuditFerryRowsImport, "dtako_ferry_rows", models.ImportRequest{FromDate: fromDate, ToDate: toDate, FerryCompany: ferryCompany}, result, err)
+	}()
+
 	// Parse dates
-	from, err := time.Parse("2006-01-02", fromDate)
+	from, err := config.ParseDate(fromDate)
 	if err != nil {
-		return nil, fmt.Errorf("invalid from date: %v", err)
+		return nil, fmt.Errorf("%w: invalid from date: %v", ErrInvalidDateRange, err)
 	}
 
-	to, err := time.Parse("2006-01-02", toDate)
+	to, err := config.ParseDate(toDate)
 	if err != nil {
-		return nil, fmt.Errorf("invalid to date: %v", err)
+		return nil, fmt.Errorf("%w: invalid to date: %v", ErrInvalidDateRange, err)
 	}
 
 	// Validate date range
 	if from.After(to) {
-		return nil, fmt.Errorf("from_date cannot be after to_date")
+		return nil, fmt.Errorf("%w: from_date cannot be after to_date", ErrInvalidDateRange)
 	}
 
 	// Fetch from production
-	records, err := s.repo.FetchFromProduction(from, to, ferryCompany)
+	start := time.Now()
+	records, err := s.repo.FetchFromProduction(ctx, from, to, ferryCompany)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch from production: %v", err)
+		recordImport(ctx, "dtako_ferry_rows", start, 0, nil, nil, err)
+		return nil, fmt.Errorf("failed to fetch from production: %w", err)
 	}
 
 	// Import to local database
 	imported := 0
 	var errors []string
 
+	validation := &importValidation{}
+	retention := newRetentionFilter()
+	months := monthSet{}
 	for _, record := range records {
-		if err := s.repo.Insert(&record); err != nil {
+		if !retention.keep(record.UnkoDate) {
+			continue
+		}
+		if !validation.record(s.validator.Validate(ctx, &record, strconv.Itoa(record.ID))) {
+			continue
+		}
+		if err := s.repo.Insert(ctx, &record); err != nil {
 			errors = append(errors, fmt.Sprintf("Failed to import ferry row record %d: %v", record.ID, err))
 		} else {
 			imported++
+			months.add(record.UnkoDate)
 		}
 	}
+	markPayrollStale(ctx, s.payrollRepo, months)
 
-	result := &models.ImportResult{
+	result = &models.ImportResult{
 		Success:      imported > 0,
 		ImportedRows: imported,
 		Message:      fmt.Sprintf("Imported %d ferry row records from %s to %s", imported, fromDate, toDate),
-		ImportedAt:   time.Now(),
+		ImportedAt:   config.Now(),
 		Errors:       errors,
 	}
 
 	if ferryCompany != "" {
 		result.Message += fmt.Sprintf(" (ferry company: %s)", ferryCompany)
 	}
+	retention.apply(result)
+
+	recordImport(ctx, "dtako_ferry_rows", start, len(records), result, validation, nil)
+	validation.apply(ctx, result, s.findingsRepo.InsertAll)
 
 	return result, nil
-}
\ No newline at end of file
+}
+
+// AddValidationRule registers an extra import validation rule for dtako_ferry_rows
+func (s *DtakoFerryRowsService) AddValidationRule(rule ValidationRule[models.DtakoFerryRow]) {
+	s.validator.AddRule(rule)
+}
@@ -1,108 +1,201 @@
 package services
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/yhonda-ohishi/dtako_mod/apperr"
+	"github.com/yhonda-ohishi/dtako_mod/config"
 	"github.com/yhonda-ohishi/dtako_mod/models"
 	"github.com/yhonda-ohishi/dtako_mod/repositories"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
 )
 
+// ErrRowNotFound is returned when a dtako_rows id does not exist
+var ErrRowNotFound = apperr.New(apperr.NotFound, "row not found")
+
 // DtakoRowsService handles business logic for dtako_rows
 type DtakoRowsService struct {
-	repo *repositories.DtakoRowsRepository
+	repo         *repositories.DtakoRowsRepository
+	validator    *Validator[models.DtakoRow]
+	findingsRepo *repositories.ValidationFindingsRepository
+	mastersRepo  *repositories.MastersRepository
+	payrollRepo  *repositories.PayrollRepository
+	audit        *auditTrail
 }
 
 // NewDtakoRowsService creates a new service instance
 func NewDtakoRowsService() *DtakoRowsService {
 	return &DtakoRowsService{
-		repo: repositories.NewDtakoRowsRepository(),
+		repo:         repositories.NewDtakoRowsRepository(),
+		validator:    newRowsValidator(),
+		findingsRepo: repositories.NewValidationFindingsRepository(),
+		mastersRepo:  repositories.NewMastersRepository(),
+		payrollRepo:  repositories.NewPayrollRepository(),
+		audit:        newAuditTrail(),
 	}
 }
 
-// GetRows retrieves rows within date range
-func (s *DtakoRowsService) GetRows(from, to string) ([]models.DtakoRow, error) {
-	// Parse dates if provided
-	var fromDate, toDate time.Time
-	var err error
+// GetRows retrieves rows within date range matching q
+func (s *DtakoRowsService) GetRows(ctx context.Context, from, to string, q models.ListQuery) (_ []models.DtakoRow, err error) {
+	ctx, span := tracing.Start(ctx, "DtakoRowsService.GetRows", tracing.Period(from, to)...)
+	defer tracing.End(span, &err)
 
-	if from != "" {
-		fromDate, err = time.Parse("2006-01-02", from)
-		if err != nil {
-			return nil, fmt.Errorf("invalid from date: %v", err)
-		}
-	} else {
-		fromDate = time.Now().AddDate(0, -1, 0)
+	fromDate, toDate, err := parseDateRange(from, to)
+	if err != nil {
+		return nil, err
 	}
 
-	if to != "" {
-		toDate, err = time.Parse("2006-01-02", to)
-		if err != nil {
-			return nil, fmt.Errorf("invalid to date: %v", err)
-		}
-	} else {
-		toDate = time.Now()
+	rows, err := s.repo.GetByDateRange(ctx, fromDate, toDate, withoutNameFields(q))
+	if err != nil {
+		return nil, err
+	}
+
+	names := loadMasterNames(ctx, s.mastersRepo)
+	for i := range rows {
+		names.enrichRow(&rows[i])
+	}
+	return rows, nil
+}
+
+// StreamRows passes rows within date range matching q to fn one at a time
+func (s *DtakoRowsService) StreamRows(ctx context.Context, from, to string, q models.ListQuery, fn func(*models.DtakoRow) error) (err error) {
+	ctx, span := tracing.Start(ctx, "DtakoRowsService.StreamRows", tracing.Period(from, to)...)
+	defer tracing.End(span, &err)
+
+	fromDate, toDate, err := parseDateRange(from, to)
+	if err != nil {
+		return err
 	}
 
-	return s.repo.GetByDateRange(fromDate, toDate)
+	names := loadMasterNames(ctx, s.mastersRepo)
+	return s.repo.StreamByDateRange(ctx, fromDate, toDate, withoutNameFields(q), func(row *models.DtakoRow) error {
+		names.enrichRow(row)
+		return fn(row)
+	})
 }
 
 // GetRowByID retrieves a specific row by ID
-func (s *DtakoRowsService) GetRowByID(id string) (*models.DtakoRow, error) {
-	row, err := s.repo.GetByID(id)
+func (s *DtakoRowsService) GetRowByID(ctx context.Context, id string) (_ *models.DtakoRow, err error) {
+	ctx, span := tracing.Start(ctx, "DtakoRowsService.GetRowByID")
+	defer tracing.End(span, &err)
+
+	row, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("row not found: %s", id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrRowNotFound, id)
 		}
 		return nil, err
 	}
+
+	loadMasterNames(ctx, s.mastersRepo).enrichRow(row)
 	return row, nil
 }
 
 // ImportFromProduction imports data from production database
-func (s *DtakoRowsService) ImportFromProduction(fromDate, toDate string) (*models.ImportResult, error) {
+func (s *DtakoRowsService) ImportFromProduction(ctx context.Context, fromDate, toDate string) (result *models.ImportResult, err error) {
+	ctx, span := tracing.Start(ctx, "DtakoRowsService.ImportFromProduction", tracing.Period(fromDate, toDate)...)
+	defer tracing.End(span, &err)
+	defer func() {
+		s.audit.record(ctx, models.AuditRowsImport, "dtako_rows", models.ImportRequest{FromDate: fromDate, ToDate: toDate}, result, err)
+	}()
+
 	// Parse dates
-	from, err := time.Parse("2006-01-02", fromDate)
+	from, err := config.ParseDate(fromDate)
 	if err != nil {
-		return nil, fmt.Errorf("invalid from date: %v", err)
+		return nil, fmt.Errorf("%w: invalid from date: %v", ErrInvalidDateRange, err)
 	}
 
-	to, err := time.Parse("2006-01-02", toDate)
+	to, err := config.ParseDate(toDate)
 	if err != nil {
-		return nil, fmt.Errorf("invalid to date: %v", err)
+		return nil, fmt.Errorf("%w: invalid to date: %v", ErrInvalidDateRange, err)
 	}
 
 	// Validate date range
 	if from.After(to) {
-		return nil, fmt.Errorf("from_date cannot be after to_date")
+		return nil, fmt.Errorf("%w: from_date cannot be after to_date", ErrInvalidDateRange)
 	}
 
 	// Fetch from production
-	rows, err := s.repo.FetchFromProduction(from, to)
+	start := time.Now()
+	rows, err := s.repo.FetchFromProduction(ctx, from, to)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch from production: %v", err)
+		recordImport(ctx, "dtako_rows", start, 0, nil, nil, err)
+		return nil, fmt.Errorf("failed to fetch from production: %w", err)
 	}
 
-	// Import to local database
+	retention := newRetentionFilter()
+	result, validation := s.importRows(ctx, rows, retention)
+	recordImport(ctx, "dtako_rows", start, len(rows), result, validation, nil)
+	result.Message = fmt.Sprintf("Imported %d rows from %s to %s", result.ImportedRows, fromDate, toDate)
+	retention.apply(result)
+	validation.apply(ctx, result, s.findingsRepo.InsertAll)
+
+	return result, nil
+}
+
+// ImportByUnkoNos imports the rows with the given 運行NO from production,
+// e.g. to restore parents of orphaned events
+func (s *DtakoRowsService) ImportByUnkoNos(ctx context.Context, unkoNos []string) (_ *models.ImportResult, err error) {
+	ctx, span := tracing.Start(ctx, "DtakoRowsService.ImportByUnkoNos")
+	defer tracing.End(span, &err)
+
+	start := time.Now()
+	rows, err := s.repo.FetchFromProductionByUnkoNos(ctx, unkoNos)
+	if err != nil {
+		recordImport(ctx, "dtako_rows", start, 0, nil, nil, err)
+		return nil, fmt.Errorf("failed to fetch from production: %w", err)
+	}
+
+	retention := newRetentionFilter()
+	result, validation := s.importRows(ctx, rows, retention)
+	recordImport(ctx, "dtako_rows", start, len(rows), result, validation, nil)
+	result.Message = fmt.Sprintf("Imported %d of %d missing rows", result.ImportedRows, len(unkoNos))
+	retention.apply(result)
+	validation.apply(ctx, result, s.findingsRepo.InsertAll)
+
+	return result, nil
+}
+
+// importRows validates and inserts rows into local database, skipping rows
+// the retention filter does not keep. The caller sets the message and then
+// applies the retention filter and the returned validation.
+func (s *DtakoRowsService) importRows(ctx context.Context, rows []models.DtakoRow, retention *retentionFilter) (*models.ImportResult, *importValidation) {
 	imported := 0
 	var errors []string
 
+	validation := &importValidation{}
+	months := monthSet{}
 	for _, row := range rows {
-		if err := s.repo.Insert(&row); err != nil {
+		if !retention.keep(row.Date) {
+			continue
+		}
+		if !validation.record(s.validator.Validate(ctx, &row, row.ID)) {
+			continue
+		}
+		if err := s.repo.Insert(ctx, &row); err != nil {
 			errors = append(errors, fmt.Sprintf("Failed to import row %s: %v", row.ID, err))
 		} else {
 			imported++
+			months.add(row.Date)
 		}
 	}
+	markPayrollStale(ctx, s.payrollRepo, months)
 
 	result := &models.ImportResult{
 		Success:      imported > 0,
 		ImportedRows: imported,
-		Message:      fmt.Sprintf("Imported %d rows from %s to %s", imported, fromDate, toDate),
-		ImportedAt:   time.Now(),
+		ImportedAt:   config.Now(),
 		Errors:       errors,
 	}
 
-	return result, nil
-}
\ No newline at end of file
+	return result, validation
+}
+
+// AddValidationRule registers an extra import validation rule for dtako_rows
+func (s *DtakoRowsService) AddValidationRule(rule ValidationRule[models.DtakoRow]) {
+	s.validator.AddRule(rule)
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/privacy"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// GetRowStats aggregates distance and fuel of rows within date range.
+// With comparePrevious the same statistics are computed for the period of
+// equal length immediately before from, and attached per group.
+func (s *DtakoRowsService) GetRowStats(ctx context.Context, from, to, groupBy string, filter models.ListFilter, comparePrevious bool) (_ *models.RowStats, err error) {
+	ctx, span := tracing.Start(ctx, "DtakoRowsService.GetRowStats", tracing.Period(from, to)...)
+	defer tracing.End(span, &err)
+
+	fromDate, toDate, err := parseDateRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := s.repo.Stats(ctx, fromDate, toDate, groupBy, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.RowStats{
+		From:    fromDate.Format("2006-01-02"),
+		To:      toDate.Format("2006-01-02"),
+		GroupBy: groupBy,
+		Total:   models.RowStatsGroup{RowStatsValues: sumRowStats(groups)},
+		Groups:  groups,
+	}
+
+	if !comparePrevious {
+		maskDriverKeys(ctx, stats)
+		return stats, nil
+	}
+
+	// 前期間: 同じ日数だけ遡った期間
+	days := int(toDate.Sub(fromDate).Hours()/24) + 1
+	prevTo := fromDate.AddDate(0, 0, -1)
+	prevFrom := prevTo.AddDate(0, 0, -(days - 1))
+
+	prevGroups, err := s.repo.Stats(ctx, prevFrom, prevTo, groupBy, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.PreviousFrom = prevFrom.Format("2006-01-02")
+	stats.PreviousTo = prevTo.Format("2006-01-02")
+
+	compareGroups(groupBy, stats.Groups, prevGroups, fromDate, prevFrom)
+	attachPrevious(&stats.Total, sumRowStats(prevGroups))
+	maskDriverKeys(ctx, stats)
+
+	return stats, nil
+}
+
+// compareGroups attaches the previous period values to each group.
+// Vehicle, driver and office groups are matched by key; day and month
+// groups by their offset from the start of the period, so the first day of
+// the period is compared with the first day of the previous period.
+func compareGroups(groupBy string, groups, prevGroups []models.RowStatsGroup, from, prevFrom time.Time) {
+	prevByKey := make(map[string]models.RowStatsValues, len(prevGroups))
+	for _, g := range prevGroups {
+		prevByKey[g.Key] = g.RowStatsValues
+	}
+	for i := range groups {
+		attachPrevious(&groups[i], prevByKey[previousKey(groupBy, groups[i].Key, from, prevFrom)])
+	}
+}
+
+// previousKey returns the key of the previous period group compared with key
+func previousKey(groupBy, key string, from, prevFrom time.Time) string {
+	switch groupBy {
+	case "day":
+		day, err := time.ParseInLocation("2006-01-02", key, from.Location())
+		if err != nil {
+			return key
+		}
+		offset := int(day.Sub(from).Round(24*time.Hour) / (24 * time.Hour))
+		return prevFrom.AddDate(0, 0, offset).Format("2006-01-02")
+	case "month":
+		month, err := time.ParseInLocation("2006-01", key, from.Location())
+		if err != nil {
+			return key
+		}
+		offset := (month.Year()-from.Year())*12 + int(month.Month()-from.Month())
+		prevMonth := time.Date(prevFrom.Year(), prevFrom.Month(), 1, 0, 0, 0, 0, prevFrom.Location())
+		return prevMonth.AddDate(0, offset, 0).Format("2006-01")
+	}
+	return key
+}
+
+// maskDriverKeys replaces driver group keys the caller of ctx may not see by
+// their pseudonyms. Called after the previous period is matched by raw key.
+func maskDriverKeys(ctx context.Context, stats *models.RowStats) {
+	if stats.GroupBy != "driver" {
+		return
+	}
+	mask := privacy.For(ctx)
+	for i := range stats.Groups {
+		stats.Groups[i].Key = mask.Code(stats.Groups[i].Key)
+	}
+}
+
+// sumRowStats combines per-group values into overall totals
+func sumRowStats(groups []models.RowStatsGroup) models.RowStatsValues {
+	var total models.RowStatsValues
+	for _, g := range groups {
+		total.Trips += g.Trips
+		total.TotalDistance += g.TotalDistance
+		total.TotalFuel += g.TotalFuel
+	}
+	if total.Trips > 0 {
+		total.AvgDistance = total.TotalDistance / float64(total.Trips)
+		total.AvgFuel = total.TotalFuel / float64(total.Trips)
+	}
+	if total.TotalFuel != 0 {
+		efficiency := total.TotalDistance / total.TotalFuel
+		total.FuelEfficiency = &efficiency
+	}
+	return total
+}
+
+// attachPrevious sets the previous period values and the change against them
+func attachPrevious(g *models.RowStatsGroup, prev models.RowStatsValues) {
+	g.Previous = &prev
+	g.Change = &models.RowStatsChange{
+		Trips:       g.Trips - prev.Trips,
+		DistancePct: percentChange(g.TotalDistance, prev.TotalDistance),
+		FuelPct:     percentChange(g.TotalFuel, prev.TotalFuel),
+	}
+	if g.FuelEfficiency != nil && prev.FuelEfficiency != nil {
+		g.Change.FuelEfficiencyPct = percentChange(*g.FuelEfficiency, *prev.FuelEfficiency)
+	}
+}
+
+// percentChange returns the change from prev to cur in percent, or nil when prev is zero
+func percentChange(cur, prev float64) *float64 {
+	if prev == 0 {
+		return nil
+	}
+	pct := (cur - prev) / prev * 100
+	return &pct
+}
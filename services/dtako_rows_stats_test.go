@@ -0,0 +1,91 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+func statsGroup(key string, trips int, distance, fuel float64) models.RowStatsGroup {
+	return models.RowStatsGroup{Key: key, RowStatsValues: models.RowStatsValues{Trips: trips, TotalDistance: distance, TotalFuel: fuel}}
+}
+
+func TestCompareGroupsAlignsTimeBuckets(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		name      string
+		groupBy   string
+		from      string
+		prevFrom  string
+		groups    []models.RowStatsGroup
+		prev      []models.RowStatsGroup
+		wantTrips []int // change in trips per group
+	}{
+		{
+			name:     "day",
+			groupBy:  "day",
+			from:     "2025-01-04",
+			prevFrom: "2025-01-01",
+			groups:   []models.RowStatsGroup{statsGroup("2025-01-04", 5, 500, 100), statsGroup("2025-01-06", 2, 100, 50)},
+			prev:     []models.RowStatsGroup{statsGroup("2025-01-01", 4, 400, 80), statsGroup("2025-01-03", 4, 200, 40)},
+			// 01-04 ↔ 01-01, 01-06 ↔ 01-03
+			wantTrips: []int{1, -2},
+		},
+		{
+			name:     "month",
+			groupBy:  "month",
+			from:     "2025-01-01",
+			prevFrom: "2024-11-03",
+			groups:   []models.RowStatsGroup{statsGroup("2025-01", 10, 1000, 200), statsGroup("2025-02", 8, 900, 150)},
+			prev:     []models.RowStatsGroup{statsGroup("2024-11", 6, 800, 160), statsGroup("2024-12", 12, 1200, 300)},
+			// 2025-01 ↔ 2024-11, 2025-02 ↔ 2024-12
+			wantTrips: []int{4, -4},
+		},
+		{
+			name:      "vehicle",
+			groupBy:   "vehicle",
+			from:      "2025-01-04",
+			prevFrom:  "2025-01-01",
+			groups:    []models.RowStatsGroup{statsGroup("101", 3, 300, 60)},
+			prev:      []models.RowStatsGroup{statsGroup("102", 9, 900, 90), statsGroup("101", 1, 100, 20)},
+			wantTrips: []int{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compareGroups(tt.groupBy, tt.groups, tt.prev, date(tt.from), date(tt.prevFrom))
+			for i, g := range tt.groups {
+				if g.Previous == nil || g.Change == nil {
+					t.Fatalf("%s: previous period not attached", g.Key)
+				}
+				if g.Change.Trips != tt.wantTrips[i] {
+					t.Errorf("%s: trips change = %d, want %d", g.Key, g.Change.Trips, tt.wantTrips[i])
+				}
+				if g.Change.DistancePct == nil || g.Change.FuelPct == nil {
+					t.Errorf("%s: expected distance and fuel change, got %+v", g.Key, g.Change)
+				}
+			}
+		})
+	}
+}
+
+func TestCompareGroupsWithoutPreviousBucket(t *testing.T) {
+	from, _ := time.Parse("2006-01-02", "2025-01-04")
+	prevFrom, _ := time.Parse("2006-01-02", "2025-01-01")
+	groups := []models.RowStatsGroup{statsGroup("2025-01-05", 3, 300, 60)}
+
+	compareGroups("day", groups, []models.RowStatsGroup{statsGroup("2025-01-01", 1, 100, 20)}, from, prevFrom)
+
+	if groups[0].Change.Trips != 3 || groups[0].Change.DistancePct != nil {
+		t.Errorf("expected comparison against an empty bucket, got %+v", groups[0].Change)
+	}
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/apperr"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/repositories"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// ErrTripNotFound is returned when a 運行NO has no events
+var ErrTripNotFound = apperr.New(apperr.NotFound, "trip not found")
+
+// DtakoTripsService assembles per-trip views from dtako_events
+type DtakoTripsService struct {
+	eventsRepo *repositories.DtakoEventsRepository
+	typesRepo  *repositories.EventTypesRepository
+}
+
+// NewDtakoTripsService creates a new service instance
+func NewDtakoTripsService() *DtakoTripsService {
+	return &DtakoTripsService{
+		eventsRepo: repositories.NewDtakoEventsRepository(),
+		typesRepo:  repositories.NewEventTypesRepository(),
+	}
+}
+
+// trackPoint is an event with a usable GPS position
+type trackPoint struct {
+	event *models.DtakoEvent
+	lat   float64
+	lng   float64
+}
+
+// getTrackPoints loads the events of a trip in time order and drops those without a valid position
+func (s *DtakoTripsService) getTrackPoints(ctx context.Context, unkoNo string) ([]trackPoint, error) {
+	events, err := s.eventsRepo.GetByUnkoNo(ctx, unkoNo)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTripNotFound, unkoNo)
+	}
+
+	points := []trackPoint{}
+	for i := range events {
+		lat, lng, ok := validPosition(&events[i])
+		if !ok {
+			continue
+		}
+		points = append(points, trackPoint{event: &events[i], lat: lat, lng: lng})
+	}
+	return points, nil
+}
+
+// validPosition returns the event position when it is present and plausible.
+// (0,0) is what the tachograph records when it has no GPS fix, so it is treated as missing.
+func validPosition(event *models.DtakoEvent) (float64, float64, bool) {
+	if event.Latitude == nil || event.Longitude == nil {
+		return 0, 0, false
+	}
+	lat, lng := *event.Latitude, *event.Longitude
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return 0, 0, false
+	}
+	if lat == 0 && lng == 0 {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
+// GetTrackGeoJSON returns the trip route as a FeatureCollection containing a
+// LineString of the path followed by one Point per event
+func (s *DtakoTripsService) GetTrackGeoJSON(ctx context.Context, unkoNo string) (_ *models.GeoJSONFeatureCollection, err error) {
+	ctx, span := tracing.Start(ctx, "DtakoTripsService.GetTrackGeoJSON")
+	defer tracing.End(span, &err)
+
+	points, err := s.getTrackPoints(ctx, unkoNo)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := &models.GeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: []models.GeoJSONFeature{},
+	}
+
+	// LineString needs at least two positions
+	if len(points) >= 2 {
+		path := make([][]float64, 0, len(points))
+		for _, p := range points {
+			path = append(path, []float64{p.lng, p.lat})
+		}
+		collection.Features = append(collection.Features, models.GeoJSONFeature{
+			Type:     "Feature",
+			Geometry: models.GeoJSONGeometry{Type: "LineString", Coordinates: path},
+			Properties: map[string]interface{}{
+				"unko_no":    unkoNo,
+				"start_time": points[0].event.EventDate.Format(time.RFC3339),
+				"end_time":   points[len(points)-1].event.EventDate.Format(time.RFC3339),
+			},
+		})
+	}
+
+	for _, p := range points {
+		collection.Features = append(collection.Features, models.GeoJSONFeature{
+			Type:     "Feature",
+			Geometry: models.GeoJSONGeometry{Type: "Point", Coordinates: []float64{p.lng, p.lat}},
+			Properties: map[string]interface{}{
+				"id":          p.event.ID,
+				"event_type":  p.event.EventType,
+				"time":        p.event.EventDate.Format(time.RFC3339),
+				"description": p.event.Description,
+			},
+		})
+	}
+
+	return collection, nil
+}
+
+// GetTrackGPX returns the trip route as a GPX document with one waypoint per
+// event and a single track segment for the path
+func (s *DtakoTripsService) GetTrackGPX(ctx context.Context, unkoNo string) (_ *models.GPX, err error) {
+	ctx, span := tracing.Start(ctx, "DtakoTripsService.GetTrackGPX")
+	defer tracing.End(span, &err)
+
+	points, err := s.getTrackPoints(ctx, unkoNo)
+	if err != nil {
+		return nil, err
+	}
+
+	gpx := &models.GPX{
+		Xmlns:     "http://www.topografix.com/GPX/1/1",
+		Version:   "1.1",
+		Creator:   "dtako_mod",
+		Metadata:  models.GPXMetadata{Name: unkoNo},
+		Waypoints: []models.GPXWaypoint{},
+	}
+
+	segment := models.GPXTrackSegment{Points: []models.GPXWaypoint{}}
+	for _, p := range points {
+		gpx.Waypoints = append(gpx.Waypoints, models.GPXWaypoint{
+			Lat:         p.lat,
+			Lon:         p.lng,
+			Time:        p.event.EventDate,
+			Name:        p.event.EventType,
+			Description: p.event.Description,
+			Type:        p.event.EventType,
+		})
+		segment.Points = append(segment.Points, models.GPXWaypoint{
+			Lat:  p.lat,
+			Lon:  p.lng,
+			Time: p.event.EventDate,
+		})
+	}
+
+	if len(points) > 0 {
+		start := points[0].event.EventDate
+		gpx.Metadata.Time = &start
+	}
+	gpx.Tracks = []models.GPXTrack{{Name: unkoNo, Segments: []models.GPXTrackSegment{segment}}}
+
+	return gpx, nil
+}
+
+// GetTimeBreakdown returns the time a trip spent driving, resting, working
+// and waiting, using the event type registry to categorize its events
+func (s *DtakoTripsService) GetTimeBreakdown(ctx context.Context, unkoNo string) (_ *models.EventTimeBreakdown, err error) {
+	ctx, span := tracing.Start(ctx, "DtakoTripsService.GetTimeBreakdown")
+	defer tracing.End(span, &err)
+
+	events, err := s.eventsRepo.GetByUnkoNo(ctx, unkoNo)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTripNotFound, unkoNo)
+	}
+
+	breakdown := loadEventTypes(ctx, s.typesRepo).breakdown(events)
+	return &breakdown, nil
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yhonda-ohishi/dtako_mod/apperr"
+	"github.com/yhonda-ohishi/dtako_mod/config"
+	"github.com/yhonda-ohishi/dtako_mod/logging"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/repositories"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+var (
+	// ErrEventTypeNotFound is returned when an event name is not registered
+	ErrEventTypeNotFound = apperr.New(apperr.NotFound, "event type not found")
+	// ErrInvalidEventType is returned for an event type with a missing name or unknown category
+	ErrInvalidEventType = apperr.New(apperr.Validation, "invalid event type")
+)
+
+// defaultEventTypes are the event names known without looking at production.
+// They are seeded by schema.sql and used when the registry cannot be read.
+var defaultEventTypes = map[string]string{
+	"運転":    models.EventCategoryDriving,
+	"休憩":    models.EventCategoryRest,
+	"休息":    models.EventCategoryRest,
+	"フェリー":  models.EventCategoryRest,
+	"作業":    models.EventCategoryWork,
+	"積み":    models.EventCategoryWork,
+	"降し":    models.EventCategoryWork,
+	"待機":    models.EventCategoryWaiting,
+	"START": models.EventCategoryOther,
+	"STOP":  models.EventCategoryOther,
+	"END":   models.EventCategoryOther,
+}
+
+// categoryKeywords guess the category of an event name seen in production for
+// the first time. Checked in order; names matching none become other.
+var categoryKeywords = []struct {
+	keyword  string
+	category string
+}{
+	{"運転", models.EventCategoryDriving},
+	{"走行", models.EventCategoryDriving},
+	{"休憩", models.EventCategoryRest},
+	{"休息", models.EventCategoryRest},
+	{"仮眠", models.EventCategoryRest},
+	{"フェリー", models.EventCategoryRest},
+	{"待機", models.EventCategoryWaiting},
+	{"待ち", models.EventCategoryWaiting},
+	{"積", models.EventCategoryWork},
+	{"降", models.EventCategoryWork},
+	{"荷", models.EventCategoryWork},
+	{"作業", models.EventCategoryWork},
+}
+
+// guessEventCategory returns the category for a newly seen event name
+func guessEventCategory(name string) string {
+	if category, ok := defaultEventTypes[name]; ok {
+		return category
+	}
+	for _, k := range categoryKeywords {
+		if strings.Contains(name, k.keyword) {
+			return k.category
+		}
+	}
+	return models.EventCategoryOther
+}
+
+func validEventCategory(category string) bool {
+	for _, c := range models.EventCategories() {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// EventTypesService handles business logic for the event type registry
+type EventTypesService struct {
+	repo  *repositories.EventTypesRepository
+	audit *auditTrail
+}
+
+// NewEventTypesService creates a new service instance
+func NewEventTypesService() *EventTypesService {
+	return &EventTypesService{
+		repo:  repositories.NewEventTypesRepository(),
+		audit: newAuditTrail(),
+	}
+}
+
+// List retrieves all registered event types
+func (s *EventTypesService) List(ctx context.Context) (_ []models.EventType, err error) {
+	ctx, span := tracing.Start(ctx, "EventTypesService.List")
+	defer tracing.End(span, &err)
+
+	return s.repo.List(ctx)
+}
+
+// Put registers an event type or changes its category
+func (s *EventTypesService) Put(ctx context.Context, et *models.EventType) (_ *models.EventType, err error) {
+	ctx, span := tracing.Start(ctx, "EventTypesService.Put")
+	defer tracing.End(span, &err)
+	defer func() { s.audit.record(ctx, models.AuditEventTypePut, et.Name, et, nil, err) }()
+
+	et.Name = strings.TrimSpace(et.Name)
+	if et.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidEventType)
+	}
+	if !validEventCategory(et.Category) {
+		return nil, fmt.Errorf("%w: category must be one of %s", ErrInvalidEventType, strings.Join(models.EventCategories(), ", "))
+	}
+
+	if err := s.repo.Upsert(ctx, et); err != nil {
+		return nil, err
+	}
+	registeredEventTypes.clear()
+	return et, nil
+}
+
+// Delete removes an event type from the registry
+func (s *EventTypesService) Delete(ctx context.Context, name string) (err error) {
+	ctx, span := tracing.Start(ctx, "EventTypesService.Delete")
+	defer tracing.End(span, &err)
+	defer func() { s.audit.record(ctx, models.AuditEventTypeDelete, name, nil, nil, err) }()
+
+	if err := s.repo.Delete(ctx, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %s", ErrEventTypeNotFound, name)
+		}
+		return err
+	}
+	registeredEventTypes.clear()
+	return nil
+}
+
+// SyncFromProduction registers every distinct production イベント名 that is
+// not in the registry yet, with a guessed category. Existing entries keep
+// their category.
+func (s *EventTypesService) SyncFromProduction(ctx context.Context) (result *models.ImportResult, err error) {
+	ctx, span := tracing.Start(ctx, "EventTypesService.SyncFromProduction")
+	defer tracing.End(span, &err)
+	defer func() { s.audit.record(ctx, models.AuditEventTypeSync, "dtako_event_types", nil, result, err) }()
+
+	names, err := s.repo.DistinctProductionNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch from production: %w", err)
+	}
+
+	imported := 0
+	var errs []string
+	for _, name := range names {
+		added, err := s.repo.InsertIfMissing(ctx, &models.EventType{Name: name, Category: guessEventCategory(name)})
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Failed to register event type %s: %v", name, err))
+		} else if added {
+			imported++
+		}
+	}
+	registeredEventTypes.clear()
+
+	return &models.ImportResult{
+		Success:      len(errs) == 0,
+		ImportedRows: imported,
+		Message:      fmt.Sprintf("Registered %d new event types (%d found in production)", imported, len(names)),
+		ImportedAt:   config.Now(),
+		Errors:       errs,
+	}, nil
+}
+
+// eventTypeRegistry resolves event names to categories
+type eventTypeRegistry struct {
+	categories map[string]string
+}
+
+// loadEventTypes loads the registry. When the table cannot be read the
+// built-in defaults are used so imports and reports keep working.
+func loadEventTypes(ctx context.Context, repo *repositories.EventTypesRepository) *eventTypeRegistry {
+	types, err := repo.List(ctx)
+	if err != nil {
+		logging.Logger().Warn("event type registry unavailable, using defaults", "error", err)
+		return &eventTypeRegistry{categories: defaultEventTypes}
+	}
+
+	categories := make(map[string]string, len(types))
+	for _, et := range types {
+		categories[et.Name] = et.Category
+	}
+	return &eventTypeRegistry{categories: categories}
+}
+
+// category returns the category of an event name and whether it is registered
+func (reg *eventTypeRegistry) category(name string) (string, bool) {
+	category, ok := reg.categories[name]
+	return category, ok
+}
+
+// breakdown sums the time spent per category. events must be in time order;
+// each event lasts until the next event of the same 運行NO, so the last
+// event of a trip adds no time.
+func (reg *eventTypeRegistry) breakdown(events []models.DtakoEvent) models.EventTimeBreakdown {
+	var b models.EventTimeBreakdown
+	unregistered := map[string]bool{}
+
+	for i := 0; i+1 < len(events); i++ {
+		cur, next := &events[i], &events[i+1]
+		if cur.UnkoNo != next.UnkoNo {
+			continue
+		}
+		minutes := next.EventDate.Sub(cur.EventDate).Minutes()
+		if minutes <= 0 {
+			continue
+		}
+
+		category, ok := reg.category(cur.EventType)
+		if !ok && !unregistered[cur.EventType] {
+			unregistered[cur.EventType] = true
+			b.Unregistered = append(b.Unregistered, cur.EventType)
+		}
+		addCategoryMinutes(&b, category, minutes)
+	}
+
+	return b
+}
+
+// addCategoryMinutes adds minutes to the total of a category; unknown
+// categories count as other
+func addCategoryMinutes(b *models.EventTimeBreakdown, category string, minutes float64) {
+	switch category {
+	case models.EventCategoryDriving:
+		b.DrivingMinutes += minutes
+	case models.EventCategoryRest:
+		b.RestMinutes += minutes
+	case models.EventCategoryWork:
+		b.WorkMinutes += minutes
+	case models.EventCategoryWaiting:
+		b.WaitingMinutes += minutes
+	default:
+		b.OtherMinutes += minutes
+	}
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/privacy"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// FuelAnomalyOptions configures fuel efficiency anomaly detection
+type FuelAnomalyOptions struct {
+	Window       int     // 車輌ごとのベースラインに使う直近運行数
+	MinBaseline  int     // 判定に必要な最小ベースライン運行数
+	ZThreshold   float64 // |z| がこの値以上で異常（負の値で無効）
+	PctThreshold float64 // 平均からの乖離率(%)がこの値以上で異常（負の値で無効）
+	LookbackDays int     // from より前にベースライン用に読み込む日数
+}
+
+// DefaultFuelAnomalyOptions are used for options left at zero
+var DefaultFuelAnomalyOptions = FuelAnomalyOptions{
+	Window:       20,
+	MinBaseline:  5,
+	ZThreshold:   3,
+	PctThreshold: 30,
+	LookbackDays: 90,
+}
+
+// fuelBaseline is a rolling window of recent km/L values of one vehicle
+type fuelBaseline struct {
+	values []float64
+	size   int
+}
+
+func (b *fuelBaseline) add(v float64) {
+	b.values = append(b.values, v)
+	if len(b.values) > b.size {
+		b.values = b.values[1:]
+	}
+}
+
+func (b *fuelBaseline) meanStdDev() (float64, float64) {
+	var sum float64
+	for _, v := range b.values {
+		sum += v
+	}
+	mean := sum / float64(len(b.values))
+
+	var sq float64
+	for _, v := range b.values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(b.values)))
+}
+
+// DetectFuelAnomalies flags trips within date range whose km/L deviates from
+// the rolling baseline of the same vehicle (車輌CD). The baseline consists of
+// the preceding normal trips of that vehicle, including trips up to
+// LookbackDays before from; flagged trips are kept out of it.
+// Trips without distance or fuel are skipped.
+func (s *DtakoRowsService) DetectFuelAnomalies(ctx context.Context, from, to string, filter models.ListFilter, opts FuelAnomalyOptions) (_ []models.FuelAnomaly, err error) {
+	ctx, span := tracing.Start(ctx, "DtakoRowsService.DetectFuelAnomalies", tracing.Period(from, to)...)
+	defer tracing.End(span, &err)
+
+	fromDate, toDate, err := parseDateRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	opts = opts.withDefaults()
+	if opts.ZThreshold <= 0 && opts.PctThreshold <= 0 {
+		return nil, fmt.Errorf("%w: z or pct threshold must be positive", ErrInvalidListQuery)
+	}
+
+	q := models.ListQuery{
+		Filter: filter,
+		Sort: []models.SortField{
+			{Field: "vehicle_no"}, {Field: "date"}, {Field: "id"},
+		},
+		Fields: []string{"id", "unko_no", "date", "vehicle_no", "driver_code", "distance", "fuel_amount"},
+	}
+
+	scan := newFuelAnomalyScan(fromDate, opts)
+	anomalies := []models.FuelAnomaly{}
+
+	mask := privacy.For(ctx)
+	lookbackFrom := fromDate.AddDate(0, 0, -opts.LookbackDays)
+	err = s.repo.StreamByDateRange(ctx, lookbackFrom, toDate, q, func(row *models.DtakoRow) error {
+		if anomaly, flagged := scan.check(row); flagged {
+			anomaly.DriverCode = mask.Code(anomaly.DriverCode)
+			anomalies = append(anomalies, anomaly)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return anomalies, nil
+}
+
+// fuelAnomalyScan keeps the per-vehicle baselines while rows ordered by
+// vehicle and date are checked
+type fuelAnomalyScan struct {
+	from      time.Time
+	opts      FuelAnomalyOptions
+	baselines map[string]*fuelBaseline
+}
+
+func newFuelAnomalyScan(from time.Time, opts FuelAnomalyOptions) *fuelAnomalyScan {
+	return &fuelAnomalyScan{from: from, opts: opts, baselines: map[string]*fuelBaseline{}}
+}
+
+// check returns the anomaly of row if it is flagged. Rows before from, rows
+// checked before the baseline has MinBaseline trips and normal rows are
+// added to the baseline instead.
+func (s *fuelAnomalyScan) check(row *models.DtakoRow) (models.FuelAnomaly, bool) {
+	if row.Distance <= 0 || row.FuelAmount <= 0 {
+		return models.FuelAnomaly{}, false
+	}
+	efficiency := row.Distance / row.FuelAmount
+
+	baseline, ok := s.baselines[row.VehicleNo]
+	if !ok {
+		baseline = &fuelBaseline{size: s.opts.Window}
+		s.baselines[row.VehicleNo] = baseline
+	}
+
+	if row.Date.Before(s.from) || len(baseline.values) < s.opts.MinBaseline {
+		baseline.add(efficiency)
+		return models.FuelAnomaly{}, false
+	}
+
+	anomaly, flagged := checkFuelEfficiency(row, efficiency, baseline, s.opts)
+	if !flagged {
+		baseline.add(efficiency)
+	}
+	return anomaly, flagged
+}
+
+// checkFuelEfficiency compares one trip against the vehicle baseline
+func checkFuelEfficiency(row *models.DtakoRow, efficiency float64, baseline *fuelBaseline, opts FuelAnomalyOptions) (models.FuelAnomaly, bool) {
+	mean, stddev := baseline.meanStdDev()
+
+	anomaly := models.FuelAnomaly{
+		RowID:          row.ID,
+		UnkoNo:         row.UnkoNo,
+		Date:           row.Date,
+		VehicleNo:      row.VehicleNo,
+		DriverCode:     row.DriverCode,
+		Distance:       row.Distance,
+		FuelAmount:     row.FuelAmount,
+		FuelEfficiency: efficiency,
+		BaselineMean:   mean,
+		BaselineStdDev: stddev,
+		BaselineTrips:  len(baseline.values),
+		Reasons:        []string{},
+	}
+	if mean != 0 {
+		anomaly.DeviationPct = (efficiency - mean) / mean * 100
+	}
+
+	// 標準偏差0（全運行が同じ燃費）の場合はzスコアを出さない
+	if stddev > 0 {
+		z := (efficiency - mean) / stddev
+		anomaly.ZScore = &z
+		if opts.ZThreshold > 0 && math.Abs(z) >= opts.ZThreshold {
+			anomaly.Reasons = append(anomaly.Reasons, "z_score")
+		}
+	}
+	if opts.PctThreshold > 0 && math.Abs(anomaly.DeviationPct) >= opts.PctThreshold {
+		anomaly.Reasons = append(anomaly.Reasons, "percent")
+	}
+
+	return anomaly, len(anomaly.Reasons) > 0
+}
+
+// withDefaults fills unset options from DefaultFuelAnomalyOptions.
+// A negative threshold disables that check.
+func (o FuelAnomalyOptions) withDefaults() FuelAnomalyOptions {
+	d := DefaultFuelAnomalyOptions
+	if o.Window <= 0 {
+		o.Window = d.Window
+	}
+	if o.MinBaseline <= 0 {
+		o.MinBaseline = d.MinBaseline
+	}
+	if o.MinBaseline > o.Window {
+		o.MinBaseline = o.Window
+	}
+	if o.ZThreshold == 0 {
+		o.ZThreshold = d.ZThreshold
+	}
+	if o.PctThreshold == 0 {
+		o.PctThreshold = d.PctThreshold
+	}
+	if o.LookbackDays <= 0 {
+		o.LookbackDays = d.LookbackDays
+	}
+	return o
+}
+
+// ParseFuelAnomalyOptions reads options from string values; empty values keep the default
+func ParseFuelAnomalyOptions(window, minBaseline, z, pct, lookbackDays string) (FuelAnomalyOptions, error) {
+	var opts FuelAnomalyOptions
+	ints := []struct {
+		name  string
+		value string
+		dest  *int
+	}{
+		{"window", window, &opts.Window},
+		{"min_baseline", minBaseline, &opts.MinBaseline},
+		{"lookback_days", lookbackDays, &opts.LookbackDays},
+	}
+	for _, p := range ints {
+		if p.value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(p.value)
+		if err != nil || n <= 0 {
+			return opts, fmt.Errorf("%w: %s must be a positive integer", ErrInvalidListQuery, p.name)
+		}
+		*p.dest = n
+	}
+
+	floats := []struct {
+		name  string
+		value string
+		dest  *float64
+	}{
+		{"z", z, &opts.ZThreshold},
+		{"pct", pct, &opts.PctThreshold},
+	}
+	for _, p := range floats {
+		if p.value == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(p.value, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return opts, fmt.Errorf("%w: %s must be a number", ErrInvalidListQuery, p.name)
+		}
+		*p.dest = f
+	}
+
+	return opts, nil
+}
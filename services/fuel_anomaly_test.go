@@ -0,0 +1,169 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+func TestFuelBaselineMeanStdDev(t *testing.T) {
+	tests := []struct {
+		name         string
+		size         int
+		values       []float64
+		wantValues   []float64
+		mean, stddev float64
+	}{
+		{name: "constant", size: 5, values: []float64{4, 4, 4}, wantValues: []float64{4, 4, 4}, mean: 4, stddev: 0},
+		{name: "population stddev", size: 5, values: []float64{2, 4, 4, 4, 5}, wantValues: []float64{2, 4, 4, 4, 5}, mean: 3.8, stddev: math.Sqrt(0.96)},
+		// 直近 size 件だけを保持する
+		{name: "rolling window", size: 3, values: []float64{100, 1, 2, 3}, wantValues: []float64{1, 2, 3}, mean: 2, stddev: math.Sqrt(2.0 / 3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &fuelBaseline{size: tt.size}
+			for _, v := range tt.values {
+				b.add(v)
+			}
+			if !reflect.DeepEqual(b.values, tt.wantValues) {
+				t.Errorf("values = %v, want %v", b.values, tt.wantValues)
+			}
+			mean, stddev := b.meanStdDev()
+			if math.Abs(mean-tt.mean) > 1e-9 || math.Abs(stddev-tt.stddev) > 1e-9 {
+				t.Errorf("mean, stddev = %v, %v, want %v, %v", mean, stddev, tt.mean, tt.stddev)
+			}
+		})
+	}
+}
+
+func TestCheckFuelEfficiency(t *testing.T) {
+	// 平均 5 km/L、標準偏差 1
+	baseline := &fuelBaseline{size: 20, values: []float64{4, 6, 4, 6}}
+	row := &models.DtakoRow{ID: "r1", VehicleNo: "101"}
+
+	tests := []struct {
+		name        string
+		efficiency  float64
+		opts        FuelAnomalyOptions
+		wantReasons []string
+		wantZ       float64
+		wantPct     float64
+	}{
+		{name: "below both thresholds", efficiency: 5.5, opts: FuelAnomalyOptions{ZThreshold: 3, PctThreshold: 30}, wantReasons: []string{}, wantZ: 0.5, wantPct: 10},
+		{name: "above z threshold", efficiency: 8, opts: FuelAnomalyOptions{ZThreshold: 3, PctThreshold: 80}, wantReasons: []string{"z_score"}, wantZ: 3, wantPct: 60},
+		{name: "above percent threshold", efficiency: 3, opts: FuelAnomalyOptions{ZThreshold: 3, PctThreshold: 30}, wantReasons: []string{"percent"}, wantZ: -2, wantPct: -40},
+		{name: "above both thresholds", efficiency: 1, opts: FuelAnomalyOptions{ZThreshold: 3, PctThreshold: 30}, wantReasons: []string{"z_score", "percent"}, wantZ: -4, wantPct: -80},
+		{name: "disabled z threshold", efficiency: 8, opts: FuelAnomalyOptions{ZThreshold: -1, PctThreshold: 80}, wantReasons: []string{}, wantZ: 3, wantPct: 60},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomaly, flagged := checkFuelEfficiency(row, tt.efficiency, baseline, tt.opts)
+			if flagged != (len(tt.wantReasons) > 0) || !reflect.DeepEqual(anomaly.Reasons, tt.wantReasons) {
+				t.Errorf("flagged = %v reasons = %v, want %v", flagged, anomaly.Reasons, tt.wantReasons)
+			}
+			if anomaly.ZScore == nil || math.Abs(*anomaly.ZScore-tt.wantZ) > 1e-9 {
+				t.Errorf("z = %v, want %v", anomaly.ZScore, tt.wantZ)
+			}
+			if math.Abs(anomaly.DeviationPct-tt.wantPct) > 1e-9 {
+				t.Errorf("deviation = %v%%, want %v%%", anomaly.DeviationPct, tt.wantPct)
+			}
+			if anomaly.BaselineMean != 5 || anomaly.BaselineStdDev != 1 || anomaly.BaselineTrips != 4 {
+				t.Errorf("baseline = %v ± %v (%d trips), want 5 ± 1 (4 trips)", anomaly.BaselineMean, anomaly.BaselineStdDev, anomaly.BaselineTrips)
+			}
+		})
+	}
+
+	t.Run("no z score without spread", func(t *testing.T) {
+		flat := &fuelBaseline{size: 20, values: []float64{5, 5, 5}}
+		anomaly, flagged := checkFuelEfficiency(row, 3, flat, FuelAnomalyOptions{ZThreshold: 3, PctThreshold: 30})
+		if anomaly.ZScore != nil {
+			t.Errorf("z = %v, want none", *anomaly.ZScore)
+		}
+		if !flagged || !reflect.DeepEqual(anomaly.Reasons, []string{"percent"}) {
+			t.Errorf("flagged = %v reasons = %v, want percent", flagged, anomaly.Reasons)
+		}
+	})
+}
+
+func TestFuelAnomalyScan(t *testing.T) {
+	from := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	row := func(id, vehicle string, day int, distance, fuel float64) *models.DtakoRow {
+		return &models.DtakoRow{ID: id, VehicleNo: vehicle, Date: time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC), Distance: distance, FuelAmount: fuel}
+	}
+	opts := FuelAnomalyOptions{Window: 20, MinBaseline: 3, ZThreshold: -1, PctThreshold: 30}
+
+	tests := []struct {
+		name        string
+		rows        []*models.DtakoRow
+		wantFlagged []string
+	}{
+		{
+			name: "too few samples",
+			rows: []*models.DtakoRow{
+				row("a", "101", 10, 500, 100), row("b", "101", 11, 500, 100),
+				// ベースラインが2件しかないため判定しない
+				row("c", "101", 12, 100, 100),
+			},
+		},
+		{
+			name: "lookback rows build the baseline but are not flagged",
+			rows: []*models.DtakoRow{
+				row("a", "101", 1, 500, 100), row("b", "101", 2, 500, 100), row("c", "101", 3, 500, 100),
+				// from より前の外れ値は判定せずベースラインに含める（平均 4 km/L）
+				row("x", "101", 4, 100, 100),
+				row("d", "101", 10, 500, 100),
+				row("e", "101", 11, 100, 100),
+			},
+			wantFlagged: []string{"e"},
+		},
+		{
+			name: "flagged trips stay out of the baseline",
+			rows: []*models.DtakoRow{
+				row("a", "101", 1, 500, 100), row("b", "101", 2, 500, 100), row("c", "101", 3, 500, 100),
+				row("d", "101", 10, 100, 100),
+				row("e", "101", 11, 100, 100),
+			},
+			wantFlagged: []string{"d", "e"},
+		},
+		{
+			name: "baselines are per vehicle",
+			rows: []*models.DtakoRow{
+				row("a", "101", 1, 500, 100), row("b", "101", 2, 500, 100), row("c", "101", 3, 500, 100),
+				row("d", "102", 10, 100, 100),
+			},
+		},
+		{
+			name: "rows without distance or fuel are skipped",
+			rows: []*models.DtakoRow{
+				row("a", "101", 1, 500, 100), row("b", "101", 2, 500, 100), row("c", "101", 3, 500, 100),
+				row("d", "101", 10, 0, 100),
+				row("e", "101", 11, 100, 0),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scan := newFuelAnomalyScan(from, opts)
+			var flagged []string
+			for _, r := range tt.rows {
+				if anomaly, ok := scan.check(r); ok {
+					flagged = append(flagged, anomaly.RowID)
+				}
+			}
+			if !reflect.DeepEqual(flagged, tt.wantFlagged) {
+				t.Errorf("flagged = %v, want %v", flagged, tt.wantFlagged)
+			}
+		})
+	}
+}
+
+func TestFuelAnomalyOptionsWithDefaults(t *testing.T) {
+	got := FuelAnomalyOptions{Window: 3, MinBaseline: 10, PctThreshold: -1}.withDefaults()
+	want := FuelAnomalyOptions{Window: 3, MinBaseline: 3, ZThreshold: 3, PctThreshold: -1, LookbackDays: 90}
+	if got != want {
+		t.Errorf("withDefaults() = %+v, want %+v", got, want)
+	}
+}
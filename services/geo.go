@@ -0,0 +1,100 @@
+package services
+
+import (
+	"math"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// earthRadiusM is the mean Earth radius used for distance calculations
+const earthRadiusM = 6371000.0
+
+// distanceM returns the great-circle distance between two points in meters
+func distanceM(a, b models.LatLng) float64 {
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := (b.Lat - a.Lat) * math.Pi / 180
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusM * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// insidePolygon reports whether p lies inside polygon using ray casting.
+// Coordinates are treated as planar, which is accurate enough for site-sized areas.
+func insidePolygon(p models.LatLng, polygon []models.LatLng) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// geofenceContains reports whether p lies inside the geofence
+func geofenceContains(g *models.Geofence, p models.LatLng) bool {
+	switch g.Shape {
+	case "circle":
+		return g.Center != nil && distanceM(*g.Center, p) <= g.RadiusM
+	case "polygon":
+		return insidePolygon(p, g.Polygon)
+	}
+	return false
+}
+
+// validLatLng reports whether p is a finite WGS84 position
+func validLatLng(p models.LatLng) bool {
+	return !math.IsNaN(p.Lat) && !math.IsNaN(p.Lng) &&
+		p.Lat >= -90 && p.Lat <= 90 && p.Lng >= -180 && p.Lng <= 180
+}
+
+// polygonArea2 returns twice the signed planar area of polygon (shoelace formula)
+func polygonArea2(polygon []models.LatLng) float64 {
+	area := 0.0
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		area += polygon[j].Lng*polygon[i].Lat - polygon[i].Lng*polygon[j].Lat
+	}
+	return area
+}
+
+// cross returns the z component of (b-a) × (c-a)
+func cross(a, b, c models.LatLng) float64 {
+	return (b.Lng-a.Lng)*(c.Lat-a.Lat) - (b.Lat-a.Lat)*(c.Lng-a.Lng)
+}
+
+// onSegment reports whether c, collinear with a-b, lies within the segment
+func onSegment(a, b, c models.LatLng) bool {
+	return math.Min(a.Lng, b.Lng) <= c.Lng && c.Lng <= math.Max(a.Lng, b.Lng) &&
+		math.Min(a.Lat, b.Lat) <= c.Lat && c.Lat <= math.Max(a.Lat, b.Lat)
+}
+
+// segmentsIntersect reports whether segments a-b and c-d touch or cross
+func segmentsIntersect(a, b, c, d models.LatLng) bool {
+	d1, d2 := cross(c, d, a), cross(c, d, b)
+	d3, d4 := cross(a, b, c), cross(a, b, d)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	return (d1 == 0 && onSegment(c, d, a)) || (d2 == 0 && onSegment(c, d, b)) ||
+		(d3 == 0 && onSegment(a, b, c)) || (d4 == 0 && onSegment(a, b, d))
+}
+
+// selfIntersects reports whether two non-adjacent edges of polygon touch or cross
+func selfIntersects(polygon []models.LatLng) bool {
+	n := len(polygon)
+	for i := 0; i < n; i++ {
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue // 最初と最後の辺は隣接
+			}
+			if segmentsIntersect(polygon[i], polygon[(i+1)%n], polygon[j], polygon[(j+1)%n]) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+func TestInsidePolygon(t *testing.T) {
+	square := []models.LatLng{{Lat: 35, Lng: 139}, {Lat: 35, Lng: 140}, {Lat: 36, Lng: 140}, {Lat: 36, Lng: 139}}
+	// L字型（凹多角形）: 北東の区画が欠けている
+	concave := []models.LatLng{
+		{Lat: 0, Lng: 0}, {Lat: 0, Lng: 2}, {Lat: 1, Lng: 2},
+		{Lat: 1, Lng: 1}, {Lat: 2, Lng: 1}, {Lat: 2, Lng: 0},
+	}
+
+	tests := []struct {
+		name    string
+		p       models.LatLng
+		polygon []models.LatLng
+		want    bool
+	}{
+		{"square center", models.LatLng{Lat: 35.5, Lng: 139.5}, square, true},
+		{"square east", models.LatLng{Lat: 35.5, Lng: 140.5}, square, false},
+		{"square north", models.LatLng{Lat: 36.5, Lng: 139.5}, square, false},
+		{"square south-west", models.LatLng{Lat: 34.9, Lng: 138.9}, square, false},
+		{"concave inside lower arm", models.LatLng{Lat: 0.5, Lng: 1.5}, concave, true},
+		{"concave inside upper arm", models.LatLng{Lat: 1.5, Lng: 0.5}, concave, true},
+		{"concave notch", models.LatLng{Lat: 1.5, Lng: 1.5}, concave, false},
+		{"ray through vertex", models.LatLng{Lat: 1, Lng: 0.5}, concave, true},
+	}
+	for _, tt := range tests {
+		if got := insidePolygon(tt.p, tt.polygon); got != tt.want {
+			t.Errorf("%s: insidePolygon(%v) = %v, want %v", tt.name, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestGeofenceContainsCircle(t *testing.T) {
+	g := &models.Geofence{Shape: "circle", Center: &models.LatLng{Lat: 35.6762, Lng: 139.6503}, RadiusM: 200}
+
+	// 緯度 0.001 度 ≒ 111m
+	if !geofenceContains(g, models.LatLng{Lat: 35.6772, Lng: 139.6503}) {
+		t.Error("point 111m away should be inside a 200m circle")
+	}
+	if geofenceContains(g, models.LatLng{Lat: 35.6792, Lng: 139.6503}) {
+		t.Error("point 333m away should be outside a 200m circle")
+	}
+}
+
+func TestValidateGeofence(t *testing.T) {
+	square := func() []models.LatLng {
+		return []models.LatLng{{Lat: 35, Lng: 139}, {Lat: 35, Lng: 140}, {Lat: 36, Lng: 140}, {Lat: 36, Lng: 139}}
+	}
+	circle := func(lat, lng, radius float64) *models.Geofence {
+		return &models.Geofence{Name: "depot", Category: "depot", Shape: "circle", Center: &models.LatLng{Lat: lat, Lng: lng}, RadiusM: radius}
+	}
+	polygon := func(points []models.LatLng) *models.Geofence {
+		return &models.Geofence{Name: "port", Category: "port", Shape: "polygon", Polygon: points}
+	}
+
+	tests := []struct {
+		name  string
+		g     *models.Geofence
+		valid bool
+	}{
+		{"circle", circle(35.6762, 139.6503, 200), true},
+		{"circle at bounds", circle(-90, 180, 1), true},
+		{"circle latitude out of range", circle(91, 139, 200), false},
+		{"circle longitude out of range", circle(35, -180.5, 200), false},
+		{"circle without radius", circle(35, 139, 0), false},
+		{"circle with negative radius", circle(35, 139, -1), false},
+		{"polygon", polygon(square()), true},
+		{"closed polygon", polygon(append(square(), models.LatLng{Lat: 35, Lng: 139})), true},
+		{"polygon with 2 points", polygon(square()[:2]), false},
+		{"closed triangle of 2 points", polygon([]models.LatLng{{Lat: 35, Lng: 139}, {Lat: 36, Lng: 139}, {Lat: 35, Lng: 139}}), false},
+		{"polygon point out of range", polygon([]models.LatLng{{Lat: 35, Lng: 139}, {Lat: 35, Lng: 200}, {Lat: 36, Lng: 140}}), false},
+		{"collinear polygon", polygon([]models.LatLng{{Lat: 35, Lng: 139}, {Lat: 35.5, Lng: 139.5}, {Lat: 36, Lng: 140}}), false},
+		{"bow tie", polygon([]models.LatLng{{Lat: 35, Lng: 139}, {Lat: 36, Lng: 140}, {Lat: 35, Lng: 140}, {Lat: 36, Lng: 139}}), false},
+		{"repeated vertex", polygon([]models.LatLng{{Lat: 35, Lng: 139}, {Lat: 35, Lng: 140}, {Lat: 35, Lng: 140}, {Lat: 36, Lng: 140}, {Lat: 36, Lng: 139}}), false},
+		{"too many points", polygon(make([]models.LatLng, maxPolygonPoints+1)), false},
+	}
+	for _, tt := range tests {
+		err := validateGeofence(tt.g)
+		if tt.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected a validation error", tt.name)
+		}
+	}
+
+	closed := polygon(append(square(), models.LatLng{Lat: 35, Lng: 139}))
+	if err := validateGeofence(closed); err != nil || len(closed.Polygon) != 4 {
+		t.Errorf("closing point should be dropped, got %d points (%v)", len(closed.Polygon), err)
+	}
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/apperr"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/privacy"
+	"github.com/yhonda-ohishi/dtako_mod/repositories"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+var (
+	// ErrGeofenceNotFound is returned when a geofence ID does not exist
+	ErrGeofenceNotFound = apperr.New(apperr.NotFound, "geofence not found")
+	// ErrInvalidGeofence is returned when a geofence definition fails validation
+	ErrInvalidGeofence = apperr.New(apperr.Validation, "invalid geofence")
+)
+
+// maxPolygonPoints limits the vertices of a polygon geofence, which are
+// tested against every event point
+const maxPolygonPoints = 1000
+
+// geofenceCategories lists the accepted geofence categories
+var geofenceCategories = map[string]bool{
+	"depot":    true,
+	"customer": true,
+	"port":     true,
+	"other":    true,
+}
+
+// GeofencesService handles geofence definitions and visit detection
+type GeofencesService struct {
+	repo       *repositories.GeofencesRepository
+	eventsRepo *repositories.DtakoEventsRepository
+	audit      *auditTrail
+}
+
+// NewGeofencesService creates a new service instance
+func NewGeofencesService() *GeofencesService {
+	return &GeofencesService{
+		repo:       repositories.NewGeofencesRepository(),
+		eventsRepo: repositories.NewDtakoEventsRepository(),
+		audit:      newAuditTrail(),
+	}
+}
+
+// validateGeofence checks that the definition is complete for its shape:
+// positions within WGS84 range, a positive radius, and a simple polygon
+// (no repeated or crossing edges, non-zero area). A closing point equal to
+// the first one is dropped.
+func validateGeofence(g *models.Geofence) error {
+	if g.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidGeofence)
+	}
+	if !geofenceCategories[g.Category] {
+		return fmt.Errorf("%w: category must be depot, customer, port or other", ErrInvalidGeofence)
+	}
+
+	switch g.Shape {
+	case "circle":
+		if g.Center == nil || !(g.RadiusM > 0) || math.IsInf(g.RadiusM, 1) {
+			return fmt.Errorf("%w: circle requires center and a positive radius_m", ErrInvalidGeofence)
+		}
+		if !validLatLng(*g.Center) {
+			return fmt.Errorf("%w: center must have lat within -90..90 and lng within -180..180", ErrInvalidGeofence)
+		}
+		g.Polygon = nil
+	case "polygon":
+		if n := len(g.Polygon); n > 1 && g.Polygon[0] == g.Polygon[n-1] {
+			g.Polygon = g.Polygon[:n-1]
+		}
+		if len(g.Polygon) < 3 {
+			return fmt.Errorf("%w: polygon requires at least 3 points", ErrInvalidGeofence)
+		}
+		if len(g.Polygon) > maxPolygonPoints {
+			return fmt.Errorf("%w: polygon is limited to %d points", ErrInvalidGeofence, maxPolygonPoints)
+		}
+		for i, p := range g.Polygon {
+			if !validLatLng(p) {
+				return fmt.Errorf("%w: polygon point %d must have lat within -90..90 and lng within -180..180", ErrInvalidGeofence, i)
+			}
+		}
+		if polygonArea2(g.Polygon) == 0 {
+			return fmt.Errorf("%w: polygon must enclose an area", ErrInvalidGeofence)
+		}
+		if selfIntersects(g.Polygon) {
+			return fmt.Errorf("%w: polygon edges must not cross or touch", ErrInvalidGeofence)
+		}
+		g.Center = nil
+		g.RadiusM = 0
+	default:
+		return fmt.Errorf("%w: shape must be circle or polygon", ErrInvalidGeofence)
+	}
+
+	return nil
+}
+
+// ListGeofences retrieves all geofences
+func (s *GeofencesService) ListGeofences(ctx context.Context) (_ []models.Geofence, err error) {
+	ctx, span := tracing.Start(ctx, "GeofencesService.ListGeofences")
+	defer tracing.End(span, &err)
+
+	return s.repo.List(ctx)
+}
+
+// GetGeofence retrieves a specific geofence by ID
+func (s *GeofencesService) GetGeofence(ctx context.Context, id int) (_ *models.Geofence, err error) {
+	ctx, span := tracing.Start(ctx, "GeofencesService.GetGeofence")
+	defer tracing.End(span, &err)
+
+	g, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %d", ErrGeofenceNotFound, id)
+		}
+		return nil, err
+	}
+	return g, nil
+}
+
+// CreateGeofence validates and stores a new geofence
+func (s *GeofencesService) CreateGeofence(ctx context.Context, g *models.Geofence) (_ *models.Geofence, err error) {
+	ctx, span := tracing.Start(ctx, "GeofencesService.CreateGeofence")
+	defer tracing.End(span, &err)
+	defer func() {
+		target := "" // 登録前に失敗した場合は ID なし
+		if g.ID != 0 {
+			target = strconv.Itoa(g.ID)
+		}
+		s.audit.record(ctx, models.AuditGeofenceCreate, target, g, nil, err)
+	}()
+
+	if err := validateGeofence(g); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Insert(ctx, g); err != nil {
+		return nil, err
+	}
+	return s.GetGeofence(ctx, g.ID)
+}
+
+// UpdateGeofence validates and replaces an existing geofence
+func (s *GeofencesService) UpdateGeofence(ctx context.Context, id int, g *models.Geofence) (_ *models.Geofence, err error) {
+	ctx, span := tracing.Start(ctx, "GeofencesService.UpdateGeofence")
+	defer tracing.End(span, &err)
+	defer func() { s.audit.record(ctx, models.AuditGeofenceUpdate, strconv.Itoa(id), g, nil, err) }()
+
+	if err := validateGeofence(g); err != nil {
+		return nil, err
+	}
+	g.ID = id
+	if err := s.repo.Update(ctx, g); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %d", ErrGeofenceNotFound, id)
+		}
+		return nil, err
+	}
+	return s.GetGeofence(ctx, id)
+}
+
+// DeleteGeofence removes a geofence
+func (s *GeofencesService) DeleteGeofence(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.Start(ctx, "GeofencesService.DeleteGeofence")
+	defer tracing.End(span, &err)
+	defer func() { s.audit.record(ctx, models.AuditGeofenceDelete, strconv.Itoa(id), nil, nil, err) }()
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: %d", ErrGeofenceNotFound, id)
+		}
+		return err
+	}
+	return nil
+}
+
+// DetectVisits scans event GPS points within the date range and returns the
+// arrivals at and departures from each geofence per trip.
+// unkoNo and geofenceID narrow the scan when set (geofenceID 0 means all).
+func (s *GeofencesService) DetectVisits(ctx context.Context, from, to, unkoNo string, geofenceID int) (_ []models.GeofenceVisit, err error) {
+	ctx, span := tracing.Start(ctx, "GeofencesService.DetectVisits", tracing.Period(from, to)...)
+	defer tracing.End(span, &err)
+
+	fromDate, toDate, err := parseDateRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var fences []models.Geofence
+	if geofenceID > 0 {
+		g, err := s.GetGeofence(ctx, geofenceID)
+		if err != nil {
+			return nil, err
+		}
+		fences = []models.Geofence{*g}
+	} else {
+		fences, err = s.repo.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	visits := []models.GeofenceVisit{}
+	if len(fences) == 0 {
+		return visits, nil
+	}
+
+	var tracker *visitTracker
+	err = s.eventsRepo.StreamTripEvents(ctx, fromDate, toDate, unkoNo, func(event *models.DtakoEvent) error {
+		if tracker == nil || tracker.unkoNo != event.UnkoNo {
+			if tracker != nil {
+				visits = append(visits, tracker.finish()...)
+			}
+			tracker = newVisitTracker(event.UnkoNo, fences)
+		}
+		tracker.observe(event)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tracker != nil {
+		visits = append(visits, tracker.finish()...)
+	}
+
+	mask := privacy.For(ctx)
+	for i := range visits {
+		visits[i].DriverCode = mask.Code(visits[i].DriverCode)
+	}
+
+	return visits, nil
+}
+
+// visitTracker follows one trip's events through the geofences
+type visitTracker struct {
+	unkoNo     string
+	fences     []models.Geofence
+	open       map[int]*models.GeofenceVisit
+	lastInside map[int]*models.DtakoEvent
+	done       []models.GeofenceVisit
+}
+
+func newVisitTracker(unkoNo string, fences []models.Geofence) *visitTracker {
+	return &visitTracker{
+		unkoNo:     unkoNo,
+		fences:     fences,
+		open:       map[int]*models.GeofenceVisit{},
+		lastInside: map[int]*models.DtakoEvent{},
+	}
+}
+
+// observe updates the open visits with the next event of the trip.
+// Events without a valid position are ignored rather than treated as leaving.
+func (t *visitTracker) observe(event *models.DtakoEvent) {
+	lat, lng, ok := validPosition(event)
+	if !ok {
+		return
+	}
+	p := models.LatLng{Lat: lat, Lng: lng}
+
+	for i := range t.fences {
+		fence := &t.fences[i]
+		inside := geofenceContains(fence, p)
+		visit := t.open[fence.ID]
+
+		switch {
+		case inside && visit == nil:
+			t.open[fence.ID] = &models.GeofenceVisit{
+				GeofenceID:     fence.ID,
+				GeofenceName:   fence.Name,
+				Category:       fence.Category,
+				UnkoNo:         event.UnkoNo,
+				VehicleNo:      event.VehicleNo,
+				DriverCode:     event.DriverCode,
+				ArrivedAt:      event.EventDate,
+				ArrivalEventID: event.ID,
+			}
+			last := *event
+			t.lastInside[fence.ID] = &last
+		case inside:
+			last := *event
+			t.lastInside[fence.ID] = &last
+		case visit != nil:
+			t.close(fence.ID)
+		}
+	}
+}
+
+// close completes an open visit using the last event seen inside the geofence
+func (t *visitTracker) close(fenceID int) {
+	visit := t.open[fenceID]
+	last := t.lastInside[fenceID]
+
+	departed := last.EventDate
+	dwell := departed.Sub(visit.ArrivedAt).Round(time.Second).Minutes()
+	visit.DepartedAt = &departed
+	visit.DepartureEventID = last.ID
+	visit.DwellMinutes = &dwell
+
+	t.done = append(t.done, *visit)
+	delete(t.open, fenceID)
+	delete(t.lastInside, fenceID)
+}
+
+// finish returns all visits of the trip. Visits still open when the trip's
+// events end have no departure.
+func (t *visitTracker) finish() []models.GeofenceVisit {
+	for i := range t.fences {
+		if visit := t.open[t.fences[i].ID]; visit != nil {
+			t.done = append(t.done, *visit)
+		}
+	}
+	return t.done
+}
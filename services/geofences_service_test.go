@@ -0,0 +1,86 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+func TestVisitTracker(t *testing.T) {
+	depot := models.Geofence{ID: 1, Name: "depot", Category: "depot", Shape: "circle", Center: &models.LatLng{Lat: 35, Lng: 139}, RadiusM: 500}
+	port := models.Geofence{ID: 2, Name: "port", Category: "port", Shape: "polygon", Polygon: []models.LatLng{
+		{Lat: 35.1, Lng: 139.1}, {Lat: 35.1, Lng: 139.2}, {Lat: 35.2, Lng: 139.2}, {Lat: 35.2, Lng: 139.1},
+	}}
+
+	start := time.Date(2025, 1, 13, 8, 0, 0, 0, time.UTC)
+	event := func(id string, minutes int, lat, lng float64) *models.DtakoEvent {
+		return &models.DtakoEvent{
+			ID: id, UnkoNo: "2025011301", VehicleNo: "101", DriverCode: "7",
+			EventDate: start.Add(time.Duration(minutes) * time.Minute),
+			Latitude:  &lat, Longitude: &lng,
+		}
+	}
+
+	tracker := newVisitTracker("2025011301", []models.Geofence{depot, port})
+	tracker.observe(event("e1", 0, 35, 139))                                                                    // 営業所に到着
+	tracker.observe(event("e2", 20, 35.001, 139))                                                               // 営業所内
+	tracker.observe(&models.DtakoEvent{ID: "e3", UnkoNo: "2025011301", EventDate: start.Add(25 * time.Minute)}) // 位置なしは無視
+	tracker.observe(event("e4", 30, 35.05, 139.05))                                                             // 営業所を出発
+	tracker.observe(event("e5", 90, 35.15, 139.15))                                                             // 港に到着
+	tracker.observe(event("e6", 120, 35.16, 139.16))                                                            // 港で運行終了
+	visits := tracker.finish()
+
+	if len(visits) != 2 {
+		t.Fatalf("got %d visits, want 2: %+v", len(visits), visits)
+	}
+
+	d := visits[0]
+	if d.GeofenceID != 1 || d.ArrivalEventID != "e1" || d.DepartureEventID != "e2" {
+		t.Errorf("depot visit = %+v, want arrival e1 and departure e2", d)
+	}
+	if d.DepartedAt == nil || !d.DepartedAt.Equal(start.Add(20*time.Minute)) {
+		t.Errorf("depot departed at %v, want the last event inside", d.DepartedAt)
+	}
+	if d.DwellMinutes == nil || *d.DwellMinutes != 20 {
+		t.Errorf("depot dwell = %v, want 20", d.DwellMinutes)
+	}
+	if d.UnkoNo != "2025011301" || d.VehicleNo != "101" || d.DriverCode != "7" {
+		t.Errorf("depot visit trip = %s/%s/%s", d.UnkoNo, d.VehicleNo, d.DriverCode)
+	}
+
+	p := visits[1]
+	if p.GeofenceID != 2 || p.ArrivalEventID != "e5" || !p.ArrivedAt.Equal(start.Add(90*time.Minute)) {
+		t.Errorf("port visit = %+v, want arrival e5", p)
+	}
+	if p.DepartedAt != nil || p.DwellMinutes != nil || p.DepartureEventID != "" {
+		t.Errorf("port visit still open at the end of the trip should have no departure, got %+v", p)
+	}
+}
+
+func TestVisitTrackerReentry(t *testing.T) {
+	depot := models.Geofence{ID: 1, Name: "depot", Category: "depot", Shape: "circle", Center: &models.LatLng{Lat: 35, Lng: 139}, RadiusM: 500}
+	start := time.Date(2025, 1, 13, 8, 0, 0, 0, time.UTC)
+	observe := func(tracker *visitTracker, id string, minutes int, lat, lng float64) {
+		tracker.observe(&models.DtakoEvent{ID: id, EventDate: start.Add(time.Duration(minutes) * time.Minute), Latitude: &lat, Longitude: &lng})
+	}
+
+	tracker := newVisitTracker("2025011301", []models.Geofence{depot})
+	observe(tracker, "e1", 0, 35, 139)
+	observe(tracker, "e2", 10, 35.1, 139)
+	observe(tracker, "e3", 60, 35, 139)
+	observe(tracker, "e4", 75, 35.1, 139)
+	visits := tracker.finish()
+
+	if len(visits) != 2 {
+		t.Fatalf("got %d visits, want 2", len(visits))
+	}
+	for i, want := range []struct{ arrival, departure string }{{"e1", "e1"}, {"e3", "e3"}} {
+		if visits[i].ArrivalEventID != want.arrival || visits[i].DepartureEventID != want.departure {
+			t.Errorf("visit %d = %s..%s, want %s..%s", i, visits[i].ArrivalEventID, visits[i].DepartureEventID, want.arrival, want.departure)
+		}
+		if visits[i].DwellMinutes == nil || *visits[i].DwellMinutes != 0 {
+			t.Errorf("visit %d dwell = %v, want 0 for a single event inside", i, visits[i].DwellMinutes)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/config"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/repositories"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// HealthService reports liveness and readiness of the module
+type HealthService struct {
+	repo      *repositories.HealthRepository
+	auditRepo *repositories.AuditRepository
+
+	mu        sync.Mutex
+	lastError map[string]databaseError
+}
+
+// databaseError is the last failed check of a database
+type databaseError struct {
+	message string
+	at      time.Time
+}
+
+// NewHealthService creates a new health service
+func NewHealthService() *HealthService {
+	s := &HealthService{
+		repo:      repositories.NewHealthRepository(),
+		auditRepo: repositories.NewAuditRepository(),
+		lastError: map[string]databaseError{},
+	}
+
+	// 起動時の接続エラーも last_error として報告する
+	now := config.Now()
+	localErr, prodErr := s.repo.ConnectErrors()
+	if localErr != nil {
+		s.lastError["local"] = databaseError{message: localErr.Error(), at: now}
+	}
+	if prodErr != nil {
+		s.lastError["production"] = databaseError{message: prodErr.Error(), at: now}
+	}
+	return s
+}
+
+// Liveness reports that the process is serving requests. It does not touch
+// the databases, so a database outage does not restart the process.
+func (s *HealthService) Liveness() models.LivenessReport {
+	return models.LivenessReport{Status: models.HealthStatusOK}
+}
+
+// Readiness checks both databases and their required tables.
+// The status is unavailable when the local database is not usable and
+// degraded when only the production database is (imports fail, reads work).
+func (s *HealthService) Readiness(ctx context.Context) models.ReadinessReport {
+	ctx, span := tracing.Start(ctx, "HealthService.Readiness")
+	defer span.End()
+
+	var local, production models.DatabaseHealth
+	var imports []models.ImportHealth
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		local = s.repo.CheckLocal(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		production = s.repo.CheckProduction(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		last, err := s.auditRepo.LastSuccessful(ctx, importActionList())
+		imports = importHealth(last, err)
+	}()
+	wg.Wait()
+
+	report := models.ReadinessReport{
+		Status:    models.HealthStatusOK,
+		CheckedAt: config.Now(),
+		Databases: []models.DatabaseHealth{s.withLastError(local), s.withLastError(production)},
+		Imports:   imports,
+	}
+	switch {
+	case local.Status != models.HealthStatusOK:
+		report.Status = models.HealthStatusUnavailable
+	case production.Status != models.HealthStatusOK:
+		report.Status = models.HealthStatusDegraded
+	}
+	return report
+}
+
+// withLastError records a failed check and adds the last failure to h
+func (s *HealthService) withLastError(h models.DatabaseHealth) models.DatabaseHealth {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if h.Error != "" {
+		s.lastError[h.Name] = databaseError{message: h.Error, at: config.Now()}
+	}
+	if last, ok := s.lastError[h.Name]; ok {
+		at := last.at
+		h.LastError = last.message
+		h.LastErrorAt = &at
+	}
+	return h
+}
+
+// importActions maps the audit action of each import to its table
+var importActions = []struct {
+	table  string
+	action string
+}{
+	{"dtako_events", models.AuditEventsImport},
+	{"dtako_ferry_rows", models.AuditFerryRowsImport},
+	{"dtako_rows", models.AuditRowsImport},
+}
+
+func importActionList() []string {
+	actions := make([]string, len(importActions))
+	for i, a := range importActions {
+		actions[i] = a.action
+	}
+	return actions
+}
+
+// importHealth reports the last successful import of each table from the
+// latest audit entries by action. When the audit log could not be read
+// (err) every table is unknown rather than reported as never imported.
+func importHealth(last map[string]models.AuditEntry, err error) []models.ImportHealth {
+	imports := make([]models.ImportHealth, 0, len(importActions))
+	for _, a := range importActions {
+		h := models.ImportHealth{Table: a.table, Status: models.ImportStatusNever}
+		if err != nil {
+			h.Status = models.ImportStatusUnknown
+		} else if e, ok := last[a.action]; ok {
+			at := e.CreatedAt.In(config.BusinessLocation())
+			h.Status = models.ImportStatusOK
+			h.LastSuccess = &at
+			h.ImportedRows = e.AffectedRows
+		}
+		imports = append(imports, h)
+	}
+	return imports
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+func TestImportHealth(t *testing.T) {
+	at := time.Date(2025, 1, 13, 6, 4, 5, 0, time.UTC)
+
+	t.Run("From the audit log", func(t *testing.T) {
+		imports := importHealth(map[string]models.AuditEntry{
+			models.AuditRowsImport: {Action: models.AuditRowsImport, AffectedRows: 150, CreatedAt: at},
+		}, nil)
+
+		byTable := map[string]models.ImportHealth{}
+		for _, h := range imports {
+			byTable[h.Table] = h
+		}
+		if len(byTable) != 3 {
+			t.Fatalf("expected rows, events and ferry_rows, got %+v", imports)
+		}
+
+		rows := byTable["dtako_rows"]
+		if rows.Status != models.ImportStatusOK || rows.ImportedRows != 150 || rows.LastSuccess == nil || !rows.LastSuccess.Equal(at) {
+			t.Errorf("unexpected dtako_rows health: %+v", rows)
+		}
+		for _, table := range []string{"dtako_events", "dtako_ferry_rows"} {
+			if h := byTable[table]; h.Status != models.ImportStatusNever || h.LastSuccess != nil {
+				t.Errorf("expected %s never imported, got %+v", table, h)
+			}
+		}
+	})
+
+	t.Run("Unreadable audit log is unknown", func(t *testing.T) {
+		for _, h := range importHealth(nil, errors.New("connection refused")) {
+			if h.Status != models.ImportStatusUnknown || h.LastSuccess != nil {
+				t.Errorf("expected %s unknown, got %+v", h.Table, h)
+			}
+		}
+	})
+}
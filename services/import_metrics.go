@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/yhonda-ohishi/dtako_mod/metrics"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// recordImport reports one import run of table to /metrics and as row counts
+// on the import span in ctx. /readyz reads the last successful import from
+// the audit log. Call it before validation.apply, which may add non-insert
+// errors to result.
+func recordImport(ctx context.Context, table string, start time.Time, fetched int, result *models.ImportResult, validation *importValidation, err error) {
+	run := metrics.ImportRun{
+		Fetched:  fetched,
+		Duration: time.Since(start),
+		Err:      err,
+	}
+	if result != nil {
+		run.Upserted = result.ImportedRows
+		run.Failed = len(result.Errors)
+	}
+	if validation != nil {
+		run.Rejected = validation.rejected
+	}
+	metrics.ObserveImport(table, run)
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(semconv.DBCollectionName(table))
+	tracing.Rows(span, "fetched", run.Fetched)
+	tracing.Rows(span, "upserted", run.Upserted)
+	tracing.Rows(span, "failed", run.Failed)
+	tracing.Rows(span, "rejected", run.Rejected)
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/repositories"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// IntegrityService builds cross-table integrity reports
+type IntegrityService struct {
+	repo        *repositories.IntegrityRepository
+	rowsService *DtakoRowsService
+	audit       *auditTrail
+}
+
+// NewIntegrityService creates a new service instance
+func NewIntegrityService() *IntegrityService {
+	return &IntegrityService{
+		repo:        repositories.NewIntegrityRepository(),
+		rowsService: NewDtakoRowsService(),
+		audit:       newAuditTrail(),
+	}
+}
+
+// GetReport checks the local tables for records within date range
+func (s *IntegrityService) GetReport(ctx context.Context, from, to string) (_ *models.IntegrityReport, err error) {
+	ctx, span := tracing.Start(ctx, "IntegrityService.GetReport", tracing.Period(from, to)...)
+	defer tracing.End(span, &err)
+
+	fromDate, toDate, err := parseDateRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.IntegrityReport{
+		From: fromDate.Format("2006-01-02"),
+		To:   toDate.Format("2006-01-02"),
+	}
+
+	if report.OrphanEvents, err = s.repo.OrphanEvents(ctx, fromDate, toDate); err != nil {
+		return nil, err
+	}
+	if report.OrphanFerryRows, err = s.repo.OrphanFerryRows(ctx, fromDate, toDate); err != nil {
+		return nil, err
+	}
+	if report.RowsWithoutEvents, err = s.repo.RowsWithoutEvents(ctx, fromDate, toDate); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// FetchMissingParents imports the dtako_rows referenced by orphaned events
+// and ferry rows from production, then reports again. Fetched holds the
+// import result; 運行NO that production does not know stay orphaned.
+func (s *IntegrityService) FetchMissingParents(ctx context.Context, from, to string) (_ *models.IntegrityReport, err error) {
+	ctx, span := tracing.Start(ctx, "IntegrityService.FetchMissingParents", tracing.Period(from, to)...)
+	defer tracing.End(span, &err)
+
+	var fetched *models.ImportResult
+	defer func() {
+		s.audit.record(ctx, models.AuditIntegrityFetchMissing, "dtako_rows", models.ImportRequest{FromDate: from, ToDate: to}, fetched, err)
+	}()
+
+	before, err := s.GetReport(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var unkoNos []string
+	for _, orphans := range [][]models.OrphanUnkoNo{before.OrphanEvents, before.OrphanFerryRows} {
+		for _, o := range orphans {
+			if !seen[o.UnkoNo] {
+				seen[o.UnkoNo] = true
+				unkoNos = append(unkoNos, o.UnkoNo)
+			}
+		}
+	}
+
+	fetched, err = s.rowsService.ImportByUnkoNos(ctx, unkoNos)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.GetReport(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	report.Fetched = fetched
+
+	return report, nil
+}
@@ -0,0 +1,6 @@
+package services
+
+import "github.com/yhonda-ohishi/dtako_mod/repositories"
+
+// ErrInvalidListQuery is returned when a list filter, sort or field is unknown for the resource or malformed
+var ErrInvalidListQuery = repositories.ErrInvalidListQuery
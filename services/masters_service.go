@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/yhonda-ohishi/dtako_mod/apperr"
+	"github.com/yhonda-ohishi/dtako_mod/config"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/privacy"
+	"github.com/yhonda-ohishi/dtako_mod/repositories"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+var (
+	// ErrUnknownMasterKind is returned for a master kind that does not exist
+	ErrUnknownMasterKind = repositories.ErrUnknownMasterKind
+	// ErrMasterNotFound is returned when a master code does not exist
+	ErrMasterNotFound = apperr.New(apperr.NotFound, "master record not found")
+	// ErrMasterExists is returned when creating a code that already exists
+	ErrMasterExists = apperr.New(apperr.Conflict, "master record already exists")
+	// ErrInvalidMaster is returned when a master record or CSV fails validation
+	ErrInvalidMaster = apperr.New(apperr.Validation, "invalid master record")
+)
+
+// MastersService handles vehicle, driver, office, ferry company and port master data
+type MastersService struct {
+	repo  *repositories.MastersRepository
+	audit *auditTrail
+}
+
+// NewMastersService creates a new service instance
+func NewMastersService() *MastersService {
+	return &MastersService{
+		repo:  repositories.NewMastersRepository(),
+		audit: newAuditTrail(),
+	}
+}
+
+func validateMaster(m *models.MasterRecord) error {
+	m.Code = strings.TrimSpace(m.Code)
+	m.Name = strings.TrimSpace(m.Name)
+	if m.Code == "" {
+		return fmt.Errorf("%w: code is required", ErrInvalidMaster)
+	}
+	if m.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidMaster)
+	}
+	return nil
+}
+
+// List retrieves all records of a master
+func (s *MastersService) List(ctx context.Context, kind string) (_ []models.MasterRecord, err error) {
+	ctx, span := tracing.Start(ctx, "MastersService.List")
+	defer tracing.End(span, &err)
+
+	records, err := s.repo.List(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+	for i := range records {
+		maskDriverMaster(ctx, kind, &records[i])
+	}
+	return records, nil
+}
+
+// Get retrieves one master record
+func (s *MastersService) Get(ctx context.Context, kind, code string) (_ *models.MasterRecord, err error) {
+	ctx, span := tracing.Start(ctx, "MastersService.Get")
+	defer tracing.End(span, &err)
+
+	m, err := s.repo.Get(ctx, kind, code)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s %s", ErrMasterNotFound, kind, code)
+		}
+		return nil, err
+	}
+	maskDriverMaster(ctx, kind, m)
+	return m, nil
+}
+
+// maskDriverMaster masks the code and name of a drivers record the caller of ctx may not see
+func maskDriverMaster(ctx context.Context, kind string, m *models.MasterRecord) {
+	if kind != "drivers" {
+		return
+	}
+	mask := privacy.For(ctx)
+	m.Code = mask.Code(m.Code)
+	m.Name = mask.Name(m.Name)
+}
+
+// Create adds a master record
+func (s *MastersService) Create(ctx context.Context, kind string, m *models.MasterRecord) (_ *models.MasterRecord, err error) {
+	ctx, span := tracing.Start(ctx, "MastersService.Create")
+	defer tracing.End(span, &err)
+	defer func() { s.audit.record(ctx, models.AuditMasterCreate, kind+"/"+m.Code, m, nil, err) }()
+
+	if err := validateMaster(m); err != nil {
+		return nil, err
+	}
+	if _, err := s.Get(ctx, kind, m.Code); err == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrMasterExists, kind, m.Code)
+	} else if !errors.Is(err, ErrMasterNotFound) {
+		return nil, err
+	}
+
+	if err := s.repo.Upsert(ctx, kind, m); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, kind, m.Code)
+}
+
+// Update replaces an existing master record; the code in the path wins
+func (s *MastersService) Update(ctx context.Context, kind, code string, m *models.MasterRecord) (_ *models.MasterRecord, err error) {
+	ctx, span := tracing.Start(ctx, "MastersService.Update")
+	defer tracing.End(span, &err)
+	defer func() { s.audit.record(ctx, models.AuditMasterUpdate, kind+"/"+code, m, nil, err) }()
+
+	m.Code = code
+	if err := validateMaster(m); err != nil {
+		return nil, err
+	}
+	if _, err := s.Get(ctx, kind, code); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Upsert(ctx, kind, m); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, kind, code)
+}
+
+// Delete removes a master record
+func (s *MastersService) Delete(ctx context.Context, kind, code string) (err error) {
+	ctx, span := tracing.Start(ctx, "MastersService.Delete")
+	defer tracing.End(span, &err)
+	defer func() { s.audit.record(ctx, models.AuditMasterDelete, kind+"/"+code, nil, nil, err) }()
+
+	if err := s.repo.Delete(ctx, kind, code); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: %s %s", ErrMasterNotFound, kind, code)
+		}
+		return err
+	}
+	return nil
+}
+
+// ImportCSV upserts records from CSV with a header row. Columns code and
+// name are required; office_code and active (true/false/1/0) are optional.
+// The whole file is validated before anything is written.
+func (s *MastersService) ImportCSV(ctx context.Context, kind string, r io.Reader) (result *models.ImportResult, err error) {
+	ctx, span := tracing.Start(ctx, "MastersService.ImportCSV")
+	defer tracing.End(span, &err)
+	defer func() { s.audit.record(ctx, models.AuditMasterImportCSV, kind, nil, result, err) }()
+
+	if _, err := s.repo.List(ctx, kind); errors.Is(err, ErrUnknownMasterKind) {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read CSV header: %v", ErrInvalidMaster, err)
+	}
+
+	cols := map[string]int{}
+	for i, name := range header {
+		// Excel出力のCSVはBOM付きのことがある
+		cols[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"code", "name"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("%w: CSV header must contain %s", ErrInvalidMaster, required)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var records []models.MasterRecord
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidMaster, line, err)
+		}
+
+		m := models.MasterRecord{
+			Code:       field(record, "code"),
+			Name:       field(record, "name"),
+			OfficeCode: field(record, "office_code"),
+		}
+		if v := field(record, "active"); v != "" {
+			active, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: active must be true or false", ErrInvalidMaster, line)
+			}
+			m.Active = &active
+		}
+		if err := validateMaster(&m); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, m)
+	}
+
+	return s.upsertAll(ctx, kind, records, fmt.Sprintf("Imported %%d %s from CSV", kind))
+}
+
+// SyncFromProduction adds master records derived from production data for
+// codes not registered yet. Existing records, including deactivated ones and
+// their office, are left as they are. Drivers only seen in ferry rows before
+// the retention cutoff are not added.
+func (s *MastersService) SyncFromProduction(ctx context.Context, kind string) (result *models.ImportResult, err error) {
+	ctx, span := tracing.Start(ctx, "MastersService.SyncFromProduction")
+	defer tracing.End(span, &err)
+	defer func() { s.audit.record(ctx, models.AuditMasterSync, kind, nil, result, err) }()
+
+	// 保持期間より前の運行にしか現れない乗務員は同期で復元しない
+	records, err := s.repo.FetchFromProduction(ctx, kind, newRetentionFilter().cutoff)
+	if err != nil {
+		if errors.Is(err, ErrUnknownMasterKind) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to fetch from production: %w", err)
+	}
+
+	imported := 0
+	var errs []string
+	for i := range records {
+		added, err := s.repo.InsertIfMissing(ctx, kind, &records[i])
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Failed to import %s %s: %v", kind, records[i].Code, err))
+		} else if added {
+			imported++
+		}
+	}
+
+	return &models.ImportResult{
+		Success:      len(errs) == 0,
+		ImportedRows: imported,
+		Message:      fmt.Sprintf("Registered %d new %s (%d found in production)", imported, kind, len(records)),
+		ImportedAt:   config.Now(),
+		Errors:       errs,
+	}, nil
+}
+
+func (s *MastersService) upsertAll(ctx context.Context, kind string, records []models.MasterRecord, message string) (*models.ImportResult, error) {
+	imported := 0
+	var errs []string
+	for i := range records {
+		if err := s.repo.Upsert(ctx, kind, &records[i]); err != nil {
+			errs = append(errs, fmt.Sprintf("Failed to import %s %s: %v", kind, records[i].Code, err))
+		} else {
+			imported++
+		}
+	}
+
+	return &models.ImportResult{
+		Success:      imported > 0,
+		ImportedRows: imported,
+		Message:      fmt.Sprintf(message, imported),
+		ImportedAt:   config.Now(),
+		Errors:       errs,
+	}, nil
+}
+
+// masterNames resolves codes to names for response enrichment and masks
+// driver data the caller may not see
+type masterNames struct {
+	vehicles map[string]string
+	drivers  map[string]string
+	mask     privacy.Mask
+}
+
+// loadMasterNames loads vehicle and driver names. Masters are optional, so
+// a failure only means responses are not enriched.
+func loadMasterNames(ctx context.Context, repo *repositories.MastersRepository) *masterNames {
+	names := &masterNames{mask: privacy.For(ctx)}
+	names.vehicles, _ = repo.Names(ctx, "vehicles")
+	names.drivers, _ = repo.Names(ctx, "drivers")
+	return names
+}
+
+func (n *masterNames) enrichRow(row *models.DtakoRow) {
+	row.VehicleName = n.vehicles[row.VehicleNo]
+	row.DriverName, row.DriverCode = n.driver(row.DriverCode)
+}
+
+func (n *masterNames) enrichEvent(event *models.DtakoEvent) {
+	event.VehicleName = n.vehicles[event.VehicleNo]
+	event.DriverName, event.DriverCode = n.driver(event.DriverCode)
+}
+
+// driver returns the name and the code of a driver as the caller may see them
+func (n *masterNames) driver(code string) (name, maskedCode string) {
+	return n.mask.Name(n.drivers[code]), n.mask.Code(code)
+}
+
+// nameFields are response fields filled from master data, with the code
+// field they are derived from
+var nameFields = map[string]string{
+	"vehicle_name": "vehicle_no",
+	"driver_name":  "driver_code",
+}
+
+// withoutNameFields replaces name fields in a field selection by the code
+// fields they need, since names are not columns of the record tables
+func withoutNameFields(q models.ListQuery) models.ListQuery {
+	if len(q.Fields) == 0 {
+		return q
+	}
+
+	seen := map[string]bool{}
+	fields := make([]string, 0, len(q.Fields))
+	for _, f := range q.Fields {
+		if code, ok := nameFields[f]; ok {
+			f = code
+		}
+		if !seen[f] {
+			seen[f] = true
+			fields = append(fields, f)
+		}
+	}
+	q.Fields = fields
+	return q
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/config"
+)
+
+// 深夜時間帯（22:00〜翌05:00）
+const (
+	nightStartHour = 22
+	nightEndHour   = 5
+)
+
+// timeSegment is a part of an interval that lies within one calendar day and
+// entirely inside or entirely outside the night window
+type timeSegment struct {
+	start time.Time
+	end   time.Time
+	night bool
+}
+
+// day returns the calendar day (YYYY-MM-DD) of the segment
+func (s timeSegment) day() string {
+	return s.start.Format("2006-01-02")
+}
+
+func (s timeSegment) minutes() float64 {
+	return s.end.Sub(s.start).Minutes()
+}
+
+// splitSegments splits [start, end) at midnight, 05:00 and 22:00 in the
+// business time zone, so an interval spanning several days yields one or
+// more segments per day
+func splitSegments(start, end time.Time) []timeSegment {
+	if !end.After(start) {
+		return nil
+	}
+
+	loc := config.BusinessLocation()
+	start, end = start.In(loc), end.In(loc)
+	segments := []timeSegment{}
+	for cur := start; cur.Before(end); {
+		next := nextBoundary(cur)
+		if next.After(end) {
+			next = end
+		}
+		hour := cur.Hour()
+		segments = append(segments, timeSegment{
+			start: cur,
+			end:   next,
+			night: hour >= nightStartHour || hour < nightEndHour,
+		})
+		cur = next
+	}
+	return segments
+}
+
+// nextBoundary returns the first of 05:00, 22:00 or midnight after t
+func nextBoundary(t time.Time) time.Time {
+	y, m, d := t.Date()
+	for _, b := range []time.Time{
+		time.Date(y, m, d, nightEndHour, 0, 0, 0, t.Location()),
+		time.Date(y, m, d, nightStartHour, 0, 0, 0, t.Location()),
+	} {
+		if b.After(t) {
+			return b
+		}
+	}
+	return time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+}
+
+// nightMinutes returns the minutes of [start, end) that fall between 22:00
+// and 05:00 in the business time zone
+func nightMinutes(start, end time.Time) float64 {
+	total := 0.0
+	for _, s := range splitSegments(start, end) {
+		if s.night {
+			total += s.minutes()
+		}
+	}
+	return total
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/repositories"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// NightHoursService computes late-night (22:00-05:00) working time
+type NightHoursService struct {
+	rowsRepo    *repositories.DtakoRowsRepository
+	eventsRepo  *repositories.DtakoEventsRepository
+	mastersRepo *repositories.MastersRepository
+	typesRepo   *repositories.EventTypesRepository
+}
+
+// NewNightHoursService creates a new service instance
+func NewNightHoursService() *NightHoursService {
+	return &NightHoursService{
+		rowsRepo:    repositories.NewDtakoRowsRepository(),
+		eventsRepo:  repositories.NewDtakoEventsRepository(),
+		mastersRepo: repositories.NewMastersRepository(),
+		typesRepo:   repositories.NewEventTypesRepository(),
+	}
+}
+
+// GetNightHours returns per driver and calendar day the night driving and
+// work minutes within from..to. Trips are selected by 運行日 starting one day
+// before from, so a trip that began the previous evening is included; only
+// the part of each event inside the range is counted. Days without night
+// work are omitted.
+func (s *NightHoursService) GetNightHours(ctx context.Context, from, to string, filter models.ListFilter) (_ []models.NightHours, err error) {
+	ctx, span := tracing.Start(ctx, "NightHoursService.GetNightHours", tracing.Period(from, to)...)
+	defer tracing.End(span, &err)
+
+	fromDate, toDate, err := parseDateRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.rowsRepo.GetByDateRange(ctx, fromDate.AddDate(0, 0, -1), toDate, models.ListQuery{
+		Filter: filter,
+		Fields: []string{"unko_no", "driver_code"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	unkoNos := make([]string, 0, len(rows))
+	driverOf := map[string]string{}
+	for _, row := range rows {
+		unkoNos = append(unkoNos, row.UnkoNo)
+		driverOf[row.UnkoNo] = row.DriverCode
+	}
+
+	events, err := loadTimeline(ctx, s.eventsRepo, unkoNos)
+	if err != nil {
+		return nil, err
+	}
+	var discard models.EventTimeBreakdown
+	fillTimeline(events, loadEventTypes(ctx, s.typesRepo), &discard)
+
+	firstDay := fromDate.Format("2006-01-02")
+	lastDay := toDate.Format("2006-01-02")
+	byKey := map[[2]string]*models.NightHours{}
+	for i := range events {
+		e := &events[i]
+		working := e.Category == models.EventCategoryWork || e.Category == models.EventCategoryWaiting
+		if e.Category != models.EventCategoryDriving && !working {
+			continue
+		}
+
+		for _, seg := range splitSegments(e.StartAt, eventEnd(e)) {
+			day := seg.day()
+			if !seg.night || day < firstDay || day > lastDay {
+				continue
+			}
+			key := [2]string{driverOf[e.UnkoNo], day}
+			nh, ok := byKey[key]
+			if !ok {
+				nh = &models.NightHours{DriverCode: key[0], Date: day}
+				byKey[key] = nh
+			}
+			if working {
+				nh.WorkMinutes += seg.minutes()
+			} else {
+				nh.DrivingMinutes += seg.minutes()
+			}
+			nh.TotalMinutes += seg.minutes()
+		}
+	}
+
+	names := loadMasterNames(ctx, s.mastersRepo)
+	results := make([]models.NightHours, 0, len(byKey))
+	for _, nh := range byKey {
+		nh.DriverName, nh.DriverCode = names.driver(nh.DriverCode)
+		results = append(results, *nh)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].DriverCode != results[j].DriverCode {
+			return results[i].DriverCode < results[j].DriverCode
+		}
+		return results[i].Date < results[j].Date
+	})
+
+	return results, nil
+}
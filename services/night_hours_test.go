@@ -0,0 +1,142 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/config"
+)
+
+func TestNextBoundary(t *testing.T) {
+	loc := config.BusinessLocation()
+	at := func(d, h, m int) time.Time { return time.Date(2025, 1, d, h, m, 0, 0, loc) }
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{"after midnight", at(10, 0, 0), at(10, 5, 0)},
+		{"before 05:00", at(10, 4, 59), at(10, 5, 0)},
+		{"at 05:00", at(10, 5, 0), at(10, 22, 0)},
+		{"daytime", at(10, 13, 30), at(10, 22, 0)},
+		{"at 22:00", at(10, 22, 0), at(11, 0, 0)},
+		{"late night", at(10, 23, 59), at(11, 0, 0)},
+		{"month end", time.Date(2025, 1, 31, 22, 30, 0, 0, loc), time.Date(2025, 2, 1, 0, 0, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		if got := nextBoundary(tt.t); !got.Equal(tt.want) {
+			t.Errorf("%s: nextBoundary(%s) = %s, want %s", tt.name, tt.t.Format("01-02 15:04"), got.Format("01-02 15:04"), tt.want.Format("01-02 15:04"))
+		}
+	}
+}
+
+func TestSplitSegments(t *testing.T) {
+	loc := config.BusinessLocation()
+	at := func(d, h, m int) time.Time { return time.Date(2025, 1, d, h, m, 0, 0, loc) }
+
+	type seg struct {
+		start, end time.Time
+		night      bool
+	}
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       []seg
+		night      float64
+	}{
+		{
+			name:  "daytime only",
+			start: at(10, 9, 0), end: at(10, 17, 0),
+			want:  []seg{{at(10, 9, 0), at(10, 17, 0), false}},
+			night: 0,
+		},
+		{
+			name:  "ends at 22:00",
+			start: at(10, 20, 0), end: at(10, 22, 0),
+			want:  []seg{{at(10, 20, 0), at(10, 22, 0), false}},
+			night: 0,
+		},
+		{
+			name:  "starts at 22:00",
+			start: at(10, 22, 0), end: at(10, 23, 0),
+			want:  []seg{{at(10, 22, 0), at(10, 23, 0), true}},
+			night: 60,
+		},
+		{
+			name:  "crosses 22:00",
+			start: at(10, 21, 0), end: at(10, 23, 30),
+			want: []seg{
+				{at(10, 21, 0), at(10, 22, 0), false},
+				{at(10, 22, 0), at(10, 23, 30), true},
+			},
+			night: 90,
+		},
+		{
+			name:  "crosses midnight and 05:00",
+			start: at(10, 23, 0), end: at(11, 6, 0),
+			want: []seg{
+				{at(10, 23, 0), at(11, 0, 0), true},
+				{at(11, 0, 0), at(11, 5, 0), true},
+				{at(11, 5, 0), at(11, 6, 0), false},
+			},
+			night: 360,
+		},
+		{
+			name:  "multi-day",
+			start: at(10, 12, 0), end: at(12, 3, 0),
+			want: []seg{
+				{at(10, 12, 0), at(10, 22, 0), false},
+				{at(10, 22, 0), at(11, 0, 0), true},
+				{at(11, 0, 0), at(11, 5, 0), true},
+				{at(11, 5, 0), at(11, 22, 0), false},
+				{at(11, 22, 0), at(12, 0, 0), true},
+				{at(12, 0, 0), at(12, 3, 0), true},
+			},
+			night: 2*60 + 5*60 + 2*60 + 3*60,
+		},
+		{
+			name:  "given in UTC",
+			start: at(10, 21, 0).UTC(), end: at(10, 22, 30).UTC(),
+			want: []seg{
+				{at(10, 21, 0), at(10, 22, 0), false},
+				{at(10, 22, 0), at(10, 22, 30), true},
+			},
+			night: 30,
+		},
+		{
+			name:  "empty",
+			start: at(10, 22, 0), end: at(10, 22, 0),
+			want:  nil,
+			night: 0,
+		},
+		{
+			name:  "reversed",
+			start: at(10, 23, 0), end: at(10, 22, 0),
+			want:  nil,
+			night: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitSegments(tt.start, tt.end)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d segments, want %d: %v", len(got), len(tt.want), got)
+			}
+			for i, w := range tt.want {
+				g := got[i]
+				if !g.start.Equal(w.start) || !g.end.Equal(w.end) || g.night != w.night {
+					t.Errorf("segment %d = %s-%s night=%v, want %s-%s night=%v", i,
+						g.start.Format("01-02 15:04"), g.end.Format("01-02 15:04"), g.night,
+						w.start.Format("01-02 15:04"), w.end.Format("01-02 15:04"), w.night)
+				}
+				if g.day() != w.start.Format("2006-01-02") {
+					t.Errorf("segment %d day = %s, want %s", i, g.day(), w.start.Format("2006-01-02"))
+				}
+			}
+			if n := nightMinutes(tt.start, tt.end); n != tt.night {
+				t.Errorf("nightMinutes = %v, want %v", n, tt.night)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/privacy"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// DefaultOdometerToleranceKm is the odometer difference between consecutive
+// trips that is still treated as continuous (rounding of the readings)
+const DefaultOdometerToleranceKm = 1.0
+
+// CheckOdometerContinuity compares, per vehicle, the end of each trip with
+// the start of the next one. A distance gap means driving that was not
+// recorded (e.g. a missing card read); an overlap means the odometer went
+// backwards or two trips cover the same distance or time.
+func (s *DtakoTripsService) CheckOdometerContinuity(ctx context.Context, from, to string, filter models.ListFilter, toleranceKm float64) (_ []models.VehicleOdometerReport, err error) {
+	ctx, span := tracing.Start(ctx, "DtakoTripsService.CheckOdometerContinuity", tracing.Period(from, to)...)
+	defer tracing.End(span, &err)
+
+	fromDate, toDate, err := parseDateRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if toleranceKm <= 0 {
+		toleranceKm = DefaultOdometerToleranceKm
+	}
+
+	trips, err := s.eventsRepo.TripOdometers(ctx, fromDate, toDate, filter)
+	if err != nil {
+		return nil, err
+	}
+	mask := privacy.For(ctx)
+	for i := range trips {
+		trips[i].DriverCode = mask.Code(trips[i].DriverCode)
+	}
+
+	return odometerReports(trips, toleranceKm), nil
+}
+
+// odometerReports groups trips ordered by vehicle and start time into one
+// report per vehicle with the discontinuities between consecutive trips
+func odometerReports(trips []models.OdometerTrip, toleranceKm float64) []models.VehicleOdometerReport {
+	reports := []models.VehicleOdometerReport{}
+	var report *models.VehicleOdometerReport
+	var prev *models.OdometerTrip         // 直前の運行（時刻の重複を検査）
+	var prevOdometer *models.OdometerTrip // 走行距離がある直前の運行（距離の不連続を検査）
+
+	for i := range trips {
+		trip := &trips[i]
+		if report == nil || report.VehicleNo != trip.VehicleNo {
+			reports = append(reports, models.VehicleOdometerReport{VehicleNo: trip.VehicleNo, Issues: []models.OdometerIssue{}})
+			report = &reports[len(reports)-1]
+			prev, prevOdometer = nil, nil
+		}
+		report.Trips++
+
+		report.Issues = append(report.Issues, compareTrips(prev, prevOdometer, trip, toleranceKm)...)
+		prev = trip
+		if hasOdometer(trip) {
+			prevOdometer = trip
+		} else {
+			report.TripsWithoutOdometer++
+		}
+	}
+
+	return reports
+}
+
+// hasOdometer reports whether a trip has both odometer readings
+func hasOdometer(trip *models.OdometerTrip) bool {
+	return trip.StartOdometer != nil && trip.EndOdometer != nil
+}
+
+// compareTrips returns the discontinuities between next and the trips before
+// it: a time overlap with the immediately previous trip prev, and a distance
+// gap or overlap with prevOdometer, the last previous trip with readings.
+// Either may be nil; distances are compared only when next has readings.
+func compareTrips(prev, prevOdometer, next *models.OdometerTrip, toleranceKm float64) []models.OdometerIssue {
+	var issues []models.OdometerIssue
+
+	if prevOdometer != nil && hasOdometer(next) {
+		gap := *next.StartOdometer - *prevOdometer.EndOdometer
+		issue := models.OdometerIssue{
+			Previous:       *prevOdometer,
+			Next:           *next,
+			DistanceGap:    &gap,
+			TimeGapMinutes: next.StartAt.Sub(prevOdometer.EndAt).Minutes(),
+		}
+		switch {
+		case gap > toleranceKm:
+			issue.Type = "distance_gap"
+			issues = append(issues, issue)
+		case gap < -toleranceKm:
+			issue.Type = "distance_overlap"
+			issues = append(issues, issue)
+		}
+	}
+
+	if prev != nil && next.StartAt.Before(prev.EndAt) {
+		issue := models.OdometerIssue{
+			Type:           "time_overlap",
+			Previous:       *prev,
+			Next:           *next,
+			TimeGapMinutes: next.StartAt.Sub(prev.EndAt).Minutes(),
+		}
+		if hasOdometer(prev) && hasOdometer(next) {
+			gap := *next.StartOdometer - *prev.EndOdometer
+			issue.DistanceGap = &gap
+		}
+		issues = append(issues, issue)
+	}
+
+	return issues
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// odometerTrip builds a trip of vehicle 101; start and end are "15:04" on
+// 2025-01-13 and nil odometer readings are given as negative values
+func odometerTrip(t *testing.T, unkoNo, start, end string, startKm, endKm float64) models.OdometerTrip {
+	t.Helper()
+	at := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02 15:04", "2025-01-13 "+s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	km := func(v float64) *float64 {
+		if v < 0 {
+			return nil
+		}
+		return &v
+	}
+	return models.OdometerTrip{UnkoNo: unkoNo, VehicleNo: "101", StartAt: at(start), EndAt: at(end), StartOdometer: km(startKm), EndOdometer: km(endKm)}
+}
+
+func TestCompareTrips(t *testing.T) {
+	prev := odometerTrip(t, "A", "08:00", "10:00", 1000, 1100)
+	noReadings := odometerTrip(t, "X", "10:30", "11:00", -1, -1)
+
+	tests := []struct {
+		name         string
+		prev         *models.OdometerTrip
+		prevOdometer *models.OdometerTrip
+		next         models.OdometerTrip
+		wantTypes    []string
+		wantGap      []float64 // DistanceGap per issue, -1 for none
+	}{
+		{
+			name: "continuous", prev: &prev, prevOdometer: &prev,
+			next: odometerTrip(t, "B", "11:00", "12:00", 1100, 1200),
+		},
+		{
+			name: "within tolerance", prev: &prev, prevOdometer: &prev,
+			next: odometerTrip(t, "B", "11:00", "12:00", 1100.8, 1200),
+		},
+		{
+			name: "distance gap", prev: &prev, prevOdometer: &prev,
+			next:      odometerTrip(t, "B", "11:00", "12:00", 1150, 1200),
+			wantTypes: []string{"distance_gap"}, wantGap: []float64{50},
+		},
+		{
+			name: "distance overlap", prev: &prev, prevOdometer: &prev,
+			next:      odometerTrip(t, "B", "11:00", "12:00", 1090, 1200),
+			wantTypes: []string{"distance_overlap"}, wantGap: []float64{-10},
+		},
+		{
+			name: "time overlap", prev: &prev, prevOdometer: &prev,
+			next:      odometerTrip(t, "B", "09:30", "12:00", 1100, 1200),
+			wantTypes: []string{"time_overlap"}, wantGap: []float64{0},
+		},
+		{
+			name: "distance and time overlap", prev: &prev, prevOdometer: &prev,
+			next:      odometerTrip(t, "B", "09:30", "12:00", 1050, 1200),
+			wantTypes: []string{"distance_overlap", "time_overlap"}, wantGap: []float64{-50, -50},
+		},
+		{
+			name: "time overlap with a trip without readings", prev: &noReadings, prevOdometer: &prev,
+			next:      odometerTrip(t, "B", "10:45", "12:00", 1100, 1200),
+			wantTypes: []string{"time_overlap"}, wantGap: []float64{-1},
+		},
+		{
+			name: "next without readings overlaps in time", prev: &prev, prevOdometer: &prev,
+			next:      odometerTrip(t, "B", "09:00", "12:00", -1, -1),
+			wantTypes: []string{"time_overlap"}, wantGap: []float64{-1},
+		},
+		{
+			name: "first trip", next: odometerTrip(t, "B", "09:00", "12:00", 1100, 1200),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := compareTrips(tt.prev, tt.prevOdometer, &tt.next, 1.0)
+
+			var types []string
+			for _, issue := range issues {
+				types = append(types, issue.Type)
+			}
+			if !reflect.DeepEqual(types, tt.wantTypes) {
+				t.Fatalf("types = %v, want %v", types, tt.wantTypes)
+			}
+			for i, issue := range issues {
+				switch want := tt.wantGap[i]; {
+				case want == -1 && issue.DistanceGap != nil:
+					t.Errorf("%s: distance gap = %v, want none", issue.Type, *issue.DistanceGap)
+				case want != -1 && (issue.DistanceGap == nil || *issue.DistanceGap != want):
+					t.Errorf("%s: distance gap = %v, want %v", issue.Type, issue.DistanceGap, want)
+				}
+			}
+		})
+	}
+}
+
+func TestOdometerReportsSkipsTripsWithoutReadingsForDistanceOnly(t *testing.T) {
+	trips := []models.OdometerTrip{
+		odometerTrip(t, "A", "08:00", "10:00", 1000, 1100),
+		// 走行距離のない運行: 距離は A と C で比較し、時刻は前後の運行と比較する
+		odometerTrip(t, "X", "09:30", "11:00", -1, -1),
+		odometerTrip(t, "C", "10:30", "12:00", 1100, 1200),
+	}
+
+	reports := odometerReports(trips, 1.0)
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reports))
+	}
+	r := reports[0]
+	if r.Trips != 3 || r.TripsWithoutOdometer != 1 {
+		t.Errorf("trips = %d without odometer = %d, want 3 and 1", r.Trips, r.TripsWithoutOdometer)
+	}
+
+	var got []string
+	for _, issue := range r.Issues {
+		got = append(got, issue.Type+":"+issue.Previous.UnkoNo+"-"+issue.Next.UnkoNo)
+	}
+	want := []string{"time_overlap:A-X", "time_overlap:X-C"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("issues = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/apperr"
+	"github.com/yhonda-ohishi/dtako_mod/auth"
+	"github.com/yhonda-ohishi/dtako_mod/config"
+	"github.com/yhonda-ohishi/dtako_mod/logging"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/privacy"
+	"github.com/yhonda-ohishi/dtako_mod/repositories"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// ErrInvalidPayrollMonth is returned for a month that is not YYYY-MM
+var ErrInvalidPayrollMonth = apperr.New(apperr.Validation, "invalid month")
+
+// payrollDailyHoursEnv sets the daily working hours above which time counts as overtime
+const payrollDailyHoursEnv = "PAYROLL_DAILY_HOURS"
+
+// defaultPayrollDailyHours is the statutory 8 hours per day
+const defaultPayrollDailyHours = 8
+
+// timelineBatchSize limits the number of 運行NO per events query
+const timelineBatchSize = 500
+
+// PayrollService computes monthly per-driver payroll figures and keeps
+// them as snapshots in dtako_payroll_summaries
+type PayrollService struct {
+	repo        *repositories.PayrollRepository
+	rowsRepo    *repositories.DtakoRowsRepository
+	eventsRepo  *repositories.DtakoEventsRepository
+	ferryRepo   *repositories.DtakoFerryRowsRepository
+	mastersRepo *repositories.MastersRepository
+	typesRepo   *repositories.EventTypesRepository
+}
+
+// NewPayrollService creates a new service instance
+func NewPayrollService() *PayrollService {
+	return &PayrollService{
+		repo:        repositories.NewPayrollRepository(),
+		rowsRepo:    repositories.NewDtakoRowsRepository(),
+		eventsRepo:  repositories.NewDtakoEventsRepository(),
+		ferryRepo:   repositories.NewDtakoFerryRowsRepository(),
+		mastersRepo: repositories.NewMastersRepository(),
+		typesRepo:   repositories.NewEventTypesRepository(),
+	}
+}
+
+// GetMonthly returns the summaries of a month (YYYY-MM), optionally for one
+// driver. The stored snapshot is used unless it is missing, marked stale by a
+// re-import, or recompute is set; then the whole month is computed again and stored.
+// For office-scoped callers the month is computed from the rows of their
+// offices only, without reading or storing the snapshot.
+func (s *PayrollService) GetMonthly(ctx context.Context, month, driver string, recompute bool) (_ []models.PayrollSummary, err error) {
+	ctx, span := tracing.Start(ctx, "PayrollService.GetMonthly")
+	defer tracing.End(span, &err)
+
+	start, err := time.ParseInLocation("2006-01", month, config.BusinessLocation())
+	if err != nil {
+		return nil, fmt.Errorf("%w: month must be YYYY-MM", ErrInvalidPayrollMonth)
+	}
+	if driver != "" && privacy.For(ctx).Codes() {
+		return nil, fmt.Errorf("%w: driver codes are masked for this role", auth.ErrForbidden)
+	}
+
+	var summaries []models.PayrollSummary
+	fresh := false
+	if _, restricted := auth.OfficeScope(ctx); restricted {
+		// スナップショットは全事業所分のため、事業所を限定された呼び出し元には
+		// その事業所の運行だけから都度集計し、保存しない
+		summaries, err = s.compute(ctx, start)
+		if err != nil {
+			return nil, err
+		}
+		fresh = true
+	} else if !recompute {
+		stored, stale, found, err := s.repo.GetMonth(ctx, month)
+		if err != nil {
+			return nil, err
+		}
+		summaries, fresh = stored, found && !stale
+	}
+
+	if !fresh {
+		version, err := s.repo.StaleVersion(ctx, month)
+		if err != nil {
+			return nil, err
+		}
+		summaries, err = s.compute(ctx, start)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.repo.ReplaceMonth(ctx, month, version, summaries); err != nil {
+			return nil, fmt.Errorf("failed to store payroll snapshot: %w", err)
+		}
+	}
+
+	names := loadMasterNames(ctx, s.mastersRepo)
+	results := []models.PayrollSummary{}
+	for _, summary := range summaries {
+		if driver != "" && summary.DriverCode != driver {
+			continue
+		}
+		summary.DriverName, summary.DriverCode = names.driver(summary.DriverCode)
+		results = append(results, summary)
+	}
+	return results, nil
+}
+
+// payrollDay accumulates one driver's 運行日
+type payrollDay struct {
+	first, last    time.Time
+	workingMinutes float64
+}
+
+// compute builds the summaries of every driver with rows in the month that
+// the caller of ctx may see.
+// Rows, events and ferry rows are all read from the local database, the
+// source whose imports mark snapshots stale.
+func (s *PayrollService) compute(ctx context.Context, month time.Time) ([]models.PayrollSummary, error) {
+	from, to := month, month.AddDate(0, 1, -1)
+	computedAt := config.Now()
+
+	rows, err := s.rowsRepo.GetByDateRange(ctx, from, to, models.ListQuery{
+		Sort: []models.SortField{{Field: "date"}, {Field: "unko_no"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	unkoNos := make([]string, 0, len(rows))
+	for _, row := range rows {
+		unkoNos = append(unkoNos, row.UnkoNo)
+	}
+
+	events, err := loadTimeline(ctx, s.eventsRepo, unkoNos)
+	if err != nil {
+		return nil, err
+	}
+	var discard models.EventTimeBreakdown
+	fillTimeline(events, loadEventTypes(ctx, s.typesRepo), &discard)
+
+	ferries, err := s.ferryRepo.GetByDateRange(ctx, from, to, models.ListQuery{Fields: []string{"unko_no"}})
+	if err != nil {
+		return nil, err
+	}
+
+	return summarizePayroll(month, rows, events, ferries, payrollDailyMinutes(), computedAt), nil
+}
+
+// summarizePayroll builds the per-driver summaries of a month from its rows,
+// the categorized timeline of their trips and the month's ferry rows.
+// dailyLimit is the working minutes per day above which time is overtime.
+func summarizePayroll(month time.Time, rows []models.DtakoRow, events []models.DailyReportEvent, ferries []models.DtakoFerryRow, dailyLimit float64, computedAt time.Time) []models.PayrollSummary {
+	driverOf := map[string]string{}
+	for _, row := range rows {
+		driverOf[row.UnkoNo] = row.DriverCode
+	}
+	eventsOf := map[string][]models.DailyReportEvent{}
+	for _, e := range events {
+		eventsOf[e.UnkoNo] = append(eventsOf[e.UnkoNo], e)
+	}
+	ferryTrips := map[string]int{}
+	for _, f := range ferries {
+		if driver, ok := driverOf[f.UnkoNo]; ok {
+			ferryTrips[driver]++
+		}
+	}
+
+	byDriver := map[string]*models.PayrollSummary{}
+	days := map[string]map[string]*payrollDay{}
+	for _, row := range rows {
+		summary, ok := byDriver[row.DriverCode]
+		if !ok {
+			summary = &models.PayrollSummary{
+				Month:      month.Format("2006-01"),
+				DriverCode: row.DriverCode,
+				FerryTrips: ferryTrips[row.DriverCode],
+				ComputedAt: computedAt,
+			}
+			byDriver[row.DriverCode] = summary
+			days[row.DriverCode] = map[string]*payrollDay{}
+		}
+		summary.Distance += row.Distance
+
+		date := row.Date.Format("2006-01-02")
+		day, ok := days[row.DriverCode][date]
+		if !ok {
+			day = &payrollDay{}
+			days[row.DriverCode][date] = day
+		}
+
+		for i := range eventsOf[row.UnkoNo] {
+			e := &eventsOf[row.UnkoNo][i]
+			end := eventEnd(e)
+			if day.first.IsZero() || e.StartAt.Before(day.first) {
+				day.first = e.StartAt
+			}
+			if end.After(day.last) {
+				day.last = end
+			}
+
+			switch e.Category {
+			case models.EventCategoryDriving:
+				summary.DrivingMinutes += e.Minutes
+			case models.EventCategoryWork, models.EventCategoryWaiting:
+			default:
+				// 休憩・その他は労働時間に含めない
+				continue
+			}
+			day.workingMinutes += e.Minutes
+			summary.NightMinutes += nightMinutes(e.StartAt, end)
+		}
+	}
+
+	summaries := make([]models.PayrollSummary, 0, len(byDriver))
+	for driver, summary := range byDriver {
+		summary.WorkingDays = len(days[driver])
+		for _, day := range days[driver] {
+			if !day.first.IsZero() {
+				summary.BindingMinutes += day.last.Sub(day.first).Minutes()
+			}
+			if day.workingMinutes > dailyLimit {
+				summary.OvertimeMinutes += day.workingMinutes - dailyLimit
+			}
+		}
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].DriverCode < summaries[j].DriverCode })
+
+	return summaries
+}
+
+// loadTimeline loads the imported events of the given trips in batches
+func loadTimeline(ctx context.Context, repo *repositories.DtakoEventsRepository, unkoNos []string) ([]models.DailyReportEvent, error) {
+	events := []models.DailyReportEvent{}
+	for start := 0; start < len(unkoNos); start += timelineBatchSize {
+		end := start + timelineBatchSize
+		if end > len(unkoNos) {
+			end = len(unkoNos)
+		}
+		batch, err := repo.GetLocalTimelineByUnkoNos(ctx, unkoNos[start:end])
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, batch...)
+	}
+	return events, nil
+}
+
+// payrollDailyMinutes reads PAYROLL_DAILY_HOURS (default 8) in minutes
+func payrollDailyMinutes() float64 {
+	if v := os.Getenv(payrollDailyHoursEnv); v != "" {
+		if hours, err := strconv.ParseFloat(v, 64); err == nil && hours > 0 {
+			return hours * 60
+		}
+		logging.Logger().Warn("invalid payroll daily hours, using default", "env", payrollDailyHoursEnv, "value", v, "default", defaultPayrollDailyHours)
+	}
+	return defaultPayrollDailyHours * 60
+}
+
+// monthSet collects the months (YYYY-MM) touched by an import
+type monthSet map[string]bool
+
+func (m monthSet) add(t time.Time) {
+	if !t.IsZero() {
+		m[t.Format("2006-01")] = true
+	}
+}
+
+// markPayrollStale flags payroll snapshots of re-imported months so they are
+// recomputed on next access. Failures only delay the recomputation.
+func markPayrollStale(ctx context.Context, repo *repositories.PayrollRepository, months monthSet) {
+	if len(months) == 0 {
+		return
+	}
+	list := make([]string, 0, len(months))
+	for m := range months {
+		list = append(list, m)
+	}
+	if err := repo.MarkStale(ctx, list); err != nil {
+		logging.Logger().Warn("failed to mark payroll snapshots stale", "months", list, "error", err)
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/config"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+func TestSummarizePayroll(t *testing.T) {
+	loc := config.BusinessLocation()
+	at := func(s string) time.Time {
+		ts, err := time.ParseInLocation("2006-01-02 15:04", s, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	event := func(unkoNo, category, start string, minutes float64) models.DailyReportEvent {
+		return models.DailyReportEvent{UnkoNo: unkoNo, Category: category, StartAt: at(start), Minutes: minutes}
+	}
+
+	month := time.Date(2025, 1, 1, 0, 0, 0, 0, loc)
+	rows := []models.DtakoRow{
+		{UnkoNo: "U1", DriverCode: "1001", Date: at("2025-01-13 00:00"), Distance: 300},
+		{UnkoNo: "U2", DriverCode: "1001", Date: at("2025-01-14 00:00"), Distance: 200},
+		{UnkoNo: "U3", DriverCode: "1002", Date: at("2025-01-13 00:00"), Distance: 50},
+	}
+	events := []models.DailyReportEvent{
+		// 1001 / 01-13: 20:00-02:00 運転 6h + 02:00-03:00 休憩 + 03:00-07:00 作業 4h → 労働10h, 深夜 22:00-02:00 と 03:00-05:00
+		event("U1", models.EventCategoryDriving, "2025-01-13 20:00", 360),
+		event("U1", models.EventCategoryRest, "2025-01-14 02:00", 60),
+		event("U1", models.EventCategoryWork, "2025-01-14 03:00", 240),
+		// 1001 / 01-14: 09:00-12:00 運転 3h
+		event("U2", models.EventCategoryDriving, "2025-01-14 09:00", 180),
+		// 1002 / 01-13: 10:00-10:30 待機
+		event("U3", models.EventCategoryWaiting, "2025-01-13 10:00", 30),
+	}
+	ferries := []models.DtakoFerryRow{{UnkoNo: "U1"}, {UnkoNo: "U1"}, {UnkoNo: "OTHER"}}
+	computedAt := at("2025-02-01 09:00")
+
+	summaries := summarizePayroll(month, rows, events, ferries, 8*60, computedAt)
+	if len(summaries) != 2 {
+		t.Fatalf("expected 2 drivers, got %+v", summaries)
+	}
+
+	want := []models.PayrollSummary{
+		{
+			Month: "2025-01", DriverCode: "1001", WorkingDays: 2,
+			BindingMinutes: 11*60 + 3*60, DrivingMinutes: 540,
+			NightMinutes: 240 + 120, OvertimeMinutes: 120,
+			Distance: 500, FerryTrips: 2, ComputedAt: computedAt,
+		},
+		{
+			Month: "2025-01", DriverCode: "1002", WorkingDays: 1,
+			BindingMinutes: 30, Distance: 50, ComputedAt: computedAt,
+		},
+	}
+	for i, w := range want {
+		if summaries[i] != w {
+			t.Errorf("driver %s:\n got %+v\nwant %+v", w.DriverCode, summaries[i], w)
+		}
+	}
+}
+
+func TestSummarizePayrollWithoutRows(t *testing.T) {
+	summaries := summarizePayroll(time.Now(), nil, nil, []models.DtakoFerryRow{{UnkoNo: "U1"}}, 480, time.Now())
+	if len(summaries) != 0 {
+		t.Errorf("expected no summaries, got %+v", summaries)
+	}
+}
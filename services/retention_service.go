@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/apperr"
+	"github.com/yhonda-ohishi/dtako_mod/config"
+	"github.com/yhonda-ohishi/dtako_mod/logging"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/repositories"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// ErrRetentionNotConfigured is returned when no valid retention period or
+// mode is set. It is a server configuration problem, not a bad request.
+var ErrRetentionNotConfigured = apperr.New(apperr.Unavailable, "driver data retention is not configured")
+
+const (
+	retentionDaysEnv = "DRIVER_DATA_RETENTION_DAYS"
+	retentionModeEnv = "DRIVER_DATA_RETENTION_MODE"
+)
+
+// RetentionService anonymizes or purges driver-identifying data older than
+// the configured retention period. Run it from the POST /retention/run
+// endpoint or from a scheduler of the host application.
+type RetentionService struct {
+	repo  *repositories.RetentionRepository
+	audit *auditTrail
+}
+
+// NewRetentionService creates a new service instance
+func NewRetentionService() *RetentionService {
+	return &RetentionService{
+		repo:  repositories.NewRetentionRepository(),
+		audit: newAuditTrail(),
+	}
+}
+
+// retentionParams are recorded in the audit log
+type retentionParams struct {
+	Mode          string `json:"mode"`
+	RetentionDays int    `json:"retention_days"`
+	Cutoff        string `json:"cutoff"`
+	DryRun        bool   `json:"dry_run"`
+}
+
+// Run processes records dated before today minus DRIVER_DATA_RETENTION_DAYS.
+// DRIVER_DATA_RETENTION_MODE anonymize (default) clears driver codes and
+// names of dtako_rows, dtako_events and dtako_ferry_rows, the messages of
+// their validation findings and the names of drivers past the period; purge
+// deletes them. Payroll snapshots of months before the cutoff are deleted
+// and driver master records in older audit entries cleared in both modes.
+// Imports skip records before the cutoff (see retentionFilter). With dryRun
+// nothing is changed and the report counts what would be.
+func (s *RetentionService) Run(ctx context.Context, dryRun bool) (_ *models.RetentionReport, err error) {
+	ctx, span := tracing.Start(ctx, "RetentionService.Run")
+	defer tracing.End(span, &err)
+
+	days, mode, err := retentionSettings()
+	if err != nil {
+		return nil, err
+	}
+	cutoff := config.Today().AddDate(0, 0, -days)
+
+	report := &models.RetentionReport{
+		Mode:          mode,
+		RetentionDays: days,
+		Cutoff:        cutoff.Format("2006-01-02"),
+		DryRun:        dryRun,
+		Tables:        []models.RetentionTableResult{},
+		StartedAt:     config.Now(),
+	}
+	defer func() {
+		result := &models.ImportResult{}
+		if !dryRun {
+			result.ImportedRows = int(report.TotalRecords)
+		}
+		params := retentionParams{Mode: mode, RetentionDays: days, Cutoff: report.Cutoff, DryRun: dryRun}
+		s.audit.record(ctx, models.AuditRetentionRun, "driver_data", params, result, err)
+	}()
+
+	var tables []models.RetentionTableResult
+	if mode == models.RetentionPurge {
+		tables, err = s.repo.Purge(ctx, cutoff, dryRun)
+		if !dryRun {
+			// 途中で失敗しても削除済みの行があり得るため、結果によらず消す
+			knownUnkoNos.clear()
+		}
+	} else {
+		tables, err = s.repo.Anonymize(ctx, cutoff, dryRun)
+	}
+	report.Tables = append(report.Tables, tables...)
+	if err == nil {
+		var payroll models.RetentionTableResult
+		payroll, err = s.repo.PurgePayroll(ctx, cutoff.Format("2006-01"), dryRun)
+		report.Tables = append(report.Tables, payroll)
+	}
+
+	for _, t := range report.Tables {
+		report.TotalRecords += t.Records
+	}
+	report.FinishedAt = config.Now()
+	if err != nil {
+		return nil, fmt.Errorf("retention %s failed after %d records: %w", mode, report.TotalRecords, err)
+	}
+
+	logging.Logger().InfoContext(ctx, "driver data retention run",
+		"mode", mode, "cutoff", report.Cutoff, "dry_run", dryRun, "records", report.TotalRecords)
+	return report, nil
+}
+
+// retentionSettings reads DRIVER_DATA_RETENTION_DAYS and DRIVER_DATA_RETENTION_MODE
+func retentionSettings() (int, string, error) {
+	days, err := retentionDays()
+	if err != nil {
+		return 0, "", err
+	}
+
+	mode := strings.ToLower(os.Getenv(retentionModeEnv))
+	switch mode {
+	case "":
+		mode = models.RetentionAnonymize
+	case models.RetentionAnonymize, models.RetentionPurge:
+	default:
+		return 0, "", fmt.Errorf("%w: %s must be anonymize or purge, got %q", ErrRetentionNotConfigured, retentionModeEnv, mode)
+	}
+	return days, mode, nil
+}
+
+// retentionDays reads DRIVER_DATA_RETENTION_DAYS
+func retentionDays() (int, error) {
+	v := os.Getenv(retentionDaysEnv)
+	if v == "" {
+		return 0, fmt.Errorf("%w: set %s", ErrRetentionNotConfigured, retentionDaysEnv)
+	}
+	days, err := strconv.Atoi(v)
+	if err != nil || days <= 0 {
+		return 0, fmt.Errorf("%w: %s must be a positive number of days, got %q", ErrRetentionNotConfigured, retentionDaysEnv, v)
+	}
+	return days, nil
+}
+
+// retentionFilter skips imported records dated before the retention cutoff,
+// so an import does not restore driver data a retention run already
+// anonymized or purged. Without a configured retention period it keeps all.
+type retentionFilter struct {
+	cutoff  time.Time
+	enabled bool
+	skipped int
+}
+
+// newRetentionFilter returns the filter for imports starting now
+func newRetentionFilter() *retentionFilter {
+	days, err := retentionDays()
+	if err != nil {
+		return &retentionFilter{}
+	}
+	return &retentionFilter{cutoff: config.Today().AddDate(0, 0, -days), enabled: true}
+}
+
+// keep reports whether a record dated date may be imported
+func (f *retentionFilter) keep(date time.Time) bool {
+	if !f.enabled || !date.Before(f.cutoff) {
+		return true
+	}
+	f.skipped++
+	return false
+}
+
+// apply reports the skipped records in result
+func (f *retentionFilter) apply(result *models.ImportResult) {
+	if f.skipped > 0 {
+		result.Message += fmt.Sprintf(", skipped %d dated before the retention cutoff %s", f.skipped, f.cutoff.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/config"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+func TestRetentionFilter(t *testing.T) {
+	today := config.Today()
+
+	t.Run("Skips records before the cutoff", func(t *testing.T) {
+		t.Setenv(retentionDaysEnv, "30")
+
+		f := newRetentionFilter()
+		cutoff := today.AddDate(0, 0, -30)
+		if !f.keep(cutoff) || !f.keep(today) {
+			t.Error("expected records on or after the cutoff to be kept")
+		}
+		if f.keep(cutoff.Add(-1)) || f.keep(cutoff.AddDate(-1, 0, 0)) {
+			t.Error("expected records before the cutoff to be skipped")
+		}
+
+		result := &models.ImportResult{Message: "Imported 2 rows"}
+		f.apply(result)
+		if !strings.Contains(result.Message, "skipped 2 dated before the retention cutoff "+cutoff.Format("2006-01-02")) {
+			t.Errorf("unexpected message %q", result.Message)
+		}
+	})
+
+	t.Run("Keeps everything without a retention period", func(t *testing.T) {
+		t.Setenv(retentionDaysEnv, "")
+
+		f := newRetentionFilter()
+		if !f.keep(today.AddDate(-10, 0, 0)) {
+			t.Error("expected old records to be kept")
+		}
+		result := &models.ImportResult{Message: "Imported 1 rows"}
+		f.apply(result)
+		if result.Message != "Imported 1 rows" {
+			t.Errorf("unexpected message %q", result.Message)
+		}
+	})
+}
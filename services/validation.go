@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yhonda-ohishi/dtako_mod/config"
+	"github.com/yhonda-ohishi/dtako_mod/logging"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// validationSeverityEnv overrides rule severities, e.g.
+// "dtako_rows.negative_distance=fix,dtako_events.orphan_unko_no=reject"
+const validationSeverityEnv = "VALIDATION_SEVERITY"
+
+// ValidationRule checks one record of type T during import.
+// Check returns a message describing the problem, or "" when the record is
+// fine; ctx is the import's context, for lookups.
+// Fix corrects the record in place and is required for SeverityFix.
+type ValidationRule[T any] struct {
+	Name     string
+	Severity models.ValidationSeverity
+	Check    func(ctx context.Context, record *T) string
+	Fix      func(record *T)
+}
+
+// Validator runs the validation rules of one table
+type Validator[T any] struct {
+	table string
+	rules []ValidationRule[T]
+}
+
+// newValidator creates a validator with the given built-in rules, applying
+// severity overrides from VALIDATION_SEVERITY
+func newValidator[T any](table string, rules ...ValidationRule[T]) *Validator[T] {
+	v := &Validator[T]{table: table}
+	for _, rule := range rules {
+		v.AddRule(rule)
+	}
+	return v
+}
+
+// AddRule registers a rule. A rule with the same name replaces the existing one.
+// Severity overrides from VALIDATION_SEVERITY apply to added rules as well.
+func (v *Validator[T]) AddRule(rule ValidationRule[T]) {
+	if severity, ok := severityOverride(v.table, rule.Name); ok {
+		rule.Severity = severity
+	}
+	if rule.Severity == models.SeverityFix && rule.Fix == nil {
+		logging.Logger().Warn("validation rule cannot fix records, using warn", "table", v.table, "rule", rule.Name)
+		rule.Severity = models.SeverityWarn
+	}
+
+	for i := range v.rules {
+		if v.rules[i].Name == rule.Name {
+			v.rules[i] = rule
+			return
+		}
+	}
+	v.rules = append(v.rules, rule)
+}
+
+// Validate runs every rule against record. Fix rules modify record in place.
+// rejected is true when at least one reject rule matched.
+func (v *Validator[T]) Validate(ctx context.Context, record *T, recordID string) (findings []models.ValidationFinding, rejected bool) {
+	now := config.Now()
+	for _, rule := range v.rules {
+		msg := rule.Check(ctx, record)
+		if msg == "" {
+			continue
+		}
+
+		switch rule.Severity {
+		case models.SeverityReject:
+			rejected = true
+		case models.SeverityFix:
+			rule.Fix(record)
+		}
+
+		findings = append(findings, models.ValidationFinding{
+			Table:     v.table,
+			RecordID:  recordID,
+			Rule:      rule.Name,
+			Severity:  rule.Severity,
+			Message:   msg,
+			CreatedAt: now,
+		})
+	}
+	return findings, rejected
+}
+
+// severityOverride looks up table.rule in VALIDATION_SEVERITY
+func severityOverride(table, rule string) (models.ValidationSeverity, bool) {
+	key := table + "." + rule
+	for _, entry := range strings.Split(os.Getenv(validationSeverityEnv), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || strings.TrimSpace(name) != key {
+			continue
+		}
+
+		severity := models.ValidationSeverity(strings.TrimSpace(value))
+		switch severity {
+		case models.SeverityReject, models.SeverityWarn, models.SeverityFix:
+			return severity, true
+		}
+		logging.Logger().Warn("invalid validation severity", "severity", value, "rule", key, "env", validationSeverityEnv)
+	}
+	return "", false
+}
+
+// importValidation collects findings over one import run
+type importValidation struct {
+	findings []models.ValidationFinding
+	rejected int
+}
+
+// record keeps findings of one record; returns false when the record must be skipped
+func (iv *importValidation) record(findings []models.ValidationFinding, rejected bool) bool {
+	iv.findings = append(iv.findings, findings...)
+	if rejected {
+		iv.rejected++
+	}
+	return !rejected
+}
+
+// apply reports findings in result and persists them for later review.
+// A persistence failure is reported as an import error, not a failed import.
+func (iv *importValidation) apply(ctx context.Context, result *models.ImportResult, store func(context.Context, []models.ValidationFinding) error) {
+	result.RejectedRows = iv.rejected
+	result.Findings = iv.findings
+	if iv.rejected > 0 {
+		result.Message += fmt.Sprintf(", rejected %d by validation", iv.rejected)
+	}
+	if err := store(ctx, iv.findings); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("Failed to store validation findings: %v", err))
+	}
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/repositories"
+	"github.com/yhonda-ohishi/dtako_mod/tracing"
+)
+
+// ValidationFindingsService provides access to persisted import validation findings
+type ValidationFindingsService struct {
+	repo *repositories.ValidationFindingsRepository
+}
+
+// NewValidationFindingsService creates a new service instance
+func NewValidationFindingsService() *ValidationFindingsService {
+	return &ValidationFindingsService{
+		repo: repositories.NewValidationFindingsRepository(),
+	}
+}
+
+// ListFindings retrieves findings recorded within date range, optionally for one table or rule
+func (s *ValidationFindingsService) ListFindings(ctx context.Context, from, to, table, rule string) (_ []models.ValidationFinding, err error) {
+	ctx, span := tracing.Start(ctx, "ValidationFindingsService.ListFindings", tracing.Period(from, to)...)
+	defer tracing.End(span, &err)
+
+	fromDate, toDate, err := parseDateRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.List(ctx, fromDate, toDate, table, rule)
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/yhonda-ohishi/dtako_mod/logging"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/repositories"
+)
+
+// newRowsValidator returns the built-in rules for dtako_rows
+func newRowsValidator() *Validator[models.DtakoRow] {
+	return newValidator("dtako_rows",
+		ValidationRule[models.DtakoRow]{
+			Name:     "missing_unko_no",
+			Severity: models.SeverityReject,
+			Check: func(_ context.Context, r *models.DtakoRow) string {
+				if r.UnkoNo == "" {
+					return "運行NO is empty"
+				}
+				return ""
+			},
+		},
+		ValidationRule[models.DtakoRow]{
+			Name:     "missing_date",
+			Severity: models.SeverityReject,
+			Check: func(_ context.Context, r *models.DtakoRow) string {
+				if r.Date.IsZero() {
+					return "運行日 is empty"
+				}
+				return ""
+			},
+		},
+		ValidationRule[models.DtakoRow]{
+			Name:     "negative_distance",
+			Severity: models.SeverityReject,
+			Check: func(_ context.Context, r *models.DtakoRow) string {
+				if r.Distance < 0 {
+					return fmt.Sprintf("総走行距離 is negative (%g)", r.Distance)
+				}
+				return ""
+			},
+			Fix: func(r *models.DtakoRow) { r.Distance = 0 },
+		},
+		ValidationRule[models.DtakoRow]{
+			Name:     "negative_fuel",
+			Severity: models.SeverityFix,
+			Check: func(_ context.Context, r *models.DtakoRow) string {
+				if r.FuelAmount < 0 {
+					return fmt.Sprintf("自社主燃料 is negative (%g)", r.FuelAmount)
+				}
+				return ""
+			},
+			Fix: func(r *models.DtakoRow) { r.FuelAmount = 0 },
+		},
+	)
+}
+
+// positiveCache remembers keys a lookup found. Validators are shared by
+// concurrent imports, so access is guarded.
+type positiveCache struct {
+	mu   sync.RWMutex
+	keys map[string]bool
+}
+
+func (c *positiveCache) has(key string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.keys[key]
+}
+
+// clear drops every key, e.g. after the looked-up data changed
+func (c *positiveCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keys = nil
+}
+
+// registeredEventTypes caches イベント名 found in the event type registry. It
+// is shared by every events validator so EventTypesService can clear it when
+// the registry changes; each events import also starts with an empty cache so
+// changes made through another instance apply from the next import.
+var registeredEventTypes = &positiveCache{}
+
+// knownUnkoNos caches 運行NO found in local dtako_rows. Rows are deleted by
+// the retention job, so it clears the cache after deleting; each events
+// import also starts with an empty cache.
+var knownUnkoNos = &positiveCache{}
+
+func (c *positiveCache) add(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.keys == nil {
+		c.keys = map[string]bool{}
+	}
+	c.keys[key] = true
+}
+
+// newEventsValidator returns the built-in rules for dtako_events.
+// orphan_unko_no looks up 運行NO in local dtako_rows, so rows should be
+// imported before events. unregistered_event_type looks up イベント名 in
+// the event type registry.
+func newEventsValidator(rowsRepo *repositories.DtakoRowsRepository, typesRepo *repositories.EventTypesRepository) *Validator[models.DtakoEvent] {
+	clearPosition := func(e *models.DtakoEvent) {
+		e.Latitude = nil
+		e.Longitude = nil
+	}
+	known := knownUnkoNos
+	registered := registeredEventTypes
+
+	return newValidator("dtako_events",
+		ValidationRule[models.DtakoEvent]{
+			Name:     "missing_event_date",
+			Severity: models.SeverityReject,
+			Check: func(_ context.Context, e *models.DtakoEvent) string {
+				if e.EventDate.IsZero() {
+					return "開始日時 is empty"
+				}
+				return ""
+			},
+		},
+		ValidationRule[models.DtakoEvent]{
+			Name:     "gps_zero",
+			Severity: models.SeverityFix,
+			Check: func(_ context.Context, e *models.DtakoEvent) string {
+				if e.Latitude != nil && e.Longitude != nil && *e.Latitude == 0 && *e.Longitude == 0 {
+					return "GPS position is (0,0)"
+				}
+				return ""
+			},
+			Fix: clearPosition,
+		},
+		ValidationRule[models.DtakoEvent]{
+			Name:     "gps_out_of_range",
+			Severity: models.SeverityFix,
+			Check: func(_ context.Context, e *models.DtakoEvent) string {
+				if e.Latitude != nil && (*e.Latitude < -90 || *e.Latitude > 90) {
+					return fmt.Sprintf("latitude out of range (%g)", *e.Latitude)
+				}
+				if e.Longitude != nil && (*e.Longitude < -180 || *e.Longitude > 180) {
+					return fmt.Sprintf("longitude out of range (%g)", *e.Longitude)
+				}
+				return ""
+			},
+			Fix: clearPosition,
+		},
+		ValidationRule[models.DtakoEvent]{
+			Name:     "orphan_unko_no",
+			Severity: models.SeverityWarn,
+			Check: func(ctx context.Context, e *models.DtakoEvent) string {
+				if e.UnkoNo == "" {
+					return ""
+				}
+				if known.has(e.UnkoNo) {
+					return ""
+				}
+				exists, err := rowsRepo.ExistsByUnkoNo(ctx, e.UnkoNo)
+				if err != nil {
+					// 確認できない場合は指摘しない
+					logging.Logger().Warn("orphan_unko_no check failed", "unko_no", e.UnkoNo, "error", err)
+					return ""
+				}
+				if !exists {
+					return fmt.Sprintf("運行NO %s has no dtako_rows record", e.UnkoNo)
+				}
+				known.add(e.UnkoNo)
+				return ""
+			},
+		},
+		ValidationRule[models.DtakoEvent]{
+			Name:     "unregistered_event_type",
+			Severity: models.SeverityWarn,
+			Check: func(ctx context.Context, e *models.DtakoEvent) string {
+				if registered.has(e.EventType) {
+					return ""
+				}
+				exists, err := typesRepo.Exists(ctx, e.EventType)
+				if err != nil {
+					logging.Logger().Warn("unregistered_event_type check failed", "event_type", e.EventType, "error", err)
+					return ""
+				}
+				if !exists {
+					return fmt.Sprintf("イベント名 %q is not in the event type registry", e.EventType)
+				}
+				registered.add(e.EventType)
+				return ""
+			},
+		},
+	)
+}
+
+// newFerryRowsValidator returns the built-in rules for dtako_ferry_rows
+func newFerryRowsValidator() *Validator[models.DtakoFerryRow] {
+	return newValidator("dtako_ferry_rows",
+		ValidationRule[models.DtakoFerryRow]{
+			Name:     "end_before_start",
+			Severity: models.SeverityReject,
+			Check: func(_ context.Context, f *models.DtakoFerryRow) string {
+				if !f.StartTime.IsZero() && !f.EndTime.IsZero() && f.EndTime.Before(f.StartTime) {
+					return fmt.Sprintf("終了日時 %s is before 開始日時 %s",
+						f.EndTime.Format("2006-01-02 15:04:05"), f.StartTime.Format("2006-01-02 15:04:05"))
+				}
+				return ""
+			},
+		},
+		ValidationRule[models.DtakoFerryRow]{
+			Name:     "negative_fare",
+			Severity: models.SeverityWarn,
+			Check: func(_ context.Context, f *models.DtakoFerryRow) string {
+				if f.StandardFare < 0 || f.ContractFare < 0 {
+					return fmt.Sprintf("negative fare (標準料金 %d, 契約料金 %d)", f.StandardFare, f.ContractFare)
+				}
+				return ""
+			},
+			Fix: func(f *models.DtakoFerryRow) {
+				f.StandardFare = max(f.StandardFare, 0)
+				f.ContractFare = max(f.ContractFare, 0)
+			},
+		},
+		ValidationRule[models.DtakoFerryRow]{
+			Name:     "negative_estimated_distance",
+			Severity: models.SeverityFix,
+			Check: func(_ context.Context, f *models.DtakoFerryRow) string {
+				if f.EstimatedDistance < 0 {
+					return fmt.Sprintf("見なし距離 is negative (%d)", f.EstimatedDistance)
+				}
+				return ""
+			},
+			Fix: func(f *models.DtakoFerryRow) { f.EstimatedDistance = 0 },
+		},
+	)
+}
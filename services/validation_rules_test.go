@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// Validators are shared by concurrent imports; unguarded maps would abort
+// the process with "concurrent map writes"
+func TestPositiveCacheConcurrentImports(t *testing.T) {
+	cache := &positiveCache{}
+
+	var wg sync.WaitGroup
+	for run := 0; run < 8; run++ {
+		wg.Add(1)
+		go func(run int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := strconv.Itoa(i)
+				if !cache.has(key) {
+					cache.add(key)
+				}
+			}
+		}(run)
+	}
+	wg.Wait()
+
+	for i := 0; i < 1000; i++ {
+		if !cache.has(strconv.Itoa(i)) {
+			t.Fatalf("Expected key %d to be cached", i)
+		}
+	}
+	if cache.has("missing") {
+		t.Error("Expected unknown key not to be cached")
+	}
+}
+
+// A deleted event type must fail unregistered_event_type again, so registry
+// changes clear the shared cache
+func TestRegisteredEventTypesCleared(t *testing.T) {
+	registeredEventTypes.add("休憩")
+	if !registeredEventTypes.has("休憩") {
+		t.Fatal("Expected event type to be cached")
+	}
+
+	registeredEventTypes.clear()
+	if registeredEventTypes.has("休憩") {
+		t.Error("Expected cleared cache to look up the registry again")
+	}
+}
+
+// orphan_unko_no uses the shared cache that the retention purge clears, so
+// rows it deletes fail the rule again
+func TestEventsValidatorUsesSharedUnkoNoCache(t *testing.T) {
+	v := newEventsValidator(nil, nil)
+	knownUnkoNos.add("2025011301")
+	defer knownUnkoNos.clear()
+
+	var rule ValidationRule[models.DtakoEvent]
+	for _, r := range v.rules {
+		if r.Name == "orphan_unko_no" {
+			rule = r
+		}
+	}
+	event := &models.DtakoEvent{UnkoNo: "2025011301"}
+	if msg := rule.Check(context.Background(), event); msg != "" {
+		t.Fatalf("Expected the cached 運行NO to pass, got %q", msg)
+	}
+
+	knownUnkoNos.clear()
+	if knownUnkoNos.has("2025011301") {
+		t.Error("Expected cleared cache to look up dtako_rows again")
+	}
+}
@@ -0,0 +1,108 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// Contract test GET /dtako/audit
+func TestGetAuditLog(t *testing.T) {
+	r := SetupTestRouter()
+
+	t.Run("Unknown action is 400", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/audit?action=rows.delete", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+		decodeProblem(t, rec)
+	})
+
+	t.Run("Invalid date is 400", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/audit?from=2025-13-01", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("Invalid page is 400", func(t *testing.T) {
+		for _, query := range []string{"limit=-1", "offset=x", "limit=10001"} {
+			req := httptest.NewRequest("GET", "/dtako/audit?"+query, nil)
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status %d, got %d", query, http.StatusBadRequest, rec.Code)
+			}
+		}
+	})
+
+	t.Run("Failed import is recorded", func(t *testing.T) {
+		payload := []byte(`{"from_date":"2025-02-01","to_date":"2025-01-01"}`)
+		req := httptest.NewRequest("POST", "/dtako/rows/import", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Request-Id", "audit-test-1")
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("Expected import status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+
+		req = httptest.NewRequest("GET", "/dtako/audit?action=rows.import&actor=anonymous", nil)
+		rec = httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code == http.StatusServiceUnavailable {
+			t.Skip("Local database unavailable")
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+
+		var entries []models.AuditEntry
+		if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+
+		var found *models.AuditEntry
+		for i := range entries {
+			if entries[i].RequestID == "audit-test-1" {
+				found = &entries[i]
+				break
+			}
+		}
+		if found == nil {
+			t.Fatalf("Expected an audit entry for request audit-test-1, got %d entries", len(entries))
+		}
+		if found.Success || len(found.Errors) == 0 {
+			t.Errorf("Expected failed entry with errors, got %+v", found)
+		}
+		if found.Actor != "anonymous" || found.Target != "dtako_rows" {
+			t.Errorf("Expected anonymous actor and dtako_rows target, got %s / %s", found.Actor, found.Target)
+		}
+
+		var params models.ImportRequest
+		if err := json.Unmarshal(found.Params, &params); err != nil {
+			t.Fatalf("Failed to parse params: %v", err)
+		}
+		if params.FromDate != "2025-02-01" || params.ToDate != "2025-01-01" {
+			t.Errorf("Expected the ImportRequest as params, got %+v", params)
+		}
+	})
+}
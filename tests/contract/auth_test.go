@@ -0,0 +1,265 @@
+package contract
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/yhonda-ohishi/dtako_mod/auth"
+)
+
+// writeJWKS writes the public part of key as a single-key JWK Set
+func writeJWKS(t *testing.T, key *ecdsa.PrivateKey, kid string) string {
+	t.Helper()
+
+	enc := base64.RawURLEncoding
+	set := map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "EC",
+			"crv": "P-256",
+			"kid": kid,
+			"use": "sig",
+			"x":   enc.EncodeToString(key.PublicKey.X.FillBytes(make([]byte, 32))),
+			"y":   enc.EncodeToString(key.PublicKey.Y.FillBytes(make([]byte, 32))),
+		}},
+	}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// signToken signs claims with key as ES256
+func signToken(t *testing.T, key *ecdsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// Contract test for authentication, roles and office scope
+func TestAuthentication(t *testing.T) {
+	r := SetupTestRouter()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwtAuth, err := auth.NewJWTAuthenticator(auth.JWTConfig{
+		JWKSFile: writeJWKS(t, key, "test-key"),
+		Issuer:   "https://idp.example.com",
+		// 事業所CDのクレームのないトークンは全事業所（token(role, nil)）
+		MissingOfficeClaimAllOffices: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to load JWKS: %v", err)
+	}
+	viewerKeyHash := sha256.Sum256([]byte("viewer-secret"))
+	keyAuth, err := auth.NewAPIKeyAuthenticator([]auth.APIKey{
+		{Name: "dashboard", KeySHA256: hex.EncodeToString(viewerKeyHash[:]), Role: "viewer"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to load API keys: %v", err)
+	}
+
+	auth.SetAuthenticator(auth.Chain{jwtAuth, keyAuth})
+	defer auth.SetAuthenticator(nil)
+
+	token := func(role string, offices interface{}) string {
+		claims := jwt.MapClaims{
+			"sub":  "user-" + role,
+			"iss":  "https://idp.example.com",
+			"exp":  time.Now().Add(time.Hour).Unix(),
+			"role": role,
+		}
+		if offices != nil {
+			claims["office_cds"] = offices
+		}
+		return signToken(t, key, "test-key", claims)
+	}
+
+	do := func(method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	bearer := func(token string) map[string]string {
+		return map[string]string{"Authorization": "Bearer " + token}
+	}
+
+	// 不正な日付範囲 → 認証・認可を通過すれば 400（DB不要）
+	const invalidImport = `{"from_date":"2025-02-01","to_date":"2025-01-01"}`
+
+	t.Run("Missing credentials are 401", func(t *testing.T) {
+		rec := do("GET", "/dtako/rows", "", nil)
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected status %d, got %d", http.StatusUnauthorized, rec.Code)
+		}
+		if rec.Header().Get("WWW-Authenticate") == "" {
+			t.Error("Expected WWW-Authenticate header")
+		}
+		problem := decodeProblem(t, rec)
+		if problem.Type != "urn:dtako:problem:unauthenticated" {
+			t.Errorf("Expected unauthenticated problem type, got %s", problem.Type)
+		}
+	})
+
+	t.Run("Probes stay public", func(t *testing.T) {
+		if rec := do("GET", "/dtako/healthz", "", nil); rec.Code != http.StatusOK {
+			t.Errorf("healthz: expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("Metrics require credentials", func(t *testing.T) {
+		if rec := do("GET", "/dtako/metrics", "", nil); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Anonymous: expected status %d, got %d", http.StatusUnauthorized, rec.Code)
+		}
+		if rec := do("GET", "/dtako/metrics", "", bearer(token("viewer", []interface{}{"10"}))); rec.Code != http.StatusForbidden {
+			t.Errorf("Office-scoped: expected status %d, got %d", http.StatusForbidden, rec.Code)
+		}
+		if rec := do("GET", "/dtako/metrics", "", bearer(token("viewer", nil))); rec.Code != http.StatusOK {
+			t.Errorf("Viewer: expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("Invalid tokens are 401", func(t *testing.T) {
+		expired := signToken(t, key, "test-key", jwt.MapClaims{
+			"iss": "https://idp.example.com", "role": "admin",
+			"exp": time.Now().Add(-time.Hour).Unix(),
+		})
+		wrongKey := signToken(t, otherKey, "test-key", jwt.MapClaims{
+			"iss": "https://idp.example.com", "role": "admin",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		wrongIssuer := signToken(t, key, "test-key", jwt.MapClaims{
+			"iss": "https://other.example.com", "role": "admin",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		hmac, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"iss": "https://idp.example.com", "role": "admin",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte("secret"))
+
+		cases := map[string]string{
+			"expired":      expired,
+			"wrong key":    wrongKey,
+			"wrong issuer": wrongIssuer,
+			"HS256":        hmac,
+			"unknown role": token("superuser", nil),
+			"empty office": token("viewer", []interface{}{}),
+		}
+		for name, tok := range cases {
+			rec := do("GET", "/dtako/rows", "", bearer(tok))
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("%s: expected status %d, got %d", name, http.StatusUnauthorized, rec.Code)
+			}
+		}
+	})
+
+	t.Run("Roles gate endpoints", func(t *testing.T) {
+		viewer := bearer(token("viewer", nil))
+
+		if rec := do("GET", "/dtako/rows?sort=no_such_field", "", viewer); rec.Code != http.StatusBadRequest {
+			t.Errorf("Viewer list: expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+
+		rec := do("POST", "/dtako/rows/import", invalidImport, viewer)
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("Viewer import: expected status %d, got %d", http.StatusForbidden, rec.Code)
+		}
+		if problem := decodeProblem(t, rec); problem.Type != "urn:dtako:problem:forbidden" {
+			t.Errorf("Expected forbidden problem type, got %s", problem.Type)
+		}
+
+		operator := bearer(token("operator", nil))
+		if rec := do("POST", "/dtako/rows/import", invalidImport, operator); rec.Code != http.StatusBadRequest {
+			t.Errorf("Operator import: expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+		if rec := do("DELETE", "/dtako/geofences/1", "", operator); rec.Code != http.StatusForbidden {
+			t.Errorf("Operator geofence delete: expected status %d, got %d", http.StatusForbidden, rec.Code)
+		}
+
+		admin := bearer(token("admin", nil))
+		if rec := do("POST", "/dtako/geofences/", `{`, admin); rec.Code != http.StatusBadRequest {
+			t.Errorf("Admin geofence create: expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("Office-scoped callers cannot use all-office endpoints", func(t *testing.T) {
+		scoped := bearer(token("admin", []interface{}{"10", 20}))
+
+		for _, c := range []struct{ method, path, body string }{
+			{"POST", "/dtako/rows/import", invalidImport},
+			{"POST", "/dtako/payroll/monthly/recompute?month=2025-01", ""},
+			{"GET", "/dtako/integrity", ""},
+			{"POST", "/dtako/masters/drivers", `{"code":"1","name":"x"}`},
+		} {
+			if rec := do(c.method, c.path, c.body, scoped); rec.Code != http.StatusForbidden {
+				t.Errorf("%s %s: expected status %d, got %d", c.method, c.path, http.StatusForbidden, rec.Code)
+			}
+		}
+
+		// 事業所で絞り込めるエンドポイントは利用可能（DB不要なエラーで確認）
+		for _, c := range []struct {
+			path string
+			want int
+		}{
+			{"/dtako/rows?sort=no_such_field", http.StatusBadRequest},
+			{"/dtako/payroll/monthly?month=2025-13", http.StatusBadRequest},
+			{"/dtako/validation/findings?from=not-a-date", http.StatusBadRequest},
+			{"/dtako/masters/no_such_kind", http.StatusNotFound},
+		} {
+			if rec := do("GET", c.path, "", scoped); rec.Code != c.want {
+				t.Errorf("%s: expected status %d, got %d", c.path, c.want, rec.Code)
+			}
+		}
+	})
+
+	t.Run("API keys", func(t *testing.T) {
+		valid := map[string]string{auth.APIKeyHeader: "viewer-secret"}
+		if rec := do("GET", "/dtako/rows?sort=no_such_field", "", valid); rec.Code != http.StatusBadRequest {
+			t.Errorf("Valid key: expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+		if rec := do("POST", "/dtako/rows/import", invalidImport, valid); rec.Code != http.StatusForbidden {
+			t.Errorf("Viewer key import: expected status %d, got %d", http.StatusForbidden, rec.Code)
+		}
+
+		invalid := map[string]string{auth.APIKeyHeader: "wrong-secret"}
+		if rec := do("GET", "/dtako/rows", "", invalid); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Invalid key: expected status %d, got %d", http.StatusUnauthorized, rec.Code)
+		}
+	})
+}
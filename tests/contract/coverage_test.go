@@ -0,0 +1,65 @@
+package contract
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// Contract test GET /dtako/coverage
+func TestGetCoverage(t *testing.T) {
+	os.Setenv("ACTIVE_VEHICLES", "9999:1")
+	defer os.Unsetenv("ACTIVE_VEHICLES")
+
+	r := SetupTestRouter()
+
+	t.Run("Matrix includes active vehicles without data", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/coverage?from=2025-01-01&to=2025-01-07&office=1", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+
+		var report models.CoverageReport
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if len(report.Dates) != 7 {
+			t.Errorf("Expected 7 dates, got %d", len(report.Dates))
+		}
+
+		found := false
+		for _, v := range report.Vehicles {
+			if len(v.Days) != len(report.Dates) {
+				t.Errorf("Vehicle %s has %d days, expected %d", v.VehicleNo, len(v.Days), len(report.Dates))
+			}
+			if v.VehicleNo == "9999" {
+				found = true
+				if !v.Active || v.MissingDays != 7 {
+					t.Errorf("Expected active vehicle 9999 missing on 7 days, got active=%v missing=%d", v.Active, v.MissingDays)
+				}
+			}
+		}
+		if !found {
+			t.Error("Expected configured active vehicle 9999 in the matrix")
+		}
+	})
+
+	t.Run("Reject ranges over the limit", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/coverage?from=2025-01-01&to=2025-12-31", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
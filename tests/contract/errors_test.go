@@ -0,0 +1,111 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// decodeProblem checks the RFC 7807 content type and decodes the body
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) models.ErrorResponse {
+	t.Helper()
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("Expected application/problem+json, got %q", ct)
+	}
+	var problem models.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("Failed to parse problem: %v. Body: %s", err, rec.Body.String())
+	}
+	if problem.Status != rec.Code || problem.Code != rec.Code {
+		t.Errorf("Expected status/code %d in body, got %d/%d", rec.Code, problem.Status, problem.Code)
+	}
+	if problem.Title != http.StatusText(rec.Code) {
+		t.Errorf("Expected title %q, got %q", http.StatusText(rec.Code), problem.Title)
+	}
+	if problem.Detail == "" || problem.Message != problem.Detail {
+		t.Errorf("Expected detail and matching message, got %+v", problem)
+	}
+	return problem
+}
+
+// Contract test for problem+json error responses
+func TestErrorResponses(t *testing.T) {
+	r := SetupTestRouter()
+
+	t.Run("Validation errors are 400", func(t *testing.T) {
+		payload := []byte(`{"from_date":"2025-02-01","to_date":"2025-01-01"}`)
+		req := httptest.NewRequest("POST", "/dtako/rows/import", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Request-Id", "problem-test-1")
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+		}
+		problem := decodeProblem(t, rec)
+		if problem.Type != "urn:dtako:problem:validation" {
+			t.Errorf("Expected validation problem type, got %s", problem.Type)
+		}
+		if problem.Instance != "/dtako/rows/import" {
+			t.Errorf("Expected instance /dtako/rows/import, got %s", problem.Instance)
+		}
+		if problem.RequestID != "problem-test-1" {
+			t.Errorf("Expected request_id problem-test-1, got %s", problem.RequestID)
+		}
+	})
+
+	t.Run("Malformed body is 400", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/dtako/events/import", bytes.NewReader([]byte(`{`)))
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+		decodeProblem(t, rec)
+	})
+
+	t.Run("Invalid list query is 400", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/rows?sort=no_such_field", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+		decodeProblem(t, rec)
+	})
+
+	t.Run("Missing record is 404, database outage is 503", func(t *testing.T) {
+		for _, path := range []string{"/dtako/rows/no-such-row", "/dtako/events/no-such-event", "/dtako/ferry_rows/no-such-ferry-row"} {
+			req := httptest.NewRequest("GET", path, nil)
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			problem := decodeProblem(t, rec)
+			switch rec.Code {
+			case http.StatusNotFound:
+				if problem.Type != "urn:dtako:problem:not-found" {
+					t.Errorf("%s: expected not-found problem type, got %s", path, problem.Type)
+				}
+			case http.StatusServiceUnavailable:
+				// DBに接続できない場合は 404 ではなく 503
+				if problem.Type != "urn:dtako:problem:upstream-unavailable" {
+					t.Errorf("%s: expected upstream-unavailable problem type, got %s", path, problem.Type)
+				}
+			default:
+				t.Errorf("%s: expected 404 or 503, got %d. Body: %s", path, rec.Code, rec.Body.String())
+			}
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// Contract test /dtako/event_types
+func TestEventTypes(t *testing.T) {
+	r := SetupTestRouter()
+	path := "/dtako/event_types/" + url.PathEscape("テスト点検")
+
+	t.Run("Register and list an event type", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"category": "work"})
+		req := httptest.NewRequest("PUT", path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+
+		req = httptest.NewRequest("GET", "/dtako/event_types", nil)
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		var types []models.EventType
+		if err := json.Unmarshal(rec.Body.Bytes(), &types); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		found := false
+		for _, et := range types {
+			if et.Name == "テスト点検" {
+				found = true
+				if et.Category != models.EventCategoryWork {
+					t.Errorf("Expected category work, got %s", et.Category)
+				}
+			}
+		}
+		if !found {
+			t.Error("Expected registered event type in the list")
+		}
+	})
+
+	t.Run("Registered type is accepted by event import", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"from_date": "2025-01-01", "to_date": "2025-01-31", "event_type": "テスト点検"})
+		req := httptest.NewRequest("POST", "/dtako/events/import", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("Reject unknown category", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"category": "sleeping"})
+		req := httptest.NewRequest("PUT", path, bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("Delete event type", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNoContent {
+			t.Errorf("Expected status %d, got %d", http.StatusNoContent, rec.Code)
+		}
+
+		req = httptest.NewRequest("DELETE", path, nil)
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d for missing event type, got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+}
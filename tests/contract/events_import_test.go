@@ -71,7 +71,7 @@ func TestPostDtakoEventsImport(t *testing.T) {
 				"to_date":     "2025-01-31",
 				"event_type":  "INVALID_TYPE",
 			},
-			expectedStatus: http.StatusInternalServerError, // or BadRequest
+			expectedStatus: http.StatusBadRequest,
 			validateBody:   nil,
 		},
 	}
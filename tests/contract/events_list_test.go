@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/yhonda-ohishi/dtako_mod/config"
 	"github.com/yhonda-ohishi/dtako_mod/models"
 )
 
@@ -67,6 +68,31 @@ func TestGetDtakoEvents(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:           "Get events outside 2024-09-13..15",
+			queryParams:    "?from=2025-03-01&to=2025-03-31&limit=500",
+			expectedStatus: http.StatusOK,
+			validateBody: func(t *testing.T, body []byte) {
+				var events []models.DtakoEvent
+				if err := json.Unmarshal(body, &events); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if len(events) > 500 {
+					t.Errorf("Expected at most 500 events, got %d", len(events))
+				}
+				for _, event := range events {
+					day := event.EventDate.In(config.BusinessLocation()).Format("2006-01-02")
+					if day < "2025-03-01" || day > "2025-03-31" {
+						t.Errorf("Event %s on %s is outside the requested range", event.ID, day)
+					}
+				}
+			},
+		},
+		{
+			name:           "Offset without limit is 400",
+			queryParams:    "?from=2025-03-01&to=2025-03-31&offset=10",
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name:           "Get events with date range and type filter",
 			queryParams:    "?from=2025-01-01&to=2025-01-31&type=START",
@@ -0,0 +1,105 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// Contract test for /dtako/geofences CRUD and visit detection
+func TestGeofences(t *testing.T) {
+	r := SetupTestRouter()
+
+	t.Run("Reject invalid geofence", func(t *testing.T) {
+		for name, body := range map[string]string{
+			"circle without center":  `{"name":"bad","category":"depot","shape":"circle"}`,
+			"latitude out of range":  `{"name":"bad","category":"depot","shape":"circle","center":{"lat":135.6,"lng":35.6},"radius_m":200}`,
+			"longitude out of range": `{"name":"bad","category":"port","shape":"polygon","polygon":[{"lat":35,"lng":139},{"lat":35,"lng":190},{"lat":36,"lng":139}]}`,
+			"crossing edges":         `{"name":"bad","category":"port","shape":"polygon","polygon":[{"lat":35,"lng":139},{"lat":36,"lng":140},{"lat":35,"lng":140},{"lat":36,"lng":139}]}`,
+		} {
+			req := httptest.NewRequest("POST", "/dtako/geofences", bytes.NewReader([]byte(body)))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status %d, got %d", name, http.StatusBadRequest, rec.Code)
+			}
+		}
+	})
+
+	t.Run("Create, update and delete geofence", func(t *testing.T) {
+		fence := models.Geofence{
+			Name:     "東京営業所",
+			Category: "depot",
+			Shape:    "circle",
+			Center:   &models.LatLng{Lat: 35.6762, Lng: 139.6503},
+			RadiusM:  300,
+		}
+		body, _ := json.Marshal(fence)
+		req := httptest.NewRequest("POST", "/dtako/geofences", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, rec.Code, rec.Body.String())
+		}
+
+		var created models.Geofence
+		if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if created.ID == 0 {
+			t.Fatal("Expected generated ID")
+		}
+		path := fmt.Sprintf("/dtako/geofences/%d", created.ID)
+
+		// Update to polygon
+		created.Shape = "polygon"
+		created.Polygon = []models.LatLng{
+			{Lat: 35.67, Lng: 139.64}, {Lat: 35.67, Lng: 139.66}, {Lat: 35.68, Lng: 139.66},
+		}
+		body, _ = json.Marshal(created)
+		req = httptest.NewRequest("PUT", path, bytes.NewReader(body))
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected status %d on update, got %d", http.StatusOK, rec.Code)
+		}
+
+		// Visits for the test trip
+		req = httptest.NewRequest("GET", fmt.Sprintf("/dtako/geofences/visits?from=2024-01-15&to=2024-01-15&geofence_id=%d", created.ID), nil)
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected status %d on visits, got %d", http.StatusOK, rec.Code)
+		}
+		var visits []models.GeofenceVisit
+		if err := json.Unmarshal(rec.Body.Bytes(), &visits); err != nil {
+			t.Errorf("Failed to unmarshal visits: %v", err)
+		}
+
+		// Delete
+		req = httptest.NewRequest("DELETE", path, nil)
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNoContent {
+			t.Errorf("Expected status %d on delete, got %d", http.StatusNoContent, rec.Code)
+		}
+
+		req = httptest.NewRequest("GET", path, nil)
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d after delete, got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+}
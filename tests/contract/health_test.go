@@ -0,0 +1,86 @@
+package contract
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// Contract test GET /dtako/healthz
+func TestGetHealthz(t *testing.T) {
+	r := SetupTestRouter()
+
+	req := httptest.NewRequest("GET", "/dtako/healthz", nil)
+	rec := httptest.NewRecorder()
+
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var report models.LivenessReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if report.Status != models.HealthStatusOK {
+		t.Errorf("Expected status ok, got %s", report.Status)
+	}
+}
+
+// Contract test GET /dtako/readyz
+func TestGetReadyz(t *testing.T) {
+	r := SetupTestRouter()
+
+	req := httptest.NewRequest("GET", "/dtako/readyz", nil)
+	rec := httptest.NewRecorder()
+
+	r.ServeHTTP(rec, req)
+
+	var report models.ReadinessReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	// DBが無い環境でも 503 とエラー内容を返す（panic しない）
+	switch report.Status {
+	case models.HealthStatusOK, models.HealthStatusDegraded:
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusOK, report.Status, rec.Code)
+		}
+	case models.HealthStatusUnavailable:
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusServiceUnavailable, report.Status, rec.Code)
+		}
+	default:
+		t.Fatalf("Unexpected readiness status %q", report.Status)
+	}
+
+	if len(report.Databases) != 2 {
+		t.Fatalf("Expected local and production checks, got %d", len(report.Databases))
+	}
+	for i, name := range []string{"local", "production"} {
+		db := report.Databases[i]
+		if db.Name != name {
+			t.Errorf("Expected database %s, got %s", name, db.Name)
+		}
+		if db.Status != models.HealthStatusOK {
+			if db.Error == "" || db.LastError == "" || db.LastErrorAt == nil {
+				t.Errorf("Expected error and last_error for unavailable %s database, got %+v", name, db)
+			}
+		}
+	}
+	if len(report.Imports) != 3 {
+		t.Errorf("Expected the last import of rows, events and ferry_rows, got %+v", report.Imports)
+	}
+	for _, imp := range report.Imports {
+		switch imp.Status {
+		case models.ImportStatusOK, models.ImportStatusNever, models.ImportStatusUnknown:
+		default:
+			t.Errorf("Unexpected import status for %s: %q", imp.Table, imp.Status)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package contract
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/logging"
+)
+
+// Contract test for structured request logging
+func TestStructuredLogging(t *testing.T) {
+	r := SetupTestRouter()
+
+	var buf bytes.Buffer
+	defer logging.SetLogger(logging.Logger())
+	logging.SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	t.Run("Request ID propagated to response and logs", func(t *testing.T) {
+		buf.Reset()
+		req := httptest.NewRequest("GET", "/dtako/metrics", nil)
+		req.Header.Set("X-Request-Id", "test-request-1")
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+		if got := rec.Header().Get("X-Request-Id"); got != "test-request-1" {
+			t.Errorf("Expected X-Request-Id test-request-1, got %q", got)
+		}
+		if !strings.Contains(buf.String(), `"request_id":"test-request-1"`) {
+			t.Errorf("Expected request_id in access log, got: %s", buf.String())
+		}
+		if !strings.Contains(buf.String(), `"route":"/dtako/metrics"`) {
+			t.Errorf("Expected route pattern in access log, got: %s", buf.String())
+		}
+	})
+
+	t.Run("Request ID generated when absent", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/metrics", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Header().Get("X-Request-Id") == "" {
+			t.Error("Expected a generated X-Request-Id")
+		}
+	})
+
+	t.Run("Driver names and DB credentials redacted", func(t *testing.T) {
+		buf.Reset()
+		logging.Logger().Info("test",
+			"driver_name", "山田太郎",
+			"error", errors.New("dial dtako:s3cret@tcp(db:3306)/dtako_local failed"))
+
+		out := buf.String()
+		for _, secret := range []string{"山田太郎", "s3cret"} {
+			if strings.Contains(out, secret) {
+				t.Errorf("Sensitive value %q leaked into log: %s", secret, out)
+			}
+		}
+		if !strings.Contains(out, logging.Redacted) {
+			t.Errorf("Expected %s marker, got: %s", logging.Redacted, out)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// Contract test /dtako/masters/{kind}
+func TestMastersCRUD(t *testing.T) {
+	r := SetupTestRouter()
+
+	// 前回の実行結果を削除
+	req := httptest.NewRequest("DELETE", "/dtako/masters/vehicles/T900", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	t.Run("Create, update and delete a vehicle", func(t *testing.T) {
+		body, _ := json.Marshal(models.MasterRecord{Code: "T900", Name: "テスト車輌", OfficeCode: "1"})
+		req := httptest.NewRequest("POST", "/dtako/masters/vehicles", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, rec.Code, rec.Body.String())
+		}
+
+		req = httptest.NewRequest("POST", "/dtako/masters/vehicles", bytes.NewReader(body))
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusConflict {
+			t.Errorf("Expected status %d for duplicate code, got %d", http.StatusConflict, rec.Code)
+		}
+
+		body, _ = json.Marshal(models.MasterRecord{Name: "テスト車輌2", OfficeCode: "1"})
+		req = httptest.NewRequest("PUT", "/dtako/masters/vehicles/T900", bytes.NewReader(body))
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+
+		req = httptest.NewRequest("GET", "/dtako/masters/vehicles/T900", nil)
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		var record models.MasterRecord
+		if err := json.Unmarshal(rec.Body.Bytes(), &record); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if record.Name != "テスト車輌2" {
+			t.Errorf("Expected updated name, got %q", record.Name)
+		}
+
+		req = httptest.NewRequest("DELETE", "/dtako/masters/vehicles/T900", nil)
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNoContent {
+			t.Errorf("Expected status %d, got %d", http.StatusNoContent, rec.Code)
+		}
+
+		req = httptest.NewRequest("GET", "/dtako/masters/vehicles/T900", nil)
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d after delete, got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+
+	t.Run("Import CSV", func(t *testing.T) {
+		csv := "code,name\nT901,テスト港A\nT902,テスト港B\n"
+		req := httptest.NewRequest("POST", "/dtako/masters/ports/import", strings.NewReader(csv))
+		req.Header.Set("Content-Type", "text/csv")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+
+		var result models.ImportResult
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if result.ImportedRows != 2 {
+			t.Errorf("Expected 2 imported rows, got %d", result.ImportedRows)
+		}
+
+		req = httptest.NewRequest("POST", "/dtako/masters/ports/import", strings.NewReader("code,name\n,名称のみ\n"))
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for missing code, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("Unknown kind", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/masters/unknown", nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+}
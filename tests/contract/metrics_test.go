@@ -0,0 +1,71 @@
+package contract
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Contract test GET /dtako/metrics
+func TestGetMetrics(t *testing.T) {
+	r := SetupTestRouter()
+
+	scrape := func(t *testing.T) string {
+		req := httptest.NewRequest("GET", "/dtako/metrics", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+			t.Errorf("Expected Prometheus text format, got %s", ct)
+		}
+		return rec.Body.String()
+	}
+
+	t.Run("Latency labelled by route pattern", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/rows/metrics-test-id", nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		body := scrape(t)
+		if !strings.Contains(body, `dtako_http_request_duration_seconds_count{method="GET",route="/dtako/rows/{id}"`) {
+			t.Errorf("Expected histogram for /dtako/rows/{id}, got:\n%s", body)
+		}
+		// 生のパスはラベルにしない
+		if strings.Contains(body, "metrics-test-id") {
+			t.Error("Raw request path leaked into a metric label")
+		}
+	})
+
+	t.Run("Import counters per table", func(t *testing.T) {
+		payload := []byte(`{"from_date":"2024-01-01","to_date":"2024-01-01"}`)
+		req := httptest.NewRequest("POST", "/dtako/rows/import", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		body := scrape(t)
+		for _, name := range []string{
+			`dtako_import_fetched_rows_total{table="dtako_rows"}`,
+			`dtako_import_upserted_rows_total{table="dtako_rows"}`,
+			`dtako_import_failed_rows_total{reason="insert",table="dtako_rows"}`,
+			`dtako_import_duration_seconds_count{result="ok",table="dtako_rows"}`,
+		} {
+			if !strings.Contains(body, name) {
+				t.Errorf("Expected %s in metrics output", name)
+			}
+		}
+	})
+
+	t.Run("Connection pool gauges for both databases", func(t *testing.T) {
+		body := scrape(t)
+		for _, db := range []string{"local", "production"} {
+			if !strings.Contains(body, `go_sql_open_connections{db_name="`+db+`"}`) {
+				t.Errorf("Expected pool stats for %s database", db)
+			}
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package contract
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Contract test for NDJSON streaming on GET /dtako/rows, /dtako/events and /dtako/ferry_rows
+func TestListNDJSON(t *testing.T) {
+	r := SetupTestRouter()
+
+	endpoints := []string{
+		"/dtako/rows?from=2024-01-01&to=2024-01-31",
+		"/dtako/events?from=2024-01-01&to=2024-01-31",
+		"/dtako/ferry_rows?from=2024-01-01&to=2024-01-31",
+	}
+
+	for _, endpoint := range endpoints {
+		t.Run(endpoint, func(t *testing.T) {
+			req := httptest.NewRequest("GET", endpoint, nil)
+			req.Header.Set("Accept", "application/x-ndjson")
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+			}
+
+			if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+				t.Errorf("Expected Content-Type application/x-ndjson, got %s", ct)
+			}
+
+			// Every line must be a standalone JSON object
+			scanner := bufio.NewScanner(rec.Body)
+			for scanner.Scan() {
+				var record map[string]interface{}
+				if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+					t.Errorf("Line is not a JSON object: %s", scanner.Text())
+				}
+			}
+		})
+	}
+
+	t.Run("Invalid date returns error before streaming", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/rows?from=invalid", nil)
+		req.Header.Set("Accept", "application/x-ndjson")
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code == http.StatusOK {
+			t.Error("Expected error status for invalid date")
+		}
+	})
+}
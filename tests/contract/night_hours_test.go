@@ -0,0 +1,58 @@
+package contract
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// Contract test GET /dtako/payroll/night_hours
+func TestGetNightHours(t *testing.T) {
+	r := SetupTestRouter()
+
+	t.Run("Night minutes per driver and day", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/payroll/night_hours?from=2024-01-01&to=2024-01-31&driver=1", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+
+		var results []models.NightHours
+		if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		for _, nh := range results {
+			if nh.DriverCode != "1" {
+				t.Errorf("Expected driver 1, got %s", nh.DriverCode)
+			}
+			if nh.Date < "2024-01-01" || nh.Date > "2024-01-31" {
+				t.Errorf("Date %s outside the requested range", nh.Date)
+			}
+			if math.Abs(nh.TotalMinutes-(nh.DrivingMinutes+nh.WorkMinutes)) > 0.01 {
+				t.Errorf("%s: total %v is not driving + work", nh.Date, nh.TotalMinutes)
+			}
+			// 1暦日の深夜時間帯は 00:00-05:00 と 22:00-24:00 の7時間
+			if nh.TotalMinutes > 7*60 {
+				t.Errorf("%s: %v night minutes exceed 7 hours in one day", nh.Date, nh.TotalMinutes)
+			}
+		}
+	})
+
+	t.Run("Reject unsupported filter", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/payroll/night_hours?boarding=1", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
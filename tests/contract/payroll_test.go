@@ -0,0 +1,71 @@
+package contract
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// Contract test GET /dtako/payroll/monthly
+func TestGetMonthlyPayroll(t *testing.T) {
+	r := SetupTestRouter()
+
+	t.Run("Summary for one driver", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/payroll/monthly?month=2024-01&driver=1", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+
+		var summaries []models.PayrollSummary
+		if err := json.Unmarshal(rec.Body.Bytes(), &summaries); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		for _, s := range summaries {
+			if s.DriverCode != "1" || s.Month != "2024-01" {
+				t.Errorf("Unexpected summary %s/%s", s.Month, s.DriverCode)
+			}
+			if s.WorkingDays == 0 {
+				t.Error("Expected working days for a driver with rows")
+			}
+			if s.NightMinutes > s.BindingMinutes {
+				t.Errorf("Night minutes %v exceed binding minutes %v", s.NightMinutes, s.BindingMinutes)
+			}
+		}
+	})
+
+	t.Run("Recompute returns CSV", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/dtako/payroll/monthly/recompute?month=2024-01&format=csv", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/csv") {
+			t.Errorf("Expected text/csv, got %s", rec.Header().Get("Content-Type"))
+		}
+		if !strings.Contains(rec.Body.String(), "month,driver_code,driver_name,working_days") {
+			t.Error("Expected CSV header row")
+		}
+	})
+
+	t.Run("Invalid month", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/payroll/monthly?month=2024-13", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
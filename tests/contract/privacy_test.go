@@ -0,0 +1,153 @@
+package contract
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/auth"
+	"github.com/yhonda-ohishi/dtako_mod/models"
+	"github.com/yhonda-ohishi/dtako_mod/privacy"
+)
+
+// Contract test for driver data masking and POST /dtako/retention/run
+func TestDriverPrivacy(t *testing.T) {
+	r := SetupTestRouter()
+
+	apiKey := func(name, secret, role string) auth.APIKey {
+		hash := sha256.Sum256([]byte(secret))
+		return auth.APIKey{Name: name, KeySHA256: hex.EncodeToString(hash[:]), Role: role}
+	}
+	keyAuth, err := auth.NewAPIKeyAuthenticator([]auth.APIKey{
+		apiKey("dashboard", "viewer-secret", "viewer"),
+		apiKey("retention-job", "admin-secret", "admin"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to load API keys: %v", err)
+	}
+
+	auth.SetAuthenticator(keyAuth)
+	defer auth.SetAuthenticator(nil)
+	privacy.SetPolicy(privacy.Policy{NameRole: auth.RoleAdmin, CodeRole: auth.RoleAdmin, Key: []byte("test-mask-key")})
+	defer privacy.ResetPolicy()
+
+	do := func(method, target, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set(auth.APIKeyHeader, key)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Masked caller cannot filter or sort by driver", func(t *testing.T) {
+		for _, target := range []string{
+			"/dtako/rows?driver=1001",
+			"/dtako/events?driver=1001",
+			"/dtako/ferry_rows?driver=1001",
+			"/dtako/payroll/monthly?month=2025-01&driver=1001",
+			"/dtako/reports/daily?driver=1001&date=2025-01-13",
+			"/dtako/rows?sort=driver_code",
+			"/dtako/events?sort=-driver_code",
+			"/dtako/ferry_rows?sort=driver_code_1",
+		} {
+			rec := do("GET", target, "viewer-secret")
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("%s: expected status %d, got %d", target, http.StatusForbidden, rec.Code)
+			}
+		}
+	})
+
+	t.Run("Rows are masked below the policy role", func(t *testing.T) {
+		rec := do("GET", "/dtako/rows?from=2025-01-01&to=2025-01-31", "viewer-secret")
+		if rec.Code == http.StatusServiceUnavailable || rec.Code == http.StatusInternalServerError {
+			t.Skip("Local database unavailable")
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+
+		var rows []models.DtakoRow
+		if err := json.Unmarshal(rec.Body.Bytes(), &rows); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		for _, row := range rows {
+			if row.DriverName != "" && row.DriverName != privacy.MaskedName {
+				t.Errorf("Row %s: expected masked driver name, got %q", row.ID, row.DriverName)
+			}
+			if row.DriverCode != "" && row.DriverCode != "0" && !strings.HasPrefix(row.DriverCode, "D-") {
+				t.Errorf("Row %s: expected pseudonymized driver code, got %q", row.ID, row.DriverCode)
+			}
+		}
+	})
+
+	t.Run("Retention run requires admin", func(t *testing.T) {
+		rec := do("POST", "/dtako/retention/run?dry_run=true", "viewer-secret")
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("Expected status %d, got %d", http.StatusForbidden, rec.Code)
+		}
+	})
+
+	t.Run("Retention run without a configured period is 503", func(t *testing.T) {
+		t.Setenv("DRIVER_DATA_RETENTION_DAYS", "")
+
+		rec := do("POST", "/dtako/retention/run?dry_run=true", "admin-secret")
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("Expected status %d, got %d", http.StatusServiceUnavailable, rec.Code)
+		}
+		decodeProblem(t, rec)
+	})
+
+	t.Run("Invalid retention mode is 503", func(t *testing.T) {
+		t.Setenv("DRIVER_DATA_RETENTION_DAYS", "1095")
+		t.Setenv("DRIVER_DATA_RETENTION_MODE", "archive")
+
+		rec := do("POST", "/dtako/retention/run?dry_run=true", "admin-secret")
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("Expected status %d, got %d", http.StatusServiceUnavailable, rec.Code)
+		}
+	})
+
+	t.Run("Invalid dry_run is 400", func(t *testing.T) {
+		rec := do("POST", "/dtako/retention/run?dry_run=maybe", "admin-secret")
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("Dry run reports every table", func(t *testing.T) {
+		t.Setenv("DRIVER_DATA_RETENTION_DAYS", "1095")
+		t.Setenv("DRIVER_DATA_RETENTION_MODE", "purge")
+
+		rec := do("POST", "/dtako/retention/run?dry_run=true", "admin-secret")
+		if rec.Code == http.StatusServiceUnavailable || rec.Code == http.StatusInternalServerError {
+			t.Skip("Local database unavailable")
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+
+		var report models.RetentionReport
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if !report.DryRun || report.Mode != models.RetentionPurge || report.RetentionDays != 1095 {
+			t.Errorf("Unexpected report settings: %+v", report)
+		}
+		tables := map[string]bool{}
+		for _, table := range report.Tables {
+			tables[table.Table] = true
+		}
+		for _, want := range []string{
+			"dtako_rows", "dtako_events", "dtako_ferry_rows", "dtako_payroll_summaries",
+			"dtako_validation_findings", "dtako_drivers", "dtako_audit_log",
+		} {
+			if !tables[want] {
+				t.Errorf("Expected %s in report, got %+v", want, report.Tables)
+			}
+		}
+	})
+}
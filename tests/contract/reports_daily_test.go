@@ -0,0 +1,98 @@
+package contract
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// Contract test GET /dtako/reports/daily
+func TestGetDailyReport(t *testing.T) {
+	r := SetupTestRouter()
+
+	t.Run("JSON report for a driver's day", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/reports/daily?driver=1&date=2024-01-15", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+
+		var report models.DailyReport
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if report.Totals.TripCount != len(report.Trips) || len(report.Trips) == 0 {
+			t.Errorf("Expected trip_count to match %d trips", len(report.Trips))
+		}
+		for _, trip := range report.Trips {
+			if trip.UnkoNo == "" {
+				t.Error("Expected unko_no on every trip")
+			}
+		}
+	})
+
+	t.Run("HTML report", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/reports/daily?driver=1&date=2024-01-15&format=html", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+			t.Errorf("Expected text/html, got %s", rec.Header().Get("Content-Type"))
+		}
+		if !strings.Contains(rec.Body.String(), "運転日報") {
+			t.Error("Expected report title in HTML")
+		}
+	})
+
+	t.Run("PDF report", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/reports/daily?driver=1&date=2024-01-15&format=pdf", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code == http.StatusNotImplemented {
+			t.Skip("No Japanese font installed (REPORT_FONT_PATH)")
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		if !strings.HasPrefix(rec.Body.String(), "%PDF") {
+			t.Error("Expected a PDF document")
+		}
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"date=2024-01-15", "driver=1&date=2024/01/15", "driver=1&date=2024-01-15&format=xml"} {
+			req := httptest.NewRequest("GET", "/dtako/reports/daily?"+query, nil)
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status %d, got %d", query, http.StatusBadRequest, rec.Code)
+			}
+		}
+	})
+
+	t.Run("No trips", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/reports/daily?driver=1&date=1999-01-01", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+}
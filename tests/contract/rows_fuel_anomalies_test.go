@@ -0,0 +1,56 @@
+package contract
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// Contract test GET /dtako/rows/fuel_anomalies
+func TestGetFuelAnomalies(t *testing.T) {
+	r := SetupTestRouter()
+
+	t.Run("List anomalies with custom thresholds", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/rows/fuel_anomalies?from=2025-01-01&to=2025-01-31&z=2.5&pct=-1", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+
+		var anomalies []models.FuelAnomaly
+		if err := json.Unmarshal(rec.Body.Bytes(), &anomalies); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if anomalies == nil {
+			t.Error("Expected array response, got nil")
+		}
+		for _, a := range anomalies {
+			if a.ZScore == nil || math.Abs(*a.ZScore) < 2.5 {
+				t.Errorf("Trip %s flagged below z threshold", a.UnkoNo)
+			}
+			if a.BaselineTrips == 0 {
+				t.Errorf("Trip %s has no baseline context", a.UnkoNo)
+			}
+		}
+	})
+
+	t.Run("Reject invalid options", func(t *testing.T) {
+		for _, params := range []string{"?window=0", "?z=abc", "?z=-1&pct=-1"} {
+			req := httptest.NewRequest("GET", "/dtako/rows/fuel_anomalies"+params, nil)
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected 400 for %s, got %d", params, rec.Code)
+			}
+		}
+	})
+}
@@ -70,7 +70,7 @@ func TestPostDtakoRowsImport(t *testing.T) {
 				FromDate: "invalid-date",
 				ToDate:   "2025-01-31",
 			},
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusBadRequest,
 			validateBody:   nil,
 		},
 		{
@@ -79,7 +79,7 @@ func TestPostDtakoRowsImport(t *testing.T) {
 				FromDate: "2025-02-01",
 				ToDate:   "2025-01-01",
 			},
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusBadRequest,
 			validateBody:   nil,
 		},
 	}
@@ -61,7 +61,7 @@ func TestGetDtakoRows(t *testing.T) {
 		{
 			name:           "Invalid date format",
 			queryParams:    "?from=invalid&to=2025-01-31",
-			expectedStatus: http.StatusInternalServerError, // or BadRequest depending on implementation
+			expectedStatus: http.StatusBadRequest,
 			validateBody:   nil,
 		},
 	}
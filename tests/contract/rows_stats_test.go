@@ -0,0 +1,85 @@
+package contract
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// Contract test GET /dtako/rows/stats
+func TestGetDtakoRowStats(t *testing.T) {
+	r := SetupTestRouter()
+
+	tests := []struct {
+		name           string
+		queryParams    string
+		expectedStatus int
+		validateBody   func(*testing.T, *models.RowStats)
+	}{
+		{
+			name:           "Totals without grouping",
+			queryParams:    "?from=2025-01-01&to=2025-01-31",
+			expectedStatus: http.StatusOK,
+			validateBody: func(t *testing.T, stats *models.RowStats) {
+				if stats.Groups == nil {
+					t.Error("Expected groups array, got nil")
+				}
+				if stats.PreviousFrom != "" || stats.Total.Previous != nil {
+					t.Error("Expected no previous period without compare")
+				}
+			},
+		},
+		{
+			name:           "Grouped by month with previous period",
+			queryParams:    "?from=2025-01-01&to=2025-01-31&group_by=month&compare=previous",
+			expectedStatus: http.StatusOK,
+			validateBody: func(t *testing.T, stats *models.RowStats) {
+				if stats.PreviousFrom != "2024-12-01" || stats.PreviousTo != "2024-12-31" {
+					t.Errorf("Expected previous period 2024-12-01..2024-12-31, got %s..%s", stats.PreviousFrom, stats.PreviousTo)
+				}
+				if stats.Total.Previous == nil {
+					t.Error("Expected previous totals")
+				}
+				for _, g := range stats.Groups {
+					if len(g.Key) != len("2025-01") {
+						t.Errorf("Expected YYYY-MM key, got %q", g.Key)
+					}
+				}
+			},
+		},
+		{
+			name:           "Unsupported group_by",
+			queryParams:    "?group_by=route",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Unsupported compare",
+			queryParams:    "?compare=last_year",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/dtako/rows/stats"+tt.queryParams, nil)
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+
+			if tt.validateBody != nil {
+				var stats models.RowStats
+				if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				tt.validateBody(t, &stats)
+			}
+		})
+	}
+}
@@ -3,10 +3,14 @@ package contract
 import (
 	"github.com/go-chi/chi/v5"
 	"github.com/yhonda-ohishi/dtako_mod"
+	"github.com/yhonda-ohishi/dtako_mod/auth"
 )
 
-// SetupTestRouter creates a test router with dtako routes mounted at /dtako
+// SetupTestRouter creates a test router with dtako routes mounted at /dtako.
+// Authentication is explicitly disabled; auth tests install their own authenticator.
 func SetupTestRouter() *chi.Mux {
+	auth.SetAuthenticator(nil)
+
 	r := chi.NewRouter()
 
 	// Mount dtako_mod routes at /dtako prefix for testing
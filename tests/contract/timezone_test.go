@@ -0,0 +1,63 @@
+package contract
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/dtako_mod/config"
+)
+
+// Contract test: dates are interpreted and returned in the business time zone
+// regardless of the server TZ (TestMain runs with a non-JST time.Local)
+func TestBusinessTimeZone(t *testing.T) {
+	r := SetupTestRouter()
+	offset := time.Date(2024, 1, 15, 0, 0, 0, 0, config.BusinessLocation()).Format("-07:00")
+
+	t.Run("Date filter selects the business day", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/rows?from=2024-01-15&to=2024-01-15", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+
+		var rows []map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &rows); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		for _, row := range rows {
+			date, _ := row["date"].(string)
+			if date != "2024-01-15T00:00:00"+offset {
+				t.Errorf("Expected date 2024-01-15 at midnight with offset %s, got %s", offset, date)
+			}
+		}
+	})
+
+	t.Run("Generated timestamps carry the offset", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/dtako/payroll/monthly/recompute?month=2024-01", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+
+		var summaries []map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &summaries); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		for _, s := range summaries {
+			computedAt, _ := s["computed_at"].(string)
+			if !strings.HasSuffix(computedAt, offset) {
+				t.Errorf("Expected computed_at with offset %s, got %s", offset, computedAt)
+			}
+		}
+	})
+}
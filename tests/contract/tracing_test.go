@@ -0,0 +1,54 @@
+package contract
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Contract test for request tracing
+func TestRequestTracing(t *testing.T) {
+	r := SetupTestRouter()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	defer provider.Shutdown(context.Background())
+
+	t.Run("Incoming trace context is continued", func(t *testing.T) {
+		const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		req := httptest.NewRequest("GET", "/dtako/metrics", nil)
+		req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+
+		spans := recorder.Ended()
+		if len(spans) == 0 {
+			t.Fatal("Expected a server span")
+		}
+		span := spans[len(spans)-1]
+		if span.Name() != "GET /dtako/metrics" {
+			t.Errorf("Expected span name 'GET /dtako/metrics', got %q", span.Name())
+		}
+		if span.SpanKind() != trace.SpanKindServer {
+			t.Errorf("Expected server span, got %s", span.SpanKind())
+		}
+		if got := span.SpanContext().TraceID().String(); got != traceID {
+			t.Errorf("Expected trace ID %s, got %s", traceID, got)
+		}
+		if !span.Parent().IsRemote() {
+			t.Error("Expected remote parent span")
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package contract
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// Contract test GET /dtako/trips/odometer_continuity
+func TestGetOdometerContinuity(t *testing.T) {
+	r := SetupTestRouter()
+
+	t.Run("Report per vehicle", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/trips/odometer_continuity?from=2024-09-13&to=2024-09-15&tolerance_km=2", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+
+		var reports []models.VehicleOdometerReport
+		if err := json.Unmarshal(rec.Body.Bytes(), &reports); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		for _, report := range reports {
+			for _, issue := range report.Issues {
+				if issue.Previous.VehicleNo != report.VehicleNo || issue.Next.VehicleNo != report.VehicleNo {
+					t.Errorf("Issue %s compares trips of another vehicle", issue.Type)
+				}
+				switch issue.Type {
+				case "distance_gap", "distance_overlap", "time_overlap":
+				default:
+					t.Errorf("Unexpected issue type %q", issue.Type)
+				}
+			}
+		}
+	})
+
+	t.Run("Reject invalid tolerance", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/trips/odometer_continuity?tolerance_km=-1", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package contract
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// Contract test GET /dtako/trips/{unko_no}/track
+func TestGetTripTrack(t *testing.T) {
+	r := SetupTestRouter()
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		validateBody   func(*testing.T, []byte)
+	}{
+		{
+			name:           "GeoJSON track",
+			path:           "/dtako/trips/2024011501/track",
+			expectedStatus: http.StatusOK,
+			validateBody: func(t *testing.T, body []byte) {
+				var collection models.GeoJSONFeatureCollection
+				if err := json.Unmarshal(body, &collection); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if collection.Type != "FeatureCollection" {
+					t.Errorf("Expected FeatureCollection, got %s", collection.Type)
+				}
+				for i, f := range collection.Features {
+					if i == 0 && len(collection.Features) > 1 && f.Geometry.Type != "LineString" {
+						t.Errorf("Expected first feature to be LineString, got %s", f.Geometry.Type)
+					}
+					if i > 0 && f.Geometry.Type != "Point" {
+						t.Errorf("Expected Point feature, got %s", f.Geometry.Type)
+					}
+				}
+			},
+		},
+		{
+			name:           "GPX track",
+			path:           "/dtako/trips/2024011501/track?format=gpx",
+			expectedStatus: http.StatusOK,
+			validateBody: func(t *testing.T, body []byte) {
+				var gpx models.GPX
+				if err := xml.Unmarshal(body, &gpx); err != nil {
+					t.Fatalf("Failed to unmarshal GPX: %v", err)
+				}
+				if gpx.Version != "1.1" {
+					t.Errorf("Expected GPX version 1.1, got %s", gpx.Version)
+				}
+			},
+		},
+		{
+			name:           "Unknown format",
+			path:           "/dtako/trips/2024011501/track?format=kml",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Unknown trip",
+			path:           "/dtako/trips/NONEXISTENT/track",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d. Body: %s",
+					tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+
+			if tt.validateBody != nil && rec.Code == http.StatusOK {
+				tt.validateBody(t, rec.Body.Bytes())
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package contract
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// Contract test GET /dtako/validation/findings
+func TestGetValidationFindings(t *testing.T) {
+	r := SetupTestRouter()
+
+	t.Run("List findings for one table", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/validation/findings?from=2025-01-01&to=2025-01-31&table=dtako_rows", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+
+		var findings []models.ValidationFinding
+		if err := json.Unmarshal(rec.Body.Bytes(), &findings); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if findings == nil {
+			t.Error("Expected array response, got nil")
+		}
+		for _, f := range findings {
+			if f.Table != "dtako_rows" {
+				t.Errorf("Expected table dtako_rows, got %s", f.Table)
+			}
+		}
+	})
+
+	t.Run("Invalid date", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/validation/findings?from=2025-13-01", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
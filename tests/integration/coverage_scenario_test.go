@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// Integration test - Coverage of imported rows matches production per vehicle
+func TestCoverageAfterImportScenario(t *testing.T) {
+	t.Setenv("ACTIVE_VEHICLES", "")
+	r := SetupTestRouter()
+
+	// テスト用本番DBには 2024-01-15〜16 に車輌 101（2件）と 102（1件）の運行がある
+	body, _ := json.Marshal(models.ImportRequest{FromDate: "2024-01-15", ToDate: "2024-01-16"})
+	req := httptest.NewRequest("POST", "/dtako/rows/import", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Import failed with status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	t.Run("Imported rows keep the production vehicle codes", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/rows?from=2024-01-15&to=2024-01-16&vehicle=101", nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Query failed with status %d: %s", rec.Code, rec.Body.String())
+		}
+
+		var rows []models.DtakoRow
+		json.Unmarshal(rec.Body.Bytes(), &rows)
+		if len(rows) != 2 {
+			t.Errorf("Expected 2 rows of vehicle 101, got %d", len(rows))
+		}
+		for _, row := range rows {
+			if row.VehicleNo != "101" || row.DriverCode != "1001" {
+				t.Errorf("Expected vehicle 101 / driver 1001, got %s / %s", row.VehicleNo, row.DriverCode)
+			}
+		}
+	})
+
+	t.Run("Both vehicles are ok on the days they ran", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/coverage?from=2024-01-15&to=2024-01-16", nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Coverage failed with status %d: %s", rec.Code, rec.Body.String())
+		}
+
+		var report models.CoverageReport
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+
+		want := map[string][]string{
+			"101": {"ok", "ok"},
+			"102": {"ok", "none"},
+		}
+		for _, v := range report.Vehicles {
+			for _, d := range v.Days {
+				if d.Status == "not_imported" || d.Status == "local_only" {
+					t.Errorf("Vehicle %s on %s: unexpected status %s (local %d)", v.VehicleNo, d.Date, d.Status, d.Local)
+				}
+			}
+			statuses, ok := want[v.VehicleNo]
+			if !ok {
+				continue
+			}
+			delete(want, v.VehicleNo)
+			for i, d := range v.Days {
+				if d.Status != statuses[i] {
+					t.Errorf("Vehicle %s on %s: expected %s, got %s", v.VehicleNo, d.Date, statuses[i], d.Status)
+				}
+			}
+		}
+		for vehicle := range want {
+			t.Errorf("Vehicle %s missing from the coverage report", vehicle)
+		}
+	})
+}
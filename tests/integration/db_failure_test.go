@@ -38,7 +38,7 @@ func TestProductionDBFailure(t *testing.T) {
 		
 		r.ServeHTTP(rec, req)
 		
-		// Should return 200 with success:false or 500 error
+		// Should return 200 with success:false or 503 (upstream unavailable)
 		if rec.Code == http.StatusOK {
 			// If 200, check for success:false
 			var result models.ImportResult
@@ -47,8 +47,8 @@ func TestProductionDBFailure(t *testing.T) {
 			} else if result.Success {
 				t.Error("Expected success:false when production DB is unavailable")
 			}
-		} else if rec.Code != http.StatusInternalServerError {
-			t.Errorf("Expected 200 or 500, got %d", rec.Code)
+		} else if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected 200 or 503, got %d", rec.Code)
 		}
 		
 		// Should return meaningful message
@@ -77,8 +77,8 @@ func TestProductionDBFailure(t *testing.T) {
 				} else if result.Success {
 					t.Errorf("Expected success:false for %s when production DB is unavailable", endpoint)
 				}
-			} else if rec.Code != http.StatusInternalServerError {
-				t.Errorf("Expected 200 or 500 for %s, got %d", endpoint, rec.Code)
+			} else if rec.Code != http.StatusServiceUnavailable {
+				t.Errorf("Expected 200 or 503 for %s, got %d", endpoint, rec.Code)
 			}
 		}
 		
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -31,6 +32,11 @@ func TestMain(m *testing.M) {
 		os.Setenv("LOCAL_DB_CHARSET", "utf8mb4")
 	}
 	
+	// サーバーのTZに依存しないことを確認するため、JST以外のローカルタイムゾーンで実行する
+	if loc, err := time.LoadLocation("America/Los_Angeles"); err == nil {
+		time.Local = loc
+	}
+
 	// Run tests
 	code := m.Run()
 	
@@ -0,0 +1,77 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// Integration test - Integrity report and fetching missing parent rows
+func TestIntegrityScenario(t *testing.T) {
+	r := SetupTestRouter()
+
+	t.Run("Report then fetch missing parents", func(t *testing.T) {
+		// Step 1: Import events only, so their 運行NO may have no row
+		importReq := models.ImportRequest{FromDate: "2025-01-01", ToDate: "2025-01-31"}
+		bodyBytes, _ := json.Marshal(importReq)
+		req := httptest.NewRequest("POST", "/dtako/events/import", bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Import failed with status %d: %s", rec.Code, rec.Body.String())
+		}
+
+		// Step 2: Report
+		req = httptest.NewRequest("GET", "/dtako/integrity?from=2025-01-01&to=2025-01-31", nil)
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Report failed with status %d: %s", rec.Code, rec.Body.String())
+		}
+
+		var before models.IntegrityReport
+		if err := json.Unmarshal(rec.Body.Bytes(), &before); err != nil {
+			t.Fatalf("Failed to unmarshal report: %v", err)
+		}
+		// 孤立イベントとして報告された運行NOは dtako_rows に存在しない
+		for _, o := range before.OrphanEvents {
+			if o.Records < 1 || o.LastAt.Before(o.FirstAt) {
+				t.Errorf("Orphan %s: invalid records %d or range %v - %v", o.UnkoNo, o.Records, o.FirstAt, o.LastAt)
+			}
+			req := httptest.NewRequest("GET", "/dtako/rows?from=2000-01-01&to=2099-12-31&unko_no="+url.QueryEscape(o.UnkoNo), nil)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			var rows []models.DtakoRow
+			json.Unmarshal(rec.Body.Bytes(), &rows)
+			if rec.Code != http.StatusOK || len(rows) != 0 {
+				t.Errorf("Orphan %s: expected no rows, got status %d with %d rows", o.UnkoNo, rec.Code, len(rows))
+			}
+		}
+
+		// Step 3: Fetch missing parents from production
+		req = httptest.NewRequest("POST", "/dtako/integrity/fetch_missing", bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Fetch missing failed with status %d: %s", rec.Code, rec.Body.String())
+		}
+
+		var after models.IntegrityReport
+		if err := json.Unmarshal(rec.Body.Bytes(), &after); err != nil {
+			t.Fatalf("Failed to unmarshal report: %v", err)
+		}
+		if after.Fetched == nil {
+			t.Fatal("Expected fetched import result")
+		}
+		if len(after.OrphanEvents) > len(before.OrphanEvents) {
+			t.Errorf("Orphaned events grew from %d to %d after fetching parents", len(before.OrphanEvents), len(after.OrphanEvents))
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yhonda-ohishi/dtako_mod/models"
+)
+
+// Integration test - Shared vehicle/driver/office filters across list endpoints
+func TestListFilterScenario(t *testing.T) {
+	r := SetupTestRouter()
+
+	t.Run("Multi-value vehicle filter on rows", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/rows?from=2024-01-01&to=2024-01-31&vehicle=1,2", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Query failed with status %d: %s", rec.Code, rec.Body.String())
+		}
+
+		var rows []models.DtakoRow
+		json.Unmarshal(rec.Body.Bytes(), &rows)
+		for _, row := range rows {
+			if row.VehicleNo != "1" && row.VehicleNo != "2" {
+				t.Errorf("Expected vehicle 1 or 2, got %s", row.VehicleNo)
+			}
+		}
+	})
+
+	t.Run("Repeated parameter equals comma separated", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/ferry_rows?from=2024-01-01&to=2024-01-31&vehicle=101&vehicle=102", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Query failed with status %d: %s", rec.Code, rec.Body.String())
+		}
+
+		var records []models.DtakoFerryRow
+		json.Unmarshal(rec.Body.Bytes(), &records)
+		for _, record := range records {
+			if record.VehicleCode != 101 && record.VehicleCode != 102 {
+				t.Errorf("Expected vehicle 101 or 102, got %d", record.VehicleCode)
+			}
+		}
+	})
+
+	t.Run("Invalid filters are rejected", func(t *testing.T) {
+		endpoints := []string{
+			"/dtako/rows?vehicle=abc",             // numeric column
+			"/dtako/rows?ferry_company=東京フェリー",    // not a rows filter
+			"/dtako/events?boarding=1",            // not an events filter
+			"/dtako/ferry_rows?office=1%20OR%201", // injection attempt
+		}
+
+		for _, endpoint := range endpoints {
+			req := httptest.NewRequest("GET", endpoint, nil)
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected 400 for %s, got %d", endpoint, rec.Code)
+			}
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Integration test - Sorting and sparse fieldsets on list endpoints
+func TestListSortFieldsScenario(t *testing.T) {
+	r := SetupTestRouter()
+
+	t.Run("Fields narrow the JSON output", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/rows?from=2024-01-01&to=2024-01-31&fields=id,unko_no,date", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Query failed with status %d: %s", rec.Code, rec.Body.String())
+		}
+
+		var rows []map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &rows); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		for _, row := range rows {
+			for key := range row {
+				if key != "id" && key != "unko_no" && key != "date" {
+					t.Errorf("Unexpected field %q in projected row", key)
+				}
+			}
+		}
+	})
+
+	t.Run("Sort ascending by date", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dtako/rows?from=2024-01-01&to=2024-01-31&sort=date,id&fields=id,date", nil)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Query failed with status %d: %s", rec.Code, rec.Body.String())
+		}
+
+		var rows []struct {
+			Date string `json:"date"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &rows)
+		for i := 1; i < len(rows); i++ {
+			if rows[i].Date < rows[i-1].Date {
+				t.Errorf("Rows not sorted ascending: %s before %s", rows[i-1].Date, rows[i].Date)
+			}
+		}
+	})
+
+	t.Run("Unknown or non-sortable fields are rejected", func(t *testing.T) {
+		endpoints := []string{
+			"/dtako/rows?sort=created_at",         // not sortable
+			"/dtako/rows?fields=id,password",      // unknown field
+			"/dtako/events?sort=description",      // not sortable
+			"/dtako/ferry_rows?sort=-id;DROP%20x", // injection attempt
+		}
+
+		for _, endpoint := range endpoints {
+			req := httptest.NewRequest("GET", endpoint, nil)
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected 400 for %s, got %d", endpoint, rec.Code)
+			}
+		}
+	})
+}
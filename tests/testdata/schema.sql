@@ -69,4 +69,136 @@ CREATE TABLE IF NOT EXISTS dtako_ferry_rows (
     INDEX idx_unko_no (運行NO),
     INDEX idx_unko_date (運行日),
     INDEX idx_ferry_company (フェリー会社名)
-);
\ No newline at end of file
+);
+-- Schema for dtako_geofences table
+CREATE TABLE IF NOT EXISTS dtako_geofences (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    category VARCHAR(20) NOT NULL,
+    shape VARCHAR(10) NOT NULL,
+    center_lat DOUBLE,
+    center_lng DOUBLE,
+    radius_m DOUBLE,
+    polygon TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_category (category)
+);
+
+-- Schema for dtako_validation_findings table
+CREATE TABLE IF NOT EXISTS dtako_validation_findings (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    table_name VARCHAR(64) NOT NULL,
+    record_id VARCHAR(255) NOT NULL,
+    rule VARCHAR(64) NOT NULL,
+    severity VARCHAR(10) NOT NULL,
+    message TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_created_at (created_at),
+    INDEX idx_table_rule (table_name, rule)
+);
+
+-- Schema for dtako_vehicles table
+CREATE TABLE IF NOT EXISTS dtako_vehicles (
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    office_code VARCHAR(50),
+    active TINYINT(1) NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_office (office_code)
+);
+
+-- Schema for dtako_drivers table
+CREATE TABLE IF NOT EXISTS dtako_drivers (
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    office_code VARCHAR(50),
+    active TINYINT(1) NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_office (office_code)
+);
+
+-- Schema for dtako_offices table
+CREATE TABLE IF NOT EXISTS dtako_offices (
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    active TINYINT(1) NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+-- Schema for dtako_ferry_companies table
+CREATE TABLE IF NOT EXISTS dtako_ferry_companies (
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    active TINYINT(1) NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+-- Schema for dtako_ports table
+CREATE TABLE IF NOT EXISTS dtako_ports (
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    active TINYINT(1) NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+-- Schema for dtako_event_types table
+CREATE TABLE IF NOT EXISTS dtako_event_types (
+    name VARCHAR(50) PRIMARY KEY,
+    category VARCHAR(20) NOT NULL DEFAULT 'other',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_category (category)
+);
+
+INSERT IGNORE INTO dtako_event_types (name, category) VALUES
+    ('運転', 'driving'),
+    ('休憩', 'rest'),
+    ('休息', 'rest'),
+    ('フェリー', 'rest'),
+    ('作業', 'work'),
+    ('積み', 'work'),
+    ('降し', 'work'),
+    ('待機', 'waiting'),
+    ('START', 'other'),
+    ('STOP', 'other'),
+    ('END', 'other');
+
+-- Schema for dtako_payroll_summaries table
+CREATE TABLE IF NOT EXISTS dtako_payroll_summaries (
+    month CHAR(7) NOT NULL,
+    driver_code VARCHAR(50) NOT NULL,
+    working_days INT NOT NULL DEFAULT 0,
+    binding_minutes DOUBLE NOT NULL DEFAULT 0,
+    driving_minutes DOUBLE NOT NULL DEFAULT 0,
+    night_minutes DOUBLE NOT NULL DEFAULT 0,
+    overtime_minutes DOUBLE NOT NULL DEFAULT 0,
+    distance DOUBLE NOT NULL DEFAULT 0,
+    ferry_trips INT NOT NULL DEFAULT 0,
+    stale TINYINT(1) NOT NULL DEFAULT 0,
+    computed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (month, driver_code)
+);
+
+-- Schema for dtako_payroll_versions table
+CREATE TABLE IF NOT EXISTS dtako_payroll_versions (
+    month CHAR(7) PRIMARY KEY,
+    version BIGINT NOT NULL DEFAULT 0
+);
+
+-- Schema for dtako_audit_log table
+CREATE TABLE IF NOT EXISTS dtako_audit_log (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    action VARCHAR(64) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    actor_role VARCHAR(20),
+    target VARCHAR(255),
+    params TEXT,
+    success TINYINT(1) NOT NULL,
+    affected_rows INT NOT NULL DEFAULT 0,
+    rejected_rows INT NOT NULL DEFAULT 0,
+    failed_rows INT NOT NULL DEFAULT 0,
+    errors TEXT,
+    request_id VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_created_at (created_at)
+);
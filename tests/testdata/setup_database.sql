@@ -16,6 +16,7 @@ CREATE TABLE IF NOT EXISTS dtako_rows (
     乗務員CD1 INT,
     対象乗務員区分 INT NOT NULL DEFAULT 0,
     対象乗務員CD INT NOT NULL DEFAULT 0,
+    事業所CD INT NOT NULL DEFAULT 0,
     出社日時 DATETIME NOT NULL DEFAULT '2000-01-01 00:00:00',
     退社日時 DATETIME NOT NULL DEFAULT '2000-01-01 00:00:00',
     出庫日時 DATETIME NOT NULL DEFAULT '2000-01-01 00:00:00',
@@ -56,7 +57,10 @@ CREATE TABLE IF NOT EXISTS dtako_rows (
     経済評価点 INT,
     INDEX idx_運行日 (運行日),
     INDEX idx_運行NO (運行NO),
-    INDEX idx_車輌CD (車輌CD)
+    INDEX idx_車輌CD (車輌CD),
+    INDEX idx_対象乗務員CD (対象乗務員CD),
+    INDEX idx_事業所CD (事業所CD),
+    INDEX idx_行先市町村名 (行先市町村名)
 );
 
 -- dtako_events テーブル
@@ -111,7 +115,12 @@ CREATE TABLE IF NOT EXISTS dtako_ferry_rows (
     ferry_srch VARCHAR(60) DEFAULT NULL,
     INDEX idx_unko_no (運行NO),
     INDEX idx_unko_date (運行日),
-    INDEX idx_ferry_company (フェリー会社名)
+    INDEX idx_ferry_company (フェリー会社名),
+    INDEX idx_vehicle (車輌CD),
+    INDEX idx_driver (乗務員CD1),
+    INDEX idx_office (事業所CD),
+    INDEX idx_boarding (乗場CD),
+    INDEX idx_landing (降場CD)
 );
 
 -- テストデータの投入（既存データがある場合は置き換え）
@@ -166,6 +175,7 @@ CREATE TABLE IF NOT EXISTS dtako_rows (
     乗務員CD1 INT,
     対象乗務員区分 INT NOT NULL DEFAULT 0,
     対象乗務員CD INT NOT NULL DEFAULT 0,
+    事業所CD INT NOT NULL DEFAULT 0,
     出社日時 DATETIME NOT NULL DEFAULT '2000-01-01 00:00:00',
     退社日時 DATETIME NOT NULL DEFAULT '2000-01-01 00:00:00',
     出庫日時 DATETIME NOT NULL DEFAULT '2000-01-01 00:00:00',
@@ -206,7 +216,10 @@ CREATE TABLE IF NOT EXISTS dtako_rows (
     経済評価点 INT,
     INDEX idx_運行日 (運行日),
     INDEX idx_運行NO (運行NO),
-    INDEX idx_車輌CD (車輌CD)
+    INDEX idx_車輌CD (車輌CD),
+    INDEX idx_対象乗務員CD (対象乗務員CD),
+    INDEX idx_事業所CD (事業所CD),
+    INDEX idx_行先市町村名 (行先市町村名)
 );
 
 -- dtako_events テーブル
@@ -261,7 +274,12 @@ CREATE TABLE IF NOT EXISTS dtako_ferry_rows (
     ferry_srch VARCHAR(60) DEFAULT NULL,
     INDEX idx_unko_no (運行NO),
     INDEX idx_unko_date (運行日),
-    INDEX idx_ferry_company (フェリー会社名)
+    INDEX idx_ferry_company (フェリー会社名),
+    INDEX idx_vehicle (車輌CD),
+    INDEX idx_driver (乗務員CD1),
+    INDEX idx_office (事業所CD),
+    INDEX idx_boarding (乗場CD),
+    INDEX idx_landing (降場CD)
 );
 
 -- テストデータの投入（既存データがある場合は置き換え）
@@ -1,14 +1,14 @@
 -- Test data for dtako_rows
 INSERT INTO dtako_rows (id, unko_no, date, vehicle_no, driver_code, route_code, distance, fuel_amount) VALUES
-('ROW001', '2024011501', '2024-01-15', 'V001', 'D001', 'R001', 150.5, 20.3),
-('ROW002', '2024011502', '2024-01-15', 'V002', 'D002', 'R002', 200.8, 25.7),
-('ROW003', '2024011601', '2024-01-16', 'V001', 'D001', 'R003', 175.2, 22.1);
+('ROW001', '2024011501', '2024-01-15', '101', '1001', 'R001', 150.5, 20.3),
+('ROW002', '2024011502', '2024-01-15', '102', '1002', 'R002', 200.8, 25.7),
+('ROW003', '2024011601', '2024-01-16', '101', '1001', 'R003', 175.2, 22.1);
 
 -- Test data for dtako_events
 INSERT INTO dtako_events (id, unko_no, event_date, event_type, vehicle_no, driver_code, description, latitude, longitude) VALUES
-('EVENT001', '2024011501', '2024-01-15 08:30:00', 'START', 'V001', 'D001', 'Trip started', 35.6762, 139.6503),
-('EVENT002', '2024011501', '2024-01-15 12:15:00', 'STOP', 'V001', 'D001', 'Lunch break', 35.6895, 139.6917),
-('EVENT003', '2024011501', '2024-01-15 16:45:00', 'END', 'V001', 'D001', 'Trip ended', 35.6762, 139.6503);
+('EVENT001', '2024011501', '2024-01-15 08:30:00', 'START', '101', '1001', 'Trip started', 35.6762, 139.6503),
+('EVENT002', '2024011501', '2024-01-15 12:15:00', 'STOP', '101', '1001', 'Lunch break', 35.6895, 139.6917),
+('EVENT003', '2024011501', '2024-01-15 16:45:00', 'END', '101', '1001', 'Trip ended', 35.6762, 139.6503);
 
 -- Test data for dtako_ferry_rows
 INSERT INTO dtako_ferry_rows (運行NO, 運行日, 読取日, 事業所CD, 事業所名, 車輌CD, 車輌名, 乗務員CD1, 乗務員名１, 対象乗務員区分, 開始日時, 終了日時, フェリー会社CD, フェリー会社名, 乗場CD, 乗場名, 便, 降場CD, 降場名, 精算区分, 精算区分名, 標準料金, 契約料金, 航送車種区分, 航送車種区分名, 見なし距離, ferry_srch) VALUES
@@ -0,0 +1,117 @@
+// Package tracing provides OpenTelemetry spans for dtako_mod handlers,
+// services and SQL calls. Spans are created with the global tracer
+// provider, so a host application that already configured OpenTelemetry
+// gets them in its own pipeline; otherwise Init installs an OTLP or stdout
+// exporter selected by OTEL_TRACES_EXPORTER.
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/yhonda-ohishi/dtako_mod"
+
+// DefaultServiceName is used when OTEL_SERVICE_NAME is not set
+const DefaultServiceName = "dtako_mod"
+
+// Tracer returns the dtako_mod tracer of the global provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start starts a span named name as a child of the span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err (if any) on span and ends it. Use it with a named error
+// result: defer tracing.End(span, &err). sql.ErrNoRows is a normal "not
+// found" outcome and is not recorded.
+func End(span trace.Span, err *error) {
+	if err != nil && *err != nil && !errors.Is(*err, sql.ErrNoRows) {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
+
+// DateRange returns the attributes of a from/to date range
+func DateRange(from, to time.Time) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("dtako.from", from.Format("2006-01-02")),
+		attribute.String("dtako.to", to.Format("2006-01-02")),
+	}
+}
+
+// Period returns the attributes of a requested from/to period as given
+// (before parsing or defaulting)
+func Period(from, to string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("dtako.from", from),
+		attribute.String("dtako.to", to),
+	}
+}
+
+// Rows sets the number of rows read or written on span
+func Rows(span trace.Span, key string, n int) {
+	span.SetAttributes(attribute.Int("dtako.rows."+key, n))
+}
+
+// Init installs a global tracer provider and W3C trace context propagation.
+// OTEL_TRACES_EXPORTER selects the exporter:
+//   - otlp: OTLP over HTTP, configured by the standard OTEL_EXPORTER_OTLP_* variables
+//   - stdout: pretty-printed spans on stdout (for development)
+//   - none or unset: no provider is installed; spans are no-ops unless the host set one
+//
+// The returned function flushes and stops the exporter.
+func Init(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagator)
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch name := strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")); name {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "stdout", "console":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unsupported OTEL_TRACES_EXPORTER: %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}